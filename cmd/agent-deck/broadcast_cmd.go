@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleBroadcast sends one prompt to many sessions at once and reports
+// their replies side by side
+func handleBroadcast(profile string, args []string) {
+	fs := flag.NewFlagSet("broadcast", flag.ExitOnError)
+	ids := fs.String("ids", "", "Sessions to ask, comma-separated (IDs, titles or paths)")
+	format := fs.String("format", session.ReportText, "Report format: text, md or json")
+	jsonOutput := fs.Bool("json", false, "Output as JSON (same as --format json)")
+	output := fs.String("output", "", "Write the report to this file instead of stdout")
+	outputShort := fs.String("o", "", "Write the report to this file instead of stdout (short)")
+	timeout := fs.Duration("timeout", 10*time.Minute, "How long to wait for the replies")
+	quiet := fs.Bool("quiet", false, "No progress output")
+	quietShort := fs.Bool("q", false, "No progress output (short)")
+	sel := addSelectorFlags(fs)
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck broadcast [--group <path>] [--status <status>] [--filter <text>] <prompt>")
+		fmt.Println("       agent-deck broadcast --ids <id,id,...> <prompt>")
+		fmt.Println()
+		fmt.Println("Send a prompt to several running sessions at once, wait for each agent to")
+		fmt.Println("answer and print a report with every reply, how long it took and the")
+		fmt.Println("status the session was left in. Stopped sessions are skipped.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Exit codes: 0 all replied, 3 some timed out, 4 an agent stopped, errored or")
+		fmt.Println("needs permission, 5 the prompt couldn't be sent to a session (the report is")
+		fmt.Println("printed either way).")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck broadcast --group forks \"which approach did you take, and why?\"")
+		fmt.Println("  agent-deck broadcast --group repos --format md -o review.md \"review the open diff\"")
+		fmt.Println("  agent-deck broadcast --ids api,web --json \"run the tests\" | jq '.results[].status'")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		*format = session.ReportJSON
+	}
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*format == session.ReportJSON, quietMode)
+
+	prompt := strings.Join(fs.Args(), " ")
+	if prompt == "" || (*ids == "") == !sel.active() {
+		out.Error("usage: agent-deck broadcast --group <path> | --ids <id,...> <prompt>", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	// Checked before asking, so a typo doesn't throw the replies away
+	if err := session.ValidateReportFormat(*format); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	// Load sessions
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	var targets []*session.Instance
+	if *ids != "" {
+		seen := make(map[string]bool)
+		for _, ref := range strings.Split(*ids, ",") {
+			inst, errMsg, errCode := ResolveSession(strings.TrimSpace(ref), instances)
+			if inst == nil {
+				out.Error(errMsg, errCode)
+				if errCode == ErrCodeNotFound {
+					os.Exit(2)
+				}
+				os.Exit(1)
+			}
+			if !seen[inst.ID] {
+				seen[inst.ID] = true
+				targets = append(targets, inst)
+			}
+		}
+	} else {
+		targets = selectBulkTargets(out, sel, "", instances)
+	}
+
+	var running []*session.Instance
+	for _, inst := range targets {
+		if inst.Exists() {
+			running = append(running, inst)
+		} else if !quietMode {
+			fmt.Fprintf(os.Stderr, "%s Skipping %s: not running\n", bulletSymbol, inst.Title)
+		}
+	}
+	if len(running) == 0 {
+		out.Error("none of the selected sessions is running", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	// Progress goes to stderr so the report can be piped
+	var mu sync.Mutex
+	finished := 0
+	done := func(result session.BroadcastResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		finished++
+		if quietMode {
+			return
+		}
+		symbol := successSymbol
+		if result.Error != "" {
+			symbol = errorSymbol
+		}
+		fmt.Fprintf(os.Stderr, "%s [%d/%d] %s (%s)\n", symbol, finished, len(running), result.Title,
+			result.Duration.Round(time.Second))
+	}
+	if !quietMode {
+		fmt.Fprintf(os.Stderr, "Asking %s...\n", pluralSessions(len(running)))
+	}
+	report := session.Broadcast(ctx, running, prompt, done)
+
+	rendered, err := report.Render(*format)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	path := mergeFlags(*output, *outputShort)
+	if path == "" {
+		fmt.Print(rendered)
+	} else if err := os.WriteFile(path, []byte(rendered), 0644); err != nil {
+		out.Error(fmt.Sprintf("failed to write %s: %v", path, err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	} else if !quietMode {
+		fmt.Fprintf(os.Stderr, "%s Wrote the report to %s\n", successSymbol, path)
+	}
+
+	if code := broadcastExitCode(report.Results); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// exitBroadcastSendFailed is broadcast's exit code when a prompt couldn't be
+// sent; timeouts and agent errors exit as session ask does
+const exitBroadcastSendFailed = 5
+
+// broadcastExitCode returns the exit code for a broadcast's results: the
+// first failure's, or a timeout's only if nothing worse happened
+func broadcastExitCode(results []session.BroadcastResult) int {
+	code := 0
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		c := askExitCode(result.Err)
+		if c == 1 {
+			c = exitBroadcastSendFailed
+		}
+		if code == 0 || code == exitAskTimeout {
+			code = c
+		}
+	}
+	return code
+}
+
+// pluralSessions formats a number of sessions, e.g. "1 session" or "3 sessions"
+func pluralSessions(n int) string {
+	if n == 1 {
+		return "1 session"
+	}
+	return fmt.Sprintf("%d sessions", n)
+}
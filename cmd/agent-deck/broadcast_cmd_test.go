@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+func TestBroadcastExitCode(t *testing.T) {
+	replied := session.BroadcastResult{}
+	timedOut := session.BroadcastResult{Err: session.ErrTurnTimeout}
+	stopped := session.BroadcastResult{Err: session.ErrAgentStopped}
+	notSent := session.BroadcastResult{Err: errors.New("failed to send keys")}
+
+	tests := []struct {
+		name    string
+		results []session.BroadcastResult
+		want    int
+	}{
+		{"all replied", []session.BroadcastResult{replied, replied}, 0},
+		{"timeout", []session.BroadcastResult{replied, timedOut}, exitAskTimeout},
+		{"agent error beats timeout", []session.BroadcastResult{timedOut, stopped}, exitAskAgentError},
+		{"send failed", []session.BroadcastResult{replied, notSent}, exitBroadcastSendFailed},
+		{"send failure beats timeout", []session.BroadcastResult{timedOut, notSent}, exitBroadcastSendFailed},
+		{"first failure wins", []session.BroadcastResult{stopped, notSent}, exitAskAgentError},
+	}
+	for _, tt := range tests {
+		if got := broadcastExitCode(tt.results); got != tt.want {
+			t.Errorf("%s: broadcastExitCode = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
@@ -182,6 +183,110 @@ func ResolveSessionOrCurrent(identifier string, instances []*session.Instance) (
 	return ResolveSession(identifier, instances)
 }
 
+// sessionSelector picks the sessions a bulk command acts on (session
+// stop|restart|send). A session must match every selector given.
+type sessionSelector struct {
+	group  string
+	status string
+	filter string
+}
+
+// addSelectorFlags registers --group, --status and --filter on fs
+func addSelectorFlags(fs *flag.FlagSet) *sessionSelector {
+	sel := &sessionSelector{}
+	fs.StringVar(&sel.group, "group", "", "Select sessions in this group path (including subgroups)")
+	fs.StringVar(&sel.status, "status", "", "Select sessions by status, comma-separated (running, waiting, idle, error, approval)")
+	fs.StringVar(&sel.filter, "filter", "", "Select sessions whose title or path contains this text")
+	return sel
+}
+
+// active reports whether any selector was given
+func (sel *sessionSelector) active() bool {
+	return sel.group != "" || sel.status != "" || sel.filter != ""
+}
+
+// selectSessions returns the sessions matching the selectors
+func (sel *sessionSelector) selectSessions(instances []*session.Instance) ([]*session.Instance, error) {
+	statuses := make(map[session.Status]bool)
+	if sel.status != "" {
+		for _, name := range strings.Split(sel.status, ",") {
+			status := session.Status(strings.TrimSpace(name))
+			switch status {
+			case session.StatusRunning, session.StatusWaiting, session.StatusIdle,
+				session.StatusError, session.StatusApproval, session.StatusStarting:
+				statuses[status] = true
+			default:
+				return nil, fmt.Errorf("unknown status '%s' (valid: running, waiting, idle, error, approval, starting)", name)
+			}
+		}
+	}
+	group := strings.Trim(sel.group, "/")
+	filter := strings.ToLower(sel.filter)
+
+	var selected []*session.Instance
+	for _, inst := range instances {
+		if group != "" && inst.GroupPath != group && !strings.HasPrefix(inst.GroupPath, group+"/") {
+			continue
+		}
+		if len(statuses) > 0 && !statuses[inst.Status] {
+			continue
+		}
+		if filter != "" && !strings.Contains(strings.ToLower(inst.Title), filter) &&
+			!strings.Contains(strings.ToLower(inst.ProjectPath), filter) {
+			continue
+		}
+		selected = append(selected, inst)
+	}
+	return selected, nil
+}
+
+// bulkResult is one session's outcome in a bulk command
+type bulkResult struct {
+	inst    *session.Instance
+	err     error
+	skipped string // Why the session was left alone ("" if acted on)
+}
+
+// printBulkResults reports a bulk command's outcomes ("Stopped", ...) and
+// returns whether none failed
+func printBulkResults(out *CLIOutput, verb string, results []bulkResult) bool {
+	var human strings.Builder
+	items := make([]map[string]interface{}, 0, len(results))
+	done, failed := 0, 0
+	for _, r := range results {
+		item := map[string]interface{}{
+			"id":      r.inst.ID,
+			"title":   r.inst.Title,
+			"success": r.err == nil && r.skipped == "",
+		}
+		switch {
+		case r.err != nil:
+			failed++
+			item["error"] = r.err.Error()
+			fmt.Fprintf(&human, "%s %s: %v\n", errorSymbol, r.inst.Title, r.err)
+		case r.skipped != "":
+			item["skipped"] = r.skipped
+			fmt.Fprintf(&human, "%s %s: skipped (%s)\n", bulletSymbol, r.inst.Title, r.skipped)
+		default:
+			done++
+			fmt.Fprintf(&human, "%s %s: %s\n", successSymbol, verb, r.inst.Title)
+		}
+		items = append(items, item)
+	}
+	fmt.Fprintf(&human, "\n%s %d of %d sessions", verb, done, len(results))
+	if failed > 0 {
+		fmt.Fprintf(&human, " (%d failed)", failed)
+	}
+	human.WriteString("\n")
+
+	out.Print(human.String(), map[string]interface{}{
+		"success": failed == 0,
+		"count":   done,
+		"results": items,
+	})
+	return failed == 0
+}
+
 // StatusSymbol returns the symbol for a status
 func StatusSymbol(status session.Status) string {
 	switch status {
@@ -189,6 +294,8 @@ func StatusSymbol(status session.Status) string {
 		return "●"
 	case session.StatusWaiting:
 		return "◐"
+	case session.StatusApproval:
+		return "◆"
 	case session.StatusIdle:
 		return "○"
 	case session.StatusError:
@@ -205,6 +312,8 @@ func StatusString(status session.Status) string {
 		return "running"
 	case session.StatusWaiting:
 		return "waiting"
+	case session.StatusApproval:
+		return "approval"
 	case session.StatusIdle:
 		return "idle"
 	case session.StatusError:
@@ -0,0 +1,8 @@
+package main
+
+import "syscall"
+
+// detachedProcAttr is a no-op on Windows (agent-deck requires tmux)
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
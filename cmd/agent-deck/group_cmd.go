@@ -98,10 +98,11 @@ func handleGroupList(profile string, args []string) {
 	if *jsonOutput {
 		// Build JSON output structure
 		type groupStatusJSON struct {
-			Running int `json:"running"`
-			Waiting int `json:"waiting"`
-			Idle    int `json:"idle"`
-			Error   int `json:"error"`
+			Running  int `json:"running"`
+			Waiting  int `json:"waiting"`
+			Approval int `json:"approval"`
+			Idle     int `json:"idle"`
+			Error    int `json:"error"`
 		}
 
 		type groupJSON struct {
@@ -123,6 +124,8 @@ func handleGroupList(profile string, args []string) {
 					status.Running++
 				case session.StatusWaiting:
 					status.Waiting++
+				case session.StatusApproval:
+					status.Approval++
 				case session.StatusIdle:
 					status.Idle++
 				case session.StatusError:
@@ -238,7 +241,7 @@ func handleGroupList(profile string, args []string) {
 		sessCount := len(g.Sessions)
 		statusStr := ""
 		if sessCount > 0 {
-			running, waiting, idle := 0, 0, 0
+			running, waiting, approval, idle := 0, 0, 0, 0
 			for _, sess := range g.Sessions {
 				_ = sess.UpdateStatus()
 				switch sess.Status {
@@ -246,11 +249,16 @@ func handleGroupList(profile string, args []string) {
 					running++
 				case session.StatusWaiting:
 					waiting++
+				case session.StatusApproval:
+					approval++
 				case session.StatusIdle:
 					idle++
 				}
 			}
 			var parts []string
+			if approval > 0 {
+				parts = append(parts, fmt.Sprintf("◆ %d", approval))
+			}
 			if running > 0 {
 				parts = append(parts, fmt.Sprintf("● %d", running))
 			}
@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -214,6 +215,24 @@ func main() {
 		case "group":
 			handleGroup(profile, args[1:])
 			return
+		case "template":
+			handleTemplate(profile, args[1:])
+			return
+		case "apply":
+			handleApply(profile, args[1:])
+			return
+		case "export":
+			handleExport(profile, args[1:])
+			return
+		case "undo":
+			handleUndo(profile, args[1:])
+			return
+		case "trash":
+			handleTrash(profile, args[1:])
+			return
+		case "broadcast":
+			handleBroadcast(profile, args[1:])
+			return
 		}
 	}
 
@@ -268,6 +287,9 @@ func main() {
 		log.SetOutput(io.Discard)
 	}
 
+	// Notify about background status changes ([notifications] in config.toml)
+	session.SetStatusNotifier(session.NewNotifier())
+
 	// Start TUI with the specified profile
 	p := tea.NewProgram(
 		ui.NewHomeWithProfile(profile),
@@ -325,6 +347,10 @@ func handleAdd(profile string, args []string) {
 	commandShort := fs.String("c", "", "Command to run (short)")
 	parent := fs.String("parent", "", "Parent session (creates sub-session, inherits group)")
 	parentShort := fs.String("p", "", "Parent session (short)")
+	worktree := fs.Bool("worktree", false, "Run the session in a new git worktree")
+	worktreeShort := fs.Bool("w", false, "Run the session in a new git worktree (short)")
+	branch := fs.String("branch", "", "Branch for --worktree (default: agent-deck/<title>)")
+	templateName := fs.String("template", "", "Session template from config.toml (see 'agent-deck template list')")
 
 	// MCP flag - can be specified multiple times
 	var mcpFlags []string
@@ -352,6 +378,8 @@ func handleAdd(profile string, args []string) {
 		fmt.Println("  agent-deck -p work add               # Add to 'work' profile")
 		fmt.Println("  agent-deck add -t \"Sub-task\" --parent \"Main Project\"  # Create sub-session")
 		fmt.Println("  agent-deck add -t \"Research\" -c claude --mcp memory --mcp sequential-thinking /tmp/x")
+		fmt.Println("  agent-deck add -t \"Refactor\" -c claude -w .   # Isolated git worktree")
+		fmt.Println("  agent-deck add --template review -w .          # From a template")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -392,6 +420,36 @@ func handleAdd(profile string, args []string) {
 	sessionGroup := mergeFlags(*group, *groupShort)
 	sessionCommand := mergeFlags(*command, *commandShort)
 	sessionParent := mergeFlags(*parent, *parentShort)
+	useWorktree := *worktree || *worktreeShort
+	if *branch != "" && !useWorktree {
+		fmt.Println("Error: --branch requires --worktree")
+		os.Exit(1)
+	}
+
+	// Resolve the template; explicit flags take precedence over it
+	var tmpl *session.TemplateDef
+	if *templateName != "" {
+		tmpl = session.GetTemplate(*templateName)
+		if tmpl == nil {
+			fmt.Printf("Error: template '%s' not found in config.toml\n", *templateName)
+			if names := session.GetTemplateNames(); len(names) > 0 {
+				fmt.Printf("\nAvailable templates: %s\n", strings.Join(names, ", "))
+			}
+			os.Exit(1)
+		}
+		if err := tmpl.Validate(); err != nil {
+			fmt.Printf("Error: template '%s': %v\n", *templateName, err)
+			os.Exit(1)
+		}
+		if sessionGroup == "" {
+			sessionGroup = tmpl.Group
+		}
+		for _, name := range tmpl.MCPs {
+			if !slices.Contains(mcpFlags, name) {
+				mcpFlags = append(mcpFlags, name)
+			}
+		}
+	}
 
 	// Default title to folder name
 	if sessionTitle == "" {
@@ -429,11 +487,13 @@ func handleAdd(profile string, args []string) {
 		sessionGroup = parentInstance.GroupPath
 	}
 
-	// Check for duplicate (same path)
-	for _, inst := range instances {
-		if inst.ProjectPath == path {
-			fmt.Printf("Session already exists: %s (%s)\n", inst.Title, inst.ID)
-			os.Exit(0)
+	// Check for duplicate (same path); a worktree gets a path of its own
+	if !useWorktree {
+		for _, inst := range instances {
+			if inst.ProjectPath == path {
+				fmt.Printf("Session already exists: %s (%s)\n", inst.Title, inst.ID)
+				os.Exit(0)
+			}
 		}
 	}
 
@@ -450,6 +510,20 @@ func handleAdd(profile string, args []string) {
 		newInstance.SetParent(parentInstance.ID)
 	}
 
+	// Move the session into its own worktree
+	if useWorktree {
+		if err := newInstance.SetupWorktree(*branch); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		path = newInstance.ProjectPath
+	}
+
+	// Apply the template once the project path is final (prompt placeholders)
+	if tmpl != nil {
+		newInstance.ApplyTemplate(tmpl)
+	}
+
 	// Set command if provided
 	if sessionCommand != "" {
 		newInstance.Command = sessionCommand
@@ -499,8 +573,14 @@ func handleAdd(profile string, args []string) {
 	fmt.Printf("  Path:    %s\n", path)
 	fmt.Printf("  Group:   %s\n", newInstance.GroupPath)
 	fmt.Printf("  ID:      %s\n", newInstance.ID)
-	if sessionCommand != "" {
-		fmt.Printf("  Cmd:     %s\n", sessionCommand)
+	if tmpl != nil {
+		fmt.Printf("  Template: %s\n", *templateName)
+	}
+	if newInstance.Command != "" {
+		fmt.Printf("  Cmd:     %s\n", newInstance.Command)
+	}
+	if newInstance.HasWorktree() {
+		fmt.Printf("  Branch:  %s\n", newInstance.WorktreeBranch)
 	}
 	if len(mcpFlags) > 0 {
 		fmt.Printf("  MCPs:    %s\n", strings.Join(mcpFlags, ", "))
@@ -508,6 +588,9 @@ func handleAdd(profile string, args []string) {
 	if parentInstance != nil {
 		fmt.Printf("  Parent:  %s (%s)\n", parentInstance.Title, parentInstance.ID[:8])
 	}
+	if newInstance.InitialPrompt != "" {
+		fmt.Printf("  Prompt:  sent on first 'agent-deck session start'\n")
+	}
 }
 
 // handleList lists all sessions
@@ -565,6 +648,7 @@ func handleList(profile string, args []string) {
 			Group     string    `json:"group"`
 			Tool      string    `json:"tool"`
 			Command   string    `json:"command,omitempty"`
+			Branch    string    `json:"branch,omitempty"`
 			Profile   string    `json:"profile"`
 			CreatedAt time.Time `json:"created_at"`
 		}
@@ -577,6 +661,7 @@ func handleList(profile string, args []string) {
 				Group:     inst.GroupPath,
 				Tool:      inst.Tool,
 				Command:   inst.Command,
+				Branch:    inst.WorktreeBranch,
 				Profile:   storage.Profile(),
 				CreatedAt: inst.CreatedAt,
 			}
@@ -710,14 +795,19 @@ func handleListAllProfiles(jsonOutput bool) {
 // handleRemove removes a session by ID or title
 func handleRemove(profile string, args []string) {
 	fs := flag.NewFlagSet("remove", flag.ExitOnError)
+	removeWorktree := fs.Bool("remove-worktree", false, "Also delete the session's git worktree (refused if it has uncommitted changes)")
 	fs.Usage = func() {
-		fmt.Println("Usage: agent-deck remove <id|title>")
+		fmt.Println("Usage: agent-deck remove [options] <id|title>")
 		fmt.Println()
 		fmt.Println("Remove a session by ID or title.")
 		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  agent-deck remove abc12345")
 		fmt.Println("  agent-deck remove \"My Project\"")
+		fmt.Println("  agent-deck remove --remove-worktree my-fork")
 		fmt.Println("  agent-deck -p work remove abc12345   # Remove from 'work' profile")
 	}
 
@@ -747,11 +837,24 @@ func handleRemove(profile string, args []string) {
 	// Find and remove the session
 	found := false
 	var removedTitle string
+	var keptWorktrees []string
 	newInstances := make([]*session.Instance, 0, len(instances))
 	for _, inst := range instances {
 		if inst.ID == identifier || strings.HasPrefix(inst.ID, identifier) || inst.Title == identifier {
 			found = true
 			removedTitle = inst.Title
+			// Remove the worktree first so a dirty one keeps its session too
+			if inst.HasWorktree() {
+				if *removeWorktree {
+					if err := inst.RemoveWorktree(); err != nil {
+						fmt.Printf("Error: %v\n", err)
+						fmt.Println("Commit or discard the changes, or remove without --remove-worktree")
+						os.Exit(1)
+					}
+				} else {
+					keptWorktrees = append(keptWorktrees, inst.WorktreePath)
+				}
+			}
 			// Kill tmux session if it exists
 			if inst.Exists() {
 				if err := inst.Kill(); err != nil {
@@ -778,15 +881,19 @@ func handleRemove(profile string, args []string) {
 	}
 
 	fmt.Printf("✓ Removed session: %s (from profile '%s')\n", removedTitle, storage.Profile())
+	for _, path := range keptWorktrees {
+		fmt.Printf("  Worktree kept: %s (use --remove-worktree to delete it)\n", path)
+	}
 }
 
 // statusCounts holds session counts by status
 type statusCounts struct {
-	running int
-	waiting int
-	idle    int
-	err     int
-	total   int
+	running  int
+	waiting  int
+	approval int
+	idle     int
+	err      int
+	total    int
 }
 
 // countByStatus counts sessions by their status
@@ -799,6 +906,8 @@ func countByStatus(instances []*session.Instance) statusCounts {
 			counts.running++
 		case session.StatusWaiting:
 			counts.waiting++
+		case session.StatusApproval:
+			counts.approval++
 		case session.StatusIdle:
 			counts.idle++
 		case session.StatusError:
@@ -814,8 +923,8 @@ func handleStatus(profile string, args []string) {
 	fs := flag.NewFlagSet("status", flag.ExitOnError)
 	verbose := fs.Bool("verbose", false, "Show detailed session list")
 	verboseShort := fs.Bool("v", false, "Show detailed session list (short)")
-	quiet := fs.Bool("quiet", false, "Only output waiting count (for scripts)")
-	quietShort := fs.Bool("q", false, "Only output waiting count (short)")
+	quiet := fs.Bool("quiet", false, "Only output waiting + approval count (for scripts)")
+	quietShort := fs.Bool("q", false, "Only output waiting + approval count (short)")
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 
 	fs.Usage = func() {
@@ -852,7 +961,7 @@ func handleStatus(profile string, args []string) {
 
 	if len(instances) == 0 {
 		if *jsonOutput {
-			fmt.Println(`{"waiting": 0, "running": 0, "idle": 0, "error": 0, "total": 0}`)
+			fmt.Println(`{"waiting": 0, "approval": 0, "running": 0, "idle": 0, "error": 0, "total": 0}`)
 		} else if *quiet || *quietShort {
 			fmt.Println("0")
 		} else {
@@ -867,22 +976,25 @@ func handleStatus(profile string, args []string) {
 	// Output based on flags
 	if *jsonOutput {
 		type statusJSON struct {
-			Waiting int `json:"waiting"`
-			Running int `json:"running"`
-			Idle    int `json:"idle"`
-			Error   int `json:"error"`
-			Total   int `json:"total"`
+			Waiting  int `json:"waiting"`
+			Approval int `json:"approval"`
+			Running  int `json:"running"`
+			Idle     int `json:"idle"`
+			Error    int `json:"error"`
+			Total    int `json:"total"`
 		}
 		output, _ := json.Marshal(statusJSON{
-			Waiting: counts.waiting,
-			Running: counts.running,
-			Idle:    counts.idle,
-			Error:   counts.err,
-			Total:   counts.total,
+			Waiting:  counts.waiting,
+			Approval: counts.approval,
+			Running:  counts.running,
+			Idle:     counts.idle,
+			Error:    counts.err,
+			Total:    counts.total,
 		})
 		fmt.Println(string(output))
 	} else if *quiet || *quietShort {
-		fmt.Println(counts.waiting)
+		// Everything that needs the user, including blocked agents
+		fmt.Println(counts.waiting + counts.approval)
 	} else if *verbose || *verboseShort {
 		// Detailed output grouped by status
 		printStatusGroup := func(label, symbol string, status session.Status) {
@@ -907,6 +1019,7 @@ func handleStatus(profile string, args []string) {
 			fmt.Println()
 		}
 
+		printStatusGroup("APPROVAL", "◆", session.StatusApproval)
 		printStatusGroup("WAITING", "◐", session.StatusWaiting)
 		printStatusGroup("RUNNING", "●", session.StatusRunning)
 		printStatusGroup("IDLE", "○", session.StatusIdle)
@@ -915,6 +1028,9 @@ func handleStatus(profile string, args []string) {
 		fmt.Printf("Total: %d sessions in profile '%s'\n", counts.total, storage.Profile())
 	} else {
 		// Compact output
+		if counts.approval > 0 {
+			fmt.Printf("%d need approval • ", counts.approval)
+		}
 		fmt.Printf("%d waiting • %d running • %d idle\n",
 			counts.waiting, counts.running, counts.idle)
 	}
@@ -1123,6 +1239,12 @@ func printHelp() {
 	fmt.Println("  session          Manage session lifecycle")
 	fmt.Println("  mcp              Manage MCP servers")
 	fmt.Println("  group            Manage groups")
+	fmt.Println("  template         Manage session templates")
+	fmt.Println("  apply -f <file>  Create/update sessions from a workspace file")
+	fmt.Println("  export           Export sessions and groups as a workspace file")
+	fmt.Println("  undo             Undo the last session/group operation")
+	fmt.Println("  trash            List, restore or purge deleted sessions")
+	fmt.Println("  broadcast        Send a prompt to many sessions and collect the replies")
 	fmt.Println("  profile          Manage profiles")
 	fmt.Println("  update           Check for and install updates")
 	fmt.Println("  version          Show version")
@@ -1132,15 +1254,18 @@ func printHelp() {
 	fmt.Println("  session start <id>        Start a session's tmux process")
 	fmt.Println("  session stop <id>         Stop session process")
 	fmt.Println("  session restart <id>      Restart session (reload MCPs)")
-	fmt.Println("  session fork <id>         Fork Claude session with context")
+	fmt.Println("  session fork <id>         Fork session with context")
 	fmt.Println("  session attach <id>       Attach to session interactively")
 	fmt.Println("  session show [id]         Show session details")
+	fmt.Println("  session ask <id> <prompt> Send a prompt and print the reply")
 	fmt.Println()
 	fmt.Println("MCP Commands:")
 	fmt.Println("  mcp list                  List available MCPs from config.toml")
 	fmt.Println("  mcp attached [id]         Show MCPs attached to a session")
 	fmt.Println("  mcp attach <id> <mcp>     Attach MCP to session")
 	fmt.Println("  mcp detach <id> <mcp>     Detach MCP from session")
+	fmt.Println("  mcp pool status           Show pooled MCP health")
+	fmt.Println("  mcp trace <mcp>           Follow a pooled MCP's JSON-RPC traffic")
 	fmt.Println()
 	fmt.Println("Group Commands:")
 	fmt.Println("  group list                List all groups")
@@ -1148,6 +1273,16 @@ func printHelp() {
 	fmt.Println("  group delete <name>       Delete a group")
 	fmt.Println("  group move <id> <group>   Move session to group")
 	fmt.Println()
+	fmt.Println("Template Commands:")
+	fmt.Println("  template list             List session templates")
+	fmt.Println("  template show <name>      Show a template")
+	fmt.Println("  template save-from <id> <name>  Save a session as a template")
+	fmt.Println()
+	fmt.Println("Trash Commands:")
+	fmt.Println("  trash list                List deleted sessions")
+	fmt.Println("  trash restore <id>        Put a deleted session back")
+	fmt.Println("  trash purge <id>|--all    Permanently delete from the trash")
+	fmt.Println()
 	fmt.Println("Profile Commands:")
 	fmt.Println("  profile list              List all profiles")
 	fmt.Println("  profile create <name>     Create a new profile")
@@ -1159,11 +1294,16 @@ func printHelp() {
 	fmt.Println("  agent-deck -p work                    # Start TUI with 'work' profile")
 	fmt.Println("  agent-deck add .                      # Add current directory")
 	fmt.Println("  agent-deck add -t \"My App\" -g dev .   # With title and group")
+	fmt.Println("  agent-deck add -w -c claude .         # In a new git worktree")
+	fmt.Println("  agent-deck add --template review .    # From a config.toml template")
 	fmt.Println("  agent-deck session start my-project   # Start a session")
 	fmt.Println("  agent-deck session show               # Show current session (in tmux)")
 	fmt.Println("  agent-deck mcp list --json            # List MCPs as JSON")
 	fmt.Println("  agent-deck mcp attach my-app exa      # Attach MCP to session")
 	fmt.Println("  agent-deck group move my-app work     # Move session to group")
+	fmt.Println("  agent-deck export > deck.toml         # Snapshot sessions and groups")
+	fmt.Println("  agent-deck apply -f deck.toml         # Recreate them elsewhere")
+	fmt.Println("  agent-deck undo -n 2                  # Undo the last two operations")
 	fmt.Println()
 	fmt.Println("Environment Variables:")
 	fmt.Println("  AGENTDECK_PROFILE    Default profile to use")
@@ -1174,6 +1314,7 @@ func printHelp() {
 	fmt.Println("  g          New group")
 	fmt.Println("  Enter      Attach to session")
 	fmt.Println("  d          Delete session/group")
+	fmt.Println("  Ctrl+Z     Undo last operation")
 	fmt.Println("  m          Move session to group")
 	fmt.Println("  R          Rename session/group")
 	fmt.Println("  /          Search")
@@ -1202,21 +1343,7 @@ func truncate(s string, max int) string {
 
 // detectTool determines the tool type from command
 func detectTool(cmd string) string {
-	cmd = strings.ToLower(cmd)
-	switch {
-	case strings.Contains(cmd, "claude"):
-		return "claude"
-	case strings.Contains(cmd, "opencode") || strings.Contains(cmd, "open-code"):
-		return "opencode"
-	case strings.Contains(cmd, "gemini"):
-		return "gemini"
-	case strings.Contains(cmd, "codex"):
-		return "codex"
-	case strings.Contains(cmd, "cursor"):
-		return "cursor"
-	default:
-		return "shell"
-	}
+	return session.DetectToolFromCommand(cmd)
 }
 
 // getLockFilePath returns the path to the lock file for a profile
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os/exec"
+	"strings"
 	"testing"
 
+	"github.com/asheshgoplani/agent-deck/internal/session"
 	"github.com/asheshgoplani/agent-deck/internal/ui"
 )
 
@@ -28,3 +32,84 @@ func TestHomeView(t *testing.T) {
 		t.Error("View() returned empty string")
 	}
 }
+
+func TestSessionSelector(t *testing.T) {
+	instances := []*session.Instance{
+		{ID: "1", Title: "api", ProjectPath: "/src/api", GroupPath: "work", Status: session.StatusIdle},
+		{ID: "2", Title: "web", ProjectPath: "/src/web", GroupPath: "work/frontend", Status: session.StatusRunning},
+		{ID: "3", Title: "notes", ProjectPath: "/home/notes", GroupPath: "workshop", Status: session.StatusIdle},
+	}
+
+	tests := []struct {
+		name string
+		sel  sessionSelector
+		want []string
+	}{
+		{"group includes subgroups", sessionSelector{group: "work"}, []string{"1", "2"}},
+		{"status list", sessionSelector{status: "idle, running"}, []string{"1", "2", "3"}},
+		{"group and status", sessionSelector{group: "work", status: "idle"}, []string{"1"}},
+		{"filter matches path", sessionSelector{filter: "SRC"}, []string{"1", "2"}},
+		{"no match", sessionSelector{filter: "nothing"}, nil},
+	}
+	for _, tt := range tests {
+		got, err := tt.sel.selectSessions(instances)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		var ids []string
+		for _, inst := range got {
+			ids = append(ids, inst.ID)
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: selected %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+
+	if _, err := (&sessionSelector{status: "busy"}).selectSessions(instances); err == nil {
+		t.Error("unknown status should be an error")
+	}
+}
+
+func TestAskExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{session.ErrTurnTimeout, exitAskTimeout},
+		{session.ErrAgentStopped, exitAskAgentError},
+		{session.ErrAgentBlocked, exitAskAgentError},
+		{session.ErrAgentFailed, exitAskAgentError},
+		{fmt.Errorf("api: %w", session.ErrAgentFailed), exitAskAgentError},
+		{errors.New("session is not running"), 1},
+	}
+	for _, tt := range tests {
+		if got := askExitCode(tt.err); got != tt.want {
+			t.Errorf("askExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRestartSessionsSkipsRunning(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+
+	// A running shell has no conversation to resume, so restarting it
+	// would only kill its work
+	inst := session.NewInstance("restart-skip-test", "/tmp")
+	inst.Command = "cat"
+	if err := inst.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() { _ = inst.Kill() }()
+	tmuxSess := inst.GetTmuxSession()
+
+	results := restartSessions([]*session.Instance{inst})
+	if len(results) != 1 || results[0].skipped == "" || results[0].err != nil {
+		t.Fatalf("restartSessions = %+v, want the session skipped", results)
+	}
+	if inst.GetTmuxSession() != tmuxSess || !tmuxSess.Exists() {
+		t.Error("a skipped session should keep running in its tmux session")
+	}
+}
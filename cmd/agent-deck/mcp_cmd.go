@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/asheshgoplani/agent-deck/internal/mcppool"
 	"github.com/asheshgoplani/agent-deck/internal/session"
 )
 
@@ -28,6 +29,10 @@ func handleMCP(profile string, args []string) {
 		handleMCPAttach(profile, args[1:])
 	case "detach":
 		handleMCPDetach(profile, args[1:])
+	case "pool":
+		handleMCPPool(profile, args[1:])
+	case "trace":
+		handleMCPTrace(args[1:])
 	case "help", "-h", "--help":
 		printMCPHelp()
 	default:
@@ -48,6 +53,8 @@ func printMCPHelp() {
 	fmt.Println("  attached [id]       Show MCPs attached to a session")
 	fmt.Println("  attach <id> <mcp>   Attach an MCP to a session")
 	fmt.Println("  detach <id> <mcp>   Detach an MCP from a session")
+	fmt.Println("  pool <command>      Inspect and control the MCP socket pool")
+	fmt.Println("  trace <mcp>         Follow or replay a pooled MCP's JSON-RPC traffic")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  agent-deck mcp list                        # List available MCPs")
@@ -56,6 +63,8 @@ func printMCPHelp() {
 	fmt.Println("  agent-deck mcp attach my-project exa       # Attach exa to my-project (local)")
 	fmt.Println("  agent-deck mcp attach my-project exa --global     # Attach globally")
 	fmt.Println("  agent-deck mcp detach my-project exa       # Detach exa from my-project")
+	fmt.Println("  agent-deck mcp pool status                 # Show pooled MCP health")
+	fmt.Println("  agent-deck mcp trace --record memory       # Watch memory's traffic live")
 }
 
 // handleMCPList lists all available MCPs from config.toml
@@ -105,11 +114,12 @@ func handleMCPList(args []string) {
 	if *jsonOutput {
 		// Build JSON output
 		type mcpJSON struct {
-			Name        string            `json:"name"`
-			Command     string            `json:"command"`
-			Args        []string          `json:"args"`
-			Env         map[string]string `json:"env,omitempty"`
-			Description string            `json:"description,omitempty"`
+			Name        string              `json:"name"`
+			Command     string              `json:"command"`
+			Args        []string            `json:"args"`
+			Env         map[string]string   `json:"env,omitempty"`
+			Description string              `json:"description,omitempty"`
+			Pool        *mcppool.ProxyState `json:"pool,omitempty"`
 		}
 
 		mcpList := make([]mcpJSON, 0, len(mcps))
@@ -120,6 +130,7 @@ func handleMCPList(args []string) {
 				Args:        def.Args,
 				Env:         def.Env,
 				Description: def.Description,
+				Pool:        mcppool.ReadProxyState(name), // nil if not pooled
 			})
 		}
 
@@ -426,7 +437,7 @@ func handleMCPAttach(profile string, args []string) {
 
 	// Restart if requested
 	restarted := false
-	if *restart && (inst.Tool == "claude" || inst.Tool == "gemini") {
+	if *restart && inst.SupportsMCP() {
 		if err := inst.Restart(); err != nil {
 			// Don't fail the whole operation, just warn
 			if !*jsonOutput && !quietMode {
@@ -578,7 +589,7 @@ func handleMCPDetach(profile string, args []string) {
 
 	// Restart if requested
 	restarted := false
-	if *restart && (inst.Tool == "claude" || inst.Tool == "gemini") {
+	if *restart && inst.SupportsMCP() {
 		if err := inst.Restart(); err != nil {
 			// Don't fail the whole operation, just warn
 			if !*jsonOutput && !quietMode {
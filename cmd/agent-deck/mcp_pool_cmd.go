@@ -0,0 +1,537 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/mcppool"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleMCPPool handles `agent-deck mcp pool` subcommands
+func handleMCPPool(profile string, args []string) {
+	if len(args) == 0 {
+		handleMCPPoolStatus(args)
+		return
+	}
+
+	switch args[0] {
+	case "status":
+		handleMCPPoolStatus(args[1:])
+	case "clients":
+		handleMCPPoolClients(profile, args[1:])
+	case "restart":
+		handleMCPPoolRestart(args[1:])
+	case "stop":
+		handleMCPPoolStop(args[1:])
+	case "start":
+		handleMCPPoolStart(args[1:])
+	case "serve":
+		// Internal: runs a detached proxy for `mcp pool start`
+		handleMCPPoolServe(args[1:])
+	case "help", "-h", "--help":
+		printMCPPoolHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown mcp pool command '%s'\n", args[0])
+		printMCPPoolHelp()
+		os.Exit(1)
+	}
+}
+
+// printMCPPoolHelp prints help for mcp pool commands
+func printMCPPoolHelp() {
+	fmt.Println("Usage: agent-deck mcp pool <command> [options]")
+	fmt.Println()
+	fmt.Println("Inspect and control the MCP socket pool ([mcp_pool] in config.toml).")
+	fmt.Println("Works against whichever agent-deck instance owns each pooled MCP.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  status              Show all pooled MCPs (default)")
+	fmt.Println("  clients <mcp>       Show sessions connected to a pooled MCP")
+	fmt.Println("  restart <mcp>       Restart the MCP process (sessions stay connected)")
+	fmt.Println("  stop <mcp>          Stop a pooled MCP and remove its socket")
+	fmt.Println("  start <mcp>         Start a pooled MCP in the background")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck mcp pool status --json          # Health check for scripts")
+	fmt.Println("  agent-deck mcp pool clients memory         # Which sessions use memory")
+	fmt.Println("  agent-deck mcp pool restart exa            # Restart a misbehaving MCP")
+}
+
+// poolServerJSON is the status of one pooled MCP as reported by the CLI
+type poolServerJSON struct {
+	*mcppool.ProxyStatus
+	Reachable bool `json:"reachable"`
+}
+
+// handleMCPPoolStatus shows the status of every pooled MCP
+func handleMCPPoolStatus(args []string) {
+	fs := flag.NewFlagSet("mcp pool status", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp pool status [options]")
+		fmt.Println()
+		fmt.Println("Show socket path, PID, uptime, clients, request counts and last error")
+		fmt.Println("for every pooled MCP.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	servers := make([]poolServerJSON, 0)
+	for _, name := range pooledMCPNames() {
+		servers = append(servers, queryPoolServer(name))
+	}
+
+	if *jsonOutput {
+		out.Print("", map[string]interface{}{
+			"servers": servers,
+		})
+		return
+	}
+
+	if len(servers) == 0 {
+		fmt.Println("No pooled MCPs.")
+		fmt.Println()
+		fmt.Println("Enable the pool in ~/.agent-deck/config.toml:")
+		fmt.Println()
+		fmt.Println("  [mcp_pool]")
+		fmt.Println("  enabled = true")
+		fmt.Println("  pool_all = true")
+		return
+	}
+
+	fmt.Printf("%-20s %-11s %-8s %-10s %-8s %-9s %s\n", "NAME", "STATUS", "PID", "UPTIME", "CLIENTS", "REQUESTS", "SOCKET")
+	fmt.Println(strings.Repeat("-", 100))
+	for _, s := range servers {
+		pid, uptime, clients, requests := "-", "-", "-", "-"
+		if s.Reachable {
+			if s.PID > 0 {
+				pid = strconv.Itoa(s.PID)
+			}
+			if s.UptimeSeconds > 0 {
+				uptime = formatUptime(time.Duration(s.UptimeSeconds) * time.Second)
+			}
+			clients = strconv.Itoa(len(s.Clients))
+			requests = strconv.FormatInt(s.Requests, 10)
+		}
+		name := s.Name
+		if len(name) > 20 {
+			name = name[:17] + "..."
+		}
+		fmt.Printf("%-20s %-11s %-8s %-10s %-8s %-9s %s\n", name, s.Status, pid, uptime, clients, requests, FormatPath(s.SocketPath))
+		if s.LastError != "" {
+			fmt.Printf("  %s last error: %s\n", errorSymbol, s.LastError)
+		}
+	}
+}
+
+// handleMCPPoolClients lists the clients of a pooled MCP and the agent-deck
+// sessions they belong to
+func handleMCPPoolClients(profile string, args []string) {
+	fs := flag.NewFlagSet("mcp pool clients", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp pool clients <mcp> [options]")
+		fmt.Println()
+		fmt.Println("Show the clients connected to a pooled MCP, mapped to agent-deck sessions.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+	name := fs.Arg(0)
+	if name == "" {
+		out.Error("MCP name is required", ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	status, err := mcppool.QueryStatus(name)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	// Map client processes to agent-deck sessions through their tmux panes
+	var instances []*session.Instance
+	if storage, err := session.NewStorageWithProfile(profile); err == nil {
+		instances, _, _ = storage.LoadWithGroups()
+	}
+	resolver := newPaneResolver(instances)
+
+	type clientJSON struct {
+		mcppool.ClientStatus
+		SessionID    string `json:"session_id,omitempty"`
+		SessionTitle string `json:"session_title,omitempty"`
+	}
+	clients := make([]clientJSON, 0, len(status.Clients))
+	for _, c := range status.Clients {
+		entry := clientJSON{ClientStatus: c}
+		if inst := resolver.instanceForPID(c.PID); inst != nil {
+			entry.SessionID = inst.ID
+			entry.SessionTitle = inst.Title
+		}
+		clients = append(clients, entry)
+	}
+
+	if *jsonOutput {
+		out.Print("", map[string]interface{}{
+			"name":    status.Name,
+			"clients": clients,
+		})
+		return
+	}
+
+	if len(clients) == 0 {
+		fmt.Printf("No clients connected to %s.\n", name)
+		return
+	}
+
+	fmt.Printf("%-24s %-10s %-8s %-10s %-9s %s\n", "CLIENT", "TRANSPORT", "PID", "CONNECTED", "REQUESTS", "SESSION")
+	fmt.Println(strings.Repeat("-", 90))
+	for _, c := range clients {
+		pid := "-"
+		if c.PID > 0 {
+			pid = strconv.Itoa(c.PID)
+		}
+		sessionDisplay := "-"
+		if c.SessionID != "" {
+			sessionDisplay = fmt.Sprintf("%s (%s)", c.SessionTitle, TruncateID(c.SessionID))
+		}
+		fmt.Printf("%-24s %-10s %-8s %-10s %-9d %s\n", c.ID, c.Transport, pid,
+			formatUptime(time.Since(c.ConnectedAt)), c.Requests, sessionDisplay)
+	}
+}
+
+// handleMCPPoolRestart restarts a pooled MCP's process in place
+func handleMCPPoolRestart(args []string) {
+	name, out := parsePoolNameArgs("restart", "Restart the MCP process. Connected sessions stay connected.", args)
+
+	if !mcppool.IsPoolSocketAlive(name) {
+		// Nothing owns it - restarting is starting
+		startPoolServer(name, out)
+		return
+	}
+
+	if err := mcppool.RequestRestart(name); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	out.Success(fmt.Sprintf("Restarting %s", name), map[string]interface{}{
+		"success": true,
+		"name":    name,
+		"action":  "restart",
+	})
+}
+
+// handleMCPPoolStop stops a pooled MCP
+func handleMCPPoolStop(args []string) {
+	name, out := parsePoolNameArgs("stop", "Stop a pooled MCP and remove its socket.", args)
+
+	if !mcppool.IsPoolSocketAlive(name) {
+		out.Error(fmt.Sprintf("%s is not running in the pool", name), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	if err := mcppool.RequestStop(name); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	out.Success(fmt.Sprintf("Stopped %s", name), map[string]interface{}{
+		"success": true,
+		"name":    name,
+		"action":  "stop",
+	})
+}
+
+// handleMCPPoolStart starts a pooled MCP in a background agent-deck process
+func handleMCPPoolStart(args []string) {
+	name, out := parsePoolNameArgs("start", "Start a pooled MCP in the background.", args)
+
+	if mcppool.IsPoolSocketAlive(name) {
+		out.Success(fmt.Sprintf("%s is already running", name), map[string]interface{}{
+			"success": true,
+			"name":    name,
+			"action":  "none",
+		})
+		return
+	}
+	startPoolServer(name, out)
+}
+
+// parsePoolNameArgs parses `mcp pool <cmd> <mcp> [--json]`
+func parsePoolNameArgs(cmd, description string, args []string) (string, *CLIOutput) {
+	fs := flag.NewFlagSet("mcp pool "+cmd, flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Printf("Usage: agent-deck mcp pool %s <mcp> [options]\n", cmd)
+		fmt.Println()
+		fmt.Println(description)
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+	name := fs.Arg(0)
+	if name == "" {
+		out.Error("MCP name is required", ErrCodeNotFound)
+		os.Exit(1)
+	}
+	return name, out
+}
+
+// startPoolServer launches `agent-deck mcp pool serve <name>` detached from the
+// terminal and waits for its socket to come up
+func startPoolServer(name string, out *CLIOutput) {
+	if _, ok := session.GetAvailableMCPs()[name]; !ok {
+		out.Error(fmt.Sprintf("MCP '%s' not found in config.toml", name), ErrCodeMCPNotAvailable)
+		os.Exit(2)
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to locate agent-deck binary: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	logDir := filepath.Join(os.Getenv("HOME"), ".agent-deck", "logs", "mcppool")
+	_ = os.MkdirAll(logDir, 0755)
+	logFile, err := os.OpenFile(filepath.Join(logDir, name+"_serve.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to open log: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(self, "mcp", "pool", "serve", name)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		out.Error(fmt.Sprintf("failed to start %s: %v", name, err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !mcppool.IsPoolSocketAlive(name) {
+		if time.Now().After(deadline) {
+			out.Error(fmt.Sprintf("%s did not come up within 10s (see %s)", name, FormatPath(logFile.Name())), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	out.Success(fmt.Sprintf("Started %s (owner PID %d)", name, pid), map[string]interface{}{
+		"success":   true,
+		"name":      name,
+		"action":    "start",
+		"owner_pid": pid,
+	})
+}
+
+// handleMCPPoolServe runs a single pooled MCP in the foreground until it is
+// stopped via `mcp pool stop` or a signal
+func handleMCPPoolServe(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: MCP name is required")
+		os.Exit(1)
+	}
+	name := args[0]
+
+	def, ok := session.GetAvailableMCPs()[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: MCP '%s' not found in config.toml\n", name)
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	proxy, err := mcppool.NewSocketProxy(ctx, name, def.Command, def.Args, def.Env)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := proxy.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to start %s: %v\n", name, err)
+		os.Exit(1)
+	}
+
+	if config, err := session.LoadUserConfig(); err == nil && config != nil {
+		poolConfig := session.NewPoolConfig(config)
+		if poolConfig.HTTPEnabled {
+			if err := proxy.StartHTTP(poolConfig.PortStart, poolConfig.PortEnd); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: HTTP endpoint not started: %v\n", err)
+			}
+		}
+		if poolConfig.ShouldRecord(name) {
+			if err := proxy.StartRecording(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: traffic recording not started: %v\n", err)
+			}
+		}
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case <-sigChan:
+		_ = proxy.Stop()
+	case <-proxy.Done():
+		// Stopped via the control protocol; wait for Stop to finish cleanup
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// pooledMCPNames returns MCPs pooled by config.toml plus any pool socket on
+// this machine (e.g. from another profile), sorted
+func pooledMCPNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+
+	if config, err := session.LoadUserConfig(); err == nil && config != nil {
+		poolConfig := session.NewPoolConfig(config)
+		for _, name := range session.GetAvailableMCPNames() {
+			if def := config.MCPs[name]; def.URL == "" && poolConfig.ShouldPool(name) {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range mcppool.ListPoolSockets() {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// queryPoolServer asks the owner of a pooled MCP for its status, falling back
+// to the published state file when the socket is unreachable
+func queryPoolServer(name string) poolServerJSON {
+	if status, err := mcppool.QueryStatus(name); err == nil {
+		return poolServerJSON{ProxyStatus: status, Reachable: true}
+	}
+
+	status := &mcppool.ProxyStatus{
+		Name:    name,
+		Status:  mcppool.StatusStopped.String(),
+		Clients: []mcppool.ClientStatus{},
+	}
+	if state := mcppool.ReadProxyState(name); state != nil {
+		status.Status = state.Status
+		status.SocketPath = state.SocketPath
+		status.OwnerPID = state.OwnerPID
+		status.Restarts = state.Restarts
+		status.LastError = state.LastError
+	}
+	return poolServerJSON{ProxyStatus: status}
+}
+
+// formatUptime formats a duration compactly (e.g. 45s, 12m, 3h20m, 2d4h)
+func formatUptime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
+
+// paneResolver maps process IDs to agent-deck sessions by walking up the
+// process tree to the tmux pane the process runs in
+type paneResolver struct {
+	paneSessions map[int]string               // pane PID -> tmux session name
+	byTmuxName   map[string]*session.Instance // tmux session name -> instance
+}
+
+func newPaneResolver(instances []*session.Instance) *paneResolver {
+	r := &paneResolver{
+		paneSessions: make(map[int]string),
+		byTmuxName:   make(map[string]*session.Instance),
+	}
+
+	for _, inst := range instances {
+		if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil {
+			r.byTmuxName[tmuxSess.Name] = inst
+		}
+	}
+
+	output, err := exec.Command("tmux", "list-panes", "-a", "-F", "#{pane_pid} #{session_name}").Output()
+	if err != nil {
+		return r
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		if pid, err := strconv.Atoi(fields[0]); err == nil {
+			r.paneSessions[pid] = fields[1]
+		}
+	}
+	return r
+}
+
+// instanceForPID returns the session whose pane is an ancestor of pid, or nil
+func (r *paneResolver) instanceForPID(pid int) *session.Instance {
+	// Bounded walk: nc -> claude -> shell -> pane is typically 3-4 levels
+	for depth := 0; pid > 1 && depth < 32; depth++ {
+		if tmuxName, ok := r.paneSessions[pid]; ok {
+			return r.byTmuxName[tmuxName]
+		}
+		pid = parentPID(pid)
+	}
+	return nil
+}
+
+// parentPID returns the parent process ID of pid, or 0 if unknown
+func parentPID(pid int) int {
+	output, err := exec.Command("ps", "-o", "ppid=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0
+	}
+	ppid, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0
+	}
+	return ppid
+}
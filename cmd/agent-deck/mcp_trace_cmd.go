@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/mcppool"
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// tracePollInterval is how often `mcp trace` checks the traffic log for new frames
+const tracePollInterval = 250 * time.Millisecond
+
+// handleMCPTrace follows (or replays) the recorded traffic of a pooled MCP
+func handleMCPTrace(args []string) {
+	fs := flag.NewFlagSet("mcp trace", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Print raw records (JSONL)")
+	lines := fs.Int("n", 20, "Number of recent frames to show before following")
+	noFollow := fs.Bool("no-follow", false, "Print recent frames and exit")
+	record := fs.Bool("record", false, "Record traffic while tracing (turned off again on exit)")
+	replay := fs.Bool("replay", false, "Replay a recorded client conversation against a fresh server instance")
+	client := fs.String("client", "", "Client to replay (default: first client in the recording)")
+	file := fs.String("file", "", "Recording to read (default: the MCP's traffic log)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck mcp trace [options] <mcp>")
+		fmt.Println()
+		fmt.Println("Follow the JSON-RPC traffic of a pooled MCP, or replay it.")
+		fmt.Println()
+		fmt.Println("Traffic is recorded for MCPs listed in [mcp_pool] record_mcps, or while")
+		fmt.Println("`mcp trace --record` runs. Recordings are kept in")
+		fmt.Println("~/.agent-deck/logs/mcppool/<mcp>_traffic.jsonl.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck mcp trace --record memory        # Watch memory's traffic live")
+		fmt.Println("  agent-deck mcp trace --no-follow -n 50 exa  # Last 50 frames")
+		fmt.Println("  agent-deck mcp trace --replay exa           # Re-run a recorded session")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+	name := fs.Arg(0)
+	if name == "" {
+		out.Error("MCP name is required", ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	path := *file
+	if path == "" {
+		path = mcppool.TrafficLogPath(name)
+	}
+
+	if *replay {
+		replayTraffic(name, path, *client, out, *jsonOutput)
+		return
+	}
+
+	if status, err := mcppool.QueryStatus(name); err == nil && !status.Recording {
+		switch {
+		case *record:
+			if err := mcppool.RequestRecording(name, true); err != nil {
+				out.Error(err.Error(), ErrCodeInvalidOperation)
+				os.Exit(1)
+			}
+			// Only undo what we turned on; record_mcps recording keeps running
+			defer func() { _ = mcppool.RequestRecording(name, false) }()
+		case !*noFollow:
+			fmt.Fprintf(os.Stderr, "Traffic recording is off for %s; use --record or add it to [mcp_pool] record_mcps\n", name)
+		}
+	} else if err != nil && *record {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	emit := func(line []byte) {
+		if *jsonOutput {
+			fmt.Println(string(line))
+			return
+		}
+		var rec mcppool.TrafficRecord
+		if json.Unmarshal(line, &rec) == nil {
+			fmt.Println(formatTrafficRecord(&rec))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	if err := followTrafficLog(ctx, path, *lines, !*noFollow, emit); err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+}
+
+// formatTrafficRecord renders a record as one line:
+// time, direction, client, id, method, latency and a preview of the message
+func formatTrafficRecord(rec *mcppool.TrafficRecord) string {
+	arrow := "→"
+	if rec.Direction == mcppool.TrafficOut {
+		arrow = "←"
+	}
+	client := rec.Client
+	if client == "" {
+		client = "*"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %-24s", rec.Time.Local().Format("15:04:05.000"), arrow, client)
+	if len(rec.ID) > 0 {
+		fmt.Fprintf(&b, " #%s", rec.ID)
+	}
+	if rec.Method != "" {
+		fmt.Fprintf(&b, " %s", rec.Method)
+	}
+	if rec.LatencyMs > 0 {
+		fmt.Fprintf(&b, " (%.1fms)", rec.LatencyMs)
+	}
+	if rec.IsError() {
+		fmt.Fprintf(&b, " %s", errorSymbol)
+	}
+
+	var compact bytes.Buffer
+	if json.Compact(&compact, rec.Message) == nil {
+		preview := compact.String()
+		if len(preview) > 120 {
+			preview = preview[:117] + "..."
+		}
+		fmt.Fprintf(&b, "  %s", preview)
+	}
+	return b.String()
+}
+
+// followTrafficLog prints the last n records of a traffic log and, if follow is
+// set, keeps printing new records (across rotations) until ctx is cancelled
+func followTrafficLog(ctx context.Context, path string, n int, follow bool, emit func([]byte)) error {
+	var (
+		f       *os.File
+		info    os.FileInfo
+		partial []byte
+	)
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	// open (re)opens the log; tail limits the first read to the last n lines
+	open := func(tail bool) bool {
+		if f != nil {
+			f.Close()
+			f = nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return false
+		}
+		data, err := io.ReadAll(file)
+		if err != nil {
+			file.Close()
+			return false
+		}
+		f = file
+		info, _ = os.Stat(path)
+		partial = nil
+
+		records := splitLines(data, &partial)
+		if tail && len(records) > n {
+			records = records[len(records)-n:]
+		}
+		for _, rec := range records {
+			emit(rec)
+		}
+		return true
+	}
+
+	if !open(true) {
+		if !follow {
+			return fmt.Errorf("no traffic recorded yet (%s)", FormatPath(path))
+		}
+		fmt.Fprintf(os.Stderr, "Waiting for traffic in %s...\n", FormatPath(path))
+	}
+	if !follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(tracePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := os.Stat(path)
+		if err != nil {
+			continue // Mid-rotation or not created yet
+		}
+		if f == nil || !os.SameFile(info, current) {
+			// Rotated (or created): read the new file from the start
+			open(false)
+			continue
+		}
+
+		data, err := io.ReadAll(f)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		for _, rec := range splitLines(data, &partial) {
+			emit(rec)
+		}
+	}
+}
+
+// splitLines splits data into complete lines, carrying an unterminated last
+// line over in partial
+func splitLines(data []byte, partial *[]byte) [][]byte {
+	data = append(*partial, data...)
+	var lines [][]byte
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		if line := bytes.TrimSpace(data[:i]); len(line) > 0 {
+			lines = append(lines, line)
+		}
+		data = data[i+1:]
+	}
+	*partial = append([]byte(nil), data...)
+	return lines
+}
+
+// replayTraffic feeds one recorded client conversation to a fresh instance of
+// the MCP and reports where the new responses diverge from the recorded ones
+func replayTraffic(name, path, client string, out *CLIOutput, jsonOutput bool) {
+	def, ok := session.GetAvailableMCPs()[name]
+	if !ok {
+		out.Error(fmt.Sprintf("MCP '%s' not found in config.toml", name), ErrCodeMCPNotAvailable)
+		os.Exit(2)
+	}
+	if def.Command == "" {
+		out.Error(fmt.Sprintf("MCP '%s' is not a stdio MCP and can't be replayed", name), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	records, err := mcppool.ReadTrafficLog(path)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to read recording: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	if client == "" {
+		clients := mcppool.RecordedClients(records)
+		if len(clients) == 0 {
+			out.Error("recording has no client messages", ErrCodeNotFound)
+			os.Exit(1)
+		}
+		client = clients[0]
+	}
+
+	type stepJSON struct {
+		Method    string          `json:"method,omitempty"`
+		Sent      json.RawMessage `json:"sent"`
+		Response  json.RawMessage `json:"response,omitempty"`
+		Recorded  json.RawMessage `json:"recorded,omitempty"`
+		LatencyMs float64         `json:"latency_ms,omitempty"`
+		Error     string          `json:"error,omitempty"`
+		Diverged  bool            `json:"diverged"`
+	}
+	steps := make([]stepJSON, 0)
+	diverged := 0
+
+	if !jsonOutput {
+		fmt.Printf("Replaying %s against a fresh %s server...\n\n", client, name)
+	}
+	err = mcppool.Replay(context.Background(), def.Command, def.Args, def.Env, records, client, func(step mcppool.ReplayStep) {
+		entry := stepJSON{
+			Method:    step.Method,
+			Sent:      step.Sent,
+			Response:  step.Response,
+			Recorded:  step.Recorded,
+			LatencyMs: float64(step.Latency.Microseconds()) / 1000,
+			Diverged:  step.Diverged(),
+		}
+		if step.Err != nil {
+			entry.Error = step.Err.Error()
+		}
+		if entry.Diverged {
+			diverged++
+		}
+		steps = append(steps, entry)
+
+		if jsonOutput {
+			return
+		}
+		symbol := successSymbol
+		if entry.Diverged {
+			symbol = errorSymbol
+		}
+		switch {
+		case step.Err != nil:
+			fmt.Printf("%s %-30s %s\n", symbol, step.Method, step.Err)
+		case step.Response == nil:
+			fmt.Printf("  %-30s (notification)\n", step.Method)
+		default:
+			outcome := "result"
+			if (&mcppool.TrafficRecord{Message: step.Response}).IsError() {
+				outcome = "error"
+			}
+			fmt.Printf("%s %-30s %s in %.1fms\n", symbol, step.Method, outcome, entry.LatencyMs)
+		}
+	})
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		out.Print("", map[string]interface{}{
+			"name":     name,
+			"client":   client,
+			"steps":    steps,
+			"diverged": diverged,
+		})
+	} else {
+		fmt.Printf("\nReplayed %d messages, %d diverged from the recording\n", len(steps), diverged)
+	}
+	if diverged > 0 {
+		os.Exit(1)
+	}
+}
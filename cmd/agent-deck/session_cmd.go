@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/asheshgoplani/agent-deck/internal/profile"
@@ -44,8 +47,16 @@ func handleSession(profile string, args []string) {
 		handleSessionSet(profile, args[1:])
 	case "send":
 		handleSessionSend(profile, args[1:])
+	case "ask":
+		handleSessionAsk(profile, args[1:])
 	case "output":
 		handleSessionOutput(profile, args[1:])
+	case "transcript":
+		handleSessionTranscript(profile, args[1:])
+	case "approve":
+		handleSessionApproval(profile, args[1:], true)
+	case "deny":
+		handleSessionApproval(profile, args[1:], false)
 	case "help", "--help", "-h":
 		printSessionHelp()
 	default:
@@ -65,13 +76,17 @@ func printSessionHelp() {
 	fmt.Println("  start <id>              Start a session's tmux process")
 	fmt.Println("  stop <id>               Stop/kill session process")
 	fmt.Println("  restart <id>            Restart session (Claude: reload MCPs)")
-	fmt.Println("  fork <id>               Fork Claude session with context")
+	fmt.Println("  fork <id>               Fork session with context")
 	fmt.Println("  attach <id>             Attach to session interactively")
 	fmt.Println("  show [id]               Show session details (auto-detect current if no id)")
 	fmt.Println("  current                 Show current session and profile (auto-detect)")
 	fmt.Println("  set <id> <field> <value>  Update session property")
 	fmt.Println("  send <id> <message>     Send a message to a running session")
+	fmt.Println("  ask <id> <prompt>       Send a prompt and print the agent's reply")
 	fmt.Println("  output <id>             Get the last response from a session")
+	fmt.Println("  transcript <id>         Export the whole conversation (md, html, json)")
+	fmt.Println("  approve <id>            Answer a pending permission prompt with yes")
+	fmt.Println("  deny <id>               Answer a pending permission prompt with no")
 	fmt.Println("  set-parent <id> <parent>  Link session as sub-session of parent")
 	fmt.Println("  unset-parent <id>       Remove sub-session link")
 	fmt.Println()
@@ -80,9 +95,15 @@ func printSessionHelp() {
 	fmt.Println("  --json                 Output as JSON")
 	fmt.Println("  -q, --quiet            Minimal output (exit codes only)")
 	fmt.Println()
+	fmt.Println("Bulk Options (stop, restart, send; instead of <id>):")
+	fmt.Println("  --group <path>         Sessions in a group (and its subgroups)")
+	fmt.Println("  --status <status>      Sessions with a status (comma-separated)")
+	fmt.Println("  --filter <text>        Sessions whose title or path contains text")
+	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  agent-deck session start my-project")
 	fmt.Println("  agent-deck session stop abc123")
+	fmt.Println("  agent-deck session stop --group work --status idle  # Stop every idle session in work")
 	fmt.Println("  agent-deck session restart my-project")
 	fmt.Println("  agent-deck session fork my-project -t \"my-project-fork\"")
 	fmt.Println("  agent-deck session attach my-project")
@@ -90,21 +111,32 @@ func printSessionHelp() {
 	fmt.Println("  agent-deck session show my-project --json")
 	fmt.Println("  agent-deck session set-parent sub-task main-project  # Make sub-task a sub-session")
 	fmt.Println("  agent-deck session unset-parent sub-task             # Remove sub-session link")
+	fmt.Println("  agent-deck session ask my-project \"summarize the diff\"  # Send and wait for the reply")
 	fmt.Println("  agent-deck session output my-project                 # Get last response from session")
 	fmt.Println("  agent-deck session output my-project --json          # Get response as JSON")
+	fmt.Println("  agent-deck session transcript -o review.md my-project  # Share the conversation")
+	fmt.Println("  agent-deck session approve my-project                # Let a blocked agent continue")
 	fmt.Println()
 	fmt.Println("Set command fields:")
-	fmt.Println("  title              Session title")
-	fmt.Println("  path               Project path")
-	fmt.Println("  command            Command to run")
-	fmt.Println("  tool               Tool type (claude, gemini, shell, etc.)")
-	fmt.Println("  claude-session-id  Claude conversation ID (for fork/resume)")
-	fmt.Println("  gemini-session-id  Gemini conversation ID (for resume)")
+	fmt.Println("  title                   Session title")
+	fmt.Println("  path                    Project path")
+	fmt.Println("  command                 Command to run")
+	fmt.Println("  tool                    Tool type (claude, gemini, shell, etc.)")
+	fmt.Println("  claude-session-id       Claude conversation ID (for fork/resume)")
+	fmt.Println("  gemini-session-id       Gemini conversation ID (for resume)")
+	fmt.Println("  codex-session-id        Codex conversation ID (for resume)")
+	fmt.Println("  opencode-session-id     OpenCode session ID (for resume)")
+	fmt.Println("  claude-model            Claude --model (empty: Claude's default)")
+	fmt.Println("  claude-permission-mode  Claude --permission-mode (default, acceptEdits, plan, bypassPermissions)")
+	fmt.Println("  claude-add-dirs         Claude --add-dir directories, comma-separated")
+	fmt.Println("  claude-allowed-tools    Claude --allowedTools, comma-separated")
+	fmt.Println("  dangerous-mode          true, false or default (use [claude] dangerous_mode)")
 	fmt.Println()
 	fmt.Println("Set examples:")
 	fmt.Println("  agent-deck session set my-project title \"New Title\"")
 	fmt.Println("  agent-deck session set my-project claude-session-id \"abc123-def456\"")
 	fmt.Println("  agent-deck session set my-project tool claude")
+	fmt.Println("  agent-deck session set my-project claude-model opus")
 }
 
 // handleSessionStart starts a session's tmux process
@@ -119,7 +151,8 @@ func handleSessionStart(profile string, args []string) {
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck session start <id|title> [options]")
 		fmt.Println()
-		fmt.Println("Start a session's tmux process.")
+		fmt.Println("Start a session's tmux process. Sessions added from a template send")
+		fmt.Println("the template's prompt on their first start (unless --message is given).")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
@@ -164,6 +197,11 @@ func handleSessionStart(profile string, args []string) {
 		os.Exit(1)
 	}
 
+	// A template's prompt is sent on the first start unless -m replaces it
+	if initialMessage == "" {
+		initialMessage = inst.InitialPrompt
+	}
+
 	// Start the session (with or without initial message)
 	if initialMessage != "" {
 		if err := inst.StartWithMessage(initialMessage); err != nil {
@@ -176,6 +214,7 @@ func handleSessionStart(profile string, args []string) {
 			os.Exit(1)
 		}
 	}
+	inst.InitialPrompt = ""
 
 	// Save updated state
 	if err := saveSessionData(storage, instances); err != nil {
@@ -207,14 +246,21 @@ func handleSessionStop(profile string, args []string) {
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	quiet := fs.Bool("quiet", false, "Minimal output")
 	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	sel := addSelectorFlags(fs)
 
 	fs.Usage = func() {
-		fmt.Println("Usage: agent-deck session stop <id|title> [options]")
+		fmt.Println("Usage: agent-deck session stop [options] <id|title>")
+		fmt.Println("       agent-deck session stop [--group <path>] [--status <status>] [--filter <text>]")
 		fmt.Println()
-		fmt.Println("Stop/kill a session's process (tmux session remains).")
+		fmt.Println("Stop/kill a session's process (tmux session remains), or every selected")
+		fmt.Println("running session.")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session stop my-project")
+		fmt.Println("  agent-deck session stop --group work --status idle")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -232,6 +278,25 @@ func handleSessionStop(profile string, args []string) {
 		os.Exit(1)
 	}
 
+	if sel.active() {
+		results := []bulkResult{}
+		for _, inst := range selectBulkTargets(out, sel, identifier, instances) {
+			if !inst.Exists() {
+				results = append(results, bulkResult{inst: inst, skipped: "not running"})
+				continue
+			}
+			results = append(results, bulkResult{inst: inst, err: inst.Kill()})
+		}
+		if err := saveSessionData(storage, instances); err != nil {
+			out.Error(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		if !printBulkResults(out, "Stopped", results) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Resolve session
 	inst, errMsg, errCode := ResolveSession(identifier, instances)
 	if inst == nil {
@@ -274,14 +339,21 @@ func handleSessionRestart(profile string, args []string) {
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	quiet := fs.Bool("quiet", false, "Minimal output")
 	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	sel := addSelectorFlags(fs)
 
 	fs.Usage = func() {
-		fmt.Println("Usage: agent-deck session restart <id|title> [options]")
+		fmt.Println("Usage: agent-deck session restart [options] <id|title>")
+		fmt.Println("       agent-deck session restart [--group <path>] [--status <status>] [--filter <text>]")
 		fmt.Println()
-		fmt.Println("Restart a session. For Claude sessions, this reloads MCPs.")
+		fmt.Println("Restart a session, or every selected session. For Claude sessions, this")
+		fmt.Println("reloads MCPs.")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session restart my-project")
+		fmt.Println("  agent-deck session restart --status error")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -299,6 +371,18 @@ func handleSessionRestart(profile string, args []string) {
 		os.Exit(1)
 	}
 
+	if sel.active() {
+		results := restartSessions(selectBulkTargets(out, sel, identifier, instances))
+		if err := saveSessionData(storage, instances); err != nil {
+			out.Error(fmt.Sprintf("failed to save session state: %v", err), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		if !printBulkResults(out, "Restarted", results) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Resolve session
 	inst, errMsg, errCode := ResolveSession(identifier, instances)
 	if inst == nil {
@@ -329,7 +413,22 @@ func handleSessionRestart(profile string, args []string) {
 	})
 }
 
-// handleSessionFork forks a Claude session
+// restartSessions restarts the targets that can be restarted: running
+// sessions without a conversation to resume are left alone
+func restartSessions(targets []*session.Instance) []bulkResult {
+	results := make([]bulkResult, 0, len(targets))
+	for _, inst := range targets {
+		if !inst.CanRestart() {
+			results = append(results, bulkResult{inst: inst, skipped: "running, no conversation to resume"})
+			continue
+		}
+		results = append(results, bulkResult{inst: inst, err: inst.Restart()})
+	}
+	return results
+}
+
+// handleSessionFork forks a session: natively for Claude, by transcript
+// seeding for other agents
 func handleSessionFork(profile string, args []string) {
 	fs := flag.NewFlagSet("session fork", flag.ExitOnError)
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
@@ -339,11 +438,17 @@ func handleSessionFork(profile string, args []string) {
 	titleShort := fs.String("t", "", "Title for forked session (short)")
 	group := fs.String("group", "", "Group for forked session")
 	groupShort := fs.String("g", "", "Group for forked session (short)")
+	worktree := fs.Bool("worktree", false, "Run the fork in a new git worktree")
+	worktreeShort := fs.Bool("w", false, "Run the fork in a new git worktree (short)")
+	branch := fs.String("branch", "", "Branch for --worktree (default: agent-deck/<title>)")
 
 	fs.Usage = func() {
 		fmt.Println("Usage: agent-deck session fork <id|title> [options]")
 		fmt.Println()
-		fmt.Println("Fork a Claude session with conversation context.")
+		fmt.Println("Fork a session with conversation context.")
+		fmt.Println()
+		fmt.Println("Claude sessions fork their conversation. Other agents start a new session")
+		fmt.Println("of the same tool that is handed a summary of the parent's transcript.")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
@@ -352,6 +457,7 @@ func handleSessionFork(profile string, args []string) {
 		fmt.Println("  agent-deck session fork my-project")
 		fmt.Println("  agent-deck session fork my-project -t \"my-fork\"")
 		fmt.Println("  agent-deck session fork my-project -t \"my-fork\" -g \"experiments\"")
+		fmt.Println("  agent-deck session fork -w --branch try-sqlite my-project   # Own git worktree")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -365,6 +471,11 @@ func handleSessionFork(profile string, args []string) {
 	// Merge short and long flags
 	forkTitle := mergeFlags(*title, *titleShort)
 	forkGroup := mergeFlags(*group, *groupShort)
+	forkOpts := session.ForkOptions{Worktree: *worktree || *worktreeShort, Branch: *branch}
+	if forkOpts.Branch != "" && !forkOpts.Worktree {
+		out.Error("--branch requires --worktree", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
 
 	// Load sessions
 	storage, instances, groupsData, err := loadSessionData(profile)
@@ -383,17 +494,16 @@ func handleSessionFork(profile string, args []string) {
 		os.Exit(1)
 	}
 
-	// Verify it's a Claude session
-	if inst.Tool != "claude" {
-		out.Error(fmt.Sprintf("session '%s' is not a Claude session (tool: %s)", inst.Title, inst.Tool), ErrCodeInvalidOperation)
-		os.Exit(1)
-	}
-
 	// Verify it can be forked
 	if !inst.CanFork() {
-		out.Error(fmt.Sprintf("session '%s' cannot be forked: no active Claude session ID", inst.Title), ErrCodeInvalidOperation)
+		reason := "it is not running"
+		if inst.Tool == "shell" {
+			reason = "it is not running an agent"
+		}
+		out.Error(fmt.Sprintf("session '%s' cannot be forked: %s", inst.Title, reason), ErrCodeInvalidOperation)
 		os.Exit(1)
 	}
+	seeded := !inst.CanForkNatively()
 
 	// Default title if not provided
 	if forkTitle == "" {
@@ -406,14 +516,14 @@ func handleSessionFork(profile string, args []string) {
 	}
 
 	// Create the forked instance
-	forkedInst, _, err := inst.CreateForkedInstance(forkTitle, forkGroup)
+	forkedInst, _, err := inst.CreateForkedInstanceWithOptions(forkTitle, forkGroup, forkOpts)
 	if err != nil {
 		out.Error(fmt.Sprintf("failed to create fork: %v", err), ErrCodeInvalidOperation)
 		os.Exit(1)
 	}
 
-	// Start the forked session
-	if err := forkedInst.Start(); err != nil {
+	// Start the forked session (seeded forks wait to hand over the transcript)
+	if err := forkedInst.StartFork(); err != nil {
 		out.Error(fmt.Sprintf("failed to start forked session: %v", err), ErrCodeInvalidOperation)
 		os.Exit(1)
 	}
@@ -434,12 +544,23 @@ func handleSessionFork(profile string, args []string) {
 	}
 
 	// Output success
-	out.Success(fmt.Sprintf("Forked session: %s -> %s (%s)", inst.Title, forkedInst.Title, TruncateID(forkedInst.ID)), map[string]interface{}{
+	result := map[string]interface{}{
 		"success":   true,
 		"parent_id": inst.ID,
 		"new_id":    forkedInst.ID,
 		"new_title": forkedInst.Title,
-	})
+		"seeded":    seeded,
+	}
+	message := fmt.Sprintf("Forked session: %s -> %s (%s)", inst.Title, forkedInst.Title, TruncateID(forkedInst.ID))
+	if seeded {
+		message += " from its transcript"
+	}
+	if forkedInst.HasWorktree() {
+		result["worktree_path"] = forkedInst.WorktreePath
+		result["worktree_branch"] = forkedInst.WorktreeBranch
+		message += fmt.Sprintf(" on branch %s", forkedInst.WorktreeBranch)
+	}
+	out.Success(message, result)
 }
 
 // handleSessionAttach attaches to a session interactively
@@ -563,9 +684,9 @@ func handleSessionShow(profile string, args []string) {
 	// Update status
 	_ = inst.UpdateStatus()
 
-	// Get MCP info if Claude session
+	// Get MCP info if agent-deck manages the tool's MCPs
 	var mcpInfo *session.MCPInfo
-	if inst.Tool == "claude" {
+	if inst.SupportsMCP() {
 		mcpInfo = inst.GetMCPInfo()
 	}
 
@@ -585,20 +706,39 @@ func handleSessionShow(profile string, args []string) {
 		jsonData["command"] = inst.Command
 	}
 
-	if inst.Tool == "claude" {
+	if inst.HasWorktree() {
+		jsonData["worktree_path"] = inst.WorktreePath
+		jsonData["worktree_branch"] = inst.WorktreeBranch
+	}
+
+	if inst.RunsClaude() {
 		jsonData["claude_session_id"] = inst.ClaudeSessionID
 		jsonData["can_fork"] = inst.CanFork()
 		jsonData["can_restart"] = inst.CanRestart()
+		jsonData["dangerous_mode"] = inst.ClaudeDangerousMode()
+		if inst.ClaudeOptions != nil {
+			jsonData["claude_options"] = inst.ClaudeOptions
+		}
+	}
 
-		if mcpInfo != nil && mcpInfo.HasAny() {
-			jsonData["mcps"] = map[string]interface{}{
-				"local":   mcpInfo.Local,
-				"global":  mcpInfo.Global,
-				"project": mcpInfo.Project,
-			}
+	if mcpInfo != nil && mcpInfo.HasAny() {
+		jsonData["mcps"] = map[string]interface{}{
+			"local":   mcpInfo.Local(),
+			"global":  mcpInfo.Global,
+			"project": mcpInfo.Project,
 		}
 	}
 
+	// Other tools resume by their own conversation ID
+	conversationID := ""
+	if !inst.RunsClaude() {
+		conversationID = inst.SessionID()
+	}
+	if conversationID != "" {
+		jsonData["conversation_id"] = conversationID
+		jsonData["can_restart"] = inst.CanRestart()
+	}
+
 	if inst.Exists() {
 		tmuxSession := inst.GetTmuxSession()
 		if tmuxSession != nil {
@@ -621,11 +761,15 @@ func handleSessionShow(profile string, args []string) {
 
 	sb.WriteString(fmt.Sprintf("Tool:    %s\n", inst.Tool))
 
+	if inst.HasWorktree() {
+		sb.WriteString(fmt.Sprintf("Branch:  %s (worktree %s)\n", inst.WorktreeBranch, FormatPath(inst.WorktreePath)))
+	}
+
 	if inst.Command != "" {
 		sb.WriteString(fmt.Sprintf("Command: %s\n", inst.Command))
 	}
 
-	if inst.Tool == "claude" {
+	if inst.RunsClaude() {
 		if inst.ClaudeSessionID != "" {
 			truncatedID := inst.ClaudeSessionID
 			if len(truncatedID) > 36 {
@@ -639,22 +783,32 @@ func handleSessionShow(profile string, args []string) {
 		} else {
 			sb.WriteString("Claude:  no session ID detected\n")
 		}
-
-		if mcpInfo != nil && mcpInfo.HasAny() {
-			var mcpParts []string
-			for _, name := range mcpInfo.Local() {
-				mcpParts = append(mcpParts, name+" (local)")
-			}
-			for _, name := range mcpInfo.Global {
-				mcpParts = append(mcpParts, name+" (global)")
+		if flags := inst.ClaudeOptions.String(); flags != "" || inst.ClaudeDangerousMode() {
+			if inst.ClaudeDangerousMode() {
+				flags = strings.TrimSpace(flags + " --dangerously-skip-permissions")
 			}
-			for _, name := range mcpInfo.Project {
-				mcpParts = append(mcpParts, name+" (project)")
-			}
-			sb.WriteString(fmt.Sprintf("MCPs:    %s\n", strings.Join(mcpParts, ", ")))
+			sb.WriteString(fmt.Sprintf("Flags:   %s\n", flags))
 		}
 	}
 
+	if mcpInfo != nil && mcpInfo.HasAny() {
+		var mcpParts []string
+		for _, name := range mcpInfo.Local() {
+			mcpParts = append(mcpParts, name+" (local)")
+		}
+		for _, name := range mcpInfo.Global {
+			mcpParts = append(mcpParts, name+" (global)")
+		}
+		for _, name := range mcpInfo.Project {
+			mcpParts = append(mcpParts, name+" (project)")
+		}
+		sb.WriteString(fmt.Sprintf("MCPs:    %s\n", strings.Join(mcpParts, ", ")))
+	}
+
+	if conversationID != "" {
+		sb.WriteString(fmt.Sprintf("Resume:  session_id=%s\n", conversationID))
+	}
+
 	sb.WriteString(fmt.Sprintf("Created: %s\n", inst.CreatedAt.Format("2006-01-02 15:04:05")))
 
 	if !inst.LastAccessedAt.IsZero() {
@@ -684,12 +838,21 @@ func handleSessionSet(profile string, args []string) {
 		fmt.Println("Update a session property.")
 		fmt.Println()
 		fmt.Println("Fields:")
-		fmt.Println("  title              Session title")
-		fmt.Println("  path               Project path")
-		fmt.Println("  command            Command to run")
-		fmt.Println("  tool               Tool type (claude, gemini, shell, etc.)")
-		fmt.Println("  claude-session-id  Claude conversation ID")
-		fmt.Println("  gemini-session-id  Gemini conversation ID")
+		fmt.Println("  title                   Session title")
+		fmt.Println("  path                    Project path")
+		fmt.Println("  command                 Command to run")
+		fmt.Println("  tool                    Tool type (claude, gemini, shell, etc.)")
+		fmt.Println("  claude-session-id       Claude conversation ID")
+		fmt.Println("  gemini-session-id       Gemini conversation ID")
+		fmt.Println("  codex-session-id        Codex conversation ID")
+		fmt.Println("  opencode-session-id     OpenCode session ID")
+		fmt.Println("  claude-model            Claude --model (empty: Claude's default)")
+		fmt.Println("  claude-permission-mode  Claude --permission-mode (default, acceptEdits, plan, bypassPermissions)")
+		fmt.Println("  claude-add-dirs         Claude --add-dir directories, comma-separated")
+		fmt.Println("  claude-allowed-tools    Claude --allowedTools, comma-separated")
+		fmt.Println("  dangerous-mode          true, false or default (use [claude] dangerous_mode)")
+		fmt.Println()
+		fmt.Println("Claude options apply from the next start, restart or fork.")
 		fmt.Println()
 		fmt.Println("Options:")
 		fs.PrintDefaults()
@@ -698,6 +861,9 @@ func handleSessionSet(profile string, args []string) {
 		fmt.Println("  agent-deck session set my-project title \"New Title\"")
 		fmt.Println("  agent-deck session set my-project claude-session-id \"abc123-def456\"")
 		fmt.Println("  agent-deck session set my-project path /new/path/to/project")
+		fmt.Println("  agent-deck session set my-project claude-permission-mode plan")
+		fmt.Println("  agent-deck session set my-project claude-add-dirs ../shared,../docs")
+		fmt.Println("  agent-deck session set my-project dangerous-mode false")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -717,16 +883,22 @@ func handleSessionSet(profile string, args []string) {
 
 	// Validate field name
 	validFields := map[string]bool{
-		"title":              true,
-		"path":               true,
-		"command":            true,
-		"tool":               true,
-		"claude-session-id":  true,
-		"gemini-session-id":  true,
+		"title":               true,
+		"path":                true,
+		"command":             true,
+		"tool":                true,
+		"claude-session-id":   true,
+		"gemini-session-id":   true,
+		"codex-session-id":    true,
+		"opencode-session-id": true,
+	}
+	for _, claudeField := range session.ClaudeOptionFields {
+		validFields[claudeField] = true
 	}
 
 	if !validFields[field] {
-		out.Error(fmt.Sprintf("invalid field: %s\nValid fields: title, path, command, tool, claude-session-id, gemini-session-id", field), ErrCodeInvalidOperation)
+		out.Error(fmt.Sprintf("invalid field: %s\nValid fields: title, path, command, tool, claude-session-id, gemini-session-id, codex-session-id, opencode-session-id, %s",
+			field, strings.Join(session.ClaudeOptionFields, ", ")), ErrCodeInvalidOperation)
 		os.Exit(1)
 	}
 
@@ -780,6 +952,20 @@ func handleSessionSet(profile string, args []string) {
 		if tmuxSess := inst.GetTmuxSession(); tmuxSess != nil && tmuxSess.Exists() {
 			_ = exec.Command("tmux", "set-environment", "-t", tmuxSess.Name, "GEMINI_SESSION_ID", value).Run()
 		}
+	case "codex-session-id":
+		oldValue = inst.CodexSessionID
+		inst.CodexSessionID = value
+		inst.CodexDetectedAt = time.Now()
+	case "opencode-session-id":
+		oldValue = inst.OpenCodeSessionID
+		inst.OpenCodeSessionID = value
+		inst.OpenCodeDetectedAt = time.Now()
+	default: // Per-session Claude options
+		oldValue = inst.ClaudeOption(field)
+		if err := inst.SetClaudeOption(field, value); err != nil {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
 	}
 
 	// Save
@@ -1143,6 +1329,22 @@ func handleSessionSend(profile string, args []string) {
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	quiet := fs.Bool("q", false, "Quiet mode")
 	noWait := fs.Bool("no-wait", false, "Don't wait for agent to be ready (send immediately)")
+	sel := addSelectorFlags(fs)
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session send [options] <id|title> <message>")
+		fmt.Println("       agent-deck session send [--group <path>] [--status <status>] [--filter <text>] <message>")
+		fmt.Println()
+		fmt.Println("Send a message to a running session, or to every selected running session.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session send my-project \"run the tests\"")
+		fmt.Println("  agent-deck session send --group work --status waiting \"continue\"")
+	}
+
 	if err := fs.Parse(args); err != nil {
 		os.Exit(1)
 	}
@@ -1150,6 +1352,23 @@ func handleSessionSend(profile string, args []string) {
 
 	out := NewCLIOutput(*jsonOutput, *quiet)
 
+	if sel.active() {
+		if len(remaining) == 0 {
+			out.Error("usage: agent-deck session send [--group|--status|--filter ...] <message>", ErrCodeInvalidOperation)
+			os.Exit(1)
+		}
+		_, instances, _, err := loadSessionData(profile)
+		if err != nil {
+			out.Error(err.Error(), ErrCodeNotFound)
+			os.Exit(1)
+		}
+		targets := selectBulkTargets(out, sel, "", instances)
+		if !printBulkResults(out, "Sent to", sendToSessions(targets, strings.Join(remaining, " "), !*noWait)) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	if len(remaining) < 2 {
 		out.Error("usage: agent-deck session send <id> <message>", ErrCodeInvalidOperation)
 		os.Exit(1)
@@ -1197,17 +1416,8 @@ func handleSessionSend(profile string, args []string) {
 	}
 
 	// Send message via tmux
-	tmuxName := tmuxSess.Name
-	cmd := exec.Command("tmux", "send-keys", "-l", "-t", tmuxName, message)
-	if err := cmd.Run(); err != nil {
-		out.Error(fmt.Sprintf("failed to send message: %v", err), ErrCodeInvalidOperation)
-		os.Exit(1)
-	}
-
-	// Send Enter
-	cmd = exec.Command("tmux", "send-keys", "-t", tmuxName, "Enter")
-	if err := cmd.Run(); err != nil {
-		out.Error(fmt.Sprintf("failed to send Enter: %v", err), ErrCodeInvalidOperation)
+	if err := inst.SendMessage(message); err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
 		os.Exit(1)
 	}
 
@@ -1219,6 +1429,297 @@ func handleSessionSend(profile string, args []string) {
 	})
 }
 
+// selectBulkTargets returns the sessions a bulk command's selectors pick,
+// exiting if there are none (or a session was named as well)
+func selectBulkTargets(out *CLIOutput, sel *sessionSelector, identifier string, instances []*session.Instance) []*session.Instance {
+	if identifier != "" {
+		out.Error("give a session or --group/--status/--filter, not both", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	targets, err := sel.selectSessions(instances)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if len(targets) == 0 {
+		out.Error("no sessions match the selection", ErrCodeNotFound)
+		os.Exit(2)
+	}
+	return targets
+}
+
+// sendToSessions sends a message to the running sessions among targets at
+// once, each after its agent is ready (if wait)
+func sendToSessions(targets []*session.Instance, message string, wait bool) []bulkResult {
+	results := make([]bulkResult, len(targets))
+	var wg sync.WaitGroup
+	for i, inst := range targets {
+		results[i].inst = inst
+		tmuxSess := inst.GetTmuxSession()
+		if !inst.Exists() || tmuxSess == nil {
+			results[i].skipped = "not running"
+			continue
+		}
+		wg.Add(1)
+		go func(r *bulkResult) {
+			defer wg.Done()
+			if wait {
+				if err := waitForAgentReady(tmuxSess, r.inst.Tool); err != nil {
+					r.err = fmt.Errorf("timeout waiting for agent: %w", err)
+					return
+				}
+			}
+			r.err = r.inst.SendMessage(message)
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
+
+// Exit codes of session ask (besides 1 for usage errors, 2 for not found)
+const (
+	exitAskTimeout    = 3 // No reply within --timeout
+	exitAskAgentError = 4 // The session stopped, errored or needs permission
+)
+
+// handleSessionAsk sends a prompt to a running session and prints the agent's
+// reply to it once the turn is done
+func handleSessionAsk(profile string, args []string) {
+	fs := flag.NewFlagSet("session ask", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+	timeout := fs.Duration("timeout", 10*time.Minute, "How long to wait for the reply (including for an earlier turn to finish)")
+	stream := fs.Bool("stream", false, "Print the reply as it is written (JSON: one event per line)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session ask [options] <id|title> <prompt>")
+		fmt.Println()
+		fmt.Println("Send a prompt to a running session, wait until the agent has answered it and")
+		fmt.Println("print only that answer. Claude and Gemini replies are read from the")
+		fmt.Println("conversation file; other tools' from the terminal output after the prompt.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Exit codes:")
+		fmt.Println("  0  Reply printed")
+		fmt.Println("  1  Invalid usage, or the prompt could not be sent")
+		fmt.Println("  2  Session not found")
+		fmt.Println("  3  Timed out (the reply so far is still printed)")
+		fmt.Println("  4  Agent error: the session stopped, errored or needs permission")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session ask my-project \"what does main.go do?\"")
+		fmt.Println("  agent-deck session ask --timeout 30m --stream my-project \"fix the failing test\"")
+		fmt.Println("  agent-deck session ask --json my-project \"list the TODOs\" | jq -r .content")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	remaining := fs.Args()
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	if len(remaining) < 2 {
+		out.Error("usage: agent-deck session ask <id> <prompt>", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	prompt := strings.Join(remaining[1:], " ")
+
+	// Load sessions
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	// Resolve session
+	inst, errMsg, errCode := ResolveSession(remaining[0], instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+	if !inst.Exists() {
+		out.Error(fmt.Sprintf("session '%s' is not running", inst.Title), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	turn, err := inst.BeginTurn(ctx, prompt)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to send prompt: %v", err), ErrCodeInvalidOperation)
+		os.Exit(askExitCode(err))
+	}
+
+	// Streaming prints what's added to the reply; a reply that changes
+	// earlier text (terminal redraws) is printed once it's final
+	printed := ""
+	var progress func(string)
+	if *stream && !quietMode {
+		progress = func(reply string) {
+			if !strings.HasPrefix(reply, printed) {
+				return
+			}
+			delta := reply[len(printed):]
+			printed = reply
+			if *jsonOutput {
+				printJSONLine(map[string]interface{}{"type": "delta", "content": delta})
+			} else {
+				fmt.Print(delta)
+			}
+		}
+	}
+
+	reply, waitErr := turn.Wait(ctx, progress)
+	duration := time.Since(turn.Sent)
+
+	switch {
+	case *stream && *jsonOutput && !quietMode:
+		event := map[string]interface{}{
+			"type":          "done",
+			"success":       waitErr == nil,
+			"session_id":    inst.ID,
+			"session_title": inst.Title,
+			"content":       reply,
+			"duration_ms":   duration.Milliseconds(),
+		}
+		if waitErr != nil {
+			event["error"] = waitErr.Error()
+		}
+		printJSONLine(event)
+	case *stream && !quietMode:
+		if strings.HasPrefix(reply, printed) {
+			fmt.Println(reply[len(printed):])
+		} else {
+			fmt.Printf("\n---\n%s\n", reply)
+		}
+	case *jsonOutput:
+		data := map[string]interface{}{
+			"success":       waitErr == nil,
+			"session_id":    inst.ID,
+			"session_title": inst.Title,
+			"tool":          inst.Tool,
+			"role":          "assistant",
+			"content":       reply,
+			"duration_ms":   duration.Milliseconds(),
+		}
+		if waitErr != nil {
+			data["error"] = waitErr.Error()
+		}
+		out.Print("", data)
+	case reply != "":
+		// Quiet mode prints the reply too: it's the command's result
+		fmt.Println(reply)
+	}
+
+	if waitErr != nil {
+		if !*jsonOutput {
+			fmt.Fprintf(os.Stderr, "Error: %v (after %s)\n", waitErr, duration.Round(time.Second))
+		}
+		os.Exit(askExitCode(waitErr))
+	}
+}
+
+// askExitCode maps a turn's error to session ask's exit code
+func askExitCode(err error) int {
+	switch {
+	case errors.Is(err, session.ErrTurnTimeout):
+		return exitAskTimeout
+	case errors.Is(err, session.ErrAgentStopped), errors.Is(err, session.ErrAgentBlocked),
+		errors.Is(err, session.ErrAgentFailed):
+		return exitAskAgentError
+	}
+	return 1
+}
+
+// printJSONLine prints data as one line of JSON (for streamed events)
+func printJSONLine(data interface{}) {
+	line, err := json.Marshal(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to format JSON: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(line))
+}
+
+// handleSessionApproval answers a session's pending permission prompt
+func handleSessionApproval(profile string, args []string, approve bool) {
+	action := "deny"
+	if approve {
+		action = "approve"
+	}
+	fs := flag.NewFlagSet("session "+action, flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Printf("Usage: agent-deck session %s <id|title> [options]\n", action)
+		fmt.Println()
+		fmt.Println("Answer a session's permission prompt (status: approval) by sending the")
+		fmt.Println("tool's keys for yes/no. Custom tools set them with approve_keys and")
+		fmt.Println("deny_keys in [tools.NAME.status].")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	identifier := fs.Arg(0)
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSession(identifier, instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+
+	if !inst.Exists() {
+		out.Error(fmt.Sprintf("session '%s' is not running", inst.Title), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	answer := inst.Deny
+	if approve {
+		answer = inst.Approve
+	}
+	if err := answer(); err != nil {
+		out.Error(fmt.Sprintf("failed to %s '%s': %v", action, inst.Title, err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	verb := "Denied"
+	if approve {
+		verb = "Approved"
+	}
+	out.Success(fmt.Sprintf("%s pending request in '%s'", verb, inst.Title), map[string]interface{}{
+		"success":  true,
+		"id":       inst.ID,
+		"title":    inst.Title,
+		"approved": approve,
+	})
+}
+
 // waitForAgentReady waits for Claude/Gemini/other agents to be ready for input
 // Uses status detection: waits for "active" → "waiting" transition
 func waitForAgentReady(tmuxSess *tmux.Session, tool string) error {
@@ -1325,13 +1826,11 @@ func handleSessionOutput(profile string, args []string) {
 		"timestamp":     response.Timestamp,
 	}
 	// Add tool-specific conversation session ID
+	// (named after tools whose adapter tracks it, e.g. "gemini_session_id")
 	if response.SessionID != "" {
-		switch response.Tool {
-		case "claude":
-			jsonData["claude_session_id"] = response.SessionID
-		case "gemini":
-			jsonData["gemini_session_id"] = response.SessionID
-		default:
+		if inst.SessionID() != "" {
+			jsonData[response.Tool+"_session_id"] = response.SessionID
+		} else {
 			jsonData["conversation_id"] = response.SessionID
 		}
 	}
@@ -1348,6 +1847,75 @@ func handleSessionOutput(profile string, args []string) {
 	out.Print(sb.String(), jsonData)
 }
 
+// handleSessionTranscript renders a session's whole conversation
+func handleSessionTranscript(profile string, args []string) {
+	fs := flag.NewFlagSet("session transcript", flag.ExitOnError)
+	format := fs.String("format", session.TranscriptMarkdown, "Output format: md, html or json")
+	output := fs.String("output", "", "Write to this file instead of stdout")
+	outputShort := fs.String("o", "", "Write to this file instead of stdout (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck session transcript [options] [id|title]")
+		fmt.Println()
+		fmt.Println("Render the whole conversation of a Claude or Gemini session, with its tool")
+		fmt.Println("calls, for sharing (e.g. in a code review). If no ID is provided,")
+		fmt.Println("auto-detects current session.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck session transcript my-project > transcript.md")
+		fmt.Println("  agent-deck session transcript --format html -o transcript.html my-project")
+		fmt.Println("  agent-deck session transcript --format json my-project | jq '.messages | length'")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*format == session.TranscriptJSON, false)
+
+	// Load sessions
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to load sessions: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	// Resolve session (allow current session detection)
+	inst, errMsg, errCode := ResolveSessionOrCurrent(fs.Arg(0), instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		if errCode == ErrCodeNotFound {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+
+	transcript, err := inst.GetTranscript()
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to read transcript: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	rendered, err := transcript.Render(*format)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	path := mergeFlags(*output, *outputShort)
+	if path == "" {
+		fmt.Print(rendered)
+		return
+	}
+	if err := os.WriteFile(path, []byte(rendered), 0644); err != nil {
+		out.Error(fmt.Sprintf("failed to write %s: %v", path, err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "✓ Wrote %d messages to %s\n", len(transcript.Messages), path)
+}
+
 // handleSessionCurrent shows current session and profile (auto-detected)
 func handleSessionCurrent(profileArg string, args []string) {
 	fs := flag.NewFlagSet("session current", flag.ExitOnError)
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleTemplate handles all template subcommands
+func handleTemplate(profile string, args []string) {
+	if len(args) == 0 {
+		handleTemplateList(nil)
+		return
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		handleTemplateList(args[1:])
+	case "show":
+		handleTemplateShow(args[1:])
+	case "save-from":
+		handleTemplateSaveFrom(profile, args[1:])
+	case "help", "-h", "--help":
+		printTemplateHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown template command '%s'\n", args[0])
+		printTemplateHelp()
+		os.Exit(1)
+	}
+}
+
+// printTemplateHelp prints help for template commands
+func printTemplateHelp() {
+	fmt.Println("Usage: agent-deck template <command> [options]")
+	fmt.Println()
+	fmt.Println("Manage session templates ([templates.NAME] in config.toml).")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  list                     List all templates")
+	fmt.Println("  show <name>              Show a template's configuration")
+	fmt.Println("  save-from <id> <name>    Save a session's configuration as a template")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck template list")
+	fmt.Println("  agent-deck template show review")
+	fmt.Println("  agent-deck template save-from my-project review")
+	fmt.Println("  agent-deck add --template review .")
+}
+
+// handleTemplateList lists all templates from config.toml
+func handleTemplateList(args []string) {
+	fs := flag.NewFlagSet("template list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck template list [options]")
+		fmt.Println()
+		fmt.Println("List all session templates from config.toml.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	names := session.GetTemplateNames()
+	if len(names) == 0 {
+		if *jsonOutput {
+			out.Print("", map[string]interface{}{
+				"templates": []interface{}{},
+			})
+		} else if !quietMode {
+			fmt.Println("No templates configured.")
+			fmt.Println()
+			fmt.Println("Define templates in ~/.agent-deck/config.toml:")
+			fmt.Println()
+			fmt.Println("  [templates.review]")
+			fmt.Println("  tool = \"claude\"")
+			fmt.Println("  mcps = [\"github\"]")
+			fmt.Println("  prompt = \"Review the changes on {{branch}}\"")
+			fmt.Println()
+			fmt.Println("or save one from a session: agent-deck template save-from <id> <name>")
+		}
+		return
+	}
+
+	if *jsonOutput {
+		templates := make([]map[string]interface{}, 0, len(names))
+		for _, name := range names {
+			templates = append(templates, templateJSON(name, session.GetTemplate(name)))
+		}
+		out.Print("", map[string]interface{}{
+			"templates": templates,
+		})
+		return
+	}
+
+	if quietMode {
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return
+	}
+
+	configPath, _ := session.GetUserConfigPath()
+	fmt.Printf("Templates (from %s):\n\n", FormatPath(configPath))
+
+	maxName := 12
+	for _, name := range names {
+		if len(name) > maxName {
+			maxName = len(name)
+		}
+	}
+	if maxName > 20 {
+		maxName = 20
+	}
+
+	fmt.Printf("%-*s %-10s %-16s %s\n", maxName, "NAME", "TOOL", "GROUP", "DESCRIPTION")
+	fmt.Println(strings.Repeat("-", maxName+50))
+	for _, name := range names {
+		tmpl := session.GetTemplate(name)
+		fmt.Printf("%-*s %-10s %-16s %s\n", maxName, truncate(name, maxName), truncate(tmpl.ToolName(), 10),
+			truncate(tmpl.Group, 16), tmpl.Description)
+	}
+
+	fmt.Printf("\nTotal: %d templates\n", len(names))
+}
+
+// handleTemplateShow shows a template's configuration
+func handleTemplateShow(args []string) {
+	fs := flag.NewFlagSet("template show", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck template show [options] <name>")
+		fmt.Println()
+		fmt.Println("Show a session template's configuration.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, false)
+
+	name := fs.Arg(0)
+	if name == "" {
+		out.Error("template name is required", ErrCodeNotFound)
+		fs.Usage()
+		os.Exit(1)
+	}
+	tmpl := session.GetTemplate(name)
+	if tmpl == nil {
+		out.Error(fmt.Sprintf("template '%s' not found in config.toml", name), ErrCodeNotFound)
+		os.Exit(2)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Template: %s\n", name))
+	if tmpl.Description != "" {
+		sb.WriteString(fmt.Sprintf("About:    %s\n", tmpl.Description))
+	}
+	sb.WriteString(fmt.Sprintf("Tool:     %s\n", tmpl.ToolName()))
+	if command := tmpl.StartCommand(); command != "" {
+		sb.WriteString(fmt.Sprintf("Command:  %s\n", command))
+	}
+	if tmpl.Group != "" {
+		sb.WriteString(fmt.Sprintf("Group:    %s\n", tmpl.Group))
+	}
+	if len(tmpl.MCPs) > 0 {
+		sb.WriteString(fmt.Sprintf("MCPs:     %s\n", strings.Join(tmpl.MCPs, ", ")))
+	}
+	if len(tmpl.Env) > 0 {
+		keys := make([]string, 0, len(tmpl.Env))
+		for key := range tmpl.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		sb.WriteString("Env:\n")
+		for _, key := range keys {
+			sb.WriteString(fmt.Sprintf("  %s=%s\n", key, tmpl.Env[key]))
+		}
+	}
+	if flags := tmpl.Claude.String(); flags != "" {
+		sb.WriteString(fmt.Sprintf("Flags:    %s\n", flags))
+	}
+	if tmpl.Claude != nil && tmpl.Claude.DangerousMode != nil {
+		sb.WriteString(fmt.Sprintf("Skip permissions: %t\n", *tmpl.Claude.DangerousMode))
+	}
+	if tmpl.Prompt != "" {
+		sb.WriteString(fmt.Sprintf("Prompt:\n  %s\n", strings.ReplaceAll(tmpl.Prompt, "\n", "\n  ")))
+	}
+
+	out.Print(sb.String(), templateJSON(name, tmpl))
+}
+
+// handleTemplateSaveFrom saves a session's configuration as a template
+func handleTemplateSaveFrom(profile string, args []string) {
+	fs := flag.NewFlagSet("template save-from", flag.ExitOnError)
+	description := fs.String("description", "", "Template description")
+	prompt := fs.String("prompt", "", "Initial prompt ({{path}} and {{branch}} are expanded)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck template save-from [options] <id|title> <name>")
+		fmt.Println()
+		fmt.Println("Save a session's tool, command, group, MCPs, environment and Claude")
+		fmt.Println("flags as a template in config.toml.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck template save-from my-project review")
+		fmt.Println("  agent-deck template save-from --prompt \"Review {{branch}}\" my-project review")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	identifier, name := fs.Arg(0), fs.Arg(1)
+	if identifier == "" || name == "" {
+		out.Error("session and template name are required", ErrCodeNotFound)
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	_, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	inst, errMsg, errCode := ResolveSession(identifier, instances)
+	if inst == nil {
+		out.Error(errMsg, errCode)
+		os.Exit(2)
+	}
+
+	tmpl := session.TemplateFromInstance(inst)
+	tmpl.Description = *description
+	tmpl.Prompt = *prompt
+
+	if err := session.SaveTemplate(name, tmpl); err != nil {
+		code := ErrCodeInvalidOperation
+		if session.GetTemplate(name) != nil {
+			code = ErrCodeAlreadyExists
+		}
+		out.Error(err.Error(), code)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Saved template '%s' from session '%s'", name, inst.Title), map[string]interface{}{
+		"success":  true,
+		"template": templateJSON(name, &tmpl),
+	})
+}
+
+// templateJSON builds the JSON representation of a template
+func templateJSON(name string, tmpl *session.TemplateDef) map[string]interface{} {
+	data := map[string]interface{}{
+		"name":    name,
+		"tool":    tmpl.ToolName(),
+		"command": tmpl.StartCommand(),
+	}
+	if tmpl.Description != "" {
+		data["description"] = tmpl.Description
+	}
+	if tmpl.Group != "" {
+		data["group"] = tmpl.Group
+	}
+	if len(tmpl.MCPs) > 0 {
+		data["mcps"] = tmpl.MCPs
+	}
+	if len(tmpl.Env) > 0 {
+		data["env"] = tmpl.Env
+	}
+	if tmpl.Prompt != "" {
+		data["prompt"] = tmpl.Prompt
+	}
+	if !tmpl.Claude.IsZero() {
+		data["claude_options"] = tmpl.Claude
+	}
+	return data
+}
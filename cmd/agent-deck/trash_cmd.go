@@ -0,0 +1,373 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleUndo reverts the last operations recorded in the profile's journal
+func handleUndo(profile string, args []string) {
+	fs := flag.NewFlagSet("undo", flag.ExitOnError)
+	count := fs.Int("n", 1, "Number of operations to undo")
+	list := fs.Bool("list", false, "List the operations that can be undone instead")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck undo [options]")
+		fmt.Println()
+		fmt.Println("Undo the last session and group operations (create, rename, move, set,")
+		fmt.Println("delete), whether made in the TUI or the CLI. Deleted sessions come back")
+		fmt.Println("stopped; restart them to resume their conversations.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck undo              # Undo the last operation")
+		fmt.Println("  agent-deck undo -n 3         # Undo the last three")
+		fmt.Println("  agent-deck undo --list       # Show what can be undone")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	if *count < 1 {
+		out.Error("-n must be at least 1", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	storage, instances, _, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	if *list {
+		printUndoList(out, storage)
+		return
+	}
+
+	entries, err := storage.Undo(*count)
+	if errors.Is(err, session.ErrNothingToUndo) {
+		out.Error("nothing to undo", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	// Sessions removed by undoing their creation are stopped like any other
+	// removed session
+	byID := make(map[string]*session.Instance, len(instances))
+	for _, inst := range instances {
+		byID[inst.ID] = inst
+	}
+	for _, entry := range entries {
+		for _, c := range entry.Changes {
+			if c.IsGroup() || c.Op != session.JournalCreate {
+				continue
+			}
+			if inst := byID[c.After.ID]; inst != nil && inst.Exists() {
+				if killErr := inst.Kill(); killErr != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to stop %q: %v\n", inst.Title, killErr)
+				}
+			}
+		}
+	}
+
+	undone := make([]map[string]interface{}, 0, len(entries))
+	var human strings.Builder
+	for _, entry := range entries {
+		undone = append(undone, journalEntryJSON(entry))
+		fmt.Fprintf(&human, "%s Undone: %s\n", successSymbol, entry.Summary())
+	}
+	if err != nil {
+		out.Print(human.String(), map[string]interface{}{
+			"success": false,
+			"undone":  undone,
+			"error":   err.Error(),
+		})
+		if !*jsonOutput {
+			out.Error(err.Error(), ErrCodeInvalidOperation)
+		}
+		os.Exit(1)
+	}
+	out.Print(human.String(), map[string]interface{}{
+		"success": true,
+		"undone":  undone,
+	})
+}
+
+// printUndoList prints the journaled operations that can be undone, newest
+// first
+func printUndoList(out *CLIOutput, storage *session.Storage) {
+	entries, err := storage.Journal()
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	undone := make(map[int64]bool)
+	for _, entry := range entries {
+		if entry.Undoes != 0 {
+			undone[entry.Undoes] = true
+		}
+	}
+	items := make([]map[string]interface{}, 0)
+	var human strings.Builder
+	for i := len(entries) - 1; i >= 0 && len(items) < 20; i-- {
+		entry := entries[i]
+		if entry.Undoes != 0 || undone[entry.Seq] {
+			continue
+		}
+		items = append(items, journalEntryJSON(entry))
+		fmt.Fprintf(&human, "%2d. %-8s %s\n", len(items), formatUptime(time.Since(entry.Time))+" ago", entry.Summary())
+	}
+	if len(items) == 0 {
+		human.WriteString("Nothing to undo.\n")
+	}
+	out.Print(human.String(), map[string]interface{}{
+		"operations": items,
+	})
+}
+
+// journalEntryJSON returns a journal entry for JSON output
+func journalEntryJSON(entry *session.JournalEntry) map[string]interface{} {
+	changes := make([]string, 0, len(entry.Changes))
+	for _, c := range entry.Changes {
+		changes = append(changes, c.String())
+	}
+	return map[string]interface{}{
+		"seq":     entry.Seq,
+		"time":    entry.Time,
+		"summary": entry.Summary(),
+		"changes": changes,
+	}
+}
+
+// handleTrash handles all trash subcommands
+func handleTrash(profile string, args []string) {
+	if len(args) == 0 {
+		handleTrashList(profile, nil)
+		return
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		handleTrashList(profile, args[1:])
+	case "restore":
+		handleTrashRestore(profile, args[1:])
+	case "purge":
+		handleTrashPurge(profile, args[1:])
+	case "help", "-h", "--help":
+		printTrashHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown trash command '%s'\n", args[0])
+		printTrashHelp()
+		os.Exit(1)
+	}
+}
+
+// printTrashHelp prints help for trash commands
+func printTrashHelp() {
+	fmt.Println("Usage: agent-deck trash <command> [options]")
+	fmt.Println()
+	fmt.Println("Restore deleted sessions. Deleted sessions are kept with their Claude/Gemini")
+	fmt.Println("conversation IDs for [trash] retention_days in config.toml (default: 30).")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  list                     List deleted sessions")
+	fmt.Println("  restore <id|title>       Put a deleted session back")
+	fmt.Println("  purge <id|title>         Permanently delete a session from the trash")
+	fmt.Println("  purge --all              Empty the trash")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  agent-deck trash list")
+	fmt.Println("  agent-deck trash restore \"My Project\"")
+	fmt.Println("  agent-deck session restart \"My Project\"   # Resume its conversation")
+	fmt.Println("  agent-deck trash purge --all")
+}
+
+// handleTrashList lists the deleted sessions that can be restored
+func handleTrashList(profile string, args []string) {
+	fs := flag.NewFlagSet("trash list", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck trash list [options]")
+		fmt.Println()
+		fmt.Println("List deleted sessions that can still be restored, newest first.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	quietMode := *quiet || *quietShort
+	out := NewCLIOutput(*jsonOutput, quietMode)
+
+	storage, err := session.NewStorageWithProfile(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	items, err := storage.Trash()
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		sessions := make([]map[string]interface{}, 0, len(items))
+		for _, item := range items {
+			s := item.Session
+			sessions = append(sessions, map[string]interface{}{
+				"id":                s.ID,
+				"title":             s.Title,
+				"path":              s.ProjectPath,
+				"group":             s.GroupPath,
+				"tool":              s.Tool,
+				"claude_session_id": s.ClaudeSessionID,
+				"gemini_session_id": s.GeminiSessionID,
+				"deleted_at":        item.DeletedAt,
+			})
+		}
+		out.Print("", map[string]interface{}{
+			"sessions": sessions,
+		})
+		return
+	}
+
+	if quietMode {
+		for _, item := range items {
+			fmt.Println(item.Session.ID)
+		}
+		return
+	}
+
+	if len(items) == 0 {
+		fmt.Println("Trash is empty.")
+		return
+	}
+
+	fmt.Printf("%-12s %-24s %-16s %-10s %s\n", "ID", "TITLE", "GROUP", "TOOL", "DELETED")
+	fmt.Println(strings.Repeat("-", 78))
+	for _, item := range items {
+		s := item.Session
+		fmt.Printf("%-12s %-24s %-16s %-10s %s ago\n", TruncateID(s.ID), truncate(s.Title, 24),
+			truncate(s.GroupPath, 16), truncate(s.Tool, 10), formatUptime(time.Since(item.DeletedAt)))
+	}
+	fmt.Printf("\nTotal: %d sessions (kept %d days)\n", len(items), session.GetTrashSettings().RetentionDays)
+}
+
+// handleTrashRestore puts a deleted session back
+func handleTrashRestore(profile string, args []string) {
+	fs := flag.NewFlagSet("trash restore", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck trash restore [options] <id|title>")
+		fmt.Println()
+		fmt.Println("Put a deleted session back. It comes back stopped; restart it to resume")
+		fmt.Println("its conversation.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	identifier := fs.Arg(0)
+	if identifier == "" {
+		out.Error("session ID or title is required", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	storage, err := session.NewStorageWithProfile(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	restored, err := storage.RestoreFromTrash(identifier)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Restored %q (restart it to resume: agent-deck session restart %s)",
+		restored.Title, TruncateID(restored.ID)), map[string]interface{}{
+		"success": true,
+		"id":      restored.ID,
+		"title":   restored.Title,
+		"group":   restored.GroupPath,
+	})
+}
+
+// handleTrashPurge permanently deletes sessions from the trash
+func handleTrashPurge(profile string, args []string) {
+	fs := flag.NewFlagSet("trash purge", flag.ExitOnError)
+	all := fs.Bool("all", false, "Empty the trash")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck trash purge [options] <id|title>")
+		fmt.Println()
+		fmt.Println("Permanently delete a session from the trash, or every session with --all.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	identifier := fs.Arg(0)
+	if identifier == "" && !*all {
+		out.Error("session ID or title is required (or --all)", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if identifier != "" && *all {
+		out.Error("give a session or --all, not both", ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	storage, err := session.NewStorageWithProfile(profile)
+	if err != nil {
+		out.Error(fmt.Sprintf("failed to initialize storage: %v", err), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	purged, err := storage.PurgeTrash(identifier)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Purged %d session(s) from the trash", purged), map[string]interface{}{
+		"success": true,
+		"purged":  purged,
+	})
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+)
+
+// handleApply brings a profile in line with a workspace file
+func handleApply(profile string, args []string) {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	file := fs.String("file", "", "Workspace file to apply")
+	fileShort := fs.String("f", "", "Workspace file to apply (short)")
+	dryRun := fs.Bool("dry-run", false, "Show the changes without saving them")
+	prune := fs.Bool("prune", false, "Remove sessions and groups that are not in the file")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Minimal output")
+	quietShort := fs.Bool("q", false, "Minimal output (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck apply -f <file> [options]")
+		fmt.Println()
+		fmt.Println("Create and update the profile's groups and sessions to match a workspace")
+		fmt.Println("file (see 'agent-deck export'). Sessions are matched by title; applying")
+		fmt.Println("the same file twice changes nothing.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck apply -f deck.toml --dry-run")
+		fmt.Println("  agent-deck apply -f deck.toml")
+		fmt.Println("  agent-deck -p work apply -f work.toml --prune")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	out := NewCLIOutput(*jsonOutput, *quiet || *quietShort)
+
+	path := mergeFlags(*file, *fileShort)
+	if path == "" {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		out.Error("workspace file is required (-f deck.toml)", ErrCodeNotFound)
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	workspace, err := session.LoadWorkspace(path)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	storage, instances, groups, err := loadSessionData(profile)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeNotFound)
+		os.Exit(1)
+	}
+	groupTree := session.NewGroupTreeWithGroups(instances, groups)
+
+	plan, err := session.PlanWorkspace(workspace, instances, groupTree, *prune)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	var sb strings.Builder
+	changes := make([]map[string]interface{}, 0, len(plan.Changes))
+	for _, c := range plan.Changes {
+		symbol := map[string]string{
+			session.WorkspaceCreate: "+",
+			session.WorkspaceUpdate: "~",
+			session.WorkspaceRemove: "-",
+		}[c.Action]
+		line := fmt.Sprintf("  %s %-8s %s", symbol, c.Kind, c.Name)
+		if len(c.Fields) > 0 {
+			line += fmt.Sprintf(" (%s)", strings.Join(c.Fields, ", "))
+		}
+		sb.WriteString(line + "\n")
+
+		change := map[string]interface{}{
+			"action": c.Action,
+			"kind":   c.Kind,
+			"name":   c.Name,
+		}
+		if len(c.Fields) > 0 {
+			change["fields"] = c.Fields
+		}
+		changes = append(changes, change)
+	}
+
+	jsonData := map[string]interface{}{
+		"success": true,
+		"profile": storage.Profile(),
+		"dry_run": *dryRun,
+		"changes": changes,
+	}
+	if len(plan.Unmanaged) > 0 {
+		jsonData["unmanaged"] = plan.Unmanaged
+	}
+
+	if !plan.HasChanges() {
+		out.Success(fmt.Sprintf("No changes: profile '%s' matches %s", storage.Profile(), path), jsonData)
+		printUnmanaged(out, plan)
+		return
+	}
+
+	if *dryRun {
+		out.Print(fmt.Sprintf("Changes to profile '%s' (dry run, nothing saved):\n%s", storage.Profile(), sb.String()), jsonData)
+		printUnmanaged(out, plan)
+		return
+	}
+
+	instances, err = plan.Apply(instances, groupTree)
+	if err != nil {
+		out.Error(err.Error(), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+	if err := storage.SaveWithGroups(instances, groupTree); err != nil {
+		out.Error(fmt.Sprintf("failed to save: %v", err), ErrCodeInvalidOperation)
+		os.Exit(1)
+	}
+
+	out.Success(fmt.Sprintf("Applied %s to profile '%s':\n%s", path, storage.Profile(), strings.TrimRight(sb.String(), "\n")), jsonData)
+	printUnmanaged(out, plan)
+}
+
+// printUnmanaged notes the sessions and groups a non-pruning apply kept
+func printUnmanaged(out *CLIOutput, plan *session.WorkspacePlan) {
+	if len(plan.Unmanaged) == 0 || out.jsonMode || out.quietMode {
+		return
+	}
+	fmt.Printf("\nNot in the file (kept, use --prune to remove): %s\n", strings.Join(plan.Unmanaged, ", "))
+}
+
+// handleExport writes a profile's groups and sessions as a workspace file
+func handleExport(profile string, args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	output := fs.String("output", "", "Write to this file instead of stdout")
+	outputShort := fs.String("o", "", "Write to this file instead of stdout (short)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: agent-deck export [options]")
+		fmt.Println()
+		fmt.Println("Export the profile's groups and sessions as a workspace file for")
+		fmt.Println("'agent-deck apply'.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  agent-deck export > deck.toml")
+		fmt.Println("  agent-deck -p work export -o work.toml")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	storage, instances, groups, err := loadSessionData(profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	groupTree := session.NewGroupTreeWithGroups(instances, groups)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# agent-deck workspace (profile '%s')\n", storage.Profile())
+	fmt.Fprintf(&buf, "# Apply with: agent-deck apply -f <this file>\n\n")
+	if err := session.ExportWorkspace(instances, groupTree).Encode(&buf); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to encode workspace: %v\n", err)
+		os.Exit(1)
+	}
+
+	path := mergeFlags(*output, *outputShort)
+	if path == "" {
+		fmt.Print(buf.String())
+		return
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Exported %d sessions to %s\n", len(instances), path)
+}
@@ -0,0 +1,165 @@
+// Package git wraps the git commands agent-deck uses to isolate sessions in
+// their own worktrees.
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// BranchPrefix namespaces the branches agent-deck creates
+const BranchPrefix = "agent-deck/"
+
+// Worktree describes a worktree created for a session
+type Worktree struct {
+	Repo   string // Main repository root
+	Path   string // Worktree root
+	Branch string // Branch checked out in the worktree
+}
+
+// run executes git in dir and returns its trimmed stdout
+func run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsRepo reports whether dir is inside a git work tree
+func IsRepo(dir string) bool {
+	out, err := run(dir, "rev-parse", "--is-inside-work-tree")
+	return err == nil && out == "true"
+}
+
+// TopLevel returns the root of the work tree containing dir (which is the
+// worktree root, not the main repository, when dir is in a linked worktree)
+func TopLevel(dir string) (string, error) {
+	return run(dir, "rev-parse", "--show-toplevel")
+}
+
+// MainRepo returns the root of the main repository for dir, following linked
+// worktrees back to the checkout that owns them
+func MainRepo(dir string) (string, error) {
+	commonDir, err := run(dir, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(commonDir), nil
+}
+
+// BranchExists reports whether a local branch exists
+func BranchExists(repo, branch string) bool {
+	_, err := run(repo, "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	return err == nil
+}
+
+// CurrentBranch returns the branch checked out in dir ("" when detached or
+// not a repository)
+func CurrentBranch(dir string) string {
+	branch, err := run(dir, "symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return branch
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug turns a session title into a branch/directory friendly name
+func Slug(title string) string {
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	if slug == "" {
+		slug = "session"
+	}
+	return slug
+}
+
+// CreateWorktree creates a worktree for the repository containing dir on a
+// new branch. An empty branch is generated from the title
+// ("agent-deck/<title>", suffixed until unused). Worktrees are placed next to
+// the main repository in "<repo>-worktrees/".
+func CreateWorktree(dir, title, branch string) (*Worktree, error) {
+	if !IsRepo(dir) {
+		return nil, fmt.Errorf("%s is not inside a git repository", dir)
+	}
+	repo, err := MainRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if branch == "" {
+		base := BranchPrefix + Slug(title)
+		branch = base
+		for n := 2; BranchExists(repo, branch); n++ {
+			branch = fmt.Sprintf("%s-%d", base, n)
+		}
+	} else if BranchExists(repo, branch) {
+		return nil, fmt.Errorf("branch %q already exists", branch)
+	}
+
+	parent := filepath.Join(filepath.Dir(repo), filepath.Base(repo)+"-worktrees")
+	name := Slug(strings.TrimPrefix(branch, BranchPrefix))
+	path := filepath.Join(parent, name)
+	for n := 2; pathExists(path); n++ {
+		path = filepath.Join(parent, fmt.Sprintf("%s-%d", name, n))
+	}
+
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", parent, err)
+	}
+	// Branch off whatever dir has checked out, so forks continue from the
+	// parent's state rather than the main checkout's
+	if _, err := run(dir, "worktree", "add", "-b", branch, path, "HEAD"); err != nil {
+		return nil, err
+	}
+
+	return &Worktree{Repo: repo, Path: path, Branch: branch}, nil
+}
+
+// HasChanges reports whether a work tree has uncommitted or untracked changes
+func HasChanges(dir string) (bool, error) {
+	out, err := run(dir, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return out != "", nil
+}
+
+// RemoveWorktree removes a worktree created by CreateWorktree. It refuses
+// when the worktree has uncommitted changes; the branch is kept so committed
+// work stays reachable.
+func RemoveWorktree(repo, path string) error {
+	if !pathExists(path) {
+		// Already gone: just drop git's bookkeeping
+		_, err := run(repo, "worktree", "prune")
+		return err
+	}
+
+	dirty, err := HasChanges(path)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("worktree %s has uncommitted changes", path)
+	}
+
+	_, err = run(repo, "worktree", "remove", path)
+	return err
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initRepo creates a repository with one commit in a temp dir
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := filepath.Join(t.TempDir(), "project")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "pkg"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "pkg", "README"), []byte("hi\n"), 0644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		_, err := run(repo, args...)
+		require.NoError(t, err)
+	}
+	return repo
+}
+
+func TestSlug(t *testing.T) {
+	assert.Equal(t, "fix-login-bug", Slug("Fix login bug!"))
+	assert.Equal(t, "api-fork", Slug("api (fork)"))
+	assert.Equal(t, "session", Slug("🚀"))
+	assert.Len(t, Slug("a very long session title that keeps going and going"), 40)
+}
+
+func TestCreateAndRemoveWorktree(t *testing.T) {
+	repo := initRepo(t)
+
+	wt, err := CreateWorktree(filepath.Join(repo, "pkg"), "My Feature", "")
+	require.NoError(t, err)
+
+	resolvedRepo, _ := filepath.EvalSymlinks(repo)
+	resolvedMain, _ := filepath.EvalSymlinks(wt.Repo)
+	assert.Equal(t, resolvedRepo, resolvedMain)
+	assert.Equal(t, "agent-deck/my-feature", wt.Branch)
+	assert.Equal(t, filepath.Join(filepath.Dir(repo), "project-worktrees", "my-feature"), wt.Path)
+	assert.FileExists(t, filepath.Join(wt.Path, "pkg", "README"))
+
+	// A second session with the same title gets its own branch and directory
+	second, err := CreateWorktree(wt.Path, "My Feature", "")
+	require.NoError(t, err)
+	assert.Equal(t, "agent-deck/my-feature-2", second.Branch)
+	assert.NotEqual(t, wt.Path, second.Path)
+	resolvedSecond, _ := filepath.EvalSymlinks(second.Repo)
+	assert.Equal(t, resolvedRepo, resolvedSecond, "worktrees of worktrees belong to the main repo")
+
+	// Dirty worktrees are kept
+	require.NoError(t, os.WriteFile(filepath.Join(wt.Path, "new.txt"), []byte("x"), 0644))
+	err = RemoveWorktree(wt.Repo, wt.Path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "uncommitted changes")
+	assert.DirExists(t, wt.Path)
+
+	require.NoError(t, os.Remove(filepath.Join(wt.Path, "new.txt")))
+	require.NoError(t, RemoveWorktree(wt.Repo, wt.Path))
+	assert.NoDirExists(t, wt.Path)
+	assert.True(t, BranchExists(repo, wt.Branch), "the branch outlives the worktree")
+}
+
+func TestCreateWorktree_Errors(t *testing.T) {
+	repo := initRepo(t)
+
+	_, err := CreateWorktree(t.TempDir(), "x", "")
+	assert.Error(t, err, "not a repository")
+
+	current, err := run(repo, "rev-parse", "--abbrev-ref", "HEAD")
+	require.NoError(t, err)
+	_, err = CreateWorktree(repo, "x", current)
+	assert.ErrorContains(t, err, "already exists")
+}
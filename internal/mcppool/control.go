@@ -0,0 +1,259 @@
+package mcppool
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Control protocol: JSON-RPC methods under agentdeck/ sent over a proxy's own
+// socket are answered by the proxy instead of the MCP. This lets any
+// agent-deck process (e.g. `agent-deck mcp pool`) inspect and control a proxy
+// owned by another one, such as the TUI.
+const (
+	controlMethodPrefix  = "agentdeck/"
+	methodControlStatus  = "agentdeck/status"
+	methodControlRestart = "agentdeck/restart"
+	methodControlStop    = "agentdeck/stop"
+	methodControlRecord  = "agentdeck/record"
+
+	controlTimeout = 5 * time.Second
+)
+
+// ProxyStatus is the live status of a proxy as reported by its owner
+type ProxyStatus struct {
+	Name          string         `json:"name"`
+	Status        string         `json:"status"`
+	SocketPath    string         `json:"socket_path"`
+	HTTPURL       string         `json:"http_url,omitempty"`
+	PID           int            `json:"pid,omitempty"`
+	OwnerPID      int            `json:"owner_pid"`
+	StartedAt     time.Time      `json:"started_at,omitempty"`
+	UptimeSeconds int64          `json:"uptime_seconds"`
+	Restarts      int            `json:"restarts"`
+	Requests      int64          `json:"requests"`
+	Pending       int            `json:"pending"`
+	LastError     string         `json:"last_error,omitempty"`
+	Recording     bool           `json:"recording"`
+	Clients       []ClientStatus `json:"clients"`
+}
+
+// ClientStatus describes one client connected to a proxy
+type ClientStatus struct {
+	ID          string    `json:"id"`
+	Transport   string    `json:"transport"`
+	PID         int       `json:"pid,omitempty"`
+	ConnectedAt time.Time `json:"connected_at"`
+	Requests    int64     `json:"requests"`
+}
+
+func isControlMethod(method string) bool {
+	return strings.HasPrefix(method, controlMethodPrefix)
+}
+
+// handleControl answers a control request from a client
+func (p *SocketProxy) handleControl(sessionID string, env *rpcEnvelope) {
+	p.clientsMu.Lock()
+	if client, ok := p.clients[sessionID]; ok {
+		client.control = true
+	}
+	p.clientsMu.Unlock()
+
+	if !env.hasID() {
+		return
+	}
+
+	switch env.Method {
+	case methodControlStatus:
+		p.replyControl(sessionID, env.ID, p.LiveStatus())
+
+	case methodControlRestart:
+		if err := p.RestartProcess(); err != nil {
+			p.replyControlError(sessionID, env.ID, err)
+			return
+		}
+		p.replyControl(sessionID, env.ID, map[string]bool{"ok": true})
+
+	case methodControlStop:
+		p.replyControl(sessionID, env.ID, map[string]bool{"ok": true})
+		// Stop closes this client's connection, so answer first
+		go func() { _ = p.Stop() }()
+
+	case methodControlRecord:
+		var params struct {
+			Enabled bool `json:"enabled"`
+		}
+		if err := json.Unmarshal(env.Params, &params); err != nil {
+			p.replyControlError(sessionID, env.ID, fmt.Errorf("invalid params: %w", err))
+			return
+		}
+		if params.Enabled {
+			if err := p.StartRecording(); err != nil {
+				p.replyControlError(sessionID, env.ID, err)
+				return
+			}
+		} else {
+			p.StopRecording()
+		}
+		p.replyControl(sessionID, env.ID, map[string]bool{"ok": true})
+
+	default:
+		p.replyControlError(sessionID, env.ID, fmt.Errorf("unknown control method %s", env.Method))
+	}
+}
+
+func (p *SocketProxy) replyControl(sessionID string, id json.RawMessage, result interface{}) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		p.replyControlError(sessionID, id, err)
+		return
+	}
+	p.replyToClient(sessionID, id, "result", data)
+}
+
+func (p *SocketProxy) replyControlError(sessionID string, id json.RawMessage, err error) {
+	data, _ := json.Marshal(map[string]interface{}{"code": -32000, "message": err.Error()})
+	p.replyToClient(sessionID, id, "error", data)
+}
+
+// LiveStatus returns the proxy's current status including its clients
+func (p *SocketProxy) LiveStatus() *ProxyStatus {
+	status := &ProxyStatus{
+		Name:       p.name,
+		Status:     p.GetStatus().String(),
+		SocketPath: p.socketPath,
+		HTTPURL:    p.GetHTTPURL(),
+		PID:        p.PID(),
+		OwnerPID:   os.Getpid(),
+		Restarts:   p.Restarts(),
+		Requests:   p.totalRequests.Load(),
+		Pending:    p.PendingRequests(),
+		LastError:  p.LastError(),
+		Recording:  p.IsRecording(),
+		Clients:    []ClientStatus{},
+	}
+
+	p.procMu.Lock()
+	if p.mcpProcess != nil && status.Status == StatusRunning.String() {
+		status.StartedAt = p.startedAt
+		status.UptimeSeconds = int64(time.Since(p.startedAt).Seconds())
+	}
+	p.procMu.Unlock()
+
+	p.clientsMu.RLock()
+	for id, client := range p.clients {
+		if client.control {
+			continue
+		}
+		status.Clients = append(status.Clients, ClientStatus{
+			ID:          id,
+			Transport:   client.transport,
+			PID:         client.pid,
+			ConnectedAt: client.connectedAt,
+			Requests:    client.requests.Load(),
+		})
+	}
+	p.clientsMu.RUnlock()
+
+	sort.Slice(status.Clients, func(i, j int) bool {
+		return status.Clients[i].ConnectedAt.Before(status.Clients[j].ConnectedAt)
+	})
+	return status
+}
+
+// QueryStatus asks the proxy serving a pooled MCP for its live status
+func QueryStatus(name string) (*ProxyStatus, error) {
+	result, err := controlCall(name, methodControlStatus, nil)
+	if err != nil {
+		return nil, err
+	}
+	var status ProxyStatus
+	if err := json.Unmarshal(result, &status); err != nil {
+		return nil, fmt.Errorf("invalid status from %s: %w", name, err)
+	}
+	return &status, nil
+}
+
+// RequestRestart asks the owner of a pooled MCP to restart its process in place.
+// Connected clients stay connected.
+func RequestRestart(name string) error {
+	_, err := controlCall(name, methodControlRestart, nil)
+	return err
+}
+
+// RequestStop asks the owner of a pooled MCP to stop it and remove its socket
+func RequestStop(name string) error {
+	_, err := controlCall(name, methodControlStop, nil)
+	return err
+}
+
+// RequestRecording asks the owner of a pooled MCP to start or stop recording
+// its traffic to TrafficLogPath
+func RequestRecording(name string, enabled bool) error {
+	_, err := controlCall(name, methodControlRecord, map[string]bool{"enabled": enabled})
+	return err
+}
+
+// IsPoolSocketAlive reports whether a pooled MCP's socket is accepting connections
+func IsPoolSocketAlive(name string) bool {
+	return isSocketAliveCheck(socketPathFor(name))
+}
+
+// ListPoolSockets returns the names of all MCPs with a pool socket on this machine
+func ListPoolSockets() []string {
+	matches, _ := filepath.Glob(socketPathFor("*"))
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if name, ok := nameFromSocketPath(match); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// controlCall sends one control request over a proxy's socket and returns its result
+func controlCall(name, method string, params interface{}) (json.RawMessage, error) {
+	conn, err := net.DialTimeout("unix", socketPathFor(name), controlTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not running in the pool", name)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(controlTimeout))
+
+	req, err := json.Marshal(JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(append(req, '\n')); err != nil {
+		return nil, err
+	}
+
+	scanner := newScanner(bufio.NewReader(conn))
+	for scanner.Scan() {
+		var resp struct {
+			ID     json.RawMessage `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &resp) != nil || string(resp.ID) != "1" {
+			continue // Not our response
+		}
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s: %s", name, resp.Error.Message)
+		}
+		return resp.Result, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%s: connection closed without a response", name)
+}
@@ -0,0 +1,67 @@
+package mcppool
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControl_QueryStatus(t *testing.T) {
+	proxy := startStubProxy(t)
+	client := dialProxy(t, proxy)
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	require.NotNil(t, client.recv(5*time.Second))
+
+	status, err := QueryStatus(proxy.name)
+	require.NoError(t, err)
+	assert.Equal(t, proxy.name, status.Name)
+	assert.Equal(t, "running", status.Status)
+	assert.Equal(t, proxy.PID(), status.PID)
+	assert.Equal(t, os.Getpid(), status.OwnerPID)
+	assert.Equal(t, proxy.GetSocketPath(), status.SocketPath)
+	assert.EqualValues(t, 1, status.Requests)
+
+	// The querying connection itself is not listed as a client
+	require.Len(t, status.Clients, 1)
+	assert.Equal(t, "socket", status.Clients[0].Transport)
+	assert.EqualValues(t, 1, status.Clients[0].Requests)
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+		assert.Equal(t, os.Getpid(), status.Clients[0].PID)
+	}
+	assert.Equal(t, 1, proxy.GetClientCount())
+}
+
+func TestControl_RestartKeepsClients(t *testing.T) {
+	fastRestarts(t, 5)
+	proxy := startStubProxy(t)
+	client := dialProxy(t, proxy)
+	pidBefore := proxy.PID()
+
+	require.NoError(t, RequestRestart(proxy.name))
+	require.Eventually(t, func() bool {
+		return proxy.GetStatus() == StatusRunning && proxy.PID() != pidBefore
+	}, 5*time.Second, 10*time.Millisecond)
+
+	client.send(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	msg := client.recv(5 * time.Second)
+	require.NotNil(t, msg)
+	assert.Equal(t, `2`, string(msg["id"]))
+	assert.Nil(t, msg["error"])
+}
+
+func TestControl_Stop(t *testing.T) {
+	proxy := startStubProxy(t)
+	require.True(t, IsPoolSocketAlive(proxy.name))
+	assert.Contains(t, ListPoolSockets(), proxy.name)
+
+	require.NoError(t, RequestStop(proxy.name))
+	require.Eventually(t, func() bool { return proxy.GetStatus() == StatusStopped }, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, IsPoolSocketAlive(proxy.name))
+
+	_, err := QueryStatus(proxy.name)
+	assert.Error(t, err)
+}
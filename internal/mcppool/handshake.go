@@ -0,0 +1,125 @@
+package mcppool
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+const (
+	methodInitialize  = "initialize"
+	methodInitialized = "notifications/initialized"
+)
+
+// handshakeState tracks the MCP initialize handshake with the shared backend.
+// The backend is initialized exactly once; later clients are answered from the
+// cached result so strict servers never see a second initialize.
+type handshakeState struct {
+	mu sync.Mutex
+
+	// result is the backend's initialize result (capabilities, serverInfo, ...)
+	result json.RawMessage
+	// params are the initialize params of the first client, kept for replay
+	params json.RawMessage
+
+	inFlight        bool
+	waiters         []initWaiter
+	initializedSent bool
+}
+
+// initWaiter is a client whose initialize arrived while the backend handshake was in flight
+type initWaiter struct {
+	sessionID  string
+	originalID json.RawMessage
+}
+
+// handleInitialize intercepts a client's initialize request. Returns true if the
+// request should be forwarded to the backend (first client), false if it was
+// answered from the cache or queued behind the in-flight handshake.
+func (p *SocketProxy) handleInitialize(sessionID string, env *rpcEnvelope) bool {
+	h := &p.handshake
+	h.mu.Lock()
+
+	if h.result != nil {
+		cached := h.result
+		h.mu.Unlock()
+		p.replyToClient(sessionID, env.ID, "result", cached)
+		return false
+	}
+
+	if h.inFlight {
+		h.waiters = append(h.waiters, initWaiter{
+			sessionID:  sessionID,
+			originalID: append(json.RawMessage(nil), env.ID...),
+		})
+		h.mu.Unlock()
+		return false
+	}
+
+	h.inFlight = true
+	h.params = append(json.RawMessage(nil), env.Params...)
+	h.mu.Unlock()
+	return true
+}
+
+// completeInitialize records the backend's initialize response and answers any
+// clients that were waiting on it with the same result (or error).
+func (p *SocketProxy) completeInitialize(line []byte) {
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	_ = json.Unmarshal(line, &resp)
+
+	h := &p.handshake
+	h.mu.Lock()
+	h.inFlight = false
+	if len(resp.Result) > 0 && len(resp.Error) == 0 {
+		h.result = resp.Result
+	}
+	waiters := h.waiters
+	h.waiters = nil
+	h.mu.Unlock()
+
+	for _, w := range waiters {
+		if len(resp.Error) > 0 {
+			p.replyToClient(w.sessionID, w.originalID, "error", resp.Error)
+		} else {
+			p.replyToClient(w.sessionID, w.originalID, "result", resp.Result)
+		}
+	}
+}
+
+// shouldForwardInitialized reports whether a client's initialized notification
+// should reach the backend. Only the first one after a successful handshake does.
+func (p *SocketProxy) shouldForwardInitialized() bool {
+	h := &p.handshake
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.result == nil || h.initializedSent {
+		return false
+	}
+	h.initializedSent = true
+	return true
+}
+
+// InitializeResult returns the cached initialize result of the backend, or nil
+// if no client has completed the handshake yet.
+func (p *SocketProxy) InitializeResult() json.RawMessage {
+	p.handshake.mu.Lock()
+	defer p.handshake.mu.Unlock()
+	return p.handshake.result
+}
+
+// replyToClient sends a response generated by the proxy itself to a client
+func (p *SocketProxy) replyToClient(sessionID string, id json.RawMessage, field string, value json.RawMessage) {
+	msg, err := json.Marshal(map[string]json.RawMessage{
+		"jsonrpc": json.RawMessage(`"2.0"`),
+		"id":      id,
+		field:     value,
+	})
+	if err != nil {
+		return
+	}
+	p.sendToClient(sessionID, msg)
+}
@@ -0,0 +1,485 @@
+package mcppool
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// httpEndpointPath is the single Streamable HTTP MCP endpoint
+	httpEndpointPath = "/mcp"
+
+	// sessionHeader carries the MCP session ID assigned on initialize
+	sessionHeader = "Mcp-Session-Id"
+
+	// httpResponseTimeout bounds how long a POST waits for the MCP to answer
+	httpResponseTimeout = 5 * time.Minute
+
+	// httpEventBuffer is how many server-initiated messages are buffered per
+	// session while no GET stream is attached; older messages are dropped
+	httpEventBuffer = 64
+)
+
+// httpSessionIdleTimeout is how long an HTTP session may go without requests
+// or an open stream before it is closed. Clients are supposed to DELETE their
+// session when done, but many just go away.
+var httpSessionIdleTimeout = 30 * time.Minute
+
+// httpFrontend exposes a SocketProxy as a Streamable HTTP MCP endpoint.
+// Every HTTP session is attached to the proxy as an ordinary client over an
+// in-memory pipe, so it gets the same ID rewriting and cached handshake as
+// socket clients.
+type httpFrontend struct {
+	proxy    *SocketProxy
+	server   *http.Server
+	listener net.Listener
+	url      string
+
+	sessions map[string]*httpSession
+	mu       sync.Mutex
+
+	idleTimeout time.Duration
+	done        chan struct{} // Closed on stop, ends the idle session sweep
+}
+
+// httpSession is one MCP client talking to the proxy over HTTP
+type httpSession struct {
+	id        string
+	conn      net.Conn // HTTP side of the pipe to the proxy
+	proxyConn net.Conn // Proxy side of the pipe, served as a client
+
+	// Guarded by httpFrontend.mu
+	inUse    int       // Requests and streams currently using the session
+	lastUsed time.Time // When the last of them ended
+
+	waiters   map[string]chan json.RawMessage // keyed by request ID
+	waitersMu sync.Mutex
+
+	events chan json.RawMessage // server-initiated messages for the GET stream
+	closed chan struct{}
+	once   sync.Once
+}
+
+// StartHTTP exposes the proxy as a Streamable HTTP MCP endpoint on the first
+// free localhost port in [portStart, portEnd].
+func (p *SocketProxy) StartHTTP(portStart, portEnd int) error {
+	if p.http != nil {
+		return nil
+	}
+
+	listener, err := listenInRange(portStart, portEnd)
+	if err != nil {
+		return err
+	}
+
+	f := &httpFrontend{
+		proxy:       p,
+		listener:    listener,
+		url:         fmt.Sprintf("http://%s%s", listener.Addr().String(), httpEndpointPath),
+		sessions:    make(map[string]*httpSession),
+		idleTimeout: httpSessionIdleTimeout,
+		done:        make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(httpEndpointPath, f.handle)
+	f.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	p.http = f
+
+	go func() {
+		if err := f.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("[Pool] %s: HTTP endpoint stopped: %v", p.name, err)
+		}
+	}()
+	go f.expireIdleSessions()
+
+	log.Printf("[Pool] %s: HTTP endpoint at %s", p.name, f.url)
+	if !p.external {
+		p.writeState()
+	}
+	return nil
+}
+
+// GetHTTPURL returns the Streamable HTTP endpoint, or "" if not exposed
+func (p *SocketProxy) GetHTTPURL() string {
+	if p.http == nil {
+		return ""
+	}
+	return p.http.url
+}
+
+// listenInRange listens on the first free localhost port in the range
+func listenInRange(portStart, portEnd int) (net.Listener, error) {
+	for port := portStart; port <= portEnd; port++ {
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err == nil {
+			return listener, nil
+		}
+	}
+	return nil, fmt.Errorf("no free port in range %d-%d", portStart, portEnd)
+}
+
+func (f *httpFrontend) stop() {
+	_ = f.server.Close()
+	close(f.done)
+
+	f.mu.Lock()
+	sessions := f.sessions
+	f.sessions = make(map[string]*httpSession)
+	f.mu.Unlock()
+
+	for _, sess := range sessions {
+		sess.close()
+	}
+}
+
+func (f *httpFrontend) handle(w http.ResponseWriter, r *http.Request) {
+	// Only local pages may talk to the endpoint (DNS rebinding protection)
+	if !isLocalOrigin(r.Header.Get("Origin")) {
+		http.Error(w, "forbidden origin", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		f.handlePost(w, r)
+	case http.MethodGet:
+		f.handleStream(w, r)
+	case http.MethodDelete:
+		f.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePost forwards one message (or a batch) to the MCP. Requests are
+// answered in the response body; notifications and responses get 202 Accepted.
+func (f *httpFrontend) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	messages, isBatch, err := splitBatch(body)
+	if err != nil {
+		http.Error(w, "invalid JSON-RPC message", http.StatusBadRequest)
+		return
+	}
+
+	envs := make([]rpcEnvelope, len(messages))
+	hasInitialize := false
+	for i, msg := range messages {
+		if err := json.Unmarshal(msg, &envs[i]); err != nil {
+			http.Error(w, "invalid JSON-RPC message", http.StatusBadRequest)
+			return
+		}
+		if envs[i].Method == methodInitialize {
+			hasInitialize = true
+		}
+	}
+
+	sess, status := f.lookupSession(r.Header.Get(sessionHeader))
+	if sess == nil {
+		if !hasInitialize {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		sess = f.newSession()
+	}
+	defer f.use(sess)()
+	w.Header().Set(sessionHeader, sess.id)
+
+	var waits []chan json.RawMessage
+	for i, msg := range messages {
+		if envs[i].isRequest() {
+			waits = append(waits, sess.expect(envs[i].ID))
+		}
+		if err := sess.send(msg); err != nil {
+			http.Error(w, "session closed", http.StatusNotFound)
+			return
+		}
+	}
+
+	if len(waits) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	timeout := time.NewTimer(httpResponseTimeout)
+	defer timeout.Stop()
+
+	responses := make([]json.RawMessage, 0, len(waits))
+	for _, ch := range waits {
+		select {
+		case resp := <-ch:
+			responses = append(responses, resp)
+		case <-sess.closed:
+			http.Error(w, "session closed", http.StatusNotFound)
+			return
+		case <-r.Context().Done():
+			return
+		case <-timeout.C:
+			http.Error(w, "timed out waiting for MCP response", http.StatusGatewayTimeout)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if isBatch {
+		_ = json.NewEncoder(w).Encode(responses)
+	} else {
+		_, _ = w.Write(responses[0])
+	}
+}
+
+// handleStream serves server-initiated messages (notifications and requests)
+// to the client as Server-Sent Events
+func (f *httpFrontend) handleStream(w http.ResponseWriter, r *http.Request) {
+	sess, status := f.lookupSession(r.Header.Get(sessionHeader))
+	if sess == nil {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	defer f.use(sess)()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set(sessionHeader, sess.id)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case msg := <-sess.events:
+			if _, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-sess.closed:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleDelete terminates a session at the client's request
+func (f *httpFrontend) handleDelete(w http.ResponseWriter, r *http.Request) {
+	sess, status := f.lookupSession(r.Header.Get(sessionHeader))
+	if sess == nil {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	f.removeSession(sess)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeSession forgets a session and closes it, which disconnects its client
+// from the proxy
+func (f *httpFrontend) removeSession(sess *httpSession) {
+	f.mu.Lock()
+	if f.sessions[sess.id] == sess {
+		delete(f.sessions, sess.id)
+	}
+	f.mu.Unlock()
+	sess.close()
+}
+
+// use marks a session as busy until the returned function is called, so it
+// isn't expired while a request or stream is open
+func (f *httpFrontend) use(sess *httpSession) func() {
+	f.mu.Lock()
+	sess.inUse++
+	f.mu.Unlock()
+	return func() {
+		f.mu.Lock()
+		sess.inUse--
+		sess.lastUsed = time.Now()
+		f.mu.Unlock()
+	}
+}
+
+// expireIdleSessions periodically closes sessions that have been idle for
+// longer than the idle timeout
+func (f *httpFrontend) expireIdleSessions() {
+	ticker := time.NewTicker(min(f.idleTimeout/2, time.Minute))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-ticker.C:
+		}
+
+		var idle []*httpSession
+		f.mu.Lock()
+		for _, sess := range f.sessions {
+			if sess.inUse == 0 && time.Since(sess.lastUsed) > f.idleTimeout {
+				idle = append(idle, sess)
+			}
+		}
+		f.mu.Unlock()
+
+		for _, sess := range idle {
+			log.Printf("[Pool] %s: closing idle HTTP session %s", f.proxy.name, sess.id)
+			f.removeSession(sess)
+		}
+	}
+}
+
+// lookupSession returns the session for a header value, or nil with the HTTP
+// status to answer: 400 if no session ID was sent, 404 if it is unknown.
+func (f *httpFrontend) lookupSession(id string) (*httpSession, int) {
+	if id == "" {
+		return nil, http.StatusBadRequest
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if sess, ok := f.sessions[id]; ok {
+		return sess, http.StatusOK
+	}
+	return nil, http.StatusNotFound
+}
+
+// newSession creates an HTTP session and attaches it to the proxy as a client
+func (f *httpFrontend) newSession() *httpSession {
+	proxySide, httpSide := net.Pipe()
+	sess := &httpSession{
+		id:        newSessionID(),
+		conn:      httpSide,
+		proxyConn: proxySide,
+		lastUsed:  time.Now(),
+		waiters:   make(map[string]chan json.RawMessage),
+		events:    make(chan json.RawMessage, httpEventBuffer),
+		closed:    make(chan struct{}),
+	}
+
+	f.mu.Lock()
+	f.sessions[sess.id] = sess
+	f.mu.Unlock()
+
+	f.proxy.attachClient("http", proxySide)
+	go func() {
+		// The proxy dropped the client: the session is gone as well
+		sess.readLoop()
+		f.removeSession(sess)
+	}()
+	return sess
+}
+
+// readLoop dispatches messages from the proxy: responses go to the waiting
+// POST, everything else to the session's event stream.
+func (s *httpSession) readLoop() {
+
+	scanner := newScanner(s.conn)
+	for scanner.Scan() {
+		msg := append(json.RawMessage(nil), scanner.Bytes()...)
+
+		var env rpcEnvelope
+		if json.Unmarshal(msg, &env) == nil && env.isResponse() {
+			s.waitersMu.Lock()
+			ch, ok := s.waiters[string(env.ID)]
+			delete(s.waiters, string(env.ID))
+			s.waitersMu.Unlock()
+			if ok {
+				ch <- msg
+				continue
+			}
+		}
+
+		select {
+		case s.events <- msg:
+		default:
+			// Nobody is listening on the GET stream; drop rather than block the proxy
+		}
+	}
+}
+
+// expect registers interest in the response to a request ID
+func (s *httpSession) expect(id json.RawMessage) chan json.RawMessage {
+	ch := make(chan json.RawMessage, 1)
+	s.waitersMu.Lock()
+	s.waiters[string(id)] = ch
+	s.waitersMu.Unlock()
+	return ch
+}
+
+func (s *httpSession) send(msg json.RawMessage) error {
+	select {
+	case <-s.closed:
+		return io.ErrClosedPipe
+	default:
+	}
+	msg = bytes.TrimSpace(msg)
+	_, err := s.conn.Write(append(msg, '\n'))
+	return err
+}
+
+func (s *httpSession) close() {
+	s.once.Do(func() {
+		close(s.closed)
+		s.conn.Close()
+		s.proxyConn.Close()
+	})
+}
+
+// splitBatch returns the messages in a POST body, which is either a single
+// JSON-RPC message or a batch array
+func splitBatch(body []byte) ([]json.RawMessage, bool, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return nil, true, err
+		}
+		if len(batch) == 0 {
+			return nil, true, fmt.Errorf("empty batch")
+		}
+		return batch, true, nil
+	}
+	if !json.Valid(body) {
+		return nil, false, fmt.Errorf("invalid JSON")
+	}
+	return []json.RawMessage{body}, false, nil
+}
+
+// isLocalOrigin accepts requests without an Origin (non-browser clients) and
+// browser requests from localhost pages
+func isLocalOrigin(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,197 @@
+package mcppool
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startStubHTTP starts a stub proxy with its HTTP endpoint on a free port
+func startStubHTTP(t *testing.T) (*SocketProxy, string) {
+	t.Helper()
+	proxy := startStubProxy(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	require.NoError(t, proxy.StartHTTP(port, port))
+	require.Equal(t, fmt.Sprintf("http://127.0.0.1:%d/mcp", port), proxy.GetHTTPURL())
+	return proxy, proxy.GetHTTPURL()
+}
+
+func postMCP(t *testing.T, url, sessionID, body string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set(sessionHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(data)
+}
+
+func initHTTPSession(t *testing.T, url string) string {
+	t.Helper()
+	resp, body := postMCP(t, url, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	sessionID := resp.Header.Get(sessionHeader)
+	require.NotEmpty(t, sessionID)
+	assert.Contains(t, body, `"serverInfo"`)
+
+	resp, _ = postMCP(t, url, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	return sessionID
+}
+
+func TestHTTPFrontend_SessionsShareProcess(t *testing.T) {
+	_, url := startStubHTTP(t)
+	s1 := initHTTPSession(t, url)
+	s2 := initHTTPSession(t, url)
+	assert.NotEqual(t, s1, s2)
+
+	// Colliding IDs from concurrent sessions are answered to the right session
+	var wg sync.WaitGroup
+	for _, tc := range []struct{ session, client string }{{s1, "one"}, {s2, "two"}} {
+		tc := tc
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				resp, body := postMCP(t, url, tc.session,
+					fmt.Sprintf(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"client":%q}}`, tc.client))
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+				var msg map[string]json.RawMessage
+				if assert.NoError(t, json.Unmarshal([]byte(body), &msg)) {
+					assert.Equal(t, `5`, string(msg["id"]))
+					assert.Equal(t, fmt.Sprintf("%q", tc.client), resultField(t, msg, "params", "client"))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Batches are answered with an array
+	resp, body := postMCP(t, url, s1, `[{"jsonrpc":"2.0","id":"a","method":"ping"},{"jsonrpc":"2.0","id":"b","method":"ping"}]`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var batch []map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(body), &batch))
+	require.Len(t, batch, 2)
+	assert.Equal(t, `"a"`, string(batch[0]["id"]))
+	assert.Equal(t, `"b"`, string(batch[1]["id"]))
+}
+
+func TestHTTPFrontend_SessionHeaderValidation(t *testing.T) {
+	proxy, url := startStubHTTP(t)
+
+	resp, _ := postMCP(t, url, "", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = postMCP(t, url, "no-such-session", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// DELETE ends the session
+	sessionID := initHTTPSession(t, url)
+	req, _ := http.NewRequest(http.MethodDelete, url, nil)
+	req.Header.Set(sessionHeader, sessionID)
+	delResp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	delResp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, delResp.StatusCode)
+
+	resp, _ = postMCP(t, url, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.Eventually(t, func() bool { return proxy.GetClientCount() == 0 }, 5*time.Second, 10*time.Millisecond,
+		"the session's proxy client should be closed")
+
+	// Browser pages from other origins are rejected
+	req, _ = http.NewRequest(http.MethodPost, url, strings.NewReader(`{}`))
+	req.Header.Set("Origin", "https://evil.example.com")
+	originResp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	originResp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, originResp.StatusCode)
+}
+
+func TestHTTPFrontend_IdleSessionsExpire(t *testing.T) {
+	saved := httpSessionIdleTimeout
+	httpSessionIdleTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { httpSessionIdleTimeout = saved })
+
+	proxy, url := startStubHTTP(t)
+	idle := initHTTPSession(t, url)
+	streaming := initHTTPSession(t, url)
+	require.Equal(t, 2, proxy.GetClientCount())
+
+	// A session with an open stream is in use, however long it stays quiet
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set(sessionHeader, streaming)
+	stream, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+
+	require.Eventually(t, func() bool { return proxy.GetClientCount() == 1 }, 5*time.Second, 20*time.Millisecond,
+		"the idle session's proxy client should be closed")
+	resp, _ := postMCP(t, url, idle, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = postMCP(t, url, streaming, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestHTTPFrontend_ServerMessagesOnStream(t *testing.T) {
+	_, url := startStubHTTP(t)
+	sessionID := initHTTPSession(t, url)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set(sessionHeader, sessionID)
+	req.Header.Set("Accept", "text/event-stream")
+	stream, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	require.Equal(t, "text/event-stream", stream.Header.Get("Content-Type"))
+
+	resp, _ := postMCP(t, url, sessionID, `{"jsonrpc":"2.0","id":9,"method":"notify"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	lines := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(stream.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case line := <-lines:
+			if strings.HasPrefix(line, "data: ") {
+				assert.Contains(t, line, `"notifications/message"`)
+				return
+			}
+		case <-deadline:
+			t.Fatal("no notification received on SSE stream")
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package mcppool
+
+import (
+	"net"
+	"syscall"
+)
+
+// localPeerPID is LOCAL_PEERPID from <sys/un.h>, not exported by package syscall
+const localPeerPID = 0x002
+
+// peerPID returns the process ID on the other end of a Unix socket, or 0
+func peerPID(conn net.Conn) int {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return 0
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return 0
+	}
+
+	pid := 0
+	_ = raw.Control(func(fd uintptr) {
+		// SOL_LOCAL is 0 on Darwin
+		if v, err := syscall.GetsockoptInt(int(fd), 0, localPeerPID); err == nil {
+			pid = v
+		}
+	})
+	return pid
+}
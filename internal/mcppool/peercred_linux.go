@@ -0,0 +1,27 @@
+package mcppool
+
+import (
+	"net"
+	"syscall"
+)
+
+// peerPID returns the process ID on the other end of a Unix socket, or 0
+func peerPID(conn net.Conn) int {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return 0
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return 0
+	}
+
+	pid := 0
+	_ = raw.Control(func(fd uintptr) {
+		cred, err := syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+		if err == nil {
+			pid = int(cred.Pid)
+		}
+	})
+	return pid
+}
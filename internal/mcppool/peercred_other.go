@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package mcppool
+
+import "net"
+
+// peerPID is not supported on this platform
+func peerPID(conn net.Conn) int {
+	return 0
+}
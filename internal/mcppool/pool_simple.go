@@ -7,7 +7,6 @@ import (
 	"net"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 )
@@ -26,6 +25,16 @@ type PoolConfig struct {
 	ExcludeMCPs    []string
 	PoolMCPs       []string
 	FallbackStdio  bool
+
+	// HTTPEnabled also exposes each owned proxy as a Streamable HTTP endpoint
+	// on a port from PortStart..PortEnd
+	HTTPEnabled bool
+	PortStart   int
+	PortEnd     int
+
+	// RecordMCPs lists MCPs whose traffic is recorded to TrafficLogPath
+	// ("*" records all)
+	RecordMCPs []string
 }
 
 func NewPool(ctx context.Context, config *PoolConfig) (*Pool, error) {
@@ -46,26 +55,63 @@ func (p *Pool) Start(name, command string, args []string, env map[string]string)
 		return nil
 	}
 
-	proxy, err := NewSocketProxy(p.ctx, name, command, args, env)
+	proxy, err := p.startProxy(name, command, args, env)
 	if err != nil {
 		return err
 	}
 
+	p.proxies[name] = proxy
+	return nil
+}
+
+// startProxy creates and starts a socket proxy, plus its HTTP endpoint if enabled.
+// A failing HTTP endpoint is logged but doesn't fail the proxy.
+func (p *Pool) startProxy(name, command string, args []string, env map[string]string) (*SocketProxy, error) {
+	proxy, err := NewSocketProxy(p.ctx, name, command, args, env)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := proxy.Start(); err != nil {
-		return err
+		return nil, err
 	}
 
-	p.proxies[name] = proxy
-	return nil
+	if p.config.HTTPEnabled && !proxy.external {
+		if err := proxy.StartHTTP(p.config.PortStart, p.config.PortEnd); err != nil {
+			log.Printf("[Pool] %s: HTTP endpoint not started: %v", name, err)
+		}
+	}
+
+	if p.config.ShouldRecord(name) && !proxy.external {
+		if err := proxy.StartRecording(); err != nil {
+			log.Printf("[Pool] %s: Traffic recording not started: %v", name, err)
+		}
+	}
+	return proxy, nil
 }
 
 func (p *Pool) ShouldPool(mcpName string) bool {
-	if !p.config.Enabled {
+	return p.config.ShouldPool(mcpName)
+}
+
+// ShouldRecord reports whether the configuration records the given MCP's traffic
+func (c *PoolConfig) ShouldRecord(mcpName string) bool {
+	for _, name := range c.RecordMCPs {
+		if name == "*" || name == mcpName {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldPool reports whether the configuration pools the given MCP
+func (c *PoolConfig) ShouldPool(mcpName string) bool {
+	if !c.Enabled {
 		return false
 	}
 
-	if p.config.PoolAll {
-		for _, excluded := range p.config.ExcludeMCPs {
+	if c.PoolAll {
+		for _, excluded := range c.ExcludeMCPs {
 			if excluded == mcpName {
 				return false
 			}
@@ -73,7 +119,7 @@ func (p *Pool) ShouldPool(mcpName string) bool {
 		return true
 	}
 
-	for _, name := range p.config.PoolMCPs {
+	for _, name := range c.PoolMCPs {
 		if name == mcpName {
 			return true
 		}
@@ -90,7 +136,7 @@ func (p *Pool) IsRunning(name string) bool {
 	}
 
 	// Double-check: verify the socket is actually alive (not just marked as running)
-	if proxy.Status == StatusRunning {
+	if proxy.GetStatus() == StatusRunning {
 		if !isSocketAliveCheck(proxy.socketPath) {
 			p.mu.RUnlock()
 			log.Printf("[Pool] ⚠️ %s: marked running but socket is DEAD - attempting restart", name)
@@ -105,7 +151,23 @@ func (p *Pool) IsRunning(name string) bool {
 		p.mu.RUnlock()
 		return true
 	}
+	status := proxy.GetStatus()
 	p.mu.RUnlock()
+
+	switch status {
+	case StatusRestarting:
+		// The supervisor is bringing the process back; the socket keeps accepting clients
+		return true
+	case StatusStopped:
+		// Stopped here (e.g. `agent-deck mcp pool stop`), but another agent-deck
+		// instance may serve it now
+		if isSocketAliveCheck(proxy.socketPath) {
+			p.mu.Lock()
+			delete(p.proxies, name)
+			p.mu.Unlock()
+			return p.RegisterExternalSocket(name, proxy.socketPath) == nil
+		}
+	}
 	return false
 }
 
@@ -127,12 +189,8 @@ func (p *Pool) RestartProxy(name string) error {
 	os.Remove(proxy.socketPath)
 
 	// Create and start new proxy
-	newProxy, err := NewSocketProxy(p.ctx, name, proxy.command, proxy.args, proxy.env)
+	newProxy, err := p.startProxy(name, proxy.command, proxy.args, proxy.env)
 	if err != nil {
-		return fmt.Errorf("failed to create proxy: %w", err)
-	}
-
-	if err := newProxy.Start(); err != nil {
 		return fmt.Errorf("failed to start proxy: %w", err)
 	}
 
@@ -140,18 +198,26 @@ func (p *Pool) RestartProxy(name string) error {
 	return nil
 }
 
+// GetURL returns the Streamable HTTP endpoint of a pooled MCP, or "" if the
+// pool doesn't expose it over HTTP
 func (p *Pool) GetURL(name string) string {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
 	if proxy, exists := p.proxies[name]; exists {
-		return proxy.GetSocketPath()
+		return proxy.GetHTTPURL()
 	}
 	return ""
 }
 
 func (p *Pool) GetSocketPath(name string) string {
-	return p.GetURL(name)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if proxy, exists := p.proxies[name]; exists {
+		return proxy.GetSocketPath()
+	}
+	return ""
 }
 
 // FallbackEnabled returns whether stdio fallback is allowed when pool isn't working
@@ -182,8 +248,12 @@ func (p *Pool) ListServers() []ProxyInfo {
 		list = append(list, ProxyInfo{
 			Name:        proxy.name,
 			SocketPath:  proxy.socketPath,
-			Status:      proxy.Status.String(),
+			Status:      proxy.GetStatus().String(),
 			Clients:     proxy.GetClientCount(),
+			PID:         proxy.PID(),
+			Restarts:    proxy.Restarts(),
+			LastError:   proxy.LastError(),
+			HTTPURL:     proxy.GetHTTPURL(),
 		})
 	}
 	return list
@@ -194,12 +264,27 @@ type ProxyInfo struct {
 	SocketPath string
 	Status     string
 	Clients    int
+	PID        int
+	Restarts   int
+	LastError  string
+	HTTPURL    string
+}
+
+// GetStatus returns the status of a pooled MCP (StatusStopped if not in the pool)
+func (p *Pool) GetStatus(name string) ServerStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if proxy, exists := p.proxies[name]; exists {
+		return proxy.GetStatus()
+	}
+	return StatusStopped
 }
 
 // DiscoverExistingSockets scans for existing pool sockets owned by another agent-deck instance
 // and registers them so this instance can use them too. Returns count of discovered sockets.
 func (p *Pool) DiscoverExistingSockets() int {
-	pattern := filepath.Join("/tmp", "agentdeck-mcp-*.sock")
+	pattern := filepath.Join(socketDir, "agentdeck-mcp-*.sock")
 	matches, err := filepath.Glob(pattern)
 	if err != nil {
 		log.Printf("[Pool] Failed to scan for existing sockets: %v", err)
@@ -209,12 +294,10 @@ func (p *Pool) DiscoverExistingSockets() int {
 	discovered := 0
 	for _, socketPath := range matches {
 		// Extract MCP name from socket path: /tmp/agentdeck-mcp-{name}.sock
-		base := filepath.Base(socketPath)
-		if !strings.HasPrefix(base, "agentdeck-mcp-") || !strings.HasSuffix(base, ".sock") {
+		name, ok := nameFromSocketPath(socketPath)
+		if !ok {
 			continue
 		}
-		name := strings.TrimPrefix(base, "agentdeck-mcp-")
-		name = strings.TrimSuffix(name, ".sock")
 
 		// Skip if we already have this MCP
 		p.mu.RLock()
@@ -273,10 +356,12 @@ func (p *Pool) RegisterExternalSocket(name, socketPath string) error {
 	proxy := &SocketProxy{
 		name:       name,
 		socketPath: socketPath,
-		clients:    make(map[string]net.Conn),
-		requestMap: make(map[interface{}]string),
-		ctx:        p.ctx,
-		Status:     StatusRunning, // External socket is alive
+		clients:     make(map[string]*clientConn),
+		requestMap:  make(map[int64]*pendingRequest),
+		clientIDMap: make(map[clientRequestKey]int64),
+		ctx:         p.ctx,
+		external:    true,
+		status:      StatusRunning, // External socket is alive
 		// mcpProcess is nil - we don't own this process
 	}
 
@@ -0,0 +1,220 @@
+package mcppool
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Traffic directions in a recording, seen from the MCP server
+const (
+	TrafficIn  = "in"  // Client to MCP
+	TrafficOut = "out" // MCP (or the proxy on its behalf) to client
+)
+
+var (
+	// trafficMaxSize is the size at which a traffic log is rotated
+	trafficMaxSize int64 = 10 * 1024 * 1024
+
+	// trafficKeep is how many rotated traffic logs are kept (name.jsonl.1 .. .N)
+	trafficKeep = 3
+)
+
+// TrafficRecord is one JSON-RPC frame seen by a proxy. Messages are recorded in
+// the client's ID space, i.e. before request IDs are rewritten and after
+// responses are restored, so a recorded conversation can be replayed as is.
+type TrafficRecord struct {
+	Time      time.Time       `json:"ts"`
+	Direction string          `json:"dir"`
+	Client    string          `json:"client,omitempty"` // Empty for broadcasts to all clients
+	Method    string          `json:"method,omitempty"` // For responses, the method of the request
+	ID        json.RawMessage `json:"id,omitempty"`
+	LatencyMs float64         `json:"latency_ms,omitempty"` // Responses only
+	Message   json.RawMessage `json:"message"`
+}
+
+// IsResponse reports whether the record is a response to a request
+func (r *TrafficRecord) IsResponse() bool {
+	var env rpcEnvelope
+	return json.Unmarshal(r.Message, &env) == nil && env.isResponse()
+}
+
+// IsError reports whether the record is an error response
+func (r *TrafficRecord) IsError() bool {
+	var msg struct {
+		Error json.RawMessage `json:"error"`
+	}
+	return json.Unmarshal(r.Message, &msg) == nil && len(msg.Error) > 0 && string(msg.Error) != "null"
+}
+
+// TrafficLogPath returns the traffic recording for a pooled MCP
+func TrafficLogPath(name string) string {
+	return filepath.Join(os.Getenv("HOME"), ".agent-deck", "logs", "mcppool", fmt.Sprintf("%s_traffic.jsonl", name))
+}
+
+// trafficRecorder appends TrafficRecords to a JSONL file, rotating it when it
+// grows past trafficMaxSize
+type trafficRecorder struct {
+	path string
+	file *os.File
+	size int64
+	mu   sync.Mutex
+}
+
+func newTrafficRecorder(path string) (*trafficRecorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	r := &trafficRecorder{path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *trafficRecorder) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+func (r *trafficRecorder) record(rec *TrafficRecord) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return
+	}
+	if r.size+int64(len(data)) > trafficMaxSize && r.size > 0 {
+		if err := r.rotate(); err != nil {
+			log.Printf("[Pool] Traffic log rotation failed: %v", err)
+			return
+		}
+	}
+	n, err := r.file.Write(data)
+	r.size += int64(n)
+	if err != nil {
+		log.Printf("[Pool] Traffic log write failed: %v", err)
+	}
+}
+
+// rotate shifts name.jsonl -> name.jsonl.1 -> ... -> name.jsonl.N and starts
+// a new file. Must be called with r.mu held.
+func (r *trafficRecorder) rotate() error {
+	r.file.Close()
+	r.file = nil
+	for i := trafficKeep - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *trafficRecorder) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+// StartRecording starts appending the proxy's traffic to TrafficLogPath
+func (p *SocketProxy) StartRecording() error {
+	if p.recorder.Load() != nil {
+		return nil
+	}
+	r, err := newTrafficRecorder(TrafficLogPath(p.name))
+	if err != nil {
+		return fmt.Errorf("failed to open traffic log: %w", err)
+	}
+	if !p.recorder.CompareAndSwap(nil, r) {
+		r.close()
+		return nil
+	}
+	log.Printf("[Pool] %s: Recording traffic to %s", p.name, r.path)
+	return nil
+}
+
+// StopRecording stops recording the proxy's traffic
+func (p *SocketProxy) StopRecording() {
+	if r := p.recorder.Swap(nil); r != nil {
+		r.close()
+		log.Printf("[Pool] %s: Stopped recording traffic", p.name)
+	}
+}
+
+// IsRecording reports whether the proxy's traffic is being recorded
+func (p *SocketProxy) IsRecording() bool {
+	return p.recorder.Load() != nil
+}
+
+// recordTraffic records a frame if recording is enabled. It is a no-op
+// (without any decoding) otherwise.
+func (p *SocketProxy) recordTraffic(dir, sessionID string, line []byte, method string, latency time.Duration) {
+	r := p.recorder.Load()
+	if r == nil || !json.Valid(line) {
+		return
+	}
+
+	rec := &TrafficRecord{
+		Time:      time.Now(),
+		Direction: dir,
+		Client:    sessionID,
+		Method:    method,
+		Message:   line,
+	}
+	var env rpcEnvelope
+	if json.Unmarshal(line, &env) == nil {
+		if rec.Method == "" {
+			rec.Method = env.Method
+		}
+		if env.hasID() {
+			rec.ID = env.ID
+		}
+	}
+	if latency > 0 {
+		rec.LatencyMs = float64(latency.Microseconds()) / 1000
+	}
+	r.record(rec)
+}
+
+// ReadTrafficLog reads all records from a traffic log, skipping malformed lines
+func ReadTrafficLog(path string) ([]TrafficRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []TrafficRecord
+	scanner := newScanner(bufio.NewReader(f))
+	for scanner.Scan() {
+		var rec TrafficRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) == nil {
+			records = append(records, rec)
+		}
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,143 @@
+package mcppool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSocketProxy_RecordsTraffic(t *testing.T) {
+	proxy := startStubProxy(t)
+	require.NoError(t, proxy.StartRecording())
+	assert.True(t, proxy.IsRecording())
+
+	c := dialProxy(t, proxy)
+	c.send(`{"jsonrpc":"2.0","id":"a1","method":"initialize","params":{}}`)
+	require.NotNil(t, c.recv(5*time.Second))
+	c.send(`{"jsonrpc":"2.0","id":"a2","method":"notify"}`)
+	require.NotNil(t, c.recv(5*time.Second))
+	require.NotNil(t, c.recv(5*time.Second))
+	proxy.StopRecording()
+
+	records, err := ReadTrafficLog(TrafficLogPath(proxy.name))
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+
+	// Client messages and responses are recorded with the client's own IDs
+	assert.Equal(t, TrafficIn, records[0].Direction)
+	assert.Equal(t, `"a1"`, string(records[0].ID))
+	assert.Equal(t, methodInitialize, records[0].Method)
+
+	assert.Equal(t, TrafficOut, records[1].Direction)
+	assert.Equal(t, `"a1"`, string(records[1].ID))
+	assert.Equal(t, methodInitialize, records[1].Method, "responses carry the request's method")
+	assert.Equal(t, records[0].Client, records[1].Client)
+	assert.Greater(t, records[1].LatencyMs, 0.0)
+	assert.True(t, records[1].IsResponse())
+
+	// Server notifications go to every client
+	assert.Equal(t, TrafficOut, records[3].Direction)
+	assert.Empty(t, records[3].Client)
+	assert.Equal(t, "notifications/message", records[3].Method)
+}
+
+func TestSocketProxy_RecordingOff(t *testing.T) {
+	proxy := startStubProxy(t)
+	c := dialProxy(t, proxy)
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	require.NotNil(t, c.recv(5*time.Second))
+
+	_, err := os.Stat(TrafficLogPath(proxy.name))
+	assert.True(t, os.IsNotExist(err), "nothing is recorded unless enabled")
+}
+
+func TestTrafficRecorder_Rotates(t *testing.T) {
+	oldMax := trafficMaxSize
+	trafficMaxSize = 200
+	t.Cleanup(func() { trafficMaxSize = oldMax })
+
+	path := filepath.Join(t.TempDir(), "x_traffic.jsonl")
+	r, err := newTrafficRecorder(path)
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		r.record(&TrafficRecord{Direction: TrafficIn, Message: json.RawMessage(fmt.Sprintf(`{"id":%d}`, i))})
+	}
+	r.close()
+
+	for _, p := range []string{path, path + ".1", path + ".2", path + ".3"} {
+		info, err := os.Stat(p)
+		require.NoError(t, err, p)
+		assert.LessOrEqual(t, info.Size(), trafficMaxSize)
+	}
+	_, err = os.Stat(path + ".4")
+	assert.True(t, os.IsNotExist(err), "only trafficKeep rotated logs are kept")
+
+	records, err := ReadTrafficLog(path)
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+	assert.Equal(t, `{"id":19}`, string(records[len(records)-1].Message))
+}
+
+func TestReplay(t *testing.T) {
+	proxy := startStubProxy(t)
+	require.NoError(t, proxy.StartRecording())
+
+	a := dialProxy(t, proxy)
+	b := dialProxy(t, proxy)
+	a.send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	require.NotNil(t, a.recv(5*time.Second))
+	b.send(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	require.NotNil(t, b.recv(5*time.Second))
+	a.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	a.send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"x"}}`)
+	require.NotNil(t, a.recv(5*time.Second))
+	proxy.StopRecording()
+
+	records, err := ReadTrafficLog(TrafficLogPath(proxy.name))
+	require.NoError(t, err)
+	clients := RecordedClients(records)
+	require.Len(t, clients, 2)
+
+	var steps []ReplayStep
+	err = Replay(context.Background(), os.Args[0], nil, map[string]string{stubMCPEnv: "1"}, records, clients[0], func(step ReplayStep) {
+		steps = append(steps, step)
+	})
+	require.NoError(t, err)
+
+	// Only client A's conversation is replayed
+	require.Len(t, steps, 3)
+	assert.Equal(t, []string{methodInitialize, methodInitialized, "tools/call"},
+		[]string{steps[0].Method, steps[1].Method, steps[2].Method})
+	assert.Nil(t, steps[1].Response, "notifications get no response")
+	for _, step := range steps {
+		assert.NoError(t, step.Err)
+		assert.False(t, step.Diverged())
+	}
+	assert.Contains(t, string(steps[2].Response), `"name":"x"`)
+}
+
+func TestReplay_Divergence(t *testing.T) {
+	records := []TrafficRecord{
+		{Direction: TrafficIn, Client: "c", Message: json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`)},
+		{Direction: TrafficOut, Client: "c", ID: json.RawMessage(`1`), Message: json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":{}}`)},
+		{Direction: TrafficIn, Client: "c", Message: json.RawMessage(`{"jsonrpc":"2.0","id":2,"method":"initialize"}`)},
+		{Direction: TrafficOut, Client: "c", ID: json.RawMessage(`2`), Message: json.RawMessage(`{"jsonrpc":"2.0","id":2,"result":{}}`)},
+	}
+
+	var steps []ReplayStep
+	err := Replay(context.Background(), os.Args[0], nil, map[string]string{stubMCPEnv: "1"}, records, "c", func(step ReplayStep) {
+		steps = append(steps, step)
+	})
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.False(t, steps[0].Diverged())
+	// The stub rejects a second initialize, unlike the recorded server
+	assert.True(t, steps[1].Diverged())
+}
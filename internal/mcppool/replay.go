@@ -0,0 +1,223 @@
+package mcppool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// replayResponseTimeout bounds how long replay waits for each response
+var replayResponseTimeout = 30 * time.Second
+
+// ReplayStep is one replayed client message and how the fresh server answered it
+type ReplayStep struct {
+	Method   string
+	Sent     json.RawMessage
+	Response json.RawMessage // Nil for notifications
+	Recorded json.RawMessage // The response in the recording, if any
+	Latency  time.Duration
+	Err      error // Set if no response arrived
+}
+
+// Diverged reports whether the replayed response differs in outcome (result vs
+// error) from the recorded one
+func (s *ReplayStep) Diverged() bool {
+	if s.Err != nil {
+		return s.Recorded != nil
+	}
+	if s.Recorded == nil || s.Response == nil {
+		return false
+	}
+	got := TrafficRecord{Message: s.Response}
+	want := TrafficRecord{Message: s.Recorded}
+	return got.IsError() != want.IsError()
+}
+
+// RecordedClients returns the clients that sent messages in a recording, in
+// order of first appearance
+func RecordedClients(records []TrafficRecord) []string {
+	seen := make(map[string]bool)
+	var clients []string
+	for _, rec := range records {
+		if rec.Direction == TrafficIn && rec.Client != "" && !seen[rec.Client] {
+			seen[rec.Client] = true
+			clients = append(clients, rec.Client)
+		}
+	}
+	return clients
+}
+
+// replayItem is a recorded client message and the response it got
+type replayItem struct {
+	record   TrafficRecord
+	recorded json.RawMessage
+}
+
+// conversation extracts one client's messages from a recording, pairing each
+// request with the response it got
+func conversation(records []TrafficRecord, client string) []replayItem {
+	var items []replayItem
+	waiting := make(map[string]int) // Request ID -> index in items
+	for _, rec := range records {
+		if rec.Client != client {
+			continue
+		}
+		switch {
+		case rec.Direction == TrafficIn:
+			var env rpcEnvelope
+			if json.Unmarshal(rec.Message, &env) != nil || env.isResponse() {
+				continue // Answers to server-initiated requests aren't replayed
+			}
+			items = append(items, replayItem{record: rec})
+			if env.isRequest() {
+				waiting[string(env.ID)] = len(items) - 1
+			}
+		case rec.Direction == TrafficOut && len(rec.ID) > 0 && rec.IsResponse():
+			if i, ok := waiting[string(rec.ID)]; ok {
+				items[i].recorded = rec.Message
+				delete(waiting, string(rec.ID))
+			}
+		}
+	}
+	return items
+}
+
+// Replay runs a fresh instance of an MCP server and feeds it one client's
+// recorded conversation, waiting for each request's response before sending
+// the next message. onStep is called for every message sent.
+func Replay(ctx context.Context, command string, args []string, env map[string]string, records []TrafficRecord, client string, onStep func(ReplayStep)) error {
+	items := conversation(records, client)
+	if len(items) == 0 {
+		return fmt.Errorf("no messages from client %q in recording", client)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = os.Environ()
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", command, err)
+	}
+	defer func() {
+		stdin.Close()
+		cancel()
+		_ = cmd.Wait()
+	}()
+
+	var (
+		waiters   = make(map[string]chan json.RawMessage)
+		waitersMu sync.Mutex
+		exited    = make(chan struct{})
+		writeMu   sync.Mutex
+	)
+	send := func(line []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, err := stdin.Write(append(append([]byte(nil), line...), '\n'))
+		return err
+	}
+
+	go func() {
+		defer close(exited)
+		scanner := newScanner(stdout)
+		for scanner.Scan() {
+			msg := append(json.RawMessage(nil), scanner.Bytes()...)
+			var e rpcEnvelope
+			if json.Unmarshal(msg, &e) != nil {
+				continue
+			}
+			switch {
+			case e.isResponse():
+				waitersMu.Lock()
+				ch, ok := waiters[string(e.ID)]
+				delete(waiters, string(e.ID))
+				waitersMu.Unlock()
+				if ok {
+					ch <- msg
+				}
+			case e.isRequest():
+				// The recorded client's answers can't be matched to a new server's
+				// requests, so decline them rather than leave the server waiting
+				reply, _ := json.Marshal(map[string]interface{}{
+					"jsonrpc": "2.0",
+					"id":      e.ID,
+					"error":   map[string]interface{}{"code": -32601, "message": "not supported during replay"},
+				})
+				_ = send(reply)
+			}
+		}
+	}()
+
+	for _, item := range items {
+		var e rpcEnvelope
+		_ = json.Unmarshal(item.record.Message, &e)
+		step := ReplayStep{Method: e.Method, Sent: item.record.Message, Recorded: item.recorded}
+
+		var ch chan json.RawMessage
+		if e.isRequest() {
+			ch = make(chan json.RawMessage, 1)
+			waitersMu.Lock()
+			waiters[string(e.ID)] = ch
+			waitersMu.Unlock()
+		}
+
+		start := time.Now()
+		if err := send(item.record.Message); err != nil {
+			return fmt.Errorf("failed to write to %s: %w", command, err)
+		}
+
+		if ch != nil {
+			select {
+			case resp := <-ch:
+				step.Response = resp
+				step.Latency = time.Since(start)
+			case <-exited:
+				// The response may have been read just before the server exited
+				select {
+				case resp := <-ch:
+					step.Response = resp
+					step.Latency = time.Since(start)
+				default:
+					step.Err = fmt.Errorf("server exited")
+				}
+			case <-time.After(replayResponseTimeout):
+				step.Err = fmt.Errorf("no response after %s", replayResponseTimeout)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if onStep != nil {
+			onStep(step)
+		}
+		if step.Err != nil && isClosed(exited) {
+			return fmt.Errorf("%s exited during replay", command)
+		}
+	}
+	return nil
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
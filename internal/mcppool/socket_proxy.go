@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -24,17 +25,37 @@ type SocketProxy struct {
 	args       []string
 	env        map[string]string
 
-	mcpProcess *exec.Cmd
-	mcpStdin   io.WriteCloser
-	mcpStdout  io.ReadCloser
+	// procMu guards the current MCP process and serializes writes to its stdin,
+	// which is shared by all client goroutines and replaced on restart
+	mcpProcess  *exec.Cmd
+	mcpStdin    io.WriteCloser
+	processDone chan struct{}
+	startedAt   time.Time
+	stopping    bool
+	// restartRequested marks the next process exit as a manual restart
+	restartRequested bool
+	procMu           sync.Mutex
 
 	listener net.Listener
 
-	clients   map[string]net.Conn
-	clientsMu sync.RWMutex
+	clients       map[string]*clientConn
+	clientCounter int
+	clientsMu     sync.RWMutex
 
-	requestMap map[interface{}]string
-	requestMu  sync.Mutex
+	http *httpFrontend // Optional Streamable HTTP endpoint
+
+	// requestMap maps proxy-assigned request IDs back to the originating client.
+	// Every client request is rewritten to a proxy-unique ID before it reaches the
+	// shared MCP process, so clients that reuse the same JSON-RPC IDs don't collide.
+	requestMap  map[int64]*pendingRequest
+	clientIDMap map[clientRequestKey]int64
+	nextID      int64
+	// serverRequests maps the IDs of requests the MCP process sent (sampling,
+	// elicitation, roots) to the one client asked to answer each of them
+	serverRequests map[string]string
+	requestMu      sync.Mutex
+
+	handshake handshakeState
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -42,7 +63,17 @@ type SocketProxy struct {
 	logFile   string
 	logWriter io.WriteCloser
 
-	Status ServerStatus
+	// external is set when the socket is owned by another agent-deck instance
+	external bool
+
+	totalRequests atomic.Int64
+
+	recorder atomic.Pointer[trafficRecorder] // Nil unless traffic recording is on
+
+	status    ServerStatus
+	restarts  int
+	lastError string
+	statusMu  sync.RWMutex
 }
 
 type JSONRPCRequest struct {
@@ -59,6 +90,68 @@ type JSONRPCResponse struct {
 	ID      interface{} `json:"id,omitempty"`
 }
 
+// maxMessageSize bounds a single JSON-RPC line (tool results can be large)
+const maxMessageSize = 16 * 1024 * 1024
+
+// methodCancelled is the MCP notification used to cancel an in-flight request
+const methodCancelled = "notifications/cancelled"
+
+// errNoClient answers a server request no client is left to answer
+var errNoClient = json.RawMessage(`{"code":-32603,"message":"no client connected to answer the request"}`)
+
+// pendingRequest tracks an in-flight request forwarded to the MCP process
+type pendingRequest struct {
+	sessionID  string
+	originalID json.RawMessage
+	method     string
+	sentAt     time.Time
+}
+
+// clientRequestKey identifies a request by the client that sent it and its original ID
+type clientRequestKey struct {
+	sessionID string
+	id        string
+}
+
+// rpcEnvelope holds the fields the proxy needs to route a JSON-RPC message
+// without decoding (and re-encoding) params or results.
+type rpcEnvelope struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+func (e *rpcEnvelope) hasID() bool {
+	return len(e.ID) > 0 && string(e.ID) != "null"
+}
+
+// isRequest reports whether the message is a request (method + id)
+func (e *rpcEnvelope) isRequest() bool {
+	return e.Method != "" && e.hasID()
+}
+
+// isResponse reports whether the message is a response (id, no method)
+func (e *rpcEnvelope) isResponse() bool {
+	return e.Method == "" && e.hasID()
+}
+
+// setMessageField replaces a top-level field in a JSON-RPC message
+func setMessageField(line []byte, field string, value json.RawMessage) ([]byte, error) {
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return nil, err
+	}
+	msg[field] = value
+	return json.Marshal(msg)
+}
+
+// newScanner returns a line scanner sized for large MCP messages
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	return scanner
+}
+
 // isSocketAlive checks if a Unix socket exists and is accepting connections
 func isSocketAlive(socketPath string) bool {
 	// Check if socket file exists
@@ -78,23 +171,25 @@ func isSocketAlive(socketPath string) bool {
 
 func NewSocketProxy(ctx context.Context, name, command string, args []string, env map[string]string) (*SocketProxy, error) {
 	ctx, cancel := context.WithCancel(ctx)
-	socketPath := filepath.Join("/tmp", fmt.Sprintf("agentdeck-mcp-%s.sock", name))
+	socketPath := socketPathFor(name)
 
 	// Check if socket already exists and is alive (another agent-deck instance owns it)
 	if isSocketAlive(socketPath) {
 		log.Printf("[Pool] Socket %s already alive (owned by another agent-deck), reusing", name)
 		// Return a proxy that just points to the existing socket (no process to manage)
 		return &SocketProxy{
-			name:       name,
-			socketPath: socketPath,
-			command:    command,
-			args:       args,
-			env:        env,
-			clients:    make(map[string]net.Conn),
-			requestMap: make(map[interface{}]string),
-			ctx:        ctx,
-			cancel:     cancel,
-			Status:     StatusRunning, // Mark as running since external socket is alive
+			name:        name,
+			socketPath:  socketPath,
+			command:     command,
+			args:        args,
+			env:         env,
+			clients:     make(map[string]*clientConn),
+			requestMap:  make(map[int64]*pendingRequest),
+			clientIDMap: make(map[clientRequestKey]int64),
+			ctx:         ctx,
+			cancel:      cancel,
+			external:    true,
+			status:      StatusRunning, // Mark as running since external socket is alive
 		}, nil
 	}
 
@@ -102,22 +197,23 @@ func NewSocketProxy(ctx context.Context, name, command string, args []string, en
 	os.Remove(socketPath)
 
 	return &SocketProxy{
-		name:       name,
-		socketPath: socketPath,
-		command:    command,
-		args:       args,
-		env:        env,
-		clients:    make(map[string]net.Conn),
-		requestMap: make(map[interface{}]string),
-		ctx:        ctx,
-		cancel:     cancel,
-		Status:     StatusStarting,
+		name:        name,
+		socketPath:  socketPath,
+		command:     command,
+		args:        args,
+		env:         env,
+		clients:     make(map[string]*clientConn),
+		requestMap:  make(map[int64]*pendingRequest),
+		clientIDMap: make(map[clientRequestKey]int64),
+		ctx:         ctx,
+		cancel:      cancel,
+		status:      StatusStarting,
 	}, nil
 }
 
 func (p *SocketProxy) Start() error {
 	// If already running (reusing external socket), skip process creation
-	if p.Status == StatusRunning {
+	if p.GetStatus() == StatusRunning {
 		log.Printf("[Pool] %s: Reusing existing socket, no process to start", p.name)
 		return nil
 	}
@@ -132,48 +228,72 @@ func (p *SocketProxy) Start() error {
 	}
 	p.logWriter = logWriter
 
-	p.mcpProcess = exec.CommandContext(p.ctx, p.command, p.args...)
+	if err := p.startProcess(); err != nil {
+		p.setStatus(StatusFailed, err.Error())
+		return err
+	}
+
+	listener, err := net.Listen("unix", p.socketPath)
+	if err != nil {
+		p.procMu.Lock()
+		p.stopping = true
+		_ = p.mcpProcess.Process.Kill()
+		p.procMu.Unlock()
+		p.setStatus(StatusFailed, err.Error())
+		return err
+	}
+	p.listener = listener
+
+	log.Printf("Socket proxy %s at: %s", p.name, p.socketPath)
+
+	p.setStatus(StatusRunning, "")
+	go p.acceptConnections()
+	return nil
+}
+
+// startProcess spawns the MCP process and starts reading its output. The
+// process is watched by the supervisor, which restarts it if it exits.
+func (p *SocketProxy) startProcess() error {
+	cmd := exec.CommandContext(p.ctx, p.command, p.args...)
 	cmdEnv := os.Environ()
 	for k, v := range p.env {
 		cmdEnv = append(cmdEnv, fmt.Sprintf("%s=%s", k, v))
 	}
-	p.mcpProcess.Env = cmdEnv
+	cmd.Env = cmdEnv
 
-	p.mcpStdin, err = p.mcpProcess.StdinPipe()
+	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return err
 	}
-	p.mcpStdout, err = p.mcpProcess.StdoutPipe()
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return err
 	}
-	stderr, _ := p.mcpProcess.StderrPipe()
+	stderr, _ := cmd.StderrPipe()
 
-	if err := p.mcpProcess.Start(); err != nil {
+	if err := cmd.Start(); err != nil {
 		return err
 	}
 
-	log.Printf("Started MCP %s (PID: %d)", p.name, p.mcpProcess.Process.Pid)
-	go func() { _, _ = io.Copy(p.logWriter, stderr) }()
-
-	listener, err := net.Listen("unix", p.socketPath)
-	if err != nil {
-		_ = p.mcpProcess.Process.Kill()
-		return err
+	log.Printf("Started MCP %s (PID: %d)", p.name, cmd.Process.Pid)
+	if p.logWriter != nil && stderr != nil {
+		go func() { _, _ = io.Copy(p.logWriter, stderr) }()
 	}
-	p.listener = listener
-
-	log.Printf("Socket proxy %s at: %s", p.name, p.socketPath)
 
-	go p.acceptConnections()
-	go p.broadcastResponses()
+	done := make(chan struct{})
+	p.procMu.Lock()
+	p.mcpProcess = cmd
+	p.mcpStdin = stdin
+	p.processDone = done
+	p.startedAt = time.Now()
+	p.procMu.Unlock()
 
-	p.Status = StatusRunning
+	go p.broadcastResponses(stdout)
+	go p.supervise(cmd, done)
 	return nil
 }
 
 func (p *SocketProxy) acceptConnections() {
-	clientCounter := 0
 	for {
 		conn, err := p.listener.Accept()
 		if err != nil {
@@ -185,16 +305,33 @@ func (p *SocketProxy) acceptConnections() {
 			}
 		}
 
-		sessionID := fmt.Sprintf("%s-client-%d", p.name, clientCounter)
-		clientCounter++
-
-		p.clientsMu.Lock()
-		p.clients[sessionID] = conn
-		p.clientsMu.Unlock()
+		p.attachClient("socket", conn)
+	}
+}
 
-		log.Printf("[%s] Client connected: %s", p.name, sessionID)
-		go p.handleClient(sessionID, conn)
+// attachClient registers a client connection and starts serving it.
+// transport is "socket" or "http".
+func (p *SocketProxy) attachClient(transport string, conn net.Conn) string {
+	kind := "client"
+	if transport == "http" {
+		kind = "http"
+	}
+	client := &clientConn{
+		conn:        conn,
+		transport:   transport,
+		pid:         peerPID(conn),
+		connectedAt: time.Now(),
 	}
+
+	p.clientsMu.Lock()
+	sessionID := fmt.Sprintf("%s-%s-%d", p.name, kind, p.clientCounter)
+	p.clientCounter++
+	p.clients[sessionID] = client
+	p.clientsMu.Unlock()
+
+	log.Printf("[%s] Client connected: %s", p.name, sessionID)
+	go p.handleClient(sessionID, conn)
+	return sessionID
 }
 
 func (p *SocketProxy) handleClient(sessionID string, conn net.Conn) {
@@ -202,94 +339,405 @@ func (p *SocketProxy) handleClient(sessionID string, conn net.Conn) {
 		p.clientsMu.Lock()
 		delete(p.clients, sessionID)
 		p.clientsMu.Unlock()
+		p.dropClientRequests(sessionID)
+		p.dropServerRequests(sessionID)
 		conn.Close()
 		log.Printf("[%s] Client disconnected: %s", p.name, sessionID)
 	}()
 
-	scanner := bufio.NewScanner(conn)
+	scanner := newScanner(conn)
 	for scanner.Scan() {
-		line := scanner.Bytes()
-
-		var req JSONRPCRequest
-		if err := json.Unmarshal(line, &req); err != nil {
+		line, ok := p.translateClientMessage(sessionID, scanner.Bytes())
+		if !ok {
 			continue
 		}
+		p.writeToMCP(line)
+	}
+}
+
+// translateClientMessage rewrites a message from a client before it is sent to the
+// shared MCP process. Requests get a proxy-unique ID and cancellation notifications
+// are pointed at that ID. Returns false if the message should be dropped.
+func (p *SocketProxy) translateClientMessage(sessionID string, line []byte) ([]byte, bool) {
+	var env rpcEnvelope
+	if err := json.Unmarshal(line, &env); err != nil {
+		return nil, false
+	}
+
+	if isControlMethod(env.Method) {
+		p.handleControl(sessionID, &env)
+		return nil, false
+	}
+	p.recordTraffic(TrafficIn, sessionID, line, "", 0)
+
+	switch {
+	case env.isRequest():
+		if p.GetStatus() != StatusRunning {
+			p.replyToClient(sessionID, env.ID, "error", errServerRestarting)
+			return nil, false
+		}
+		if env.Method == methodInitialize && !p.handleInitialize(sessionID, &env) {
+			return nil, false
+		}
+		p.countRequest(sessionID)
+		proxyID := p.registerRequest(sessionID, env.ID, env.Method)
+		rewritten, err := setMessageField(line, "id", json.RawMessage(fmt.Sprintf("%d", proxyID)))
+		if err != nil {
+			p.forgetRequest(proxyID)
+			return nil, false
+		}
+		return rewritten, true
+
+	case env.Method == methodCancelled:
+		return p.translateCancellation(sessionID, line, env.Params)
+
+	case env.Method == methodInitialized:
+		// Duplicate initialized notifications are swallowed; the backend was
+		// initialized once on behalf of all clients
+		return line, p.shouldForwardInitialized()
+
+	case env.isResponse():
+		// Only the client a server request was sent to may answer it
+		return line, p.takeServerRequest(sessionID, env.ID)
+	}
+
+	// Other notifications pass through
+	return line, true
+}
+
+// translateCancellation maps the requestId in a cancellation notification from the
+// client's ID space to the proxy's. Cancellations for unknown requests are dropped,
+// since forwarding them could cancel another client's request with the same ID.
+func (p *SocketProxy) translateCancellation(sessionID string, line []byte, rawParams json.RawMessage) ([]byte, bool) {
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, false
+	}
+
+	p.requestMu.Lock()
+	proxyID, exists := p.clientIDMap[clientRequestKey{sessionID: sessionID, id: string(params["requestId"])}]
+	p.requestMu.Unlock()
+	if !exists {
+		return nil, false
+	}
+
+	params["requestId"] = json.RawMessage(fmt.Sprintf("%d", proxyID))
+	newParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, false
+	}
+	rewritten, err := setMessageField(line, "params", newParams)
+	if err != nil {
+		return nil, false
+	}
+	return rewritten, true
+}
+
+// registerRequest allocates a proxy-unique ID for a client request
+func (p *SocketProxy) registerRequest(sessionID string, originalID json.RawMessage, method string) int64 {
+	p.requestMu.Lock()
+	defer p.requestMu.Unlock()
+
+	p.nextID++
+	proxyID := p.nextID
+	p.requestMap[proxyID] = &pendingRequest{
+		sessionID:  sessionID,
+		originalID: append(json.RawMessage(nil), originalID...),
+		method:     method,
+		sentAt:     time.Now(),
+	}
+	p.clientIDMap[clientRequestKey{sessionID: sessionID, id: string(originalID)}] = proxyID
+	return proxyID
+}
+
+// takeRequest removes and returns the pending request for a proxy ID
+func (p *SocketProxy) takeRequest(proxyID int64) (*pendingRequest, bool) {
+	p.requestMu.Lock()
+	defer p.requestMu.Unlock()
+
+	req, exists := p.requestMap[proxyID]
+	if exists {
+		delete(p.requestMap, proxyID)
+		delete(p.clientIDMap, clientRequestKey{sessionID: req.sessionID, id: string(req.originalID)})
+	}
+	return req, exists
+}
+
+func (p *SocketProxy) forgetRequest(proxyID int64) {
+	_, _ = p.takeRequest(proxyID)
+}
 
-		if req.ID != nil {
-			p.requestMu.Lock()
-			p.requestMap[req.ID] = sessionID
-			p.requestMu.Unlock()
+// dropClientRequests forgets all in-flight requests of a disconnected client
+func (p *SocketProxy) dropClientRequests(sessionID string) {
+	p.requestMu.Lock()
+	defer p.requestMu.Unlock()
+
+	for proxyID, req := range p.requestMap {
+		// An in-flight initialize is kept: other clients may be waiting on its result
+		if req.sessionID == sessionID && req.method != methodInitialize {
+			delete(p.requestMap, proxyID)
+			delete(p.clientIDMap, clientRequestKey{sessionID: req.sessionID, id: string(req.originalID)})
 		}
+	}
+}
+
+// PendingRequests returns the number of requests awaiting a response from the MCP
+func (p *SocketProxy) PendingRequests() int {
+	p.requestMu.Lock()
+	defer p.requestMu.Unlock()
+	return len(p.requestMap)
+}
 
-		_, _ = p.mcpStdin.Write(line)
-		_, _ = p.mcpStdin.Write([]byte("\n"))
+// writeToMCP forwards a message to the MCP process. Writes are serialized because
+// several client goroutines share the same stdin pipe.
+func (p *SocketProxy) writeToMCP(line []byte) {
+	p.procMu.Lock()
+	defer p.procMu.Unlock()
+	if p.mcpStdin != nil {
+		writeLine(p.mcpStdin, line)
 	}
 }
 
-func (p *SocketProxy) broadcastResponses() {
-	scanner := bufio.NewScanner(p.mcpStdout)
+func (p *SocketProxy) broadcastResponses(stdout io.Reader) {
+	scanner := newScanner(stdout)
 	for scanner.Scan() {
 		line := scanner.Bytes()
 
-		var resp JSONRPCResponse
-		if json.Unmarshal(line, &resp) != nil {
+		var env rpcEnvelope
+		if json.Unmarshal(line, &env) != nil {
 			p.broadcastToAll(line)
 			continue
 		}
 
-		if resp.ID != nil {
-			p.routeToClient(resp.ID, line)
-		} else {
+		switch {
+		case env.isResponse():
+			p.routeToClient(env.ID, line)
+		case env.isRequest():
+			p.routeServerRequest(env.ID, line)
+		default:
 			p.broadcastToAll(line)
 		}
 	}
 }
 
-func (p *SocketProxy) routeToClient(responseID interface{}, line []byte) {
+// routeServerRequest sends a request from the MCP process to a single client,
+// since only one answer can go back. The client that sent the latest pending
+// request is asked, as it most likely triggered the server's request.
+func (p *SocketProxy) routeServerRequest(id json.RawMessage, line []byte) {
+	sessionID := p.serverRequestTarget()
+	if sessionID == "" {
+		log.Printf("[%s] No client to answer server request %s", p.name, id)
+		p.replyToMCP(id, errNoClient)
+		return
+	}
+
 	p.requestMu.Lock()
-	sessionID, exists := p.requestMap[responseID]
-	if exists {
-		delete(p.requestMap, responseID)
+	if p.serverRequests == nil {
+		p.serverRequests = make(map[string]string)
+	}
+	p.serverRequests[string(id)] = sessionID
+	p.requestMu.Unlock()
+
+	p.sendToClient(sessionID, line)
+}
+
+// serverRequestTarget picks the client to answer a server request: the one with
+// the latest pending request, else the longest-connected one. Returns "" if no
+// client is connected.
+func (p *SocketProxy) serverRequestTarget() string {
+	p.clientsMu.RLock()
+	defer p.clientsMu.RUnlock()
+
+	p.requestMu.Lock()
+	var latest int64
+	target := ""
+	for proxyID, req := range p.requestMap {
+		if client, ok := p.clients[req.sessionID]; ok && !client.control && proxyID > latest {
+			latest, target = proxyID, req.sessionID
+		}
 	}
 	p.requestMu.Unlock()
+	if target != "" {
+		return target
+	}
+
+	var oldest time.Time
+	for sessionID, client := range p.clients {
+		if !client.control && (target == "" || client.connectedAt.Before(oldest)) {
+			target, oldest = sessionID, client.connectedAt
+		}
+	}
+	return target
+}
+
+// takeServerRequest forgets a server request answered by a client. Returns
+// false if the request wasn't sent to that client, so the answer is dropped.
+func (p *SocketProxy) takeServerRequest(sessionID string, id json.RawMessage) bool {
+	p.requestMu.Lock()
+	defer p.requestMu.Unlock()
+
+	if target, ok := p.serverRequests[string(id)]; !ok || target != sessionID {
+		log.Printf("[%s] Dropping unexpected response %s from %s", p.name, id, sessionID)
+		return false
+	}
+	delete(p.serverRequests, string(id))
+	return true
+}
+
+// dropServerRequests answers the server requests still waiting on a
+// disconnected client with an error, so the MCP process doesn't wait forever
+func (p *SocketProxy) dropServerRequests(sessionID string) {
+	p.requestMu.Lock()
+	var orphaned []string
+	for id, target := range p.serverRequests {
+		if target == sessionID {
+			orphaned = append(orphaned, id)
+			delete(p.serverRequests, id)
+		}
+	}
+	p.requestMu.Unlock()
+
+	for _, id := range orphaned {
+		p.replyToMCP(json.RawMessage(id), errNoClient)
+	}
+}
+
+// replyToMCP answers a server request on behalf of the clients
+func (p *SocketProxy) replyToMCP(id json.RawMessage, rpcErr json.RawMessage) {
+	msg, err := json.Marshal(map[string]json.RawMessage{
+		"jsonrpc": json.RawMessage(`"2.0"`),
+		"id":      id,
+		"error":   rpcErr,
+	})
+	if err != nil {
+		return
+	}
+	p.writeToMCP(msg)
+}
+
+// routeToClient delivers a response to the client that issued the request,
+// restoring the client's original request ID.
+func (p *SocketProxy) routeToClient(responseID json.RawMessage, line []byte) {
+	var proxyID int64
+	if err := json.Unmarshal(responseID, &proxyID); err != nil {
+		log.Printf("[%s] Dropping response with unknown id %s", p.name, responseID)
+		return
+	}
 
+	req, exists := p.takeRequest(proxyID)
 	if !exists {
-		p.broadcastToAll(line)
+		// Response to a request whose client has gone away (or was never ours)
+		log.Printf("[%s] Dropping response for unknown request %d", p.name, proxyID)
 		return
 	}
 
+	if req.method == methodInitialize {
+		p.completeInitialize(line)
+		if req.sessionID == "" {
+			// The proxy's own replay after a restart; no client is waiting on it
+			p.completeReplay()
+			return
+		}
+	}
+
+	restored, err := setMessageField(line, "id", req.originalID)
+	if err != nil {
+		return
+	}
+	p.deliver(req.sessionID, restored, req.method, time.Since(req.sentAt))
+}
+
+// sendToClient writes a message to a single client, if it is still connected
+func (p *SocketProxy) sendToClient(sessionID string, line []byte) {
+	p.deliver(sessionID, line, "", 0)
+}
+
+// deliver writes a message to a client and records it. method and latency
+// describe the request being answered, if any.
+func (p *SocketProxy) deliver(sessionID string, line []byte, method string, latency time.Duration) {
 	p.clientsMu.RLock()
-	conn, exists := p.clients[sessionID]
+	client, exists := p.clients[sessionID]
 	p.clientsMu.RUnlock()
 
-	if exists {
-		_, _ = conn.Write(line)
-		_, _ = conn.Write([]byte("\n"))
+	if !exists {
+		return
+	}
+	if !client.control {
+		p.recordTraffic(TrafficOut, sessionID, line, method, latency)
 	}
+	writeLine(client.conn, line)
 }
 
 func (p *SocketProxy) broadcastToAll(line []byte) {
+	p.recordTraffic(TrafficOut, "", line, "", 0)
+
 	p.clientsMu.RLock()
 	defer p.clientsMu.RUnlock()
 
-	for _, conn := range p.clients {
-		_, _ = conn.Write(line)
-		_, _ = conn.Write([]byte("\n"))
+	for _, client := range p.clients {
+		if !client.control {
+			writeLine(client.conn, line)
+		}
 	}
 }
 
+// clientConn is a connected client plus the bookkeeping shown by `mcp pool clients`
+type clientConn struct {
+	conn        net.Conn
+	transport   string // "socket" or "http"
+	pid         int    // Peer process ID, 0 if unknown
+	connectedAt time.Time
+	requests    atomic.Int64
+
+	// control clients only issue agentdeck/* commands (e.g. the CLI); they are
+	// hidden from client listings and don't receive server broadcasts
+	control bool
+}
+
+// countRequest records a forwarded request for the client and the proxy
+func (p *SocketProxy) countRequest(sessionID string) {
+	p.totalRequests.Add(1)
+	p.clientsMu.RLock()
+	if client, ok := p.clients[sessionID]; ok {
+		client.requests.Add(1)
+	}
+	p.clientsMu.RUnlock()
+}
+
+// writeLine writes a newline-terminated message in a single write so concurrent
+// writers never interleave partial frames.
+func writeLine(w io.Writer, line []byte) {
+	msg := make([]byte, 0, len(line)+1)
+	msg = append(msg, line...)
+	msg = append(msg, '\n')
+	_, _ = w.Write(msg)
+}
+
 func (p *SocketProxy) Stop() error {
-	p.cancel()
+	p.procMu.Lock()
+	p.stopping = true
+	cmd, stdin, done := p.mcpProcess, p.mcpStdin, p.processDone
+	p.procMu.Unlock()
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+	if p.http != nil {
+		p.http.stop()
+	}
 	if p.listener != nil {
 		p.listener.Close()
 	}
 	// Only kill process and remove socket if we OWN it (mcpProcess != nil)
 	// If mcpProcess is nil, we're just reusing an external socket
-	if p.mcpProcess != nil {
-		p.mcpStdin.Close()
-		_ = p.mcpProcess.Process.Signal(syscall.SIGTERM)
-		_ = p.mcpProcess.Wait()
+	if cmd != nil {
+		if stdin != nil {
+			stdin.Close()
+		}
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+		<-done                  // The supervisor reaps the process
 		os.Remove(p.socketPath) // Only remove socket if we created it
+		os.Remove(stateFilePath(p.name))
 		log.Printf("[Pool] %s: Stopped owned process and removed socket", p.name)
 	} else {
 		log.Printf("[Pool] %s: Disconnected from external socket (not removing)", p.name)
@@ -297,10 +745,18 @@ func (p *SocketProxy) Stop() error {
 	if p.logWriter != nil {
 		p.logWriter.Close()
 	}
-	p.Status = StatusStopped
+	p.StopRecording()
+	p.statusMu.Lock()
+	p.status = StatusStopped
+	p.statusMu.Unlock()
 	return nil
 }
 
+// Done is closed when the proxy is stopped
+func (p *SocketProxy) Done() <-chan struct{} {
+	return p.ctx.Done()
+}
+
 func (p *SocketProxy) GetSocketPath() string {
 	return p.socketPath
 }
@@ -308,14 +764,59 @@ func (p *SocketProxy) GetSocketPath() string {
 func (p *SocketProxy) GetClientCount() int {
 	p.clientsMu.RLock()
 	defer p.clientsMu.RUnlock()
-	return len(p.clients)
+
+	count := 0
+	for _, client := range p.clients {
+		if !client.control {
+			count++
+		}
+	}
+	return count
+}
+
+// GetStatus returns the current state of the proxy
+func (p *SocketProxy) GetStatus() ServerStatus {
+	p.statusMu.RLock()
+	defer p.statusMu.RUnlock()
+	return p.status
+}
+
+// setStatus records a state transition and publishes it for other agent-deck processes
+func (p *SocketProxy) setStatus(status ServerStatus, lastError string) {
+	p.statusMu.Lock()
+	changed := p.status != status
+	p.status = status
+	if lastError != "" {
+		p.lastError = lastError
+	}
+	p.statusMu.Unlock()
+
+	if changed {
+		log.Printf("[Pool] %s: status → %s", p.name, status)
+	}
+	if !p.external {
+		p.writeState()
+	}
+}
+
+// PID returns the process ID of the MCP process, or 0 if this proxy doesn't own one
+func (p *SocketProxy) PID() int {
+	p.procMu.Lock()
+	defer p.procMu.Unlock()
+	if p.mcpProcess == nil || p.mcpProcess.Process == nil {
+		return 0
+	}
+	return p.mcpProcess.Process.Pid
 }
 
 func (p *SocketProxy) HealthCheck() error {
-	if p.mcpProcess == nil {
+	p.procMu.Lock()
+	cmd := p.mcpProcess
+	p.procMu.Unlock()
+	if cmd == nil {
 		return fmt.Errorf("process not running")
 	}
-	if err := p.mcpProcess.Process.Signal(syscall.Signal(0)); err != nil {
+	if err := cmd.Process.Signal(syscall.Signal(0)); err != nil {
 		return err
 	}
 	if _, err := os.Stat(p.socketPath); err != nil {
@@ -0,0 +1,227 @@
+package mcppool
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startStubProxy starts a SocketProxy in front of the stub MCP server built into the test binary
+func startStubProxy(t *testing.T) *SocketProxy {
+	t.Helper()
+	return startStubProxyMode(t, "1")
+}
+
+func startStubProxyMode(t *testing.T, mode string) *SocketProxy {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir()) // Keep proxy logs out of the real ~/.agent-deck
+
+	name := fmt.Sprintf("test-%d-%d", os.Getpid(), time.Now().UnixNano())
+	proxy, err := NewSocketProxy(context.Background(), name, os.Args[0], nil, map[string]string{stubMCPEnv: mode})
+	require.NoError(t, err)
+	require.NoError(t, proxy.Start())
+	t.Cleanup(func() { _ = proxy.Stop() })
+	return proxy
+}
+
+type testClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialProxy(t *testing.T, proxy *SocketProxy) *testClient {
+	t.Helper()
+	conn, err := net.Dial("unix", proxy.GetSocketPath())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return &testClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *testClient) send(msg string) {
+	c.t.Helper()
+	_, err := c.conn.Write([]byte(msg + "\n"))
+	require.NoError(c.t, err)
+}
+
+// recv reads the next message, or returns nil if none arrives within timeout
+func (c *testClient) recv(timeout time.Duration) map[string]json.RawMessage {
+	c.t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(timeout))
+	line, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		return nil
+	}
+	var msg map[string]json.RawMessage
+	require.NoError(c.t, json.Unmarshal(line, &msg))
+	return msg
+}
+
+func resultField(t *testing.T, msg map[string]json.RawMessage, path ...string) string {
+	t.Helper()
+	raw := msg["result"]
+	for _, key := range path {
+		var obj map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(raw, &obj))
+		raw = obj[key]
+	}
+	return string(raw)
+}
+
+func TestSocketProxy_CollidingRequestIDs(t *testing.T) {
+	proxy := startStubProxy(t)
+	a := dialProxy(t, proxy)
+	b := dialProxy(t, proxy)
+
+	// Both clients use the same numeric and string IDs
+	a.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"client":"a"}}`)
+	b.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"client":"b"}}`)
+	a.send(`{"jsonrpc":"2.0","id":"req-x","method":"tools/list","params":{"client":"a"}}`)
+	b.send(`{"jsonrpc":"2.0","id":"req-x","method":"tools/list","params":{"client":"b"}}`)
+
+	serverIDs := map[string]bool{}
+	for _, tc := range []struct {
+		client *testClient
+		name   string
+	}{{a, `"a"`}, {b, `"b"`}} {
+		seen := map[string]string{}
+		for i := 0; i < 2; i++ {
+			msg := tc.client.recv(5 * time.Second)
+			require.NotNil(t, msg, "client %s: missing response", tc.name)
+			assert.Equal(t, tc.name, resultField(t, msg, "params", "client"), "response routed to wrong client")
+			seen[string(msg["id"])] = resultField(t, msg, "method")
+			serverIDs[resultField(t, msg, "serverId")] = true
+		}
+		assert.Equal(t, map[string]string{`1`: `"tools/call"`, `"req-x"`: `"tools/list"`}, seen)
+	}
+	assert.Len(t, serverIDs, 4, "server should see a unique ID for every request")
+
+	assert.Equal(t, 0, proxy.PendingRequests())
+}
+
+func TestSocketProxy_CancellationTranslation(t *testing.T) {
+	proxy := startStubProxy(t)
+	a := dialProxy(t, proxy)
+	b := dialProxy(t, proxy)
+
+	a.send(`{"jsonrpc":"2.0","id":7,"method":"slow"}`)
+	b.send(`{"jsonrpc":"2.0","id":7,"method":"slow"}`)
+	require.Eventually(t, func() bool { return proxy.PendingRequests() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// Cancelling A's request must not affect B's request with the same ID
+	a.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"user"}}`)
+	msg := a.recv(5 * time.Second)
+	require.NotNil(t, msg)
+	assert.Equal(t, `7`, string(msg["id"]))
+	assert.Contains(t, string(msg["error"]), "cancelled")
+	assert.Nil(t, b.recv(200*time.Millisecond), "client b should not see a's cancellation")
+
+	// Cancelling an unknown request is dropped instead of reaching the server
+	a.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7}}`)
+	assert.Nil(t, b.recv(200*time.Millisecond))
+
+	b.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7}}`)
+	msg = b.recv(5 * time.Second)
+	require.NotNil(t, msg)
+	assert.Equal(t, `7`, string(msg["id"]))
+	assert.Equal(t, 0, proxy.PendingRequests())
+}
+
+func TestSocketProxy_DisconnectDropsPendingRequests(t *testing.T) {
+	proxy := startStubProxy(t)
+	a := dialProxy(t, proxy)
+
+	a.send(`{"jsonrpc":"2.0","id":1,"method":"slow"}`)
+	require.Eventually(t, func() bool { return proxy.PendingRequests() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	a.conn.Close()
+	require.Eventually(t, func() bool { return proxy.PendingRequests() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSocketProxy_ServerRequestGoesToOneClient(t *testing.T) {
+	proxy := startStubProxy(t)
+	a := dialProxy(t, proxy)
+	b := dialProxy(t, proxy)
+
+	a.send(`{"jsonrpc":"2.0","id":1,"method":"sample"}`)
+	msg := a.recv(5 * time.Second)
+	require.NotNil(t, msg, "the client that triggered the request should be asked")
+	assert.Equal(t, `"sampling/createMessage"`, string(msg["method"]))
+	serverID := string(msg["id"])
+	assert.Nil(t, b.recv(200*time.Millisecond), "other clients should not see the request")
+
+	// An answer from a client that wasn't asked is dropped
+	b.send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"from":"b"}}`, serverID))
+	a.send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"from":"a"}}`, serverID))
+
+	msg = a.recv(5 * time.Second)
+	require.NotNil(t, msg)
+	assert.Equal(t, `1`, string(msg["id"]))
+	assert.Equal(t, `"a"`, resultField(t, msg, "sampled", "from"))
+	assert.Nil(t, b.recv(200*time.Millisecond))
+}
+
+func TestSocketProxy_ServerRequestFailsWhenClientLeaves(t *testing.T) {
+	proxy := startStubProxy(t)
+	a := dialProxy(t, proxy)
+	b := dialProxy(t, proxy)
+
+	a.send(`{"jsonrpc":"2.0","id":1,"method":"sample"}`)
+	require.NotNil(t, a.recv(5*time.Second))
+
+	// a leaves without answering: the server gets an error instead of waiting
+	a.conn.Close()
+	require.Eventually(t, func() bool {
+		b.send(`{"jsonrpc":"2.0","id":2,"method":"stats"}`)
+		msg := b.recv(5 * time.Second)
+		return msg != nil && resultField(t, msg, "sampling") == `0`
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestSocketProxy_InitializeHandshakeCached(t *testing.T) {
+	proxy := startStubProxy(t)
+	const initReq = `{"jsonrpc":"2.0","id":%d,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"%s"}}}`
+	const initialized = `{"jsonrpc":"2.0","method":"notifications/initialized"}`
+
+	clients := []*testClient{dialProxy(t, proxy), dialProxy(t, proxy), dialProxy(t, proxy)}
+	for i, c := range clients {
+		c.send(fmt.Sprintf(initReq, i+1, fmt.Sprintf("client-%d", i)))
+	}
+
+	var results []string
+	for i, c := range clients {
+		msg := c.recv(5 * time.Second)
+		require.NotNil(t, msg, "client %d: no initialize response", i)
+		assert.Equal(t, fmt.Sprintf("%d", i+1), string(msg["id"]))
+		assert.Nil(t, msg["error"], "client %d: initialize should not fail", i)
+		results = append(results, string(msg["result"]))
+		c.send(initialized)
+	}
+	assert.Equal(t, results[0], results[1])
+	assert.Equal(t, results[0], results[2])
+	assert.JSONEq(t, results[0], string(proxy.InitializeResult()))
+
+	// A client connecting later is answered from the cache as well
+	late := dialProxy(t, proxy)
+	late.send(fmt.Sprintf(initReq, 42, "late"))
+	msg := late.recv(5 * time.Second)
+	require.NotNil(t, msg)
+	assert.Equal(t, `42`, string(msg["id"]))
+	assert.JSONEq(t, results[0], string(msg["result"]))
+	late.send(initialized)
+
+	// The backend saw exactly one initialize and one initialized
+	late.send(`{"jsonrpc":"2.0","id":43,"method":"stats"}`)
+	msg = late.recv(5 * time.Second)
+	require.NotNil(t, msg)
+	assert.Equal(t, `1`, resultField(t, msg, "initialize"))
+	assert.Equal(t, `1`, resultField(t, msg, "initialized"))
+}
@@ -0,0 +1,97 @@
+package mcppool
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// socketDir holds pool sockets and state files. Shared by all agent-deck
+// instances on the machine so they can discover each other's proxies.
+const socketDir = "/tmp"
+
+func socketPathFor(name string) string {
+	return filepath.Join(socketDir, fmt.Sprintf("agentdeck-mcp-%s.sock", name))
+}
+
+// nameFromSocketPath extracts the MCP name from /tmp/agentdeck-mcp-{name}.sock
+func nameFromSocketPath(socketPath string) (string, bool) {
+	base := filepath.Base(socketPath)
+	if !strings.HasPrefix(base, "agentdeck-mcp-") || !strings.HasSuffix(base, ".sock") {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(base, "agentdeck-mcp-"), ".sock"), true
+}
+
+func stateFilePath(name string) string {
+	return filepath.Join(socketDir, fmt.Sprintf("agentdeck-mcp-%s.state.json", name))
+}
+
+// ProxyState is the status of a pooled MCP as published by the agent-deck
+// instance that owns its process. Other processes (e.g. `agent-deck mcp list`)
+// read it since they have no access to the owner's in-memory Pool.
+type ProxyState struct {
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	PID        int       `json:"pid,omitempty"`
+	OwnerPID   int       `json:"owner_pid"`
+	SocketPath string    `json:"socket_path"`
+	HTTPURL    string    `json:"http_url,omitempty"`
+	Restarts   int       `json:"restarts"`
+	LastError  string    `json:"last_error,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// writeState publishes the proxy's current state (atomic rename, best effort)
+func (p *SocketProxy) writeState() {
+	p.statusMu.RLock()
+	state := ProxyState{
+		Name:       p.name,
+		Status:     p.status.String(),
+		OwnerPID:   os.Getpid(),
+		SocketPath: p.socketPath,
+		Restarts:   p.restarts,
+		LastError:  p.lastError,
+		UpdatedAt:  time.Now(),
+	}
+	p.statusMu.RUnlock()
+	state.PID = p.PID()
+	state.HTTPURL = p.GetHTTPURL()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return
+	}
+	path := stateFilePath(p.name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, path)
+}
+
+// ReadProxyState returns the published state of a pooled MCP, validated against
+// the socket: a state claiming "running" for a dead socket is reported as stopped.
+// Returns nil if the MCP isn't pooled by any agent-deck instance.
+func ReadProxyState(name string) *ProxyState {
+	socketPath := socketPathFor(name)
+	alive := isSocketAliveCheck(socketPath)
+
+	var state ProxyState
+	data, err := os.ReadFile(stateFilePath(name))
+	if err != nil || json.Unmarshal(data, &state) != nil {
+		if !alive {
+			return nil
+		}
+		// Socket from an instance that predates state files
+		return &ProxyState{Name: name, Status: StatusRunning.String(), SocketPath: socketPath}
+	}
+
+	if state.Status == StatusRunning.String() && !alive {
+		state.Status = StatusStopped.String()
+	}
+	return &state
+}
@@ -0,0 +1,248 @@
+package mcppool
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// Restart policy for crashed MCP processes. Variables so tests can shorten them.
+var (
+	// restartBaseDelay is the delay before the first restart attempt; it doubles
+	// with every consecutive crash up to restartMaxDelay
+	restartBaseDelay = 1 * time.Second
+	restartMaxDelay  = 30 * time.Second
+
+	// restartMaxAttempts consecutive crashes mark the proxy as failed
+	restartMaxAttempts = 8
+
+	// restartStableAfter is how long a process must run before its crash counter resets
+	restartStableAfter = 60 * time.Second
+)
+
+// errServerRestarting is returned to clients whose requests can't be served
+// because the MCP process crashed or is being restarted
+var errServerRestarting = json.RawMessage(`{"code":-32603,"message":"MCP server restarting"}`)
+
+// replayRequestID is the original ID used for the proxy's own initialize replay
+var replayRequestID = json.RawMessage(`"agentdeck-replay"`)
+
+// supervise reaps an MCP process and restarts it with exponential backoff if it
+// exits while the proxy is still in use. Client connections stay open across the
+// restart; the cached initialize handshake is replayed against the new process.
+func (p *SocketProxy) supervise(cmd *exec.Cmd, done chan struct{}) {
+	err := cmd.Wait()
+	close(done)
+
+	p.procMu.Lock()
+	stopping := p.stopping
+	requested := p.restartRequested
+	p.restartRequested = false
+	ranFor := time.Since(p.startedAt)
+	p.mcpStdin = nil
+	p.procMu.Unlock()
+
+	if stopping || p.ctx.Err() != nil {
+		return
+	}
+
+	if requested {
+		log.Printf("[Pool] %s: restarting MCP process on request", p.name)
+		p.setStatus(StatusRestarting, "")
+		p.failPendingRequests()
+		p.restartWithBackoff(true)
+		return
+	}
+
+	reason := "exited"
+	if err != nil {
+		reason = err.Error()
+	}
+	log.Printf("[Pool] %s: MCP process died after %s (%s)", p.name, ranFor.Round(time.Millisecond), reason)
+
+	p.statusMu.Lock()
+	if ranFor >= restartStableAfter {
+		p.restarts = 0
+	}
+	p.statusMu.Unlock()
+
+	p.setStatus(StatusRestarting, fmt.Sprintf("process %s", reason))
+	p.failPendingRequests()
+	p.restartWithBackoff(false)
+}
+
+// restartWithBackoff keeps trying to start a new MCP process until one starts,
+// the proxy is stopped, or restartMaxAttempts is exceeded. If immediate is set
+// the first attempt happens without delay.
+func (p *SocketProxy) restartWithBackoff(immediate bool) {
+	for {
+		p.statusMu.Lock()
+		p.restarts++
+		attempt := p.restarts
+		p.statusMu.Unlock()
+
+		if attempt > restartMaxAttempts {
+			log.Printf("[Pool] %s: giving up after %d restart attempts", p.name, restartMaxAttempts)
+			p.setStatus(StatusFailed, fmt.Sprintf("crashed %d times in a row", restartMaxAttempts))
+			return
+		}
+
+		delay := restartDelay(attempt)
+		if immediate {
+			delay, immediate = 0, false
+		}
+		log.Printf("[Pool] %s: restarting in %s (attempt %d/%d)", p.name, delay, attempt, restartMaxAttempts)
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		p.procMu.Lock()
+		stopping := p.stopping
+		p.procMu.Unlock()
+		if stopping {
+			return
+		}
+
+		p.setStatus(StatusStarting, "")
+		if err := p.startProcess(); err != nil {
+			log.Printf("[Pool] %s: restart failed: %v", p.name, err)
+			p.setStatus(StatusRestarting, err.Error())
+			continue
+		}
+
+		p.replayInitialize()
+		p.setStatus(StatusRunning, "")
+		return
+	}
+}
+
+// RestartProcess restarts the MCP process in place. Clients stay connected and
+// the handshake is replayed, as after a crash. A proxy that gave up after
+// repeated crashes is started again.
+func (p *SocketProxy) RestartProcess() error {
+	if p.external {
+		return fmt.Errorf("%s is owned by another agent-deck instance", p.name)
+	}
+
+	p.statusMu.Lock()
+	p.restarts = 0
+	p.statusMu.Unlock()
+
+	p.procMu.Lock()
+	if p.stopping {
+		p.procMu.Unlock()
+		return fmt.Errorf("%s is stopped", p.name)
+	}
+	cmd, done := p.mcpProcess, p.processDone
+	alive := false
+	if done != nil {
+		select {
+		case <-done:
+		default:
+			alive = true
+		}
+	}
+	if alive {
+		p.restartRequested = true
+	}
+	p.procMu.Unlock()
+
+	if alive {
+		// The supervisor notices the exit and starts a new process right away
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+
+	if p.GetStatus() == StatusFailed {
+		p.setStatus(StatusRestarting, "")
+		go p.restartWithBackoff(true)
+	}
+	return nil
+}
+
+// restartDelay returns the backoff delay for the given (1-based) attempt
+func restartDelay(attempt int) time.Duration {
+	delay := restartBaseDelay
+	for i := 1; i < attempt && delay < restartMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > restartMaxDelay {
+		delay = restartMaxDelay
+	}
+	return delay
+}
+
+// failPendingRequests answers every in-flight request with an error, since the
+// process that would have answered them is gone.
+func (p *SocketProxy) failPendingRequests() {
+	p.requestMu.Lock()
+	pending := p.requestMap
+	p.requestMap = make(map[int64]*pendingRequest)
+	p.clientIDMap = make(map[clientRequestKey]int64)
+	p.serverRequests = nil // Asked by the old process; answers would confuse the new one
+	p.requestMu.Unlock()
+
+	for _, req := range pending {
+		if req.method == methodInitialize {
+			p.completeInitialize([]byte(`{"error":` + string(errServerRestarting) + `}`))
+		}
+		if req.sessionID != "" {
+			p.replyToClient(req.sessionID, req.originalID, "error", errServerRestarting)
+		}
+	}
+}
+
+// replayInitialize re-runs the cached initialize handshake against a freshly
+// started process so that already-connected clients keep working without
+// initializing again. Nothing is replayed if no client has initialized yet.
+func (p *SocketProxy) replayInitialize() {
+	h := &p.handshake
+	h.mu.Lock()
+	params := h.params
+	if params == nil || h.result == nil {
+		h.mu.Unlock()
+		return
+	}
+	h.inFlight = true
+	h.initializedSent = false
+	h.mu.Unlock()
+
+	proxyID := p.registerRequest("", replayRequestID, methodInitialize)
+	req, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      proxyID,
+		"method":  methodInitialize,
+		"params":  params,
+	})
+	if err != nil {
+		p.forgetRequest(proxyID)
+		return
+	}
+	log.Printf("[Pool] %s: replaying initialize handshake", p.name)
+	p.writeToMCP(req)
+}
+
+// completeReplay finishes a replayed handshake by sending the initialized notification
+func (p *SocketProxy) completeReplay() {
+	if p.shouldForwardInitialized() {
+		p.writeToMCP([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	}
+}
+
+// Restarts returns the number of consecutive restart attempts
+func (p *SocketProxy) Restarts() int {
+	p.statusMu.RLock()
+	defer p.statusMu.RUnlock()
+	return p.restarts
+}
+
+// LastError returns the most recent failure reason, if any
+func (p *SocketProxy) LastError() string {
+	p.statusMu.RLock()
+	defer p.statusMu.RUnlock()
+	return p.lastError
+}
@@ -0,0 +1,100 @@
+package mcppool
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastRestarts shortens the restart policy for the duration of a test
+func fastRestarts(t *testing.T, maxAttempts int) {
+	t.Helper()
+	base, maxDelay, attempts := restartBaseDelay, restartMaxDelay, restartMaxAttempts
+	restartBaseDelay, restartMaxDelay, restartMaxAttempts = 10*time.Millisecond, 50*time.Millisecond, maxAttempts
+	t.Cleanup(func() {
+		restartBaseDelay, restartMaxDelay, restartMaxAttempts = base, maxDelay, attempts
+	})
+}
+
+func TestRestartDelay(t *testing.T) {
+	assert.Equal(t, restartBaseDelay, restartDelay(1))
+	assert.Equal(t, 2*restartBaseDelay, restartDelay(2))
+	assert.Equal(t, 4*restartBaseDelay, restartDelay(3))
+	assert.Equal(t, restartMaxDelay, restartDelay(100))
+}
+
+func TestSupervisor_RestartsCrashedProcessKeepingClients(t *testing.T) {
+	fastRestarts(t, 5)
+	proxy := startStubProxy(t)
+	client := dialProxy(t, proxy)
+
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`)
+	require.NotNil(t, client.recv(5*time.Second))
+	client.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	pidBefore := proxy.PID()
+
+	// An in-flight request fails with an error instead of hanging forever
+	client.send(`{"jsonrpc":"2.0","id":2,"method":"slow"}`)
+	require.Eventually(t, func() bool { return proxy.PendingRequests() == 1 }, 5*time.Second, 10*time.Millisecond)
+	client.send(`{"jsonrpc":"2.0","id":3,"method":"crash"}`)
+
+	// Both the held request and the crashing one are failed (in any order)
+	failed := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		msg := client.recv(5 * time.Second)
+		require.NotNil(t, msg)
+		assert.Contains(t, string(msg["error"]), "restarting")
+		failed[string(msg["id"])] = true
+	}
+	assert.Equal(t, map[string]bool{`2`: true, `3`: true}, failed)
+
+	require.Eventually(t, func() bool {
+		return proxy.GetStatus() == StatusRunning && proxy.PID() != pidBefore
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, proxy.Restarts())
+	assert.NotEmpty(t, proxy.LastError())
+
+	// Same connection keeps working; the new process was initialized by the replay
+	var stats map[string]string
+	require.Eventually(t, func() bool {
+		client.send(`{"jsonrpc":"2.0","id":4,"method":"stats"}`)
+		msg := client.recv(time.Second)
+		if msg == nil || msg["error"] != nil {
+			return false
+		}
+		stats = map[string]string{
+			"initialize":  resultField(t, msg, "initialize"),
+			"initialized": resultField(t, msg, "initialized"),
+		}
+		return stats["initialized"] == "1"
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, "1", stats["initialize"])
+
+	state := ReadProxyState(proxy.name)
+	require.NotNil(t, state)
+	assert.Equal(t, "running", state.Status)
+	assert.Equal(t, proxy.PID(), state.PID)
+	assert.Equal(t, 1, state.Restarts)
+}
+
+func TestSupervisor_GivesUpAfterMaxAttempts(t *testing.T) {
+	fastRestarts(t, 3)
+	proxy := startStubProxyMode(t, "exit")
+
+	require.Eventually(t, func() bool { return proxy.GetStatus() == StatusFailed }, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, proxy.LastError(), fmt.Sprintf("%d times", 3))
+
+	// Clients get an immediate error instead of a hang
+	client := dialProxy(t, proxy)
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	msg := client.recv(5 * time.Second)
+	require.NotNil(t, msg)
+	assert.NotNil(t, msg["error"])
+
+	state := ReadProxyState(proxy.name)
+	require.NotNil(t, state)
+	assert.Equal(t, "failed", state.Status)
+}
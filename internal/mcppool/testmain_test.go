@@ -0,0 +1,127 @@
+package mcppool
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+)
+
+// stubMCPEnv switches the test binary into a minimal stdio MCP server, so proxy
+// tests can spawn a real child process without external dependencies.
+const stubMCPEnv = "AGENTDECK_TEST_STUB_MCP"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(stubMCPEnv) {
+	case "1":
+		runStubMCPServer()
+		os.Exit(0)
+	case "exit":
+		// A server that dies on startup, for exercising restart backoff
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// runStubMCPServer answers JSON-RPC requests on stdin/stdout:
+//   - initialize succeeds once; like strict servers, a second initialize fails
+//   - "stats" reports how many initialize/initialized messages were received and
+//     how many sampling requests are unanswered
+//   - "slow" requests are held until cancelled
+//   - "crash" kills the server
+//   - "notify" emits a server notification before answering
+//   - "sample" asks the client for a sampling/createMessage and answers with
+//     the client's reply
+//   - notifications/cancelled answers the held request with a -32800 error
+//   - every other request echoes its method, params and the ID the server saw
+func runStubMCPServer() {
+	out := bufio.NewWriter(os.Stdout)
+	reply := func(msg map[string]interface{}) {
+		msg["jsonrpc"] = "2.0"
+		data, _ := json.Marshal(msg)
+		fmt.Fprintf(out, "%s\n", data)
+		out.Flush()
+	}
+
+	initializeCount, initializedCount := 0, 0
+	sampling := map[string]json.RawMessage{} // Server request ID -> the "sample" request's ID
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		var msg struct {
+			ID     json.RawMessage            `json:"id"`
+			Method string                     `json:"method"`
+			Params map[string]json.RawMessage `json:"params"`
+			Result json.RawMessage            `json:"result"`
+			Error  json.RawMessage            `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+
+		switch {
+		case msg.Method == methodInitialize:
+			initializeCount++
+			if initializeCount > 1 {
+				reply(map[string]interface{}{
+					"id":    msg.ID,
+					"error": map[string]interface{}{"code": -32600, "message": "already initialized"},
+				})
+				continue
+			}
+			reply(map[string]interface{}{
+				"id": msg.ID,
+				"result": map[string]interface{}{
+					"protocolVersion": "2024-11-05",
+					"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+					"serverInfo":      map[string]interface{}{"name": "stub", "version": "1.0"},
+				},
+			})
+		case msg.Method == methodInitialized:
+			initializedCount++
+		case msg.Method == "stats":
+			reply(map[string]interface{}{
+				"id":     msg.ID,
+				"result": map[string]interface{}{"initialize": initializeCount, "initialized": initializedCount, "sampling": len(sampling)},
+			})
+		case msg.Method == methodCancelled:
+			reply(map[string]interface{}{
+				"id":    msg.Params["requestId"],
+				"error": map[string]interface{}{"code": -32800, "message": "cancelled"},
+			})
+		case len(msg.ID) == 0:
+			// Other notifications need no answer
+		case msg.Method == "":
+			// A client's answer to a sampling request
+			if requestID, ok := sampling[string(msg.ID)]; ok {
+				delete(sampling, string(msg.ID))
+				reply(map[string]interface{}{
+					"id":     requestID,
+					"result": map[string]interface{}{"sampled": msg.Result, "error": msg.Error},
+				})
+			}
+		case msg.Method == "sample":
+			serverID := fmt.Sprintf(`"sample-%d"`, len(sampling)+1)
+			sampling[serverID] = msg.ID
+			reply(map[string]interface{}{"id": json.RawMessage(serverID), "method": "sampling/createMessage"})
+		case msg.Method == "notify":
+			reply(map[string]interface{}{"method": "notifications/message", "params": map[string]interface{}{"data": "hello"}})
+			reply(map[string]interface{}{"id": msg.ID, "result": map[string]interface{}{}})
+		case msg.Method == "crash":
+			os.Exit(1)
+		case msg.Method == "slow":
+			// Held until cancelled
+		default:
+			reply(map[string]interface{}{
+				"id": msg.ID,
+				"result": map[string]interface{}{
+					"method":   msg.Method,
+					"params":   msg.Params,
+					"serverId": msg.ID,
+				},
+			})
+		}
+	}
+}
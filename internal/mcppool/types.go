@@ -8,6 +8,7 @@ const (
 	StatusStarting  
 	StatusRunning
 	StatusFailed
+	StatusRestarting
 )
 
 func (s ServerStatus) String() string {
@@ -20,6 +21,8 @@ func (s ServerStatus) String() string {
 		return "running"
 	case StatusFailed:
 		return "failed"
+	case StatusRestarting:
+		return "restarting"
 	default:
 		return "unknown"
 	}
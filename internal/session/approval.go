@@ -0,0 +1,66 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// ApprovalKeys returns the tmux keys that answer a tool's permission prompt.
+// [tools.NAME.status] approve_keys/deny_keys take precedence over the tool
+// adapter's keys.
+func ApprovalKeys(toolName string, approve bool) []string {
+	if def := GetToolDef(toolName); def != nil {
+		if approve && len(def.Status.ApproveKeys) > 0 {
+			return def.Status.ApproveKeys
+		}
+		if !approve && len(def.Status.DenyKeys) > 0 {
+			return def.Status.DenyKeys
+		}
+	}
+
+	return GetToolAdapter(toolName).ApprovalKeys(approve)
+}
+
+// PendingApproval reports whether the session's pane currently shows a
+// permission prompt
+func (i *Instance) PendingApproval() (bool, error) {
+	if i.tmuxSession == nil || !i.tmuxSession.Exists() {
+		return false, fmt.Errorf("session is not running")
+	}
+	i.tmuxSession.SetStatusDetector(GetToolStatusDetector(i.Tool))
+	state, err := i.tmuxSession.DetectState()
+	if err != nil {
+		return false, err
+	}
+	return state == tmux.StatePermission, nil
+}
+
+// Approve answers the session's pending permission prompt with yes
+func (i *Instance) Approve() error {
+	return i.answerApproval(true)
+}
+
+// Deny answers the session's pending permission prompt with no
+func (i *Instance) Deny() error {
+	return i.answerApproval(false)
+}
+
+func (i *Instance) answerApproval(approve bool) error {
+	pending, err := i.PendingApproval()
+	if err != nil {
+		return err
+	}
+	if !pending {
+		return fmt.Errorf("session is not waiting for approval")
+	}
+	if err := i.tmuxSession.SendKeyNames(ApprovalKeys(i.Tool, approve)...); err != nil {
+		return fmt.Errorf("failed to send keys: %w", err)
+	}
+	// The tool resumes (or stops) on its own; let the next status check tell
+	i.Status = StatusRunning
+	if !approve {
+		i.Status = StatusWaiting
+	}
+	return nil
+}
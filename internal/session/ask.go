@@ -0,0 +1,229 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Errors a turn ends with when the agent doesn't reply
+var (
+	ErrTurnTimeout  = errors.New("timed out waiting for the agent's reply")
+	ErrAgentStopped = errors.New("session stopped before the agent replied")
+	ErrAgentBlocked = errors.New("agent is waiting for permission (see session approve/deny)")
+	ErrAgentFailed  = errors.New("agent reported an error")
+)
+
+// turnPollInterval is how often a turn checks the session's status
+var turnPollInterval = 500 * time.Millisecond
+
+// turnSettleTime is how long a turn waits, once the agent is done, for its
+// reply to show up in the transcript (tools write it after redrawing)
+var turnSettleTime = 5 * time.Second
+
+// turnPromptPattern matches a line that is only a shell or agent prompt
+var turnPromptPattern = regexp.MustCompile(`^[\s]*(>|>>>|\$|❯|➜|#|%)\s*$`)
+
+// Turn is one prompt sent to a session, tracked until the agent has replied.
+// The reply is read from the tool's transcript when the session has one,
+// otherwise from what the terminal shows after the prompt.
+type Turn struct {
+	Prompt string
+	Sent   time.Time
+
+	pane       turnPane
+	transcript func() (*Transcript, error)
+	messages   int    // Transcript messages before the prompt (-1: read the terminal)
+	history    string // Terminal history before the prompt
+}
+
+// turnPane is what a turn reads of the session's tmux pane
+type turnPane interface {
+	GetStatus() (string, error)
+	CaptureFullHistory() (string, error)
+}
+
+// BeginTurn waits until the session's agent is done with any earlier turn
+// (or ctx ends), then sends it the prompt
+func (i *Instance) BeginTurn(ctx context.Context, prompt string) (*Turn, error) {
+	if i.tmuxSession == nil || !i.tmuxSession.Exists() {
+		return nil, fmt.Errorf("session is not running")
+	}
+	i.tmuxSession.SetStatusDetector(GetToolStatusDetector(i.Tool))
+
+	t := &Turn{Prompt: prompt, pane: i.tmuxSession, transcript: i.GetTranscript, messages: -1}
+	if err := t.begin(ctx, i.SendMessage); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// begin waits for the agent to be done with any earlier turn, notes where
+// the reply will start and sends the prompt
+func (t *Turn) begin(ctx context.Context, send func(string) error) error {
+	// Two quiet checks in a row: the first status after loading is "waiting"
+	// even while the agent is still busy
+	quiet := 0
+	for quiet < 2 {
+		status, err := t.pane.GetStatus()
+		switch {
+		case err != nil || status == "active":
+			quiet = 0
+		case status == "inactive":
+			return ErrAgentStopped
+		case status == "approval":
+			return ErrAgentBlocked
+		default:
+			quiet++
+		}
+		if quiet < 2 {
+			select {
+			case <-ctx.Done():
+				return ErrTurnTimeout
+			case <-time.After(turnPollInterval):
+			}
+		}
+	}
+
+	if transcript, err := t.transcript(); err == nil {
+		t.messages = len(transcript.Messages)
+	} else if t.history, err = t.pane.CaptureFullHistory(); err != nil {
+		return fmt.Errorf("failed to capture terminal output: %w", err)
+	}
+
+	if err := send(t.Prompt); err != nil {
+		return err
+	}
+	t.Sent = time.Now()
+	return nil
+}
+
+// Wait waits until the agent has worked on the prompt and is waiting for
+// input again, and returns its reply. progress, if set, is called with the
+// reply so far whenever it changes.
+func (t *Turn) Wait(ctx context.Context, progress func(reply string)) (string, error) {
+	sawBusy := false
+	var doneAt time.Time // When the agent was first seen done
+	last := ""
+
+	for {
+		select {
+		case <-ctx.Done():
+			return last, ErrTurnTimeout
+		case <-time.After(turnPollInterval):
+		}
+
+		status, err := t.pane.GetStatus()
+		if err != nil {
+			continue
+		}
+
+		reply, _ := t.Reply()
+		if reply != last {
+			last = reply
+			if progress != nil {
+				progress(reply)
+			}
+		}
+
+		switch status {
+		case "active":
+			sawBusy = true
+			doneAt = time.Time{}
+			continue
+		case "inactive":
+			return last, ErrAgentStopped
+		case "approval":
+			return last, ErrAgentBlocked
+		case "error":
+			return last, ErrAgentFailed
+		}
+
+		// Waiting or idle: done once the agent was busy with the prompt, or
+		// (a reply too quick to see it busy) once the reply is there. Terminal
+		// output is only taken once it had time to settle.
+		if !sawBusy && (last == "" || (t.messages < 0 && time.Since(t.Sent) < turnSettleTime)) {
+			continue
+		}
+		if doneAt.IsZero() {
+			doneAt = time.Now()
+		}
+		if last != "" || time.Since(doneAt) >= turnSettleTime {
+			return last, nil
+		}
+	}
+}
+
+// Reply returns what the agent has replied to the prompt so far
+func (t *Turn) Reply() (string, error) {
+	if t.messages >= 0 {
+		transcript, err := t.transcript()
+		if err != nil {
+			return "", err
+		}
+		return transcriptReply(transcript, t.messages), nil
+	}
+
+	history, err := t.pane.CaptureFullHistory()
+	if err != nil {
+		return "", fmt.Errorf("failed to capture terminal output: %w", err)
+	}
+	return terminalReply(t.history, history, t.Prompt), nil
+}
+
+// transcriptReply joins the text of the assistant messages after the first
+// before messages of a transcript
+func transcriptReply(t *Transcript, before int) string {
+	if before > len(t.Messages) {
+		// The transcript was replaced (e.g. /clear): all of it is new
+		before = 0
+	}
+	var parts []string
+	for _, msg := range t.Messages[before:] {
+		if msg.Role == "assistant" && strings.TrimSpace(msg.Text) != "" {
+			parts = append(parts, strings.TrimSpace(msg.Text))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// terminalReply returns the terminal output after the prompt was echoed: the
+// lines after the last one showing the prompt's (last) line, or else the
+// lines after those shared with the history from before the prompt
+func terminalReply(before, after, prompt string) string {
+	lines := strings.Split(after, "\n")
+
+	start := -1
+	promptLines := strings.Split(strings.TrimSpace(prompt), "\n")
+	echo := strings.TrimSpace(promptLines[len(promptLines)-1])
+	if echo != "" {
+		for idx := len(lines) - 1; idx >= 0; idx-- {
+			if strings.Contains(lines[idx], echo) {
+				start = idx + 1
+				break
+			}
+		}
+	}
+	if start < 0 {
+		beforeLines := strings.Split(before, "\n")
+		start = 0
+		for start < len(lines) && start < len(beforeLines) &&
+			strings.TrimRight(lines[start], " ") == strings.TrimRight(beforeLines[start], " ") {
+			start++
+		}
+	}
+
+	reply := lines[start:]
+	// Drop the prompt the agent shows again when done
+	for len(reply) > 0 {
+		last := strings.TrimSpace(reply[len(reply)-1])
+		if last != "" && !turnPromptPattern.MatchString(last) {
+			break
+		}
+		reply = reply[:len(reply)-1]
+	}
+	return strings.TrimSpace(strings.Join(reply, "\n"))
+}
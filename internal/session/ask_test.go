@@ -0,0 +1,164 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTranscriptReply(t *testing.T) {
+	tr := parseClaudeTranscript([]byte(claudeTranscriptFixture))
+
+	// Everything after the first user message is the reply, tool-call text included
+	if got := transcriptReply(tr, 1); got != "Let me look.\n\nThere is one file." {
+		t.Errorf("transcriptReply(1) = %q", got)
+	}
+	// Nothing new yet
+	if got := transcriptReply(tr, len(tr.Messages)); got != "" {
+		t.Errorf("transcriptReply(all) = %q, want empty", got)
+	}
+	// A transcript shorter than before was replaced: all of it is new
+	if got := transcriptReply(tr, 10); got != "Let me look.\n\nThere is one file." {
+		t.Errorf("transcriptReply(10) = %q", got)
+	}
+}
+
+func TestTerminalReply(t *testing.T) {
+	before := "$ agent\nWelcome\n> "
+	after := "$ agent\nWelcome\n> what is 2+2\n\n4\n\n> \n"
+
+	if got := terminalReply(before, after, "what is 2+2"); got != "4" {
+		t.Errorf("terminalReply with echoed prompt = %q, want %q", got, "4")
+	}
+
+	// Prompt not echoed (e.g. redrawn elsewhere): lines after the shared history
+	after = "$ agent\nWelcome\nThe answer is 4\n❯"
+	if got := terminalReply(before, after, "what is 2+2"); got != "The answer is 4" {
+		t.Errorf("terminalReply without echo = %q, want %q", got, "The answer is 4")
+	}
+
+	// The reply follows the last line of a multi-line prompt
+	after = "> review this\nand this\nLooks good\n>"
+	if got := terminalReply("", after, "review this\nand this"); got != "Looks good" {
+		t.Errorf("terminalReply multi-line = %q", got)
+	}
+}
+
+// fakeTurnPane plays back statuses (the last one repeats) and shows the
+// reply from the replyAt-th status check on (never if replyAt is 0)
+type fakeTurnPane struct {
+	mu       sync.Mutex
+	statuses []string
+	replyAt  int
+	polls    int
+}
+
+func (p *fakeTurnPane) GetStatus() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status := p.statuses[min(p.polls, len(p.statuses)-1)]
+	p.polls++
+	return status, nil
+}
+
+func (p *fakeTurnPane) replied() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.replyAt > 0 && p.polls >= p.replyAt
+}
+
+func (p *fakeTurnPane) CaptureFullHistory() (string, error) {
+	if p.replied() {
+		return "> what now?\nhi\n>", nil
+	}
+	return "> what now?", nil
+}
+
+func (p *fakeTurnPane) transcript() (*Transcript, error) {
+	tr := &Transcript{Messages: []TranscriptMessage{{Role: "user", Text: "what now?"}}}
+	if p.replied() {
+		tr.Messages = append(tr.Messages, TranscriptMessage{Role: "assistant", Text: "hi"})
+	}
+	return tr, nil
+}
+
+func fastTurns(t *testing.T) {
+	t.Helper()
+	poll, settle := turnPollInterval, turnSettleTime
+	turnPollInterval, turnSettleTime = time.Millisecond, 50*time.Millisecond
+	t.Cleanup(func() { turnPollInterval, turnSettleTime = poll, settle })
+}
+
+func TestTurnWait(t *testing.T) {
+	fastTurns(t)
+	tests := []struct {
+		name       string
+		statuses   []string
+		replyAt    int
+		terminal   bool // Read the reply from the terminal, not a transcript
+		wantReply  string
+		wantErr    error
+		wantSettle bool // Returns only after turnSettleTime
+	}{
+		{name: "busy then done", statuses: []string{"active", "active", "waiting"}, replyAt: 2, wantReply: "hi"},
+		{name: "reply too quick to see busy", statuses: []string{"waiting"}, replyAt: 1, wantReply: "hi"},
+		{name: "never busy and no reply", statuses: []string{"waiting"}, wantErr: ErrTurnTimeout},
+		{name: "terminal reply settles", statuses: []string{"idle"}, replyAt: 1, terminal: true, wantReply: "hi", wantSettle: true},
+		{name: "busy without reply settles", statuses: []string{"active", "waiting"}, wantSettle: true},
+		{name: "still busy", statuses: []string{"active"}, replyAt: 1, wantReply: "hi", wantErr: ErrTurnTimeout},
+		{name: "stopped", statuses: []string{"active", "inactive"}, wantErr: ErrAgentStopped},
+		{name: "needs permission", statuses: []string{"active", "approval"}, replyAt: 1, wantReply: "hi", wantErr: ErrAgentBlocked},
+		{name: "agent error", statuses: []string{"error"}, wantErr: ErrAgentFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pane := &fakeTurnPane{statuses: tt.statuses, replyAt: tt.replyAt}
+			turn := &Turn{Prompt: "what now?", Sent: time.Now(), pane: pane, transcript: pane.transcript, messages: 1}
+			if tt.terminal {
+				turn.messages, turn.history = -1, "> what now?"
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			reply, err := turn.Wait(ctx, nil)
+			if reply != tt.wantReply || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Wait() = %q, %v; want %q, %v", reply, err, tt.wantReply, tt.wantErr)
+			}
+			if elapsed := time.Since(turn.Sent); tt.wantSettle && elapsed < turnSettleTime {
+				t.Errorf("Wait() returned after %v, before the reply settled", elapsed)
+			}
+		})
+	}
+}
+
+func TestTurnBegin(t *testing.T) {
+	fastTurns(t)
+
+	// The prompt is only sent after two quiet checks in a row
+	pane := &fakeTurnPane{statuses: []string{"waiting", "active", "waiting", "waiting"}}
+	turn := &Turn{Prompt: "what now?", pane: pane, transcript: pane.transcript, messages: -1}
+	sent := ""
+	err := turn.begin(context.Background(), func(prompt string) error {
+		if pane.polls != 4 {
+			t.Errorf("prompt sent after %d status checks, want 4", pane.polls)
+		}
+		sent = prompt
+		return nil
+	})
+	if err != nil || sent != "what now?" || turn.messages != 1 || turn.Sent.IsZero() {
+		t.Errorf("begin() = %v, sent %q, messages %d", err, sent, turn.messages)
+	}
+
+	// A blocked agent isn't sent anything
+	pane = &fakeTurnPane{statuses: []string{"approval"}}
+	turn = &Turn{Prompt: "what now?", pane: pane, transcript: pane.transcript, messages: -1}
+	err = turn.begin(context.Background(), func(string) error {
+		t.Error("prompt sent to a blocked agent")
+		return nil
+	})
+	if !errors.Is(err, ErrAgentBlocked) {
+		t.Errorf("begin() = %v, want ErrAgentBlocked", err)
+	}
+}
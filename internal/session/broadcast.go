@@ -0,0 +1,182 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Broadcast report formats
+const (
+	ReportText     = "text"
+	ReportMarkdown = "md"
+	ReportJSON     = "json"
+)
+
+// BroadcastResult is one session's answer to a broadcast prompt
+type BroadcastResult struct {
+	SessionID  string        `json:"session_id"`
+	Title      string        `json:"title"`
+	Tool       string        `json:"tool"`
+	Reply      string        `json:"reply"`
+	Status     Status        `json:"status"` // Status the session was left in
+	Duration   time.Duration `json:"-"`
+	DurationMS int64         `json:"duration_ms"`
+	Error      string        `json:"error,omitempty"`
+	Err        error         `json:"-"`
+}
+
+// BroadcastReport is the answers of many sessions to the same prompt
+type BroadcastReport struct {
+	Prompt  string            `json:"prompt"`
+	Started time.Time         `json:"started"`
+	Results []BroadcastResult `json:"results"`
+}
+
+// Broadcast sends a prompt to sessions at once and waits (until ctx ends) for
+// each to reply. done, if set, is called as each session finishes, from its
+// own goroutine. Results are in the order of insts.
+func Broadcast(ctx context.Context, insts []*Instance, prompt string, done func(BroadcastResult)) *BroadcastReport {
+	report := &BroadcastReport{
+		Prompt:  prompt,
+		Started: time.Now(),
+		Results: make([]BroadcastResult, len(insts)),
+	}
+	var wg sync.WaitGroup
+	for idx, inst := range insts {
+		wg.Add(1)
+		go func(idx int, inst *Instance) {
+			defer wg.Done()
+			result := askForBroadcast(ctx, inst, prompt)
+			report.Results[idx] = result
+			if done != nil {
+				done(result)
+			}
+		}(idx, inst)
+	}
+	wg.Wait()
+	return report
+}
+
+// askForBroadcast runs one session's turn of a broadcast
+func askForBroadcast(ctx context.Context, inst *Instance, prompt string) BroadcastResult {
+	result := BroadcastResult{SessionID: inst.ID, Title: inst.Title, Tool: inst.Tool}
+	start := time.Now()
+
+	turn, err := inst.BeginTurn(ctx, prompt)
+	if err == nil {
+		start = turn.Sent
+		result.Reply, err = turn.Wait(ctx, nil)
+		if err == nil && result.Reply == "" {
+			// Nothing new was found: the last response is the best answer
+			if response, rerr := inst.GetLastResponse(); rerr == nil {
+				result.Reply = response.Content
+			}
+		}
+	}
+
+	result.Duration = time.Since(start)
+	result.DurationMS = result.Duration.Milliseconds()
+	result.Err = err
+	if err != nil {
+		result.Error = err.Error()
+	}
+	// What the session shows now, not what the error suggests (an agent
+	// blocked on permission may have been answered meanwhile)
+	_ = inst.UpdateStatus()
+	result.Status = inst.Status
+	return result
+}
+
+// Failed returns how many sessions didn't reply
+func (r *BroadcastReport) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// ValidateReportFormat returns an error unless Render supports format
+func ValidateReportFormat(format string) error {
+	switch format {
+	case ReportText, "", ReportMarkdown, "markdown", ReportJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown report format %q (text, md, json)", format)
+}
+
+// Render formats the report as text, Markdown or JSON
+func (r *BroadcastReport) Render(format string) (string, error) {
+	if err := ValidateReportFormat(format); err != nil {
+		return "", err
+	}
+	switch format {
+	case ReportMarkdown, "markdown":
+		return r.markdown(), nil
+	case ReportJSON:
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	default:
+		return r.text(), nil
+	}
+}
+
+// text renders the report for reading in a terminal
+func (r *BroadcastReport) text() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Prompt: %s\n", r.Prompt)
+	fmt.Fprintf(&sb, "%d sessions, %d replied\n", len(r.Results), len(r.Results)-r.Failed())
+	for _, result := range r.Results {
+		fmt.Fprintf(&sb, "\n━━ %s · %s\n", result.Title, result.details())
+		if result.Reply != "" {
+			sb.WriteString(result.Reply + "\n")
+		}
+		if result.Error != "" {
+			fmt.Fprintf(&sb, "Error: %s\n", result.Error)
+		}
+	}
+	return sb.String()
+}
+
+// markdown renders the report for sharing: an overview table, then each reply
+func (r *BroadcastReport) markdown() string {
+	var sb strings.Builder
+	sb.WriteString("# Broadcast\n\n")
+	for _, line := range strings.Split(r.Prompt, "\n") {
+		fmt.Fprintf(&sb, "> %s\n", line)
+	}
+	fmt.Fprintf(&sb, "\n*%s · %d sessions, %d replied*\n\n", r.Started.Local().Format("2006-01-02 15:04"),
+		len(r.Results), len(r.Results)-r.Failed())
+
+	sb.WriteString("| Session | Tool | Status | Duration |\n|---|---|---|---|\n")
+	for _, result := range r.Results {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", strings.ReplaceAll(result.Title, "|", "\\|"),
+			result.Tool, result.Status, result.Duration.Round(time.Second))
+	}
+
+	for _, result := range r.Results {
+		fmt.Fprintf(&sb, "\n## %s\n\n", result.Title)
+		fmt.Fprintf(&sb, "*%s*\n\n", result.details())
+		if result.Reply != "" {
+			sb.WriteString(result.Reply + "\n")
+		}
+		if result.Error != "" {
+			fmt.Fprintf(&sb, "\n**Error:** %s\n", result.Error)
+		}
+	}
+	return sb.String()
+}
+
+// details describes how the session answered: tool, status and duration
+func (r BroadcastResult) details() string {
+	return fmt.Sprintf("%s · %s · %s", r.Tool, r.Status, r.Duration.Round(time.Second))
+}
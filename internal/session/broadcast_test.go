@@ -0,0 +1,88 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBroadcastReportsSessionStatus(t *testing.T) {
+	// Both fail the same way, but a new session isn't in error during its
+	// start-up grace period
+	fresh := NewInstance("fresh", "/tmp")
+	old := NewInstance("old", "/tmp")
+	old.CreatedAt = time.Now().Add(-time.Minute)
+
+	report := Broadcast(context.Background(), []*Instance{fresh, old}, "hello", nil)
+	for _, result := range report.Results {
+		if result.Err == nil {
+			t.Fatalf("%s: a session that isn't running should fail", result.Title)
+		}
+	}
+	if got := report.Results[0].Status; got != StatusIdle {
+		t.Errorf("fresh session status = %s, want %s", got, StatusIdle)
+	}
+	if got := report.Results[1].Status; got != StatusError {
+		t.Errorf("old session status = %s, want %s", got, StatusError)
+	}
+}
+
+func TestBroadcastReportRender(t *testing.T) {
+	report := &BroadcastReport{
+		Prompt:  "Review the diff",
+		Started: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+		Results: []BroadcastResult{
+			{SessionID: "1", Title: "api", Tool: "claude", Reply: "Looks good.", Status: StatusWaiting,
+				Duration: 42 * time.Second, DurationMS: 42000},
+			{SessionID: "2", Title: "web|ui", Tool: "gemini", Status: StatusRunning,
+				Duration: time.Minute, DurationMS: 60000, Error: ErrTurnTimeout.Error()},
+		},
+	}
+
+	if report.Failed() != 1 {
+		t.Errorf("Failed() = %d, want 1", report.Failed())
+	}
+
+	text, err := report.Render(ReportText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"2 sessions, 1 replied", "━━ api · claude · waiting · 42s\nLooks good.", "Error: timed out"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text report missing %q:\n%s", want, text)
+		}
+	}
+
+	md, err := report.Render(ReportMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"> Review the diff", "| web\\|ui | gemini | running | 1m0s |", "## api\n\n*claude · waiting · 42s*\n\nLooks good."} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+
+	data, err := report.Render(ReportJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded BroadcastReport
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatalf("JSON report does not parse: %v", err)
+	}
+	if len(decoded.Results) != 2 || decoded.Results[0].DurationMS != 42000 || decoded.Results[1].Error == "" {
+		t.Errorf("Unexpected JSON report: %+v", decoded)
+	}
+
+	if _, err := report.Render("pdf"); err == nil {
+		t.Error("unknown format should be an error")
+	}
+	for _, format := range []string{"", "text", "md", "markdown", "json"} {
+		if err := ValidateReportFormat(format); err != nil {
+			t.Errorf("ValidateReportFormat(%q) = %v", format, err)
+		}
+	}
+}
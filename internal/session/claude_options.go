@@ -0,0 +1,311 @@
+package session
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ClaudeOptions are per-session Claude Code flags. They apply to every
+// command that runs Claude interactively for the session (start, resume on
+// restart, fork); unset fields fall back to the [claude] settings. Templates
+// set them in [templates.NAME.claude].
+type ClaudeOptions struct {
+	// DangerousMode overrides [claude] dangerous_mode (nil: use the setting)
+	DangerousMode  *bool    `json:"dangerous_mode,omitempty" toml:"dangerous_mode,omitempty"`
+	Model          string   `json:"model,omitempty" toml:"model,omitempty"`                     // --model
+	PermissionMode string   `json:"permission_mode,omitempty" toml:"permission_mode,omitempty"` // --permission-mode
+	AddDirs        []string `json:"add_dirs,omitempty" toml:"add_dirs,omitempty"`               // --add-dir, one per directory
+	AllowedTools   []string `json:"allowed_tools,omitempty" toml:"allowed_tools,omitempty"`     // --allowedTools
+}
+
+// ClaudePermissionModes are the values Claude accepts for --permission-mode
+var ClaudePermissionModes = []string{"default", "acceptEdits", "plan", "bypassPermissions"}
+
+// ClaudeOptionFields are the `session set` fields of the per-session Claude
+// options
+var ClaudeOptionFields = []string{"claude-model", "claude-permission-mode", "claude-add-dirs", "claude-allowed-tools", "dangerous-mode"}
+
+// IsZero reports whether no option is set
+func (o *ClaudeOptions) IsZero() bool {
+	return o == nil || (o.DangerousMode == nil && o.Model == "" && o.PermissionMode == "" &&
+		len(o.AddDirs) == 0 && len(o.AllowedTools) == 0)
+}
+
+// Clone returns a deep copy (nil for nil)
+func (o *ClaudeOptions) Clone() *ClaudeOptions {
+	if o == nil {
+		return nil
+	}
+	clone := *o
+	if o.DangerousMode != nil {
+		dangerous := *o.DangerousMode
+		clone.DangerousMode = &dangerous
+	}
+	clone.AddDirs = append([]string(nil), o.AddDirs...)
+	clone.AllowedTools = append([]string(nil), o.AllowedTools...)
+	return &clone
+}
+
+// Args returns the options as command-line arguments, without
+// --dangerously-skip-permissions (it also depends on the user config)
+func (o *ClaudeOptions) Args() []string {
+	if o == nil {
+		return nil
+	}
+	var args []string
+	if o.Model != "" {
+		args = append(args, "--model", o.Model)
+	}
+	if o.PermissionMode != "" {
+		args = append(args, "--permission-mode", o.PermissionMode)
+	}
+	for _, dir := range o.AddDirs {
+		args = append(args, "--add-dir", dir)
+	}
+	if len(o.AllowedTools) > 0 {
+		args = append(args, "--allowedTools")
+		args = append(args, o.AllowedTools...)
+	}
+	return args
+}
+
+// String returns the options as they would be typed on the command line
+func (o *ClaudeOptions) String() string {
+	args := o.Args()
+	for idx, arg := range args {
+		args[idx] = shellQuote(arg)
+	}
+	return strings.Join(args, " ")
+}
+
+// ParseClaudeFlags parses Claude flags as typed in the New/Fork dialogs, e.g.
+// `--model opus --add-dir ../shared --allowedTools "Bash(git log:*)" Edit`.
+// Returns nil for an empty string.
+func ParseClaudeFlags(s string) (*ClaudeOptions, error) {
+	words, err := splitShellWords(s)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &ClaudeOptions{}
+	for idx := 0; idx < len(words); idx++ {
+		flag, value, hasValue := strings.Cut(words[idx], "=")
+		// values collects the arguments of a flag: the =value, else the
+		// words up to the next flag (all of them when variadic)
+		values := func(variadic bool) ([]string, error) {
+			if hasValue {
+				return []string{value}, nil
+			}
+			var vals []string
+			for idx+1 < len(words) && !strings.HasPrefix(words[idx+1], "-") {
+				idx++
+				vals = append(vals, words[idx])
+				if !variadic {
+					break
+				}
+			}
+			if len(vals) == 0 {
+				return nil, fmt.Errorf("%s needs a value", flag)
+			}
+			return vals, nil
+		}
+
+		var vals []string
+		switch flag {
+		case "--model":
+			if vals, err = values(false); err == nil {
+				opts.Model = vals[0]
+			}
+		case "--permission-mode":
+			if vals, err = values(false); err == nil {
+				err = opts.setPermissionMode(vals[0])
+			}
+		case "--add-dir":
+			if vals, err = values(true); err == nil {
+				opts.AddDirs = append(opts.AddDirs, vals...)
+			}
+		case "--allowedTools", "--allowed-tools":
+			if vals, err = values(true); err == nil {
+				opts.AllowedTools = append(opts.AllowedTools, vals...)
+			}
+		case "--dangerously-skip-permissions":
+			dangerous := true
+			opts.DangerousMode = &dangerous
+		default:
+			err = fmt.Errorf("unsupported Claude flag: %s (supported: --model, --permission-mode, --add-dir, --allowedTools, --dangerously-skip-permissions)", words[idx])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.IsZero() {
+		return nil, nil
+	}
+	return opts, nil
+}
+
+func (o *ClaudeOptions) setPermissionMode(mode string) error {
+	if mode != "" && !contains(ClaudePermissionModes, mode) {
+		return fmt.Errorf("invalid permission mode %q (valid: %s)", mode, strings.Join(ClaudePermissionModes, ", "))
+	}
+	o.PermissionMode = mode
+	return nil
+}
+
+// ClaudeOption returns a per-session Claude option by its `session set` field
+// name. Lists are comma-separated; dangerous-mode is "default" when unset.
+func (i *Instance) ClaudeOption(field string) string {
+	opts := i.ClaudeOptions
+	if opts == nil {
+		opts = &ClaudeOptions{}
+	}
+	switch field {
+	case "claude-model":
+		return opts.Model
+	case "claude-permission-mode":
+		return opts.PermissionMode
+	case "claude-add-dirs":
+		return strings.Join(opts.AddDirs, ",")
+	case "claude-allowed-tools":
+		return strings.Join(opts.AllowedTools, ",")
+	case "dangerous-mode":
+		if opts.DangerousMode == nil {
+			return "default"
+		}
+		return strconv.FormatBool(*opts.DangerousMode)
+	}
+	return ""
+}
+
+// SetClaudeOption sets a per-session Claude option by its `session set` field
+// name. Lists are comma-separated; an empty value (dangerous-mode: "default")
+// clears the option.
+func (i *Instance) SetClaudeOption(field, value string) error {
+	opts := i.ClaudeOptions.Clone()
+	if opts == nil {
+		opts = &ClaudeOptions{}
+	}
+	value = strings.TrimSpace(value)
+
+	switch field {
+	case "claude-model":
+		opts.Model = value
+	case "claude-permission-mode":
+		if err := opts.setPermissionMode(value); err != nil {
+			return err
+		}
+	case "claude-add-dirs":
+		opts.AddDirs = splitList(value)
+	case "claude-allowed-tools":
+		opts.AllowedTools = splitList(value)
+	case "dangerous-mode":
+		if value == "" || value == "default" {
+			opts.DangerousMode = nil
+			break
+		}
+		dangerous, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid dangerous-mode %q (use true, false or default)", value)
+		}
+		opts.DangerousMode = &dangerous
+	default:
+		return fmt.Errorf("unknown Claude option: %s", field)
+	}
+
+	if opts.IsZero() {
+		opts = nil
+	}
+	i.ClaudeOptions = opts
+	return nil
+}
+
+// RunsClaude reports whether the session is driven by the Claude adapter,
+// whose commands take the session's ClaudeOptions
+func (i *Instance) RunsClaude() bool {
+	_, ok := i.adapter().(claudeAdapter)
+	return ok
+}
+
+// ClaudeDangerousMode reports whether Claude runs with
+// --dangerously-skip-permissions: the session's choice, else the user config
+func (i *Instance) ClaudeDangerousMode() bool {
+	if i.ClaudeOptions != nil && i.ClaudeOptions.DangerousMode != nil {
+		return *i.ClaudeOptions.DangerousMode
+	}
+	if userConfig, err := LoadUserConfig(); err == nil && userConfig != nil {
+		return userConfig.Claude.DangerousMode
+	}
+	return false
+}
+
+// claudeFlags returns the flags of interactive Claude commands, with a
+// leading space ("" when there are none)
+func (i *Instance) claudeFlags() string {
+	var flags string
+	if args := i.ClaudeOptions.String(); args != "" {
+		flags = " " + args
+	}
+	if i.ClaudeDangerousMode() {
+		flags += " --dangerously-skip-permissions"
+	}
+	return flags
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// shellQuote quotes a word for sh when it contains anything but safe characters
+func shellQuote(word string) string {
+	if word != "" && strings.Trim(word, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@+,") == "" {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'"'"'`) + "'"
+}
+
+// splitShellWords splits a string into words like sh does for simple input:
+// whitespace separates words, single and double quotes group them
+func splitShellWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
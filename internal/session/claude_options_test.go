@@ -0,0 +1,172 @@
+package session
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// setDangerousMode installs a user config with [claude] dangerous_mode for
+// the duration of a test
+func setDangerousMode(t *testing.T, dangerous bool) {
+	t.Helper()
+	userConfigCacheMu.Lock()
+	userConfigCache = &UserConfig{Claude: ClaudeSettings{DangerousMode: dangerous}}
+	userConfigCacheMu.Unlock()
+	t.Cleanup(func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = nil
+		userConfigCacheMu.Unlock()
+	})
+}
+
+func TestParseClaudeFlags(t *testing.T) {
+	opts, err := ParseClaudeFlags(`--model opus --permission-mode=plan --add-dir ../shared /tmp/docs --allowedTools "Bash(git log:*)" Edit`)
+	if err != nil {
+		t.Fatalf("ParseClaudeFlags: %v", err)
+	}
+	if opts.Model != "opus" || opts.PermissionMode != "plan" {
+		t.Errorf("Model/PermissionMode = %q/%q", opts.Model, opts.PermissionMode)
+	}
+	if strings.Join(opts.AddDirs, "|") != "../shared|/tmp/docs" {
+		t.Errorf("AddDirs = %v", opts.AddDirs)
+	}
+	if strings.Join(opts.AllowedTools, "|") != "Bash(git log:*)|Edit" {
+		t.Errorf("AllowedTools = %v", opts.AllowedTools)
+	}
+	if opts.DangerousMode != nil {
+		t.Error("DangerousMode should be unset")
+	}
+
+	// String() round-trips through the parser
+	again, err := ParseClaudeFlags(opts.String())
+	if err != nil || again.String() != opts.String() {
+		t.Errorf("round trip: %q -> %q (%v)", opts.String(), again.String(), err)
+	}
+
+	if opts, err := ParseClaudeFlags("  "); opts != nil || err != nil {
+		t.Errorf("empty flags = %+v, %v; want nil, nil", opts, err)
+	}
+
+	for _, bad := range []string{"--model", "--permission-mode yolo", "--verbose", `--model "opus`} {
+		if _, err := ParseClaudeFlags(bad); err == nil {
+			t.Errorf("ParseClaudeFlags(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSetClaudeOption(t *testing.T) {
+	inst := NewInstanceWithTool("c", "/tmp", "claude")
+
+	if err := inst.SetClaudeOption("claude-model", "sonnet"); err != nil {
+		t.Fatal(err)
+	}
+	if err := inst.SetClaudeOption("claude-add-dirs", "../a, ../b,"); err != nil {
+		t.Fatal(err)
+	}
+	if err := inst.SetClaudeOption("dangerous-mode", "false"); err != nil {
+		t.Fatal(err)
+	}
+	if got := inst.ClaudeOption("claude-add-dirs"); got != "../a,../b" {
+		t.Errorf("claude-add-dirs = %q", got)
+	}
+	if got := inst.ClaudeOption("dangerous-mode"); got != "false" {
+		t.Errorf("dangerous-mode = %q", got)
+	}
+
+	if err := inst.SetClaudeOption("claude-permission-mode", "yolo"); err == nil {
+		t.Error("invalid permission mode should be rejected")
+	}
+	if err := inst.SetClaudeOption("dangerous-mode", "maybe"); err == nil {
+		t.Error("invalid dangerous-mode should be rejected")
+	}
+
+	// Clearing every option drops the struct
+	for _, field := range []string{"claude-model", "claude-add-dirs"} {
+		if err := inst.SetClaudeOption(field, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := inst.SetClaudeOption("dangerous-mode", "default"); err != nil {
+		t.Fatal(err)
+	}
+	if inst.ClaudeOptions != nil {
+		t.Errorf("ClaudeOptions = %+v, want nil", inst.ClaudeOptions)
+	}
+}
+
+func TestClaudeCommandsHonourOptions(t *testing.T) {
+	setDangerousMode(t, false)
+
+	inst := NewInstanceWithTool("c", "/tmp", "claude")
+	inst.ClaudeSessionID = "abc-123"
+	inst.ClaudeDetectedAt = time.Now()
+
+	// No options and dangerous_mode off: plain commands
+	if cmd := inst.buildClaudeResumeCommand(); strings.Contains(cmd, "--dangerously-skip-permissions") {
+		t.Errorf("resume should not skip permissions: %s", cmd)
+	}
+	fork, _, err := inst.CreateForkedInstance("fork", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(fork.Command, "--dangerously-skip-permissions") {
+		t.Errorf("fork should respect dangerous_mode = false: %s", fork.Command)
+	}
+
+	inst.ClaudeOptions = &ClaudeOptions{Model: "opus", AddDirs: []string{"/tmp/shared dir"}}
+	want := ` --model opus --add-dir '/tmp/shared dir'`
+	for name, cmd := range map[string]string{
+		"start":  inst.buildClaudeCommand("claude"),
+		"resume": inst.buildClaudeResumeCommand(),
+	} {
+		if !strings.HasSuffix(cmd, `claude --resume "$session_id"`+want) && !strings.HasSuffix(cmd, "claude --resume abc-123"+want) {
+			t.Errorf("%s command should end with the session's flags: %s", name, cmd)
+		}
+	}
+
+	// Forks inherit the parent's options unless given their own
+	fork, _, err = inst.CreateForkedInstance("fork", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(fork.Command, want) || fork.ClaudeOptions == inst.ClaudeOptions || fork.ClaudeOptions.Model != "opus" {
+		t.Errorf("fork should inherit a copy of the options: %s", fork.Command)
+	}
+
+	dangerous := true
+	fork, _, err = inst.CreateForkedInstanceWithOptions("fork", "", ForkOptions{
+		Claude: &ClaudeOptions{PermissionMode: "plan", DangerousMode: &dangerous},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(fork.Command, `claude --resume "$session_id" --permission-mode plan --dangerously-skip-permissions`) {
+		t.Errorf("fork should use its own options: %s", fork.Command)
+	}
+
+	fork, _, err = inst.CreateForkedInstanceWithOptions("fork", "", ForkOptions{Claude: &ClaudeOptions{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fork.ClaudeOptions != nil || !strings.HasSuffix(fork.Command, `claude --resume "$session_id"`) {
+		t.Errorf("empty fork options should clear the parent's: %s", fork.Command)
+	}
+}
+
+func TestClaudeDangerousModeOverride(t *testing.T) {
+	setDangerousMode(t, true)
+
+	inst := NewInstanceWithTool("c", "/tmp", "claude")
+	inst.ClaudeSessionID = "abc-123"
+	if !strings.HasSuffix(inst.buildClaudeResumeCommand(), " --dangerously-skip-permissions") {
+		t.Error("dangerous_mode = true should skip permissions")
+	}
+
+	if err := inst.SetClaudeOption("dangerous-mode", "false"); err != nil {
+		t.Fatal(err)
+	}
+	if inst.ClaudeDangerousMode() || strings.Contains(inst.buildClaudeResumeCommand(), "--dangerously-skip-permissions") {
+		t.Error("the session's dangerous-mode should override the user config")
+	}
+}
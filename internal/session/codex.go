@@ -0,0 +1,270 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GetCodexHomeDir returns $CODEX_HOME or ~/.codex
+func GetCodexHomeDir() string {
+	if dir := os.Getenv("CODEX_HOME"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".codex")
+}
+
+// GetCodexSessionsDir returns the directory Codex writes its rollouts to
+// Format: ~/.codex/sessions/YYYY/MM/DD/rollout-<timestamp>-<uuid>.jsonl
+func GetCodexSessionsDir() string {
+	return filepath.Join(GetCodexHomeDir(), "sessions")
+}
+
+// CodexSessionInfo holds the metadata of a Codex rollout file
+type CodexSessionInfo struct {
+	SessionID string
+	Cwd       string
+	StartTime time.Time
+	Path      string
+}
+
+// codexRecord is one line of a rollout file. Current versions wrap every
+// item as {"type": "session_meta"|"response_item"|..., "payload": {...}};
+// older ones wrote a bare metadata line followed by bare items.
+type codexRecord struct {
+	Timestamp string          `json:"timestamp"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+
+	// Legacy metadata line
+	ID string `json:"id"`
+}
+
+// codexMessage is a response item of type "message"
+type codexMessage struct {
+	Type    string `json:"type"`
+	Role    string `json:"role"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// text joins the output text blocks of an assistant message
+func (m codexMessage) text() string {
+	var parts []string
+	for _, block := range m.Content {
+		if block.Type == "output_text" && block.Text != "" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// parseCodexSessionMeta reads the session_meta line of a rollout file
+func parseCodexSessionMeta(path string) (CodexSessionInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return CodexSessionInfo{}, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return CodexSessionInfo{}, fmt.Errorf("empty rollout file: %s", path)
+	}
+
+	var record codexRecord
+	if err := json.Unmarshal(line, &record); err != nil {
+		return CodexSessionInfo{}, fmt.Errorf("failed to parse rollout header: %w", err)
+	}
+	if record.Type != "session_meta" {
+		// Legacy rollouts don't record the working directory
+		return CodexSessionInfo{}, fmt.Errorf("rollout has no session_meta: %s", path)
+	}
+
+	var meta struct {
+		ID        string `json:"id"`
+		Timestamp string `json:"timestamp"`
+		Cwd       string `json:"cwd"`
+	}
+	if err := json.Unmarshal(record.Payload, &meta); err != nil {
+		return CodexSessionInfo{}, fmt.Errorf("failed to parse session_meta: %w", err)
+	}
+	startTime, _ := time.Parse(time.RFC3339Nano, meta.Timestamp)
+
+	return CodexSessionInfo{
+		SessionID: meta.ID,
+		Cwd:       meta.Cwd,
+		StartTime: startTime,
+		Path:      path,
+	}, nil
+}
+
+// FindCodexSession returns the first Codex conversation started in
+// projectPath at or after since, or nil if there is none yet. Day directories
+// older than since are skipped, so the scan stays cheap.
+func FindCodexSession(projectPath string, since time.Time, exclude map[string]bool) *CodexSessionInfo {
+	sessionsDir := GetCodexSessionsDir()
+	wantCwd := normalizePath(projectPath)
+	// Day directories use local time; allow a day of slack for time zones
+	earliestDay := since.AddDate(0, 0, -1).Format("2006/01/02")
+	// Codex may start a moment before agent-deck records the start time
+	since = since.Add(-5 * time.Second)
+
+	var found *CodexSessionInfo
+	_ = filepath.WalkDir(sessionsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(sessionsDir, path)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			// sessions/YYYY/MM/DD: compare the date prefix at each depth
+			if rel != "." && rel < earliestDay[:min(len(rel), len(earliestDay))] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasPrefix(d.Name(), "rollout-") || !strings.HasSuffix(d.Name(), ".jsonl") {
+			return nil
+		}
+
+		info, err := parseCodexSessionMeta(path)
+		if err != nil || info.SessionID == "" || exclude[info.SessionID] {
+			return nil
+		}
+		if normalizePath(info.Cwd) != wantCwd || info.StartTime.Before(since) {
+			return nil
+		}
+		if found == nil || info.StartTime.Before(found.StartTime) {
+			found = &info
+		}
+		return nil
+	})
+	return found
+}
+
+// findCodexRollout returns the rollout file of a Codex session
+func findCodexRollout(sessionID string) (string, error) {
+	files, _ := filepath.Glob(filepath.Join(GetCodexSessionsDir(), "*", "*", "*", "rollout-*-"+sessionID+".jsonl"))
+	if len(files) == 0 {
+		return "", fmt.Errorf("session file not found for ID: %s", sessionID)
+	}
+	return files[len(files)-1], nil
+}
+
+// getCodexLastResponse extracts the last assistant message from Codex's rollout file
+func (i *Instance) getCodexLastResponse() (*ResponseOutput, error) {
+	if i.CodexSessionID == "" {
+		return nil, fmt.Errorf("no Codex session ID available for this instance")
+	}
+	path, err := findCodexRollout(i.CodexSessionID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	return parseCodexLastAssistantMessage(data)
+}
+
+// parseCodexLastAssistantMessage parses a rollout file (current or legacy
+// format) to extract the last assistant message
+func parseCodexLastAssistantMessage(data []byte) (*ResponseOutput, error) {
+	var sessionID, lastContent, lastTimestamp string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	// Tool outputs can make single lines large
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+
+	first := true
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var record codexRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			continue // Skip malformed lines
+		}
+
+		var msg codexMessage
+		switch {
+		case record.Type == "session_meta":
+			var meta struct {
+				ID string `json:"id"`
+			}
+			if json.Unmarshal(record.Payload, &meta) == nil {
+				sessionID = meta.ID
+			}
+		case record.Type == "response_item":
+			if err := json.Unmarshal(record.Payload, &msg); err != nil {
+				continue
+			}
+		case first && record.ID != "":
+			sessionID = record.ID // Legacy metadata line
+		case record.Type == "message":
+			// Legacy items aren't wrapped in a payload
+			if err := json.Unmarshal(line, &msg); err != nil {
+				continue
+			}
+		}
+		first = false
+
+		if msg.Type == "message" && msg.Role == "assistant" {
+			if text := msg.text(); text != "" {
+				lastContent = text
+				lastTimestamp = record.Timestamp
+			}
+		}
+	}
+
+	if lastContent == "" {
+		return nil, fmt.Errorf("no assistant response found in session")
+	}
+
+	return &ResponseOutput{
+		Tool:      "codex",
+		Role:      "assistant",
+		Content:   lastContent,
+		Timestamp: lastTimestamp,
+		SessionID: sessionID,
+	}, nil
+}
+
+// normalizePath cleans a path and resolves symlinks when it exists, so paths
+// recorded by tools compare equal to ours (macOS: /tmp -> /private/tmp)
+func normalizePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	path = filepath.Clean(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// UpdateCodexSession discovers the Codex conversation of the session from
+// the rollout files. Codex doesn't report its session ID, so the first
+// rollout started in the project after the session started is taken. Scans
+// are throttled while the ID is unknown; once found it is kept.
+func (i *Instance) UpdateCodexSession(excludeIDs map[string]bool) {
+	if i.Tool != "codex" || i.CodexSessionID != "" || !i.sessionScanDue() {
+		return
+	}
+	if info := FindCodexSession(i.ProjectPath, i.sessionStartTime(), excludeIDs); info != nil {
+		i.CodexSessionID = info.SessionID
+		i.CodexDetectedAt = time.Now()
+	}
+}
@@ -0,0 +1,133 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const codexFixtureID = "0199ec1a-5b2e-7c30-9d4e-2f1a3b4c5d6e"
+
+func useCodexFixtures(t *testing.T) {
+	t.Helper()
+	dir, err := filepath.Abs(filepath.Join("testdata", "codex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CODEX_HOME", dir)
+}
+
+func TestParseCodexLastAssistantMessage(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "codex", "sessions", "2025", "10", "16",
+		"rollout-2025-10-16T09-12-03-"+codexFixtureID+".jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := parseCodexLastAssistantMessage(data)
+	if err != nil {
+		t.Fatalf("parseCodexLastAssistantMessage: %v", err)
+	}
+	want := "Added `GET /healthz` in `main.go`.\n\nIt returns `200 ok` and skips the auth middleware."
+	if resp.Content != want {
+		t.Errorf("Content = %q, want %q", resp.Content, want)
+	}
+	if resp.SessionID != codexFixtureID {
+		t.Errorf("SessionID = %q, want %q", resp.SessionID, codexFixtureID)
+	}
+	if resp.Timestamp != "2025-10-16T09:12:31.008Z" {
+		t.Errorf("Timestamp = %q", resp.Timestamp)
+	}
+	if resp.Tool != "codex" || resp.Role != "assistant" {
+		t.Errorf("Tool/Role = %q/%q", resp.Tool, resp.Role)
+	}
+}
+
+func TestParseCodexLastAssistantMessage_Legacy(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "codex", "legacy",
+		"rollout-2025-08-04-1f3e5a7c-9b2d-4e6f-8a1c-3d5e7f9b1a2c.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := parseCodexLastAssistantMessage(data)
+	if err != nil {
+		t.Fatalf("parseCodexLastAssistantMessage: %v", err)
+	}
+	if resp.Content != "`make lint` runs golangci-lint with the config in `.golangci.yml`." {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if resp.SessionID != "1f3e5a7c-9b2d-4e6f-8a1c-3d5e7f9b1a2c" {
+		t.Errorf("SessionID = %q", resp.SessionID)
+	}
+}
+
+func TestParseCodexLastAssistantMessage_NoResponse(t *testing.T) {
+	data := []byte(`{"timestamp":"2025-10-16T09:12:03.120Z","type":"session_meta","payload":{"id":"x","cwd":"/tmp"}}` + "\n")
+	if _, err := parseCodexLastAssistantMessage(data); err == nil {
+		t.Error("expected an error for a rollout without assistant messages")
+	}
+}
+
+func TestFindCodexSession(t *testing.T) {
+	useCodexFixtures(t)
+	started := time.Date(2025, 10, 16, 9, 12, 1, 0, time.UTC)
+
+	info := FindCodexSession("/home/dev/webapp", started, nil)
+	if info == nil {
+		t.Fatal("expected the webapp rollout to be found")
+	}
+	if info.SessionID != codexFixtureID || info.Cwd != "/home/dev/webapp" {
+		t.Errorf("found %+v", info)
+	}
+
+	// Conversations claimed by another session are skipped
+	if info := FindCodexSession("/home/dev/webapp", started, map[string]bool{codexFixtureID: true}); info != nil {
+		t.Errorf("excluded rollout returned: %+v", info)
+	}
+	// Conversations started before the session are not its own
+	if info := FindCodexSession("/home/dev/webapp", started.Add(time.Hour), nil); info != nil {
+		t.Errorf("earlier rollout returned: %+v", info)
+	}
+	// Other projects don't match
+	if info := FindCodexSession("/home/dev/api", started, nil); info != nil {
+		t.Errorf("rollout of another project returned: %+v", info)
+	}
+}
+
+func TestCodexInstanceResume(t *testing.T) {
+	useCodexFixtures(t)
+
+	inst := NewInstanceWithTool("codex", "/home/dev/webapp", "codex")
+	inst.Command = "codex --full-auto"
+	inst.CreatedAt = time.Date(2025, 10, 16, 9, 12, 0, 0, time.UTC)
+	if inst.adapter().ResumeCommand(inst) != "" {
+		t.Error("codex without a session ID has nothing to resume")
+	}
+	if cmd := inst.adapter().StartCommand(inst); cmd != "codex --full-auto" {
+		t.Errorf("StartCommand = %q", cmd)
+	}
+
+	inst.adapter().DetectSessionID(inst)
+	if inst.CodexSessionID != codexFixtureID {
+		t.Fatalf("CodexSessionID = %q, want %q", inst.CodexSessionID, codexFixtureID)
+	}
+	if cmd := inst.adapter().ResumeCommand(inst); cmd != "codex resume "+codexFixtureID {
+		t.Errorf("ResumeCommand = %q", cmd)
+	}
+	if cmd := inst.adapter().StartCommand(inst); cmd != "codex resume "+codexFixtureID {
+		t.Errorf("StartCommand should resume, got %q", cmd)
+	}
+	if !inst.CanRestart() {
+		t.Error("codex session with an ID should be restartable")
+	}
+
+	resp, err := inst.GetLastResponse()
+	if err != nil {
+		t.Fatalf("GetLastResponse: %v", err)
+	}
+	if resp.SessionID != codexFixtureID {
+		t.Errorf("response SessionID = %q", resp.SessionID)
+	}
+}
@@ -77,7 +77,7 @@ func GroupByProject(instances []*Instance) map[string][]*Instance {
 }
 
 // FilterByQuery filters sessions by title, project path, tool, or status
-// Supports status filters: "waiting", "running", "idle", "error"
+// Supports status filters: "waiting", "approval", "running", "idle", "error"
 func FilterByQuery(instances []*Instance, query string) []*Instance {
 	if query == "" {
 		return instances
@@ -87,10 +87,11 @@ func FilterByQuery(instances []*Instance, query string) []*Instance {
 
 	// Check for status filters
 	statusFilters := map[string]Status{
-		"waiting": StatusWaiting,
-		"running": StatusRunning,
-		"idle":    StatusIdle,
-		"error":   StatusError,
+		"waiting":  StatusWaiting,
+		"approval": StatusApproval,
+		"running":  StatusRunning,
+		"idle":     StatusIdle,
+		"error":    StatusError,
 	}
 
 	// If query matches a status filter exactly, filter by status
@@ -125,22 +126,7 @@ func filterByStatus(instances []*Instance, status Status) []*Instance {
 
 // detectToolFromName tries to detect tool type from session name
 func detectToolFromName(name string) string {
-	nameLower := strings.ToLower(name)
-
-	if strings.Contains(nameLower, "claude") {
-		return "claude"
-	}
-	if strings.Contains(nameLower, "gemini") {
-		return "gemini"
-	}
-	if strings.Contains(nameLower, "opencode") || strings.Contains(nameLower, "open-code") {
-		return "opencode"
-	}
-	if strings.Contains(nameLower, "codex") {
-		return "codex"
-	}
-
-	return "shell"
+	return DetectToolFromCommand(name)
 }
 
 // extractProjectName extracts the parent directory name from a path
@@ -0,0 +1,16 @@
+//go:build windows
+
+package session
+
+import "os"
+
+// tryLockFile is a no-op on Windows, where agent-deck is not supported (no
+// tmux); every process acts as the lock holder
+func tryLockFile(f *os.File) error {
+	return nil
+}
+
+// lockFile is a no-op on Windows (see tryLockFile)
+func lockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,171 @@
+package session
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// forkSeedMaxLines caps the parent transcript carried into a seeded fork, so
+// the seed stays a summary the agent can read in one go
+const forkSeedMaxLines = 200
+
+// canSeedFork reports whether a transcript-seeded fork is possible: the
+// session runs an agent (not a plain shell) whose terminal can still be read
+func (i *Instance) canSeedFork() bool {
+	if i.Tool == "" || i.Tool == "shell" || i.tmuxSession == nil {
+		return false
+	}
+	return i.tmuxSession.Exists()
+}
+
+// createSeededFork forks a session whose tool can't fork conversations: a
+// new session of the same tool starts fresh and is handed a summary of the
+// parent's transcript once ready (see StartFork). The fork is recorded as a
+// sub-session of its parent, so the tree shows the lineage.
+func (i *Instance) createSeededFork(newTitle, newGroupPath string, opts ForkOptions) (*Instance, string, error) {
+	if !i.canSeedFork() {
+		return nil, "", fmt.Errorf("cannot fork: %s session is not running", i.Tool)
+	}
+
+	seed, err := i.buildForkSeed()
+	if err != nil {
+		return nil, "", err
+	}
+
+	forked := NewInstanceWithTool(newTitle, i.ProjectPath, i.Tool)
+	if newGroupPath != "" {
+		forked.GroupPath = newGroupPath
+	} else {
+		forked.GroupPath = i.GroupPath
+	}
+	if opts.Worktree {
+		if err := forked.SetupWorktree(opts.Branch); err != nil {
+			return nil, "", err
+		}
+	}
+
+	forked.Command = i.seededForkCommand()
+	forked.ClaudeOptions = opts.claudeOptions(i)
+	forked.Env = maps.Clone(i.Env)
+	forked.SetParent(i.ID)
+	forked.forkSeed = seed
+
+	return forked, forked.Command, nil
+}
+
+// seededForkCommand is the command a seeded fork starts with: the parent's,
+// unless that resumes the parent's conversation (e.g. a session picked in the
+// global search), then the tool's base command
+func (i *Instance) seededForkCommand() string {
+	id := i.adapter().SessionID(i)
+	if i.Command != "" && (id == "" || !strings.Contains(i.Command, id)) {
+		return i.Command
+	}
+	if def := GetToolDef(i.Tool); def != nil && def.Command != "" {
+		return def.Command
+	}
+	return i.adapter().Name()
+}
+
+// buildForkSeed summarizes the parent session for a seeded fork from its
+// terminal history and last response
+func (i *Instance) buildForkSeed() (string, error) {
+	var history string
+	if i.tmuxSession != nil {
+		history, _ = i.tmuxSession.CaptureFullHistory()
+	}
+	var lastResponse string
+	if resp, err := i.GetLastResponse(); err == nil {
+		lastResponse = resp.Content
+	}
+
+	seed := formatForkSeed(i.Title, i.Tool, history, lastResponse)
+	if seed == "" {
+		return "", fmt.Errorf("cannot fork: no transcript available for '%s'", i.Title)
+	}
+	return seed, nil
+}
+
+// formatForkSeed builds the message a seeded fork starts with. Terminal
+// chrome (colors, borders, blank runs) is dropped and only the last
+// forkSeedMaxLines lines are kept. Returns "" when there is nothing to carry.
+func formatForkSeed(title, tool, history, lastResponse string) string {
+	transcript := summarizeTranscript(history, forkSeedMaxLines)
+	lastResponse = strings.TrimSpace(lastResponse)
+	if transcript == "" && lastResponse == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "This session is a fork of the %s session %q. ", tool, title)
+	b.WriteString("Its conversation can't be carried over directly, so here is a summary of it. ")
+	b.WriteString("Use it as context, continue from where it left off and wait for my next instruction.\n")
+	if transcript != "" {
+		b.WriteString("\n--- transcript ---\n")
+		b.WriteString(transcript)
+		b.WriteString("\n--- end of transcript ---\n")
+	}
+	// The last response may have scrolled out of the kept lines
+	if lastResponse != "" && !strings.Contains(transcript, lastResponse) {
+		b.WriteString("\n--- last response ---\n")
+		b.WriteString(lastResponse)
+		b.WriteString("\n--- end of last response ---\n")
+	}
+	return b.String()
+}
+
+// summarizeTranscript cleans captured terminal output and keeps its last
+// maxLines lines
+func summarizeTranscript(history string, maxLines int) string {
+	var lines []string
+	blank := true // Also drops leading blank lines
+	for _, line := range strings.Split(tmux.StripANSI(history), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if isTerminalChrome(line) {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			if blank {
+				continue
+			}
+			blank = true
+			lines = append(lines, "")
+			continue
+		}
+		blank = false
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) > maxLines {
+		omitted := len(lines) - maxLines
+		lines = append([]string{fmt.Sprintf("[%d earlier lines omitted]", omitted)}, lines[omitted:]...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isTerminalChrome reports whether a line only draws UI (box borders,
+// separators) and carries no conversation text
+func isTerminalChrome(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false
+	}
+	return strings.Trim(trimmed, "─━═│┃║╭╮╰╯┌┐└┘├┤┬┴┼▔▁-=_ ") == ""
+}
+
+// StartFork starts a forked session. Seeded forks are sent the parent's
+// transcript once the agent is ready, so this blocks until it was delivered.
+func (i *Instance) StartFork() error {
+	if i.forkSeed == "" {
+		return i.Start()
+	}
+	seed := i.forkSeed
+	i.forkSeed = ""
+	return i.StartWithMessage(seed)
+}
@@ -0,0 +1,167 @@
+package session
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummarizeTranscript(t *testing.T) {
+	history := "\n\n\x1b[1m> fix the login bug\x1b[0m\n" +
+		"╭──────────────────╮\n" +
+		"│ Reading auth.go  │\n" +
+		"╰──────────────────╯\n" +
+		"\n\n\n" +
+		"The session check compared the wrong field.   \n" +
+		"────────────────────\n" +
+		"\n"
+
+	got := summarizeTranscript(history, 10)
+	want := "> fix the login bug\n│ Reading auth.go  │\n\nThe session check compared the wrong field."
+	if got != want {
+		t.Errorf("summarizeTranscript() = %q, want %q", got, want)
+	}
+}
+
+func TestSummarizeTranscript_KeepsLastLines(t *testing.T) {
+	var lines []string
+	for n := 1; n <= 10; n++ {
+		lines = append(lines, fmt.Sprintf("line %d", n))
+	}
+
+	got := summarizeTranscript(strings.Join(lines, "\n"), 3)
+	want := "[7 earlier lines omitted]\nline 8\nline 9\nline 10"
+	if got != want {
+		t.Errorf("summarizeTranscript() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatForkSeed(t *testing.T) {
+	if seed := formatForkSeed("api", "codex", "\n  \n", ""); seed != "" {
+		t.Errorf("empty transcript should give no seed, got %q", seed)
+	}
+
+	seed := formatForkSeed("api", "codex", "> add tests\nAdded tests for the handler.", "Added tests for the handler.")
+	if !strings.Contains(seed, `fork of the codex session "api"`) {
+		t.Errorf("seed should name the parent: %q", seed)
+	}
+	if !strings.Contains(seed, "--- transcript ---\n> add tests\nAdded tests for the handler.\n--- end of transcript ---") {
+		t.Errorf("seed should carry the transcript: %q", seed)
+	}
+	// The last response is already part of the transcript
+	if strings.Contains(seed, "--- last response ---") {
+		t.Errorf("last response should not be repeated: %q", seed)
+	}
+
+	seed = formatForkSeed("api", "gemini", "", "Use a connection pool.")
+	if !strings.Contains(seed, "--- last response ---\nUse a connection pool.\n") {
+		t.Errorf("seed should carry the last response: %q", seed)
+	}
+}
+
+func TestCanFork_Seeded(t *testing.T) {
+	// Not running: nothing to read the transcript from
+	inst := NewInstanceWithTool("codex", "/tmp", "codex")
+	if inst.CanFork() {
+		t.Error("a codex session that isn't running can't be forked")
+	}
+	if _, _, err := inst.CreateForkedInstance("fork", ""); err == nil {
+		t.Error("CreateForkedInstance() should fail for a stopped codex session")
+	}
+
+	// Plain shells are never seeded: the transcript would run as commands
+	shell := NewInstance("shell", "/tmp")
+	if shell.canSeedFork() {
+		t.Error("shell sessions can't be seeded")
+	}
+}
+
+// TestSeededFork_Integration forks a running non-Claude session from its
+// terminal transcript
+func TestSeededFork_Integration(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	parent := NewInstanceWithTool("seed-parent", "/tmp", "aider")
+	parent.Command = "echo 'refactored the parser'; cat"
+	if err := parent.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() { _ = parent.Kill() }()
+
+	// Wait for the output to reach the pane
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if content, _ := parent.tmuxSession.CapturePane(); strings.Contains(content, "refactored the parser\n") {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if parent.CanForkNatively() || !parent.CanFork() {
+		t.Fatal("a running aider session should fork by seeding")
+	}
+
+	forked, cmd, err := parent.CreateForkedInstance("seed-child", "experiments")
+	if err != nil {
+		t.Fatalf("CreateForkedInstance: %v", err)
+	}
+	if forked.Tool != "aider" || cmd != parent.Command || forked.Command != parent.Command {
+		t.Errorf("fork should start the parent's tool fresh, got tool=%q cmd=%q", forked.Tool, cmd)
+	}
+	if forked.ParentSessionID != parent.ID {
+		t.Errorf("ParentSessionID = %q, want %q", forked.ParentSessionID, parent.ID)
+	}
+	if forked.GroupPath != "experiments" {
+		t.Errorf("GroupPath = %q, want experiments", forked.GroupPath)
+	}
+	if !strings.Contains(forked.forkSeed, "refactored the parser") {
+		t.Errorf("seed should carry the parent's transcript: %q", forked.forkSeed)
+	}
+}
+
+// TestSeededFork_ResumedParent forks a session that resumes a conversation:
+// the fork starts the tool fresh instead of resuming the parent's
+func TestSeededFork_ResumedParent(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	parent := NewInstanceWithTool("seed-resumed", "/tmp", "codex")
+	parent.Command = "echo 'picked up the migration'; cat"
+	if err := parent.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() { _ = parent.Kill() }()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if content, _ := parent.tmuxSession.CapturePane(); strings.Contains(content, "picked up the migration\n") {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// As saved for a conversation resumed from the global search
+	parent.CodexSessionID = "codex-abc"
+	parent.Command = "codex resume codex-abc"
+
+	forked, cmd, err := parent.CreateForkedInstance("seed-resumed-child", "")
+	if err != nil {
+		t.Fatalf("CreateForkedInstance: %v", err)
+	}
+	if cmd != "codex" || forked.Command != "codex" || forked.CodexSessionID != "" {
+		t.Errorf("fork should start codex fresh, got cmd=%q id=%q", forked.Command, forked.CodexSessionID)
+	}
+	if start := forked.adapter().StartCommand(forked); strings.Contains(start, "codex-abc") {
+		t.Errorf("fork would resume the parent's conversation: %q", start)
+	}
+}
@@ -66,8 +66,10 @@ func WriteGeminiMCPSettings(enabledNames []string) error {
 	mcpServers := make(map[string]MCPServerConfig)
 	for _, name := range enabledNames {
 		if def, ok := availableMCPs[name]; ok {
-			// Check if should use socket pool mode
-			if pool != nil && pool.ShouldPool(name) && pool.IsRunning(name) {
+			// Prefer the pool's HTTP endpoint (no nc bridge needed), then its socket
+			if httpURL := GetPooledHTTPURL(name); httpURL != "" {
+				mcpServers[name] = MCPServerConfig{HTTPURL: httpURL}
+			} else if pool != nil && pool.ShouldPool(name) && pool.IsRunning(name) {
 				// Use Unix socket
 				socketPath := pool.GetSocketPath(name)
 				mcpServers[name] = MCPServerConfig{
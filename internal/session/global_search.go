@@ -3,8 +3,10 @@ package session
 import (
 	"bufio"
 	"bytes"
+	"container/list"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"os"
@@ -35,10 +37,11 @@ const TierThresholdInstant = 100 * 1024 * 1024
 // TierThresholdBalanced is the max size for balanced tier (500MB)
 const TierThresholdBalanced = 500 * 1024 * 1024
 
-// SearchEntry represents a searchable Claude session
+// SearchEntry represents a searchable conversation
 type SearchEntry struct {
-	SessionID    string    // Claude session UUID
-	FilePath     string    // Path to .jsonl file
+	SessionID    string    // Conversation ID of the tool
+	Tool         string    // Tool that wrote the conversation ("claude", "gemini", ...)
+	FilePath     string    // Path to the transcript
 	CWD          string    // Project working directory
 	Content      string    // Full conversation content (original case)
 	ContentLower string    // Lowercased for search
@@ -56,6 +59,9 @@ type MatchRange struct {
 // Match searches for query in entry content (case-insensitive)
 // Returns match positions for highlighting
 func (e *SearchEntry) Match(query string) []MatchRange {
+	if query == "" {
+		return nil
+	}
 	queryLower := strings.ToLower(query)
 	var matches []MatchRange
 
@@ -151,6 +157,7 @@ func byteIndexToRuneIndex(s string, byteIdx int) int {
 type claudeJSONLRecord struct {
 	SessionID string          `json:"sessionId"`
 	Type      string          `json:"type"`
+	IsMeta    bool            `json:"isMeta"`
 	Message   json.RawMessage `json:"message"`
 	Timestamp string          `json:"timestamp"`
 	CWD       string          `json:"cwd"`
@@ -159,10 +166,56 @@ type claudeJSONLRecord struct {
 
 // claudeMessage represents the message field in a record
 type claudeMessage struct {
+	ID      string          `json:"id"` // API message ID, shared by its records
 	Role    string          `json:"role"`
 	Content json.RawMessage `json:"content"`
 }
 
+// claudeContentBlock is one block of a message's content
+type claudeContentBlock struct {
+	Type      string          `json:"type"` // "text", "tool_use", "tool_result", "thinking"
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// decodeClaudeLine decodes one line of a Claude JSONL file and its message
+// (nil if the record has none). ok is false for lines that aren't JSON.
+func decodeClaudeLine(line []byte) (record claudeJSONLRecord, msg *claudeMessage, ok bool) {
+	if len(line) == 0 || json.Unmarshal(line, &record) != nil {
+		return record, nil, false
+	}
+	if len(record.Message) > 0 {
+		var m claudeMessage
+		if json.Unmarshal(record.Message, &m) == nil {
+			msg = &m
+		}
+	}
+	return record, msg, true
+}
+
+// content returns the message's text and, when the content is a list of
+// blocks rather than a string, the blocks (text is their text blocks joined)
+func (m *claudeMessage) content() (text string, blocks []claudeContentBlock) {
+	if json.Unmarshal(m.Content, &text) == nil {
+		return text, nil
+	}
+	if json.Unmarshal(m.Content, &blocks) != nil {
+		return "", nil
+	}
+	var texts []string
+	for _, block := range blocks {
+		if block.Type == "text" {
+			texts = append(texts, block.Text)
+		}
+	}
+	return strings.Join(texts, "\n"), blocks
+}
+
 // parseClaudeJSONL parses a Claude JSONL file into a SearchEntry
 func parseClaudeJSONL(filePath string, data []byte) (*SearchEntry, error) {
 	entry := &SearchEntry{
@@ -293,18 +346,27 @@ type SearchResult struct {
 	Snippet string
 }
 
-// GlobalSearchIndex manages the searchable session index
+// SearchResultLimit is the number of conversations Search returns
+const SearchResultLimit = 15
+
+// GlobalSearchIndex searches the conversations of every supported tool
+// (see transcriptSource) through a persistent full-text index (see
+// searchStore). Transcripts are indexed in the background and kept up to date
+// as they grow; only the conversations shown in results are read into
+// memory, through a size-bounded cache.
 type GlobalSearchIndex struct {
 	// Configuration
 	config    GlobalSearchSettings
 	claudeDir string
 
-	// Index data (protected by atomic pointer for lock-free reads)
-	entries atomic.Pointer[[]SearchEntry]
+	// Where conversations come from
+	sources []transcriptSource
+
+	// Persistent index
+	store *searchStore
 
-	// File tracking for incremental updates
-	fileTrackers map[string]*FileTracker
-	trackerMu    sync.RWMutex
+	// Transcripts loaded for results
+	cache *contentCache
 
 	// File watcher
 	watcher *fsnotify.Watcher
@@ -312,6 +374,9 @@ type GlobalSearchIndex struct {
 	// Rate limiter for background indexing
 	limiter *rate.Limiter
 
+	// Serializes indexing between the initial load and the watcher
+	indexMu sync.Mutex
+
 	// Tier
 	tier SearchTier
 
@@ -326,15 +391,17 @@ type GlobalSearchIndex struct {
 
 // FileTracker tracks file state for incremental updates
 type FileTracker struct {
-	Path       string
-	LastOffset int64
-	LastSize   int64
-	LastMod    time.Time
+	Path       string    `json:"path"`
+	LastOffset int64     `json:"offset"` // Bytes indexed (complete lines)
+	LastSize   int64     `json:"size"`
+	LastMod    time.Time `json:"mod_time"`
 }
 
-// NewGlobalSearchIndex creates a new search index
+// NewGlobalSearchIndex opens the search index in ~/.agent-deck/search and
+// starts bringing it up to date with the conversations of Claude (in
+// claudeDir), Gemini, Codex and OpenCode
 func NewGlobalSearchIndex(claudeDir string, config GlobalSearchSettings) (*GlobalSearchIndex, error) {
-	if !config.Enabled {
+	if !config.Enabled || config.Tier == "disabled" {
 		return nil, nil
 	}
 
@@ -342,68 +409,90 @@ func NewGlobalSearchIndex(claudeDir string, config GlobalSearchSettings) (*Globa
 	if config.IndexRateLimit == 0 {
 		config.IndexRateLimit = 20
 	}
+	if config.MemoryLimitMB <= 0 {
+		config.MemoryLimitMB = 100
+	}
+
+	agentDeckDir, err := GetAgentDeckDir()
+	if err != nil {
+		return nil, err
+	}
+	store, err := openSearchStore(filepath.Join(agentDeckDir, "search"))
+	if err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	idx := &GlobalSearchIndex{
-		config:       config,
-		claudeDir:    claudeDir,
-		fileTrackers: make(map[string]*FileTracker),
-		limiter:      rate.NewLimiter(rate.Limit(config.IndexRateLimit), 5),
-		ctx:          ctx,
-		cancel:       cancel,
+		config:    config,
+		claudeDir: claudeDir,
+		store:     store,
+		limiter:   rate.NewLimiter(rate.Limit(config.IndexRateLimit), 5),
+		ctx:       ctx,
+		cancel:    cancel,
 	}
-
-	// Initialize empty entries
-	emptyEntries := make([]SearchEntry, 0)
-	idx.entries.Store(&emptyEntries)
-
-	// Measure data size and determine tier
-	projectsDir := filepath.Join(claudeDir, "projects")
-	totalSize, err := measureDataSize(projectsDir, config.RecentDays)
-	if err != nil {
-		// Don't fail if projects dir doesn't exist, just use empty index
-		if !os.IsNotExist(err) {
-			cancel()
-			return nil, err
-		}
+	// Gemini is last: its project directories are found among the others'
+	idx.sources = []transcriptSource{
+		claudeSource{projectsDir: filepath.Join(claudeDir, "projects")},
+		codexSource{sessionsDir: GetCodexSessionsDir()},
+		openCodeSource{storageDir: GetOpenCodeStorageDir()},
+		&geminiSource{tmpDir: filepath.Join(GetGeminiConfigDir(), "tmp"), projects: idx.knownProjects},
 	}
+	store.messageText = idx.messageText
 
-	// Determine tier (respect config override)
+	// Determine tier (respect config override). The indexed size is known
+	// without walking the transcripts once the index exists.
 	switch config.Tier {
 	case "instant":
 		idx.tier = TierInstant
 	case "balanced":
 		idx.tier = TierBalanced
-	case "disabled":
-		cancel()
-		return nil, nil
 	default:
+		totalSize := store.dataSize()
+		if store.docCount() == 0 {
+			for _, src := range idx.sources {
+				for _, root := range src.Roots() {
+					size, err := measureDataSize(root, 0)
+					if err != nil && !os.IsNotExist(err) {
+						cancel()
+						store.close()
+						return nil, err
+					}
+					totalSize += size
+				}
+			}
+		}
 		idx.tier = DetectTier(totalSize)
 	}
 
+	// The instant tier keeps as much loaded as it would have held in full
+	cacheLimit := int64(TierThresholdInstant)
+	if idx.tier == TierBalanced {
+		cacheLimit = int64(config.MemoryLimitMB) * 1024 * 1024
+	}
+	idx.cache = newContentCache(cacheLimit)
+
 	// Start file watcher
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		cancel()
+		store.close()
 		return nil, err
 	}
 	idx.watcher = watcher
 
-	// Watch the projects directory (create if doesn't exist check)
-	if _, err := os.Stat(projectsDir); err == nil {
-		if err := watcher.Add(projectsDir); err != nil {
-			log.Printf("GlobalSearch: failed to watch projects dir: %v", err)
-		}
-
-		// Also watch subdirectories
-		_ = filepath.WalkDir(projectsDir, func(path string, d os.DirEntry, err error) error {
-			if err != nil || !d.IsDir() {
-				return nil
+	// Watch every source directory and its subdirectories
+	for _, src := range idx.sources {
+		for _, root := range src.Roots() {
+			if _, err := os.Stat(root); err != nil {
+				continue
 			}
-			_ = watcher.Add(path) // Ignore error - best effort watching
-			return nil
-		})
+			if err := watcher.Add(root); err != nil {
+				log.Printf("GlobalSearch: failed to watch %s: %v", root, err)
+			}
+			idx.watchSubdirs(root, nil)
+		}
 	}
 
 	// Set loading state
@@ -417,7 +506,23 @@ func NewGlobalSearchIndex(claudeDir string, config GlobalSearchSettings) (*Globa
 	return idx, nil
 }
 
-// measureDataSize calculates total size of JSONL files
+// watchSubdirs watches the directories below dir, passing the files found to
+// found (if set)
+func (idx *GlobalSearchIndex) watchSubdirs(dir string, found func(path string)) {
+	_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			_ = idx.watcher.Add(path) // Ignore error - best effort watching
+		} else if found != nil {
+			found(path)
+		}
+		return nil
+	})
+}
+
+// measureDataSize calculates total size of JSON and JSONL files
 func measureDataSize(projectsDir string, recentDays int) (int64, error) {
 	var totalSize int64
 	cutoff := time.Time{}
@@ -429,7 +534,7 @@ func measureDataSize(projectsDir string, recentDays int) (int64, error) {
 		if err != nil || d.IsDir() {
 			return nil
 		}
-		if !strings.HasSuffix(path, ".jsonl") {
+		if !strings.HasSuffix(path, ".jsonl") && !strings.HasSuffix(path, ".json") {
 			return nil
 		}
 		info, err := d.Info()
@@ -446,83 +551,415 @@ func measureDataSize(projectsDir string, recentDays int) (int64, error) {
 	return totalSize, err
 }
 
-// initialLoad loads all session files on startup
+// initialLoad indexes whatever changed since the index was last saved: new
+// conversations, appended messages and deleted files
 func (idx *GlobalSearchIndex) initialLoad() {
 	defer idx.wg.Done()
+	defer idx.loading.Store(false)
 
-	projectsDir := filepath.Join(idx.claudeDir, "projects")
-	cutoff := time.Time{}
-	if idx.config.RecentDays > 0 {
-		cutoff = time.Now().AddDate(0, 0, -idx.config.RecentDays)
-	}
+	for _, src := range idx.sources {
+		seen := make(map[string]bool)
+		complete := true
 
-	var entries []SearchEntry
+		for _, root := range src.Roots() {
+			_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+				if err != nil || d.IsDir() || !src.Matches(path) {
+					return nil
+				}
 
-	_ = filepath.WalkDir(projectsDir, func(path string, d os.DirEntry, err error) error {
-		if err != nil || d.IsDir() {
-			return nil
+				// Check cancellation
+				select {
+				case <-idx.ctx.Done():
+					complete = false
+					return filepath.SkipAll
+				default:
+				}
+
+				seen[path] = true
+				info, err := d.Info()
+				if err != nil {
+					return nil
+				}
+				if doc := idx.store.doc(path); doc != nil && upToDate(doc, info) {
+					return nil
+				}
+
+				// Rate limit the files that need indexing
+				if err := idx.limiter.Wait(idx.ctx); err != nil {
+					complete = false
+					return filepath.SkipAll
+				}
+				idx.indexFile(src, path, info)
+				return nil
+			})
 		}
-		if !strings.HasSuffix(path, ".jsonl") {
-			return nil
+
+		// Forget conversations deleted while agent-deck wasn't running
+		if !complete {
+			break
 		}
+		for _, doc := range idx.store.liveDocs() {
+			if doc.Tool == src.Tool() && !seen[doc.Path] && underAny(doc.Path, src.Roots()) {
+				idx.store.removeDoc(doc.Path)
+			}
+		}
+	}
 
-		// Check cancellation
-		select {
-		case <-idx.ctx.Done():
-			return filepath.SkipAll
-		default:
+	idx.save()
+}
+
+// underAny reports whether path is inside one of dirs
+func underAny(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
 		}
+	}
+	return false
+}
 
-		// Rate limit
-		_ = idx.limiter.Wait(idx.ctx)
+// upToDate reports whether a transcript is indexed as it is on disk
+func upToDate(doc *indexedDoc, info os.FileInfo) bool {
+	return doc.LastSize == info.Size() && doc.LastMod.Equal(info.ModTime())
+}
 
-		info, err := d.Info()
-		if err != nil {
-			return nil
+// save flushes buffered messages and merges segments when needed
+func (idx *GlobalSearchIndex) save() {
+	if err := idx.store.flush(); err != nil {
+		log.Printf("GlobalSearch: failed to save search index: %v", err)
+		return
+	}
+	if idx.store.needsMerge() {
+		if err := idx.store.merge(); err != nil {
+			log.Printf("GlobalSearch: failed to merge search index: %v", err)
 		}
+	}
+}
 
-		// Check recency
-		if !cutoff.IsZero() && info.ModTime().Before(cutoff) {
-			return nil
+// indexFile indexes the messages of a transcript not indexed yet. Appended
+// lines of incremental sources are read from the last indexed offset; other
+// transcripts, and files that shrank, are reindexed from the start.
+func (idx *GlobalSearchIndex) indexFile(src transcriptSource, path string, info os.FileInfo) {
+	idx.indexMu.Lock()
+	defer idx.indexMu.Unlock()
+
+	doc := idx.store.doc(path)
+	var start int64
+	if doc != nil && src.Incremental() && info.Size() >= doc.LastOffset {
+		start = doc.LastOffset
+	} else {
+		doc = nil
+	}
+
+	tr, err := src.Read(path, start, info.Size())
+	if err != nil {
+		return
+	}
+	if tr.SessionID == "" && doc == nil {
+		return // Not a conversation (yet)
+	}
+
+	for i := range tr.Messages {
+		if tr.Messages[i].time.IsZero() {
+			tr.Messages[i].time = info.ModTime()
 		}
+	}
+	update := indexedDoc{
+		FileTracker: FileTracker{Path: path, LastOffset: tr.Offset, LastSize: info.Size(), LastMod: info.ModTime()},
+		Tool:        src.Tool(),
+		SessionID:   tr.SessionID,
+		CWD:         tr.CWD,
+		Summary:     tr.Summary,
+	}
+	idx.store.addMessages(doc, update, tr.Messages)
+	idx.cache.remove(path)
 
-		// Only UUID-named files (skip agent-*.jsonl)
-		baseName := filepath.Base(path)
-		if !isUUIDFileName(baseName) {
-			return nil
+	if idx.store.pending() >= maxPendingMessages {
+		idx.save()
+	}
+}
+
+// source returns the source a file belongs to (nil if none)
+func (idx *GlobalSearchIndex) source(path string) transcriptSource {
+	for _, src := range idx.sources {
+		if underAny(path, src.Roots()) && src.Matches(path) {
+			return src
 		}
+	}
+	return nil
+}
 
-		// Parse file
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return nil
+// toolSource returns the source of a tool's conversations (nil if none)
+func (idx *GlobalSearchIndex) toolSource(tool string) transcriptSource {
+	for _, src := range idx.sources {
+		if src.Tool() == tool {
+			return src
 		}
+	}
+	return nil
+}
 
-		entry, err := parseClaudeJSONL(path, data)
-		if err != nil || entry.SessionID == "" {
-			return nil
+// messageText reads back an indexed message, to check phrases
+func (idx *GlobalSearchIndex) messageText(doc *indexedDoc, ref messageRef) (string, bool) {
+	src := idx.toolSource(doc.Tool)
+	if src == nil {
+		return "", false
+	}
+	return src.MessageText(doc.Path, ref)
+}
+
+// knownProjects returns the project directories of indexed conversations
+func (idx *GlobalSearchIndex) knownProjects() []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		seen[home] = true
+		dirs = append(dirs, home)
+	}
+	for _, doc := range idx.store.liveDocs() {
+		if doc.CWD != "" && !seen[doc.CWD] {
+			seen[doc.CWD] = true
+			dirs = append(dirs, doc.CWD)
 		}
+	}
+	return dirs
+}
+
+// transcriptSource finds and reads one tool's conversations. Append-only
+// transcripts (JSONL) are read incrementally from the last indexed offset;
+// others are reread whole when they change.
+type transcriptSource interface {
+	// Tool is the tool that writes the conversations
+	Tool() string
+
+	// Roots are the directories walked and watched for conversations
+	Roots() []string
+
+	// Matches reports whether a file under a root is a conversation
+	Matches(path string) bool
+
+	// Incremental reports whether conversations only grow by appended lines
+	Incremental() bool
+
+	// Read reads a conversation from start (always 0 unless Incremental) up
+	// to size
+	Read(path string, start, size int64) (*transcriptRead, error)
+
+	// MessageText reads back the text of a message Read returned
+	MessageText(path string, ref messageRef) (string, bool)
 
-		entry.ModTime = info.ModTime()
-		entry.FileSize = info.Size()
-		entries = append(entries, *entry)
+	// Load reads a whole conversation for display
+	Load(path string) (*SearchEntry, error)
+}
+
+// transcriptRead is what reading (part of) a conversation found
+type transcriptRead struct {
+	SessionID string
+	CWD       string
+	Summary   string
+	Messages  []transcriptMessage
+	Offset    int64 // Where the next incremental read starts
+}
 
-		// Track file for incremental updates
-		idx.trackerMu.Lock()
-		idx.fileTrackers[path] = &FileTracker{
-			Path:       path,
-			LastOffset: info.Size(),
-			LastSize:   info.Size(),
-			LastMod:    info.ModTime(),
+// transcriptMessage is a message read from a transcript
+type transcriptMessage struct {
+	role   uint8
+	time   time.Time
+	offset int64 // Offset of the line in a JSONL transcript, else the message number
+	length int   // Length of the line
+	text   string
+}
+
+// summarize cuts a first user message down to a summary
+func summarize(text string) string {
+	if len(text) > 200 {
+		return text[:200] + "..."
+	}
+	return text
+}
+
+// loadTranscript reads a whole conversation for display, with each message
+// prefixed by its role like parseClaudeJSONL does
+func loadTranscript(src transcriptSource, path string) (*SearchEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := src.Read(path, 0, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	var content strings.Builder
+	for _, m := range tr.Messages {
+		switch m.role {
+		case roleUser:
+			content.WriteString("User: ")
+		case roleAssistant:
+			content.WriteString("Assistant: ")
 		}
-		idx.trackerMu.Unlock()
+		content.WriteString(m.text)
+		content.WriteString("\n")
+	}
+	return &SearchEntry{
+		SessionID:    tr.SessionID,
+		FilePath:     path,
+		CWD:          tr.CWD,
+		Content:      content.String(),
+		ContentLower: strings.ToLower(content.String()),
+		Summary:      tr.Summary,
+	}, nil
+}
 
-		return nil
-	})
+// readJSONL reads the complete lines of a JSONL transcript from start up to
+// size. parse handles one line and reports whether it was valid; an invalid
+// last line without a newline is left for when it has been written out.
+func readJSONL(path string, start, size int64, parse func(line []byte, offset int64, tr *transcriptRead) bool) (*transcriptRead, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.NewSectionReader(f, start, size-start))
+	if err != nil {
+		return nil, err
+	}
 
-	// Store entries and mark loading complete
-	idx.entries.Store(&entries)
-	idx.loading.Store(false)
+	tr := &transcriptRead{Offset: start}
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		line := data
+		if end >= 0 {
+			line = data[:end]
+		}
+		ok := parse(bytes.TrimSpace(line), tr.Offset, tr)
+		if end < 0 && !ok {
+			break // Partially written last line
+		}
+		if end >= 0 {
+			data = data[end+1:]
+			tr.Offset += int64(end + 1)
+		} else {
+			data = nil
+			tr.Offset += int64(len(line))
+		}
+	}
+	return tr, nil
+}
+
+// readJSONLMessage reads back the message on one line of a JSONL transcript
+func readJSONLMessage(path string, ref messageRef, parse func(line []byte, offset int64, tr *transcriptRead) bool) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	line := make([]byte, ref.length)
+	if _, err := f.ReadAt(line, ref.offset); err != nil {
+		return "", false
+	}
+	var tr transcriptRead
+	if !parse(bytes.TrimSpace(line), ref.offset, &tr) || len(tr.Messages) == 0 {
+		return "", false
+	}
+	return tr.Messages[0].text, true
+}
+
+// claudeSource reads Claude's transcripts:
+// <config dir>/projects/<project>/<session uuid>.jsonl
+type claudeSource struct {
+	projectsDir string
+}
+
+func (s claudeSource) Tool() string      { return "claude" }
+func (s claudeSource) Roots() []string   { return []string{s.projectsDir} }
+func (s claudeSource) Incremental() bool { return true }
+
+// Matches skips agent-*.jsonl (subagent transcripts)
+func (s claudeSource) Matches(path string) bool {
+	return isUUIDFileName(filepath.Base(path))
+}
+
+func (s claudeSource) Read(path string, start, size int64) (*transcriptRead, error) {
+	return readJSONL(path, start, size, parseClaudeTranscriptLine)
+}
+
+func (s claudeSource) MessageText(path string, ref messageRef) (string, bool) {
+	return readJSONLMessage(path, ref, parseClaudeTranscriptLine)
+}
+
+func (s claudeSource) Load(path string) (*SearchEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseClaudeJSONL(path, data)
+}
+
+// parseClaudeTranscriptLine adds a line of a Claude transcript to tr
+func parseClaudeTranscriptLine(line []byte, offset int64, tr *transcriptRead) bool {
+	record, msg, ok := parseClaudeLine(line, offset)
+	if !ok {
+		return false
+	}
+	if tr.SessionID == "" {
+		tr.SessionID = record.SessionID
+	}
+	if tr.CWD == "" {
+		tr.CWD = record.CWD
+	}
+	if tr.Summary == "" {
+		tr.Summary = recordSummary(record)
+	}
+	if msg.text != "" {
+		tr.Messages = append(tr.Messages, msg)
+	}
+	return true
+}
+
+// parseClaudeLine parses one JSONL line. ok is false for lines that aren't
+// JSON; the message text is empty for records without one.
+func parseClaudeLine(line []byte, offset int64) (record claudeJSONLRecord, m transcriptMessage, ok bool) {
+	record, msg, ok := decodeClaudeLine(line)
+	if !ok {
+		return record, m, false
+	}
+	m.offset, m.length = offset, len(line)
+	if t, err := time.Parse(time.RFC3339Nano, record.Timestamp); err == nil {
+		m.time = t
+	}
+	if msg == nil {
+		return record, m, true
+	}
+	switch msg.Role {
+	case "user":
+		m.role = roleUser
+	case "assistant":
+		m.role = roleAssistant
+	}
+	m.text, _ = msg.content()
+	return record, m, true
+}
+
+// recordSummary returns the summary a record provides: its summary field, or
+// the text of a user message
+func recordSummary(record claudeJSONLRecord) string {
+	if record.Summary != "" {
+		return record.Summary
+	}
+	if record.Type != "user" || len(record.Message) == 0 {
+		return ""
+	}
+	var msg claudeMessage
+	if json.Unmarshal(record.Message, &msg) != nil {
+		return ""
+	}
+	// Only typed prompts: tool results come as block lists
+	text, blocks := msg.content()
+	if blocks != nil {
+		return ""
+	}
+	return summarize(text)
 }
 
 // isUUIDFileName checks if filename matches UUID pattern
@@ -532,6 +969,304 @@ func isUUIDFileName(name string) bool {
 	return uuidFilePattern.MatchString(name)
 }
 
+// codexSource reads Codex rollouts:
+// $CODEX_HOME/sessions/YYYY/MM/DD/rollout-<timestamp>-<uuid>.jsonl
+type codexSource struct {
+	sessionsDir string
+}
+
+func (s codexSource) Tool() string      { return "codex" }
+func (s codexSource) Roots() []string   { return []string{s.sessionsDir} }
+func (s codexSource) Incremental() bool { return true }
+
+func (s codexSource) Matches(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, "rollout-") && strings.HasSuffix(name, ".jsonl")
+}
+
+func (s codexSource) Read(path string, start, size int64) (*transcriptRead, error) {
+	return readJSONL(path, start, size, parseCodexTranscriptLine)
+}
+
+func (s codexSource) MessageText(path string, ref messageRef) (string, bool) {
+	return readJSONLMessage(path, ref, parseCodexTranscriptLine)
+}
+
+func (s codexSource) Load(path string) (*SearchEntry, error) {
+	return loadTranscript(s, path)
+}
+
+// parseCodexTranscriptLine adds a line of a rollout (current or legacy
+// format) to tr. The context Codex injects as user messages is skipped.
+func parseCodexTranscriptLine(line []byte, offset int64, tr *transcriptRead) bool {
+	var record codexRecord
+	if len(line) == 0 || json.Unmarshal(line, &record) != nil {
+		return false
+	}
+
+	var msg codexMessage
+	switch {
+	case record.Type == "session_meta":
+		var meta struct {
+			ID  string `json:"id"`
+			Cwd string `json:"cwd"`
+		}
+		if json.Unmarshal(record.Payload, &meta) == nil && tr.SessionID == "" {
+			tr.SessionID, tr.CWD = meta.ID, meta.Cwd
+		}
+		return true
+	case record.Type == "response_item":
+		if json.Unmarshal(record.Payload, &msg) != nil {
+			return true
+		}
+	case offset == 0 && record.ID != "":
+		tr.SessionID = record.ID // Legacy metadata line
+		return true
+	case record.Type == "message":
+		// Legacy items aren't wrapped in a payload
+		if json.Unmarshal(line, &msg) != nil {
+			return true
+		}
+	}
+	if msg.Type != "message" {
+		return true
+	}
+
+	var parts []string
+	for _, block := range msg.Content {
+		if (block.Type == "input_text" || block.Type == "output_text") && block.Text != "" {
+			parts = append(parts, block.Text)
+		}
+	}
+	text := strings.TrimSpace(strings.Join(parts, "\n"))
+	if text == "" || strings.HasPrefix(text, "<environment_context>") || strings.HasPrefix(text, "<user_instructions>") {
+		return true
+	}
+
+	m := transcriptMessage{offset: offset, length: len(line), text: text}
+	m.time, _ = time.Parse(time.RFC3339Nano, record.Timestamp)
+	switch msg.Role {
+	case "user":
+		m.role = roleUser
+		if tr.Summary == "" {
+			tr.Summary = summarize(text)
+		}
+	case "assistant":
+		m.role = roleAssistant
+	}
+	tr.Messages = append(tr.Messages, m)
+	return true
+}
+
+// openCodeSource reads OpenCode sessions. The session file
+// (storage/session/<project>/<session_id>.json) is updated with every
+// message; the messages are separate files (see listOpenCodeMessages).
+type openCodeSource struct {
+	storageDir string
+}
+
+func (s openCodeSource) Tool() string      { return "opencode" }
+func (s openCodeSource) Roots() []string   { return []string{filepath.Join(s.storageDir, "session")} }
+func (s openCodeSource) Incremental() bool { return false }
+
+func (s openCodeSource) Matches(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, "ses_") && strings.HasSuffix(name, ".json")
+}
+
+// Read skips child sessions: their messages belong to the parent's task
+func (s openCodeSource) Read(path string, start, size int64) (*transcriptRead, error) {
+	info, err := parseOpenCodeSessionFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tr := &transcriptRead{SessionID: info.SessionID, CWD: info.Directory, Summary: info.Title, Offset: size}
+	for n, msg := range listOpenCodeMessages(s.storageDir, info.SessionID) {
+		text := openCodeMessageText(s.storageDir, msg.ID)
+		if text == "" {
+			continue
+		}
+		m := transcriptMessage{offset: int64(n), text: text}
+		if msg.Time.Created > 0 {
+			m.time = time.UnixMilli(msg.Time.Created)
+		}
+		switch msg.Role {
+		case "user":
+			m.role = roleUser
+		case "assistant":
+			m.role = roleAssistant
+		}
+		tr.Messages = append(tr.Messages, m)
+	}
+	return tr, nil
+}
+
+func (s openCodeSource) MessageText(path string, ref messageRef) (string, bool) {
+	sessionID := strings.TrimSuffix(filepath.Base(path), ".json")
+	messages := listOpenCodeMessages(s.storageDir, sessionID)
+	if ref.offset < 0 || ref.offset >= int64(len(messages)) {
+		return "", false
+	}
+	return openCodeMessageText(s.storageDir, messages[ref.offset].ID), true
+}
+
+func (s openCodeSource) Load(path string) (*SearchEntry, error) {
+	return loadTranscript(s, path)
+}
+
+// geminiSource reads Gemini CLI sessions, which are rewritten whole:
+// ~/.gemini/tmp/<project hash>/chats/session-<time>-<id>.json. Sessions only
+// record the hash of their project directory, so the directory is looked up
+// among the projects of other conversations.
+type geminiSource struct {
+	tmpDir   string
+	projects func() []string
+
+	mu     sync.Mutex
+	hashes map[string]string // Project hash -> directory
+	hashed map[string]bool   // Directories hashed so far
+}
+
+func (s *geminiSource) Tool() string      { return "gemini" }
+func (s *geminiSource) Roots() []string   { return []string{s.tmpDir} }
+func (s *geminiSource) Incremental() bool { return false }
+
+func (s *geminiSource) Matches(path string) bool {
+	name := filepath.Base(path)
+	return filepath.Base(filepath.Dir(path)) == "chats" &&
+		strings.HasPrefix(name, "session-") && strings.HasSuffix(name, ".json")
+}
+
+// geminiSessionFile is a Gemini session file, as read by the search and the
+// transcript view
+type geminiSessionFile struct {
+	SessionID   string          `json:"sessionId"`
+	ProjectHash string          `json:"projectHash"`
+	Messages    []geminiMessage `json:"messages"`
+}
+
+// geminiMessage is one message of a Gemini session file
+type geminiMessage struct {
+	Type      string           `json:"type"` // "user" or "gemini" (also "info", "error")
+	Content   json.RawMessage  `json:"content"`
+	Timestamp string           `json:"timestamp"`
+	ToolCalls []geminiToolCall `json:"toolCalls"`
+}
+
+// geminiToolCall is a tool Gemini called while answering
+type geminiToolCall struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Args          json.RawMessage `json:"args"`
+	Status        string          `json:"status"`
+	ResultDisplay json.RawMessage `json:"resultDisplay"` // A string, or an object for diffs
+}
+
+func readGeminiSessionFile(path string) (*geminiSessionFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeGeminiSessionFile(data)
+}
+
+func decodeGeminiSessionFile(data []byte) (*geminiSessionFile, error) {
+	var session geminiSessionFile
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+	return &session, nil
+}
+
+// geminiContentText returns a message's text: a string, or a list of parts
+func geminiContentText(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(raw, &parts) != nil {
+		return ""
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (s *geminiSource) Read(path string, start, size int64) (*transcriptRead, error) {
+	session, err := readGeminiSessionFile(path)
+	if err != nil {
+		return nil, err
+	}
+	hash := session.ProjectHash
+	if hash == "" {
+		hash = filepath.Base(filepath.Dir(filepath.Dir(path)))
+	}
+
+	tr := &transcriptRead{SessionID: session.SessionID, CWD: s.projectDir(hash), Offset: size}
+	for n, msg := range session.Messages {
+		m := transcriptMessage{offset: int64(n), text: geminiContentText(msg.Content)}
+		switch msg.Type {
+		case "user":
+			m.role = roleUser
+			if tr.Summary == "" {
+				tr.Summary = summarize(m.text)
+			}
+		case "gemini":
+			m.role = roleAssistant
+		default:
+			continue
+		}
+		if m.text == "" {
+			continue
+		}
+		m.time, _ = time.Parse(time.RFC3339Nano, msg.Timestamp)
+		tr.Messages = append(tr.Messages, m)
+	}
+	return tr, nil
+}
+
+func (s *geminiSource) MessageText(path string, ref messageRef) (string, bool) {
+	session, err := readGeminiSessionFile(path)
+	if err != nil || ref.offset < 0 || ref.offset >= int64(len(session.Messages)) {
+		return "", false
+	}
+	return geminiContentText(session.Messages[ref.offset].Content), true
+}
+
+func (s *geminiSource) Load(path string) (*SearchEntry, error) {
+	return loadTranscript(s, path)
+}
+
+// projectDir returns the known project directory with the given hash, or ""
+func (s *geminiSource) projectDir(hash string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.hashes == nil {
+		s.hashes = make(map[string]string)
+		s.hashed = make(map[string]bool)
+	}
+	if dir, ok := s.hashes[hash]; ok || s.projects == nil {
+		return dir
+	}
+	for _, dir := range s.projects() {
+		if s.hashed[dir] {
+			continue
+		}
+		s.hashed[dir] = true
+		if h := HashProjectPath(dir); h != "" {
+			s.hashes[h] = dir
+		}
+	}
+	return s.hashes[hash]
+}
+
 // watcherLoop handles file system events
 func (idx *GlobalSearchIndex) watcherLoop() {
 	defer idx.wg.Done()
@@ -540,35 +1275,57 @@ func (idx *GlobalSearchIndex) watcherLoop() {
 	debounce := make(map[string]*time.Timer)
 	debounceMu := sync.Mutex{}
 
+	// Debounce: wait 300ms after last event for a file
+	schedule := func(path string) {
+		debounceMu.Lock()
+		defer debounceMu.Unlock()
+		if timer, exists := debounce[path]; exists {
+			timer.Stop()
+		}
+		debounce[path] = time.AfterFunc(300*time.Millisecond, func() {
+			idx.updateFile(path)
+			debounceMu.Lock()
+			delete(debounce, path)
+			debounceMu.Unlock()
+		})
+	}
+
 	for {
 		select {
 		case <-idx.ctx.Done():
+			debounceMu.Lock()
+			for _, timer := range debounce {
+				timer.Stop()
+			}
+			debounceMu.Unlock()
 			return
 		case event, ok := <-idx.watcher.Events:
 			if !ok {
 				return
 			}
 
-			// Only care about writes and creates for .jsonl files
-			if !strings.HasSuffix(event.Name, ".jsonl") {
-				continue
+			// Watch new directories, and index what was written in them
+			// before the watch started
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					_ = idx.watcher.Add(event.Name)
+					idx.watchSubdirs(event.Name, func(path string) {
+						if idx.source(path) != nil {
+							schedule(path)
+						}
+					})
+					continue
+				}
 			}
-			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+
+			// Only care about changes to conversations
+			if idx.source(event.Name) == nil {
 				continue
 			}
-
-			// Debounce: wait 300ms after last event for this file
-			debounceMu.Lock()
-			if timer, exists := debounce[event.Name]; exists {
-				timer.Stop()
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
 			}
-			debounce[event.Name] = time.AfterFunc(300*time.Millisecond, func() {
-				idx.updateFile(event.Name)
-				debounceMu.Lock()
-				delete(debounce, event.Name)
-				debounceMu.Unlock()
-			})
-			debounceMu.Unlock()
+			schedule(event.Name)
 
 		case err, ok := <-idx.watcher.Errors:
 			if !ok {
@@ -579,163 +1336,125 @@ func (idx *GlobalSearchIndex) watcherLoop() {
 	}
 }
 
-// updateFile handles incremental update for a single file
+// updateFile brings the index up to date with a changed conversation
 func (idx *GlobalSearchIndex) updateFile(path string) {
-	if !isUUIDFileName(filepath.Base(path)) {
+	src := idx.source(path)
+	if src == nil || idx.ctx.Err() != nil {
 		return
 	}
 
 	info, err := os.Stat(path)
 	if err != nil {
-		return // File deleted, ignore for now
+		idx.store.removeDoc(path)
+		idx.cache.remove(path)
+		return
 	}
-
-	idx.trackerMu.RLock()
-	tracker, exists := idx.fileTrackers[path]
-	idx.trackerMu.RUnlock()
-
-	if exists && info.Size() < tracker.LastSize {
-		// File was truncated/replaced, do full reload of this file
-		tracker = nil
+	if doc := idx.store.doc(path); doc != nil && upToDate(doc, info) {
+		return
 	}
+	idx.indexFile(src, path, info)
+}
 
-	// Read file (or just new portion for append-only)
-	var data []byte
-	if tracker != nil && info.Size() > tracker.LastOffset {
-		// Incremental read
-		f, err := os.Open(path)
-		if err != nil {
-			return
-		}
-		defer f.Close()
-		_, _ = f.Seek(tracker.LastOffset, 0)
-		data, _ = io.ReadAll(f)
-	} else {
-		// Full read
-		data, _ = os.ReadFile(path)
+// Search finds the conversations matching a query (see ParseSearchQuery),
+// best first
+func (idx *GlobalSearchIndex) Search(query string) []*SearchResult {
+	q := ParseSearchQuery(query)
+	if q.IsEmpty() {
+		return nil
 	}
-
-	if len(data) == 0 {
-		return
+	if idx.config.RecentDays > 0 && q.After.IsZero() {
+		q.After = time.Now().AddDate(0, 0, -idx.config.RecentDays)
 	}
 
-	// Parse and update
-	entry, err := parseClaudeJSONL(path, data)
-	if err != nil || entry.SessionID == "" {
-		return
-	}
-	entry.ModTime = info.ModTime()
-	entry.FileSize = info.Size()
+	var results []*SearchResult
+	for _, hit := range idx.store.search(q, SearchResultLimit) {
+		entry := idx.loadEntry(hit.doc)
+		if entry == nil {
+			continue
+		}
 
-	// Update entries atomically
-	oldEntries := idx.entries.Load()
-	newEntries := make([]SearchEntry, 0, len(*oldEntries)+1)
-
-	found := false
-	for _, e := range *oldEntries {
-		if e.FilePath == path {
-			// Merge content for incremental update
-			if tracker != nil {
-				entry.Content = e.Content + entry.Content
-				entry.ContentLower = strings.ToLower(entry.Content)
+		// Matches of every word and phrase, the snippet around the first
+		var matches []MatchRange
+		snippetText := ""
+		for _, clause := range q.Clauses {
+			clauseMatches := entry.Match(clause.Text)
+			if snippetText == "" && len(clauseMatches) > 0 {
+				snippetText = clause.Text
 			}
-			newEntries = append(newEntries, *entry)
-			found = true
-		} else {
-			newEntries = append(newEntries, e)
+			matches = append(matches, clauseMatches...)
 		}
-	}
-	if !found {
-		newEntries = append(newEntries, *entry)
-	}
+		sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
 
-	idx.entries.Store(&newEntries)
-
-	// Update tracker
-	idx.trackerMu.Lock()
-	idx.fileTrackers[path] = &FileTracker{
-		Path:       path,
-		LastOffset: info.Size(),
-		LastSize:   info.Size(),
-		LastMod:    info.ModTime(),
+		results = append(results, &SearchResult{
+			Entry:   entry,
+			Matches: matches,
+			Score:   hit.hits * 10,
+			Snippet: entry.GetSnippet(snippetText, 60),
+		})
 	}
-	idx.trackerMu.Unlock()
+	return results
 }
 
-// Search performs a simple substring search
-func (idx *GlobalSearchIndex) Search(query string) []*SearchResult {
-	if query == "" {
+// loadEntry reads an indexed conversation for display
+func (idx *GlobalSearchIndex) loadEntry(doc indexedDoc) *SearchEntry {
+	src := idx.toolSource(doc.Tool)
+	if src == nil {
 		return nil
 	}
-
-	entries := idx.entries.Load()
-	if entries == nil {
+	info, err := os.Stat(doc.Path)
+	if err != nil {
 		return nil
 	}
-
-	queryLower := strings.ToLower(query)
-	var results []*SearchResult
-
-	for i := range *entries {
-		entry := &(*entries)[i]
-		if strings.Contains(entry.ContentLower, queryLower) {
-			matches := entry.Match(query)
-			results = append(results, &SearchResult{
-				Entry:   entry,
-				Matches: matches,
-				Score:   len(matches) * 10,
-				Snippet: entry.GetSnippet(query, 60),
-			})
-		}
+	if entry := idx.cache.get(doc.Path, info); entry != nil {
+		return entry
 	}
 
-	// Sort by score (more matches = higher score) - O(n log n)
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
-
-	return results
+	entry, err := src.Load(doc.Path)
+	if err != nil {
+		return nil
+	}
+	entry.Tool = doc.Tool
+	if entry.SessionID == "" {
+		entry.SessionID = doc.SessionID
+	}
+	if entry.CWD == "" {
+		entry.CWD = doc.CWD
+	}
+	entry.ModTime = info.ModTime()
+	entry.FileSize = info.Size()
+	idx.cache.add(entry)
+	return entry
 }
 
-// fuzzySearchSource implements fuzzy.Source for our entries
-type fuzzySearchSource struct {
-	entries *[]SearchEntry
-}
+// fuzzySearchSource implements fuzzy.Source over conversation summaries
+type fuzzySearchSource []indexedDoc
 
 func (s fuzzySearchSource) String(i int) string {
-	entry := &(*s.entries)[i]
-	// Use summary + first part of content for fuzzy matching
-	contentPreview := entry.Content
-	if len(contentPreview) > 500 {
-		contentPreview = contentPreview[:500]
-	}
-	return entry.Summary + " " + contentPreview
+	return s[i].Summary
 }
 
 func (s fuzzySearchSource) Len() int {
-	return len(*s.entries)
+	return len(s)
 }
 
-// FuzzySearch performs fuzzy matching with typo tolerance
+// FuzzySearch matches conversation summaries with typo tolerance
 func (idx *GlobalSearchIndex) FuzzySearch(query string) []*SearchResult {
 	if query == "" {
 		return nil
 	}
 
-	entries := idx.entries.Load()
-	if entries == nil {
-		return nil
-	}
-
-	// Create fuzzy source
-	source := fuzzySearchSource{entries: entries}
-
-	// Fuzzy match
+	source := fuzzySearchSource(idx.store.liveDocs())
 	matches := fuzzy.FindFrom(query, source)
 
 	var results []*SearchResult
 	for _, match := range matches {
-		entry := &(*entries)[match.Index]
+		if len(results) == SearchResultLimit {
+			break
+		}
+		entry := idx.loadEntry(source[match.Index])
+		if entry == nil {
+			continue
+		}
 		results = append(results, &SearchResult{
 			Entry:   entry,
 			Score:   match.Score,
@@ -751,25 +1470,88 @@ func (idx *GlobalSearchIndex) GetTier() SearchTier {
 	return idx.tier
 }
 
-// EntryCount returns the number of indexed entries
+// EntryCount returns the number of indexed conversations
 func (idx *GlobalSearchIndex) EntryCount() int {
-	entries := idx.entries.Load()
-	if entries == nil {
-		return 0
-	}
-	return len(*entries)
+	return idx.store.docCount()
 }
 
-// IsLoading returns true if the index is still loading
+// IsLoading returns true while the index is catching up with the transcripts
 func (idx *GlobalSearchIndex) IsLoading() bool {
 	return idx.loading.Load()
 }
 
-// Close shuts down the index
+// Close shuts down the index, saving what was indexed
 func (idx *GlobalSearchIndex) Close() {
 	idx.cancel()
 	if idx.watcher != nil {
 		idx.watcher.Close()
 	}
 	idx.wg.Wait()
+	idx.indexMu.Lock()
+	idx.store.close()
+	idx.indexMu.Unlock()
+}
+
+// contentCache keeps recently shown transcripts in memory, up to a total
+// size
+type contentCache struct {
+	mu      sync.Mutex
+	limit   int64
+	size    int64
+	order   *list.List // Most recently used first
+	entries map[string]*list.Element
+}
+
+func newContentCache(limit int64) *contentCache {
+	return &contentCache{limit: limit, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// get returns the cached transcript if the file hasn't changed since
+func (c *contentCache) get(path string, info os.FileInfo) *SearchEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[path]
+	if !ok {
+		return nil
+	}
+	entry := el.Value.(*SearchEntry)
+	if entry.FileSize != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+		c.removeElement(el)
+		return nil
+	}
+	c.order.MoveToFront(el)
+	return entry
+}
+
+func (c *contentCache) add(entry *SearchEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[entry.FilePath]; ok {
+		c.removeElement(el)
+	}
+	c.entries[entry.FilePath] = c.order.PushFront(entry)
+	c.size += entrySize(entry)
+	// Evict down to the limit, always keeping the newest entry
+	for c.size > c.limit && c.order.Len() > 1 {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *contentCache) remove(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[path]; ok {
+		c.removeElement(el)
+	}
+}
+
+func (c *contentCache) removeElement(el *list.Element) {
+	entry := c.order.Remove(el).(*SearchEntry)
+	delete(c.entries, entry.FilePath)
+	c.size -= entrySize(entry)
+}
+
+// entrySize approximates the memory a loaded transcript uses
+func entrySize(entry *SearchEntry) int64 {
+	return int64(len(entry.Content) + len(entry.ContentLower) + len(entry.Summary))
 }
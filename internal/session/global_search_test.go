@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -156,6 +157,7 @@ func TestTierName(t *testing.T) {
 }
 
 func TestGlobalSearchIndexInstantTier(t *testing.T) {
+	useConfigHome(t) // The index is stored under ~/.agent-deck
 	// Create temp directory with test JSONL files
 	tmpDir := t.TempDir()
 	projectDir := filepath.Join(tmpDir, "projects", "-Users-test-project")
@@ -219,6 +221,7 @@ func TestGlobalSearchIndexInstantTier(t *testing.T) {
 }
 
 func TestGlobalSearchIndexFuzzyMatch(t *testing.T) {
+	useConfigHome(t)
 	tmpDir := t.TempDir()
 	projectDir := filepath.Join(tmpDir, "projects", "-Users-test-project")
 	_ = os.MkdirAll(projectDir, 0755)
@@ -253,6 +256,7 @@ func TestGlobalSearchIndexDisabled(t *testing.T) {
 }
 
 func TestGlobalSearchIndexEmptyQuery(t *testing.T) {
+	useConfigHome(t)
 	tmpDir := t.TempDir()
 	projectDir := filepath.Join(tmpDir, "projects", "-Users-test-project")
 	_ = os.MkdirAll(projectDir, 0755)
@@ -271,6 +275,7 @@ func TestGlobalSearchIndexEmptyQuery(t *testing.T) {
 }
 
 func TestGlobalSearchIndexBalancedTier(t *testing.T) {
+	useConfigHome(t)
 	tmpDir := t.TempDir()
 	projectDir := filepath.Join(tmpDir, "projects", "-Users-test-project")
 	_ = os.MkdirAll(projectDir, 0755)
@@ -316,6 +321,7 @@ func TestGlobalSearchIndexBalancedTier(t *testing.T) {
 }
 
 func TestGlobalSearchIndexTierAutoDetect(t *testing.T) {
+	useConfigHome(t)
 	tmpDir := t.TempDir()
 	projectDir := filepath.Join(tmpDir, "projects", "-Users-test-project")
 	_ = os.MkdirAll(projectDir, 0755)
@@ -339,3 +345,298 @@ func TestGlobalSearchIndexTierAutoDetect(t *testing.T) {
 		t.Errorf("Expected instant tier for small data, got %v", TierName(index.GetTier()))
 	}
 }
+
+func TestParseSearchQuery(t *testing.T) {
+	q := ParseSearchQuery(`"React Hooks" user:migrate project:Agent-Deck tool:claude after:2025-01-02 before:7d http://x`)
+
+	if len(q.Clauses) != 3 {
+		t.Fatalf("Expected 3 clauses, got %+v", q.Clauses)
+	}
+	if c := q.Clauses[0]; c.Text != "react hooks" || !c.Phrase || c.Role != "" {
+		t.Errorf("Unexpected phrase clause: %+v", c)
+	}
+	if c := q.Clauses[1]; c.Text != "migrate" || c.Phrase || c.Role != "user" {
+		t.Errorf("Unexpected role clause: %+v", c)
+	}
+	if c := q.Clauses[2]; c.Text != "http://x" {
+		t.Errorf("Unknown keys should be searched as text, got %+v", c)
+	}
+	if len(q.Projects) != 1 || q.Projects[0] != "agent-deck" {
+		t.Errorf("Projects = %v", q.Projects)
+	}
+	if len(q.Tools) != 1 || q.Tools[0] != "claude" {
+		t.Errorf("Tools = %v", q.Tools)
+	}
+	if q.After.Format("2006-01-02") != "2025-01-02" {
+		t.Errorf("After = %v", q.After)
+	}
+	if time.Since(q.Before) < 6*24*time.Hour {
+		t.Errorf("Before = %v, want a week ago", q.Before)
+	}
+	if q.HighlightText() != "react hooks migrate http://x" {
+		t.Errorf("HighlightText = %q", q.HighlightText())
+	}
+
+	if q := ParseSearchQuery("after:yesterday"); len(q.Clauses) != 1 || !q.After.IsZero() {
+		t.Errorf("Invalid dates should be searched as text, got %+v", q)
+	}
+	if q := ParseSearchQuery("  "); !q.IsEmpty() {
+		t.Error("Blank query should be empty")
+	}
+}
+
+// writeTranscript writes a Claude transcript with one line per message
+func writeTranscript(t *testing.T, dir, sessionID, cwd string, messages ...string) string {
+	t.Helper()
+	var sb strings.Builder
+	for i, m := range messages {
+		role, text, _ := strings.Cut(m, ": ")
+		fmt.Fprintf(&sb, `{"sessionId":%q,"type":%q,"cwd":%q,"timestamp":"2025-03-0%dT10:00:00Z","message":{"role":%q,"content":%q}}`+"\n",
+			sessionID, role, cwd, i+1, role, text)
+	}
+	path := filepath.Join(dir, sessionID+".jsonl")
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// waitForIndex waits for the index to catch up with the transcripts
+func waitForIndex(t *testing.T, index *GlobalSearchIndex) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for index.IsLoading() {
+		if time.Now().After(deadline) {
+			t.Fatal("Index did not finish loading")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGlobalSearchIndexQueries(t *testing.T) {
+	useConfigHome(t)
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "projects", "-Users-test")
+	_ = os.MkdirAll(projectDir, 0755)
+
+	writeTranscript(t, projectDir, "e5f6a7b8-c9d0-1234-ef56-000000000001", "/src/agent-deck",
+		"user: migrate the database schema",
+		"assistant: The schema migration needs a new column")
+	writeTranscript(t, projectDir, "e5f6a7b8-c9d0-1234-ef56-000000000002", "/src/website",
+		"user: the column layout is broken",
+		"assistant: Let me migrate it to grid")
+
+	config := GlobalSearchSettings{Enabled: true, Tier: "auto", IndexRateLimit: 100}
+	index, err := NewGlobalSearchIndex(tmpDir, config)
+	if err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	defer index.Close()
+	waitForIndex(t, index)
+
+	tests := []struct {
+		query string
+		want  []string // Session ID suffixes
+	}{
+		{"migrat", []string{"1", "2"}},
+		{"column", []string{"1", "2"}},
+		{`"schema migration"`, []string{"1"}},
+		{`"migration schema"`, nil},
+		{"project:agent-deck column", []string{"1"}},
+		{"user:migrate", []string{"1"}},
+		{"assistant:migrate", []string{"2"}},
+		{"migrate column user:broken", []string{"2"}},
+		{"tool:claude project:website", []string{"2"}},
+		{"tool:gemini migrate", nil},
+		{"after:2025-03-02 user:migrate", nil},
+		{"before:2025-03-02 migrate", []string{"1"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, r := range index.Search(tt.query) {
+			got = append(got, r.Entry.SessionID[len(r.Entry.SessionID)-1:])
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	results := index.Search(`"schema migration"`)
+	if len(results) != 1 || len(results[0].Matches) != 1 || !strings.Contains(results[0].Snippet, "schema migration") {
+		t.Errorf("Phrase should be highlighted once, got %+v", results)
+	}
+}
+
+func TestGlobalSearchIndexPersists(t *testing.T) {
+	home := useConfigHome(t)
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "projects", "-Users-test")
+	_ = os.MkdirAll(projectDir, 0755)
+
+	path := writeTranscript(t, projectDir, "f6a7b8c9-d0e1-2345-f678-000000000001", "/src/api",
+		"user: add rate limiting")
+	gone := writeTranscript(t, projectDir, "f6a7b8c9-d0e1-2345-f678-000000000002", "/src/api",
+		"user: remove the cache")
+
+	config := GlobalSearchSettings{Enabled: true, Tier: "auto", IndexRateLimit: 100}
+	index, err := NewGlobalSearchIndex(tmpDir, config)
+	if err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	waitForIndex(t, index)
+	index.Close()
+
+	if _, err := os.Stat(filepath.Join(home, ".agent-deck", "search", "meta.json")); err != nil {
+		t.Fatalf("Index should be saved: %v", err)
+	}
+
+	// Changes while closed: a message appended, a transcript deleted
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(f, `{"sessionId":"f6a7b8c9-d0e1-2345-f678-000000000001","type":"assistant","message":{"role":"assistant","content":"Added a token bucket"}}`)
+	f.Close()
+	_ = os.Remove(gone)
+
+	index, err = NewGlobalSearchIndex(tmpDir, config)
+	if err != nil {
+		t.Fatalf("Failed to reopen index: %v", err)
+	}
+	defer index.Close()
+
+	// Searchable before catching up
+	if len(index.Search("rate limiting")) != 1 {
+		t.Error("Reopened index should find earlier messages immediately")
+	}
+
+	waitForIndex(t, index)
+	if index.EntryCount() != 1 {
+		t.Errorf("Expected 1 entry after deleting a transcript, got %d", index.EntryCount())
+	}
+	if len(index.Search("bucket")) != 1 {
+		t.Error("Appended message should be indexed")
+	}
+	if len(index.Search("cache")) != 0 {
+		t.Error("Deleted transcript should not be found")
+	}
+}
+
+func TestSearchStoreMerge(t *testing.T) {
+	dir := t.TempDir()
+	store, err := openSearchStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < maxSegments; i++ {
+		path := fmt.Sprintf("/transcripts/%d.jsonl", i)
+		doc := indexedDoc{FileTracker: FileTracker{Path: path}, Tool: "claude", SessionID: fmt.Sprint(i)}
+		store.addMessages(nil, doc, []transcriptMessage{
+			{role: roleUser, time: time.Now(), text: fmt.Sprintf("shared words%d", i)},
+		})
+		if err := store.flush(); err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+	}
+	store.removeDoc("/transcripts/0.jsonl")
+
+	if !store.needsMerge() {
+		t.Fatal("Expected a merge to be needed")
+	}
+	if err := store.merge(); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if len(store.segments) != 1 {
+		t.Errorf("Expected 1 segment after merge, got %d", len(store.segments))
+	}
+	store.close()
+
+	store, err = openSearchStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.close()
+	if hits := store.search(ParseSearchQuery("shared"), 0); len(hits) != maxSegments-1 {
+		t.Errorf("Expected %d hits for 'shared', got %d", maxSegments-1, len(hits))
+	}
+	if hits := store.search(ParseSearchQuery("words5"), 0); len(hits) != 1 || hits[0].doc.SessionID != "5" {
+		t.Errorf("Expected session 5 for 'words5', got %+v", hits)
+	}
+	if hits := store.search(ParseSearchQuery("words0"), 0); len(hits) != 0 {
+		t.Errorf("Removed transcript should be dropped by the merge, got %+v", hits)
+	}
+}
+
+func TestGlobalSearchIndexOtherTools(t *testing.T) {
+	home := useConfigHome(t)
+	useCodexFixtures(t)
+	useOpenCodeFixtures(t)
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "projects", "-Users-test")
+	_ = os.MkdirAll(projectDir, 0755)
+
+	// Gemini only records the hash of the project directory, which a Claude
+	// conversation in the same directory makes known
+	webapp := t.TempDir()
+	writeTranscript(t, projectDir, "a7b8c9d0-e1f2-3456-a789-000000000001", webapp,
+		"user: fix the login form")
+	chatsDir := filepath.Join(home, ".gemini", "tmp", HashProjectPath(webapp), "chats")
+	_ = os.MkdirAll(chatsDir, 0755)
+	geminiSession := fmt.Sprintf(`{"sessionId":"gemini-1","projectHash":%q,"messages":[
+		{"type":"user","content":"Add a health check endpoint","timestamp":"2025-10-16T10:00:00.000Z"},
+		{"type":"info","content":"Request cancelled","timestamp":"2025-10-16T10:00:01.000Z"},
+		{"type":"gemini","content":[{"text":"Registered the probe"}],"timestamp":"2025-10-16T10:00:02.000Z"}]}`,
+		HashProjectPath(webapp))
+	if err := os.WriteFile(filepath.Join(chatsDir, "session-2025-10-16T10-00-gemini1.json"), []byte(geminiSession), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := GlobalSearchSettings{Enabled: true, Tier: "auto", IndexRateLimit: 100}
+	index, err := NewGlobalSearchIndex(tmpDir, config)
+	if err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	defer index.Close()
+	waitForIndex(t, index)
+
+	tests := []struct {
+		query string
+		want  []string // tool:session ID
+	}{
+		{"healthz", []string{"codex:" + codexFixtureID, "opencode:" + openCodeFixtureID}},
+		{`"health check endpoint"`, []string{"codex:" + codexFixtureID, "gemini:gemini-1", "opencode:" + openCodeFixtureID}},
+		{"tool:codex health", []string{"codex:" + codexFixtureID}},
+		{"assistant:probe", []string{"gemini:gemini-1"}},
+		{"cancelled", nil},           // Gemini info messages aren't conversation
+		{"tool:codex approval", nil}, // Nor is the context Codex sends
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, r := range index.Search(tt.query) {
+			got = append(got, r.Entry.Tool+":"+r.Entry.SessionID)
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	results := index.Search("tool:gemini probe")
+	if len(results) != 1 {
+		t.Fatalf("Expected the Gemini conversation, got %d results", len(results))
+	}
+	entry := results[0].Entry
+	if entry.CWD != webapp {
+		t.Errorf("Gemini CWD = %q, want %q", entry.CWD, webapp)
+	}
+	if entry.Summary != "Add a health check endpoint" || !strings.Contains(entry.Content, "Assistant: Registered the probe") {
+		t.Errorf("Unexpected Gemini entry: summary %q, content %q", entry.Summary, entry.Content)
+	}
+
+	results = index.Search("tool:opencode healthz")
+	if len(results) != 1 || results[0].Entry.CWD != "/home/dev/webapp" || results[0].Entry.Summary != "Add a health check endpoint" {
+		t.Errorf("Unexpected OpenCode results: %+v", results)
+	}
+}
@@ -540,6 +540,29 @@ func (t *GroupTree) CreateSubgroup(parentPath, name string) *Group {
 	return group
 }
 
+// ensureGroupPath returns the group at path, creating it and any missing
+// parent groups (named after their last path segment)
+func (t *GroupTree) ensureGroupPath(path string) *Group {
+	if group, exists := t.Groups[path]; exists {
+		return group
+	}
+	if parentPath := getParentPath(path); parentPath != "" {
+		t.ensureGroupPath(parentPath)
+	}
+
+	group := &Group{
+		Name:     path[strings.LastIndex(path, "/")+1:],
+		Path:     path,
+		Expanded: true,
+		Sessions: []*Instance{},
+		Order:    len(t.GroupList),
+	}
+	t.Groups[path] = group
+	t.Expanded[path] = true
+	t.rebuildGroupList()
+	return group
+}
+
 // RenameGroup renames a group and updates all subgroups
 func (t *GroupTree) RenameGroup(oldPath, newName string) {
 	group, exists := t.Groups[oldPath]
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -28,6 +29,7 @@ const (
 	StatusIdle     Status = "idle"
 	StatusError    Status = "error"
 	StatusStarting Status = "starting" // Session is being created (tmux initializing)
+	StatusApproval Status = "approval" // Blocked on a permission prompt (see Approve/Deny)
 )
 
 // Instance represents a single agent/shell session
@@ -47,14 +49,38 @@ type Instance struct {
 	ClaudeSessionID  string    `json:"claude_session_id,omitempty"`
 	ClaudeDetectedAt time.Time `json:"claude_detected_at,omitempty"`
 
+	// Per-session Claude flags (see ClaudeOptions); nil uses the [claude] settings
+	ClaudeOptions *ClaudeOptions `json:"claude_options,omitempty"`
+
+	// Environment variables set for the session's processes (e.g. from a template)
+	Env map[string]string `json:"env,omitempty"`
+
+	// InitialPrompt is sent by `session start` when the session first starts
+	// (set by templates for sessions added without starting them)
+	InitialPrompt string `json:"initial_prompt,omitempty"`
+
 	// Gemini CLI integration
 	GeminiSessionID  string    `json:"gemini_session_id,omitempty"`
 	GeminiDetectedAt time.Time `json:"gemini_detected_at,omitempty"`
 
+	// Codex CLI integration (discovered from its rollout files)
+	CodexSessionID  string    `json:"codex_session_id,omitempty"`
+	CodexDetectedAt time.Time `json:"codex_detected_at,omitempty"`
+
+	// OpenCode integration (discovered from its storage directory)
+	OpenCodeSessionID  string    `json:"opencode_session_id,omitempty"`
+	OpenCodeDetectedAt time.Time `json:"opencode_detected_at,omitempty"`
+
 	// MCP tracking - which MCPs were loaded when session started/restarted
 	// Used to detect pending MCPs (added after session start) and stale MCPs (removed but still running)
 	LoadedMCPNames []string `json:"loaded_mcp_names,omitempty"`
 
+	// Git worktree isolation - set when the session got its own worktree
+	// (ProjectPath then points inside WorktreePath)
+	WorktreePath   string `json:"worktree_path,omitempty"`
+	WorktreeBranch string `json:"worktree_branch,omitempty"`
+	WorktreeRepo   string `json:"worktree_repo,omitempty"` // Main repository root
+
 	tmuxSession *tmux.Session // Internal tmux session
 
 	// lastErrorCheck tracks when we last confirmed the session doesn't exist
@@ -62,6 +88,22 @@ type Instance struct {
 	// Not serialized - resets on load, but that's fine since we'll recheck on first poll
 	lastErrorCheck time.Time
 
+	// statusObserved is set after the first status poll. Transitions are only
+	// reported from then on: the status loaded from disk may be hours old.
+	statusObserved bool
+
+	// lastSessionScan throttles scanning Codex/OpenCode state directories
+	// while their conversation ID is still unknown
+	lastSessionScan time.Time
+
+	// claimedElsewhere holds discovered conversation IDs that belong to
+	// another session (see UpdateClaudeSessionsWithDedup)
+	claimedElsewhere map[string]bool
+
+	// forkSeed is the parent transcript a seeded fork sends once its agent
+	// is ready (see StartFork). Not serialized - only used while starting.
+	forkSeed string
+
 	// lastStartTime tracks when Start() was called
 	// Used to provide grace period for tmux session creation (prevents error flash)
 	// Not serialized - only relevant for current TUI session
@@ -185,33 +227,26 @@ func (i *Instance) buildClaudeCommandWithMessage(baseCommand, message string) st
 
 	configDir := GetClaudeConfigDir()
 
-	// Check if dangerous mode is enabled in user config
-	dangerousMode := false
-	if userConfig, err := LoadUserConfig(); err == nil && userConfig != nil {
-		dangerousMode = userConfig.Claude.DangerousMode
+	// Per-session options and dangerous mode (session choice, else user config)
+	flags := i.claudeFlags()
+
+	// A known conversation is resumed (e.g. one picked in the global search)
+	if baseCommand == "claude" && message == "" && i.ClaudeSessionID != "" {
+		return i.buildClaudeResumeCommand()
 	}
 
 	// If baseCommand is just "claude", build the capture-resume command
 	// This command:
 	// 1. Starts Claude in print mode to get session ID
 	// 2. Stores session ID in tmux environment (for retrieval by agent-deck)
-	// 3. Resumes that session interactively (with dangerous mode if enabled)
+	// 3. Resumes that session interactively (with the session's flags)
 	// 4. Optionally waits for prompt and sends initial message
 	if baseCommand == "claude" {
-		var baseCmd string
-		if dangerousMode {
-			baseCmd = fmt.Sprintf(
-				`session_id=$(CLAUDE_CONFIG_DIR=%s claude -p "." --output-format json 2>/dev/null | jq -r '.session_id') && `+
-					`tmux set-environment CLAUDE_SESSION_ID "$session_id" && `+
-					`CLAUDE_CONFIG_DIR=%s claude --resume "$session_id" --dangerously-skip-permissions`,
-				configDir, configDir)
-		} else {
-			baseCmd = fmt.Sprintf(
-				`session_id=$(CLAUDE_CONFIG_DIR=%s claude -p "." --output-format json 2>/dev/null | jq -r '.session_id') && `+
-					`tmux set-environment CLAUDE_SESSION_ID "$session_id" && `+
-					`CLAUDE_CONFIG_DIR=%s claude --resume "$session_id"`,
-				configDir, configDir)
-		}
+		baseCmd := fmt.Sprintf(
+			`session_id=$(CLAUDE_CONFIG_DIR=%s claude -p "." --output-format json 2>/dev/null | jq -r '.session_id') && `+
+				`tmux set-environment CLAUDE_SESSION_ID "$session_id" && `+
+				`CLAUDE_CONFIG_DIR=%s claude --resume "$session_id"%s`,
+			configDir, configDir, flags)
 
 		// If message provided, append wait-and-send logic
 		if message != "" {
@@ -226,12 +261,7 @@ func (i *Instance) buildClaudeCommandWithMessage(baseCommand, message string) st
 					`tmux set-environment CLAUDE_SESSION_ID "$session_id" && `+
 					`(sleep 2; SESSION_NAME=$(tmux display-message -p '#S'); while ! tmux capture-pane -p -t "$SESSION_NAME" | tail -5 | grep -qE "^>"; do sleep 0.2; done; tmux send-keys -l -t "$SESSION_NAME" '%s'; tmux send-keys -t "$SESSION_NAME" Enter) & `+
 					`CLAUDE_CONFIG_DIR=%s claude --resume "$session_id"%s`,
-				configDir, escapedMsg, configDir, func() string {
-					if dangerousMode {
-						return " --dangerously-skip-permissions"
-					}
-					return ""
-				}())
+				configDir, escapedMsg, configDir, flags)
 		}
 
 		return baseCmd
@@ -283,17 +313,10 @@ func (i *Instance) Start() error {
 	}
 
 	// Build command (adds config dir for claude, capture-resume for gemini)
-	var command string
-	switch i.Tool {
-	case "claude":
-		command = i.buildClaudeCommand(i.Command)
-	case "gemini":
-		command = i.buildGeminiCommand(i.Command)
-	default:
-		command = i.Command
-	}
+	command := i.adapter().StartCommand(i)
 
 	// Start the tmux session
+	i.tmuxSession.Env = i.Env
 	if err := i.tmuxSession.Start(command); err != nil {
 		return fmt.Errorf("failed to start tmux session: %w", err)
 	}
@@ -323,17 +346,10 @@ func (i *Instance) StartWithMessage(message string) error {
 	}
 
 	// Start session normally (no embedded message logic)
-	var command string
-	switch i.Tool {
-	case "claude":
-		command = i.buildClaudeCommand(i.Command)
-	case "gemini":
-		command = i.buildGeminiCommand(i.Command)
-	default:
-		command = i.Command
-	}
+	command := i.adapter().StartCommand(i)
 
 	// Start the tmux session
+	i.tmuxSession.Env = i.Env
 	if err := i.tmuxSession.Start(command); err != nil {
 		return fmt.Errorf("failed to start tmux session: %w", err)
 	}
@@ -371,8 +387,6 @@ func (i *Instance) sendMessageWhenReady(message string) error {
 		return fmt.Errorf("tmux session not initialized")
 	}
 
-	sessionName := i.tmuxSession.Name
-
 	// Track state transitions: we need to see "active" before accepting "waiting"
 	// This ensures we don't send the message during initial startup (false "waiting")
 	sawActive := false
@@ -410,23 +424,44 @@ func (i *Instance) sendMessageWhenReady(message string) error {
 			// Small delay to ensure UI is fully rendered
 			time.Sleep(300 * time.Millisecond)
 
-			// Send the message using tmux send-keys
-			// -l flag for literal text, then Enter separately
-			cmd := exec.Command("tmux", "send-keys", "-l", "-t", sessionName, message)
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to send message: %w", err)
-			}
+			return i.SendMessage(message)
+		}
+	}
 
-			cmd = exec.Command("tmux", "send-keys", "-t", sessionName, "Enter")
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to send Enter: %w", err)
-			}
+	return fmt.Errorf("timeout waiting for agent to be ready")
+}
 
-			return nil
+// SendMessage types a message into the running session and submits it,
+// without waiting for the agent to be ready
+func (i *Instance) SendMessage(message string) error {
+	if i.tmuxSession == nil {
+		return fmt.Errorf("tmux session not initialized")
+	}
+	sessionName := i.tmuxSession.Name
+
+	// Multi-line messages (fork seeds) are pasted: typed newlines
+	// would submit them line by line
+	if strings.Contains(message, "\n") {
+		if err := i.tmuxSession.PasteText(message); err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
+		}
+		// Give the agent a moment to take the paste before submitting
+		time.Sleep(300 * time.Millisecond)
+	} else {
+		// Send the message using tmux send-keys
+		// -l flag for literal text, then Enter separately
+		cmd := exec.Command("tmux", "send-keys", "-l", "-t", sessionName, message)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
 		}
 	}
 
-	return fmt.Errorf("timeout waiting for agent to be ready")
+	cmd := exec.Command("tmux", "send-keys", "-t", sessionName, "Enter")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to send Enter: %w", err)
+	}
+
+	return nil
 }
 
 // errorRecheckInterval - how often to recheck sessions that don't exist
@@ -436,6 +471,15 @@ const errorRecheckInterval = 30 * time.Second
 
 // UpdateStatus updates the session status by checking tmux
 func (i *Instance) UpdateStatus() error {
+	// Report transitions (e.g. running -> waiting) to the notifier
+	previous := i.Status
+	defer func() {
+		if i.statusObserved && previous != i.Status && previous != StatusStarting {
+			notifyStatusChange(i, previous, i.Status)
+		}
+		i.statusObserved = true
+	}()
+
 	// Grace period FIRST: Skip all checks for recently created sessions
 	// If session was created within last 5 seconds, keep status as starting
 	// This prevents error flash during auto-reload while tmux initializes
@@ -470,6 +514,9 @@ func (i *Instance) UpdateStatus() error {
 	// Session exists - clear error check timestamp
 	i.lastErrorCheck = time.Time{}
 
+	// Custom tools (and overrides of built-in ones) bring their own patterns
+	i.tmuxSession.SetStatusDetector(GetToolStatusDetector(i.Tool))
+
 	// Get status from tmux session
 	status, err := i.tmuxSession.GetStatus()
 	if err != nil {
@@ -485,23 +532,22 @@ func (i *Instance) UpdateStatus() error {
 		i.Status = StatusWaiting
 	case "idle":
 		i.Status = StatusIdle
-	default:
+	case "approval":
+		i.Status = StatusApproval
+	default: // "inactive", or "error" from the tool's status detector
 		i.Status = StatusError
 	}
 
-	// Update tool detection dynamically (enables fork when Claude starts)
-	if detectedTool := i.tmuxSession.DetectTool(); detectedTool != "" {
-		i.Tool = detectedTool
+	// Update tool detection dynamically (enables fork when Claude starts).
+	// Custom tools keep their name so their config (icon, patterns) applies.
+	if GetToolDef(i.Tool) == nil {
+		if detectedTool := i.tmuxSession.DetectTool(); detectedTool != "" {
+			i.Tool = detectedTool
+		}
 	}
 
-	// Update Claude session tracking (non-blocking, best-effort)
-	// Pass nil for excludeIDs - deduplication happens at manager level
-	i.UpdateClaudeSession(nil)
-
-	// Update Gemini session tracking (non-blocking, best-effort)
-	if i.Tool == "gemini" {
-		i.UpdateGeminiSession(nil)
-	}
+	// Update conversation ID tracking (non-blocking, best-effort)
+	i.adapter().DetectSessionID(i)
 
 	return nil
 }
@@ -635,20 +681,30 @@ type ResponseOutput struct {
 // For Gemini: Parses the JSON session file for the last assistant message
 // For Codex/Others: Attempts to parse terminal output
 func (i *Instance) GetLastResponse() (*ResponseOutput, error) {
-	if i.Tool == "claude" {
-		return i.getClaudeLastResponse()
-	}
-	if i.Tool == "gemini" {
-		return i.getGeminiLastResponse()
-	}
-	return i.getTerminalLastResponse()
+	return i.adapter().LastResponse(i)
 }
 
 // getClaudeLastResponse extracts the last assistant message from Claude's JSONL file
 func (i *Instance) getClaudeLastResponse() (*ResponseOutput, error) {
+	sessionFile, err := i.claudeSessionFile()
+	if err != nil {
+		return nil, err
+	}
+
+	// Read and parse the JSONL file
+	data, err := os.ReadFile(sessionFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	return parseClaudeLastAssistantMessage(data, filepath.Base(sessionFile))
+}
+
+// claudeSessionFile returns the JSONL file of the session's Claude conversation
+func (i *Instance) claudeSessionFile() (string, error) {
 	// Require stored session ID - no fallback to file scanning
 	if i.ClaudeSessionID == "" {
-		return nil, fmt.Errorf("no Claude session ID available for this instance")
+		return "", fmt.Errorf("no Claude session ID available for this instance")
 	}
 
 	configDir := GetClaudeConfigDir()
@@ -669,16 +725,9 @@ func (i *Instance) getClaudeLastResponse() (*ResponseOutput, error) {
 
 	// Check file exists
 	if _, err := os.Stat(sessionFile); os.IsNotExist(err) {
-		return nil, fmt.Errorf("session file not found: %s", sessionFile)
-	}
-
-	// Read and parse the JSONL file
-	data, err := os.ReadFile(sessionFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read session file: %w", err)
+		return "", fmt.Errorf("session file not found: %s", sessionFile)
 	}
-
-	return parseClaudeLastAssistantMessage(data, filepath.Base(sessionFile))
+	return sessionFile, nil
 }
 
 // parseClaudeLastAssistantMessage parses a Claude JSONL file to extract the last assistant message
@@ -780,9 +829,25 @@ func parseClaudeLastAssistantMessage(data []byte, sessionID string) (*ResponseOu
 
 // getGeminiLastResponse extracts the last assistant message from Gemini's JSON file
 func (i *Instance) getGeminiLastResponse() (*ResponseOutput, error) {
+	sessionFile, err := i.geminiSessionFile()
+	if err != nil {
+		return nil, err
+	}
+
+	// Read and parse the JSON file
+	data, err := os.ReadFile(sessionFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	return parseGeminiLastAssistantMessage(data)
+}
+
+// geminiSessionFile returns the JSON file of the session's Gemini conversation
+func (i *Instance) geminiSessionFile() (string, error) {
 	// Require stored session ID - no fallback to file scanning
 	if i.GeminiSessionID == "" || len(i.GeminiSessionID) < 8 {
-		return nil, fmt.Errorf("no Gemini session ID available for this instance")
+		return "", fmt.Errorf("no Gemini session ID available for this instance")
 	}
 
 	sessionsDir := GetGeminiSessionsDir(i.ProjectPath)
@@ -792,17 +857,9 @@ func (i *Instance) getGeminiLastResponse() (*ResponseOutput, error) {
 	pattern := filepath.Join(sessionsDir, "session-*-"+i.GeminiSessionID[:8]+".json")
 	files, _ := filepath.Glob(pattern)
 	if len(files) == 0 {
-		return nil, fmt.Errorf("session file not found for ID: %s", i.GeminiSessionID)
-	}
-	sessionFile := files[0]
-
-	// Read and parse the JSON file
-	data, err := os.ReadFile(sessionFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read session file: %w", err)
+		return "", fmt.Errorf("session file not found for ID: %s", i.GeminiSessionID)
 	}
-
-	return parseGeminiLastAssistantMessage(data)
+	return files[0], nil
 }
 
 // parseGeminiLastAssistantMessage parses a Gemini JSON file to extract the last assistant message
@@ -844,8 +901,8 @@ func parseGeminiLastAssistantMessage(data []byte) (*ResponseOutput, error) {
 }
 
 // getTerminalLastResponse extracts the last response from terminal output
-// This is used for Gemini, Codex, and other tools without structured output
-func (i *Instance) getTerminalLastResponse() (*ResponseOutput, error) {
+// This is used for Codex and other tools without structured output
+func (i *Instance) getTerminalLastResponse(parse func(content string) (*ResponseOutput, error)) (*ResponseOutput, error) {
 	if i.tmuxSession == nil {
 		return nil, fmt.Errorf("tmux session not initialized")
 	}
@@ -856,15 +913,7 @@ func (i *Instance) getTerminalLastResponse() (*ResponseOutput, error) {
 		return nil, fmt.Errorf("failed to capture terminal output: %w", err)
 	}
 
-	// Parse based on tool type
-	switch i.Tool {
-	case "gemini":
-		return parseGeminiOutput(content)
-	case "codex":
-		return parseCodexOutput(content)
-	default:
-		return parseGenericOutput(content, i.Tool)
-	}
+	return parse(content)
 }
 
 // parseGeminiOutput parses Gemini CLI output to extract the last response
@@ -992,23 +1041,23 @@ func (i *Instance) Kill() error {
 	return nil
 }
 
-// Restart restarts the Claude session
-// For Claude sessions with known ID: sends Ctrl+C twice and resume command to existing session
-// For dead sessions or unknown ID: recreates the tmux session
+// Restart restarts the session
+// For running sessions whose tool restarts in place (Claude) with a resumable
+// conversation: respawns the pane with the resume command
+// Otherwise: recreates the tmux session, resuming the conversation if known
 func (i *Instance) Restart() error {
 	log.Printf("[MCP-DEBUG] Instance.Restart() called - Tool=%s, ClaudeSessionID=%q, tmuxSession=%v, tmuxExists=%v",
 		i.Tool, i.ClaudeSessionID, i.tmuxSession != nil, i.tmuxSession != nil && i.tmuxSession.Exists())
 
 	// Regenerate .mcp.json before restart to use socket pool if available
 	// This ensures Claude picks up socket configs instead of stdio
-	if i.Tool == "claude" {
-		i.regenerateMCPConfig()
-	}
+	i.regenerateMCPConfig()
+
+	adapter := i.adapter()
+	resumeCmd := adapter.ResumeCommand(i)
 
-	// If Claude session with known ID AND tmux session exists, use respawn-pane
-	if i.Tool == "claude" && i.ClaudeSessionID != "" && i.tmuxSession != nil && i.tmuxSession.Exists() {
-		// Build the resume command with proper config
-		resumeCmd := i.buildClaudeResumeCommand()
+	// If the conversation is resumable AND tmux session exists, use respawn-pane
+	if adapter.RestartInPlace() && resumeCmd != "" && i.tmuxSession != nil && i.tmuxSession.Exists() {
 		log.Printf("[MCP-DEBUG] Using respawn-pane with command: %s", resumeCmd)
 
 		// Use respawn-pane for atomic restart
@@ -1016,7 +1065,7 @@ func (i *Instance) Restart() error {
 		// respawn-pane -k kills the current process and starts the new command atomically
 		if err := i.tmuxSession.RespawnPane(resumeCmd); err != nil {
 			log.Printf("[MCP-DEBUG] RespawnPane failed: %v", err)
-			return fmt.Errorf("failed to restart Claude session: %w", err)
+			return fmt.Errorf("failed to restart session: %w", err)
 		}
 
 		log.Printf("[MCP-DEBUG] RespawnPane succeeded")
@@ -1031,29 +1080,23 @@ func (i *Instance) Restart() error {
 
 	log.Printf("[MCP-DEBUG] Using fallback: recreate tmux session")
 
-	// Fallback: recreate tmux session (for dead sessions or unknown ID)
+	// Fallback: recreate tmux session (for dead sessions, unknown ID or tools
+	// that don't respawn in place). A session still running is killed first so
+	// its agent isn't left behind.
+	if i.tmuxSession != nil && i.tmuxSession.Exists() {
+		if err := i.tmuxSession.Kill(); err != nil {
+			return fmt.Errorf("failed to stop the running tmux session: %w", err)
+		}
+	}
 	i.tmuxSession = tmux.NewSession(i.Title, i.ProjectPath)
 
-	var command string
-	if i.Tool == "claude" && i.ClaudeSessionID != "" {
-		command = i.buildClaudeResumeCommand()
-	} else if i.Tool == "gemini" && i.GeminiSessionID != "" {
-		// Set GEMINI_SESSION_ID in tmux env so detection works after restart
-		command = fmt.Sprintf("tmux set-environment GEMINI_SESSION_ID %s && gemini --resume %s",
-			i.GeminiSessionID, i.GeminiSessionID)
-	} else {
-		// Route to appropriate command builder based on tool
-		switch i.Tool {
-		case "claude":
-			command = i.buildClaudeCommand(i.Command)
-		case "gemini":
-			command = i.buildGeminiCommand(i.Command)
-		default:
-			command = i.Command
-		}
+	command := resumeCmd
+	if command == "" {
+		command = adapter.StartCommand(i)
 	}
 	log.Printf("[MCP-DEBUG] Starting new tmux session with command: %s", command)
 
+	i.tmuxSession.Env = i.Env
 	if err := i.tmuxSession.Start(command); err != nil {
 		log.Printf("[MCP-DEBUG] tmuxSession.Start() failed: %v", err)
 		i.Status = StatusError
@@ -1081,35 +1124,19 @@ func (i *Instance) Restart() error {
 func (i *Instance) buildClaudeResumeCommand() string {
 	configDir := GetClaudeConfigDir()
 
-	// Check if dangerous mode is enabled in user config
-	dangerousMode := false
-	if userConfig, err := LoadUserConfig(); err == nil && userConfig != nil {
-		dangerousMode = userConfig.Claude.DangerousMode
-	}
-
 	// Build the command with tmux environment update
 	// This ensures CLAUDE_SESSION_ID is set in tmux env after restart,
 	// so GetSessionIDFromTmux() works correctly and detects the session
-	if dangerousMode {
-		return fmt.Sprintf("tmux set-environment CLAUDE_SESSION_ID %s && CLAUDE_CONFIG_DIR=%s claude --resume %s --dangerously-skip-permissions",
-			i.ClaudeSessionID, configDir, i.ClaudeSessionID)
-	}
-	return fmt.Sprintf("tmux set-environment CLAUDE_SESSION_ID %s && CLAUDE_CONFIG_DIR=%s claude --resume %s",
-		i.ClaudeSessionID, configDir, i.ClaudeSessionID)
+	return fmt.Sprintf("tmux set-environment CLAUDE_SESSION_ID %s && CLAUDE_CONFIG_DIR=%s claude --resume %s%s",
+		i.ClaudeSessionID, configDir, i.ClaudeSessionID, i.claudeFlags())
 }
 
 // CanRestart returns true if the session can be restarted
-// For Claude sessions with known ID: can always restart (interrupt and resume)
-// For Gemini sessions with known ID: can always restart (interrupt and resume)
+// For sessions with a known conversation ID: can always restart (interrupt and resume)
 // For other sessions: only if dead/error state
 func (i *Instance) CanRestart() bool {
-	// Gemini sessions with known session ID can always be restarted
-	if i.Tool == "gemini" && i.GeminiSessionID != "" {
-		return true
-	}
-
-	// Claude sessions with known session ID can always be restarted
-	if i.Tool == "claude" && i.ClaudeSessionID != "" {
+	// Sessions with a resumable conversation can always be restarted
+	if i.adapter().SessionID(i) != "" {
 		return true
 	}
 
@@ -1117,29 +1144,28 @@ func (i *Instance) CanRestart() bool {
 	return i.Status == StatusError || i.tmuxSession == nil || !i.tmuxSession.Exists()
 }
 
-// CanFork returns true if this session can be forked
+// CanFork returns true if this session can be forked: natively when the tool
+// supports it, otherwise by seeding a new session with the parent's transcript
 func (i *Instance) CanFork() bool {
-	// Gemini CLI doesn't support forking
-	if i.Tool == "gemini" {
-		return false
-	}
+	return i.CanForkNatively() || i.canSeedFork()
+}
 
-	// Claude sessions can fork if session ID is recent
-	if i.ClaudeSessionID == "" {
-		return false
-	}
-	return time.Since(i.ClaudeDetectedAt) < 5*time.Minute
+// CanForkNatively returns true if the tool itself can fork the conversation
+// (Claude), so the fork inherits the full history
+func (i *Instance) CanForkNatively() bool {
+	return i.adapter().CanFork(i)
 }
 
-// Fork returns the command to create a forked Claude session
-// Uses capture-resume pattern: starts fork in print mode to get new session ID,
-// stores in tmux environment, then resumes interactively
+// Fork returns the command to create a forked session
+// For Claude uses capture-resume pattern: starts fork in print mode to get new
+// session ID, stores in tmux environment, then resumes interactively
 func (i *Instance) Fork(newTitle, newGroupPath string) (string, error) {
-	if !i.CanFork() {
-		return "", fmt.Errorf("cannot fork: no active Claude session")
-	}
+	return i.adapter().ForkCommand(i, i)
+}
 
-	workDir := i.ProjectPath
+// forkCommand builds the command of fork, a fork of the session's
+// conversation running in fork.ProjectPath with fork's Claude options
+func (i *Instance) forkCommand(fork *Instance) string {
 	configDir := GetClaudeConfigDir()
 
 	// Capture-resume pattern for fork:
@@ -1149,10 +1175,10 @@ func (i *Instance) Fork(newTitle, newGroupPath string) (string, error) {
 	cmd := fmt.Sprintf(
 		`cd %s && session_id=$(CLAUDE_CONFIG_DIR=%s claude -p "." --output-format json --resume %s --fork-session 2>/dev/null | jq -r '.session_id') && `+
 			`tmux set-environment CLAUDE_SESSION_ID "$session_id" && `+
-			`CLAUDE_CONFIG_DIR=%s claude --resume "$session_id" --dangerously-skip-permissions`,
-		workDir, configDir, i.ClaudeSessionID, configDir)
+			`CLAUDE_CONFIG_DIR=%s claude --resume "$session_id"%s`,
+		fork.ProjectPath, configDir, i.ClaudeSessionID, configDir, fork.claudeFlags())
 
-	return cmd, nil
+	return cmd
 }
 
 // GetActualWorkDir returns the actual working directory from tmux, or falls back to ProjectPath
@@ -1165,11 +1191,41 @@ func (i *Instance) GetActualWorkDir() string {
 	return i.ProjectPath
 }
 
+// ForkOptions customizes CreateForkedInstanceWithOptions
+type ForkOptions struct {
+	// Worktree runs the fork in a new git worktree instead of the parent's checkout
+	Worktree bool
+
+	// Branch names the worktree's branch (default: generated from the title)
+	Branch string
+
+	// Claude replaces the fork's per-session Claude options (nil: inherit
+	// the parent's)
+	Claude *ClaudeOptions
+}
+
+// claudeOptions returns the Claude options of a fork of parent
+func (o ForkOptions) claudeOptions(parent *Instance) *ClaudeOptions {
+	if o.Claude == nil {
+		return parent.ClaudeOptions.Clone()
+	}
+	if o.Claude.IsZero() {
+		return nil
+	}
+	return o.Claude.Clone()
+}
+
 // CreateForkedInstance creates a new Instance configured for forking
 func (i *Instance) CreateForkedInstance(newTitle, newGroupPath string) (*Instance, string, error) {
-	cmd, err := i.Fork(newTitle, newGroupPath)
-	if err != nil {
-		return nil, "", err
+	return i.CreateForkedInstanceWithOptions(newTitle, newGroupPath, ForkOptions{})
+}
+
+// CreateForkedInstanceWithOptions creates a new Instance configured for
+// forking, optionally isolated in its own git worktree
+func (i *Instance) CreateForkedInstanceWithOptions(newTitle, newGroupPath string, opts ForkOptions) (*Instance, string, error) {
+	adapter := i.adapter()
+	if !adapter.CanFork(i) {
+		return i.createSeededFork(newTitle, newGroupPath, opts)
 	}
 
 	// Create new instance with the PARENT's project path
@@ -1180,8 +1236,29 @@ func (i *Instance) CreateForkedInstance(newTitle, newGroupPath string) (*Instanc
 	} else {
 		forked.GroupPath = i.GroupPath
 	}
+
+	if opts.Worktree {
+		if err := forked.SetupWorktree(opts.Branch); err != nil {
+			return nil, "", err
+		}
+		// Claude looks the parent conversation up in the fork's project directory
+		if err := copyClaudeSession(i.ClaudeSessionID, i.ProjectPath, forked.ProjectPath); err != nil {
+			_ = forked.RemoveWorktree()
+			return nil, "", fmt.Errorf("failed to copy conversation into worktree: %w", err)
+		}
+	}
+
+	forked.ClaudeOptions = opts.claudeOptions(i)
+	forked.Env = maps.Clone(i.Env)
+	cmd, err := adapter.ForkCommand(i, forked)
+	if err != nil {
+		if opts.Worktree {
+			_ = forked.RemoveWorktree()
+		}
+		return nil, "", err
+	}
 	forked.Command = cmd
-	forked.Tool = "claude"
+	forked.Tool = adapter.Name()
 
 	return forked, cmd, nil
 }
@@ -1213,29 +1290,28 @@ func (i *Instance) GetSessionIDFromTmux() string {
 }
 
 // GetMCPInfo returns MCP server information for this session
-// Returns nil if not a Claude or Gemini session
+// Returns nil if the tool's MCPs aren't managed by agent-deck
 func (i *Instance) GetMCPInfo() *MCPInfo {
-	switch i.Tool {
-	case "claude":
-		return GetMCPInfo(i.ProjectPath)
-	case "gemini":
-		return GetGeminiMCPInfo(i.ProjectPath)
-	default:
-		return nil
-	}
+	return i.adapter().MCPInfo(i.ProjectPath)
+}
+
+// SessionID returns the tool's conversation ID ("" if unknown)
+func (i *Instance) SessionID() string {
+	return i.adapter().SessionID(i)
+}
+
+// SupportsMCP returns true if agent-deck manages the session's MCPs
+func (i *Instance) SupportsMCP() bool {
+	return i.adapter().SupportsMCP()
 }
 
 // CaptureLoadedMCPs captures the current MCP names as the "loaded" state
 // This should be called when a session starts or restarts, so we can track
 // which MCPs are actually loaded in the running Claude session vs just configured
+// Only tools with per-project MCPs are tracked
 func (i *Instance) CaptureLoadedMCPs() {
-	if i.Tool != "claude" {
-		i.LoadedMCPNames = nil
-		return
-	}
-
-	mcpInfo := GetMCPInfo(i.ProjectPath)
-	if mcpInfo == nil {
+	mcpInfo := i.GetMCPInfo()
+	if mcpInfo == nil || !i.adapter().ProjectMCPs() {
 		i.LoadedMCPNames = nil
 		return
 	}
@@ -1247,7 +1323,10 @@ func (i *Instance) CaptureLoadedMCPs() {
 // If socket pool is running, MCPs will use socket configs (nc -U /tmp/...)
 // Otherwise, MCPs will use stdio configs (npx ...)
 func (i *Instance) regenerateMCPConfig() {
-	mcpInfo := GetMCPInfo(i.ProjectPath)
+	if !i.adapter().ProjectMCPs() {
+		return
+	}
+	mcpInfo := i.GetMCPInfo()
 	if mcpInfo == nil {
 		return
 	}
@@ -1257,9 +1336,9 @@ func (i *Instance) regenerateMCPConfig() {
 		return
 	}
 
-	// Regenerate .mcp.json - WriteMCPJsonFromConfig checks pool status
-	// and writes socket configs if pool is running
-	if err := WriteMCPJsonFromConfig(i.ProjectPath, localMCPs); err != nil {
+	// Regenerate .mcp.json - Claude's writer (WriteMCPJsonFromConfig) checks
+	// pool status and writes socket configs if pool is running
+	if err := i.adapter().WriteMCPConfig(i.ProjectPath, localMCPs); err != nil {
 		log.Printf("[MCP-DEBUG] Failed to regenerate .mcp.json: %v", err)
 	} else {
 		log.Printf("[MCP-DEBUG] Regenerated .mcp.json for %s with %d MCPs", i.Title, len(localMCPs))
@@ -1309,4 +1388,36 @@ func UpdateClaudeSessionsWithDedup(instances []*Instance) {
 	}
 	// No re-detection step - tmux env is the authoritative source
 	// Sessions will get their IDs from UpdateClaudeSession() during normal status updates
+
+	// Codex and OpenCode IDs are discovered by scanning, so two sessions in
+	// one project can pick the same conversation. The newer session skips it
+	// on its next scan.
+	dedupDiscoveredSessionIDs(instances, "codex", func(inst *Instance) (*string, *time.Time) {
+		return &inst.CodexSessionID, &inst.CodexDetectedAt
+	})
+	dedupDiscoveredSessionIDs(instances, "opencode", func(inst *Instance) (*string, *time.Time) {
+		return &inst.OpenCodeSessionID, &inst.OpenCodeDetectedAt
+	})
+}
+
+// dedupDiscoveredSessionIDs clears conversation IDs already claimed by an
+// older session (instances must be sorted oldest first)
+func dedupDiscoveredSessionIDs(instances []*Instance, tool string, fields func(*Instance) (*string, *time.Time)) {
+	claimed := make(map[string]bool)
+	for _, inst := range instances {
+		id, detectedAt := fields(inst)
+		if inst.Tool != tool || *id == "" {
+			continue
+		}
+		if !claimed[*id] {
+			claimed[*id] = true
+			continue
+		}
+		if inst.claimedElsewhere == nil {
+			inst.claimedElsewhere = make(map[string]bool)
+		}
+		inst.claimedElsewhere[*id] = true
+		*id = ""
+		*detectedAt = time.Time{}
+	}
 }
@@ -517,6 +517,38 @@ func TestInstance_Restart_InterruptsAndResumes(t *testing.T) {
 	}
 }
 
+func TestInstance_Restart_ReplacesRunningSession(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+
+	// Codex restarts in a new tmux session rather than respawning its pane
+	inst := NewInstanceWithTool("restart-replace-test", "/tmp", "codex")
+	inst.Command = "cat"
+	if err := inst.Start(); err != nil {
+		t.Fatalf("Failed to start initial session: %v", err)
+	}
+	old := inst.tmuxSession
+	defer func() { _ = old.Kill() }()
+	inst.CodexSessionID = "codex-session-xyz"
+	inst.CodexDetectedAt = time.Now()
+
+	if !inst.CanRestart() {
+		t.Fatal("CanRestart() should return true for a running Codex session with known ID")
+	}
+	if err := inst.Restart(); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+	defer func() { _ = inst.Kill() }()
+
+	if inst.tmuxSession == old {
+		t.Fatal("Restart should have created a new tmux session")
+	}
+	if old.Exists() {
+		t.Error("the old tmux session should be gone after restart")
+	}
+}
+
 func TestInstance_GeminiSessionFields(t *testing.T) {
 	inst := NewInstanceWithTool("test", "/tmp/test", "gemini")
 
@@ -0,0 +1,448 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Journal operations
+const (
+	JournalCreate = "create"
+	JournalRename = "rename"
+	JournalMove   = "move"
+	JournalSet    = "set"
+	JournalDelete = "delete"
+)
+
+// ErrNothingToUndo is returned by Undo when every journaled operation has
+// already been undone
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// JournalEntry is one operation in a profile's journal (journal.jsonl next to
+// sessions.json, append-only): the changes a single save made
+type JournalEntry struct {
+	Seq     int64           `json:"seq"`
+	Time    time.Time       `json:"time"`
+	Undoes  int64           `json:"undoes,omitempty"` // Seq of the entry these changes revert
+	Changes []JournalChange `json:"changes"`
+}
+
+// JournalChange is the change to one session (Before/After) or group
+// (GroupBefore/GroupAfter). Before is nil for a create, After for a delete.
+type JournalChange struct {
+	Op          string        `json:"op"`
+	Before      *InstanceData `json:"before,omitempty"`
+	After       *InstanceData `json:"after,omitempty"`
+	GroupBefore *GroupData    `json:"group_before,omitempty"`
+	GroupAfter  *GroupData    `json:"group_after,omitempty"`
+}
+
+// journalIgnoredFields are fields that change on their own as sessions run.
+// Changes to them alone aren't operations, and undo leaves them as they are.
+var journalIgnoredFields = map[string]bool{
+	"status":               true,
+	"last_accessed_at":     true,
+	"tmux_session":         true,
+	"claude_session_id":    true,
+	"claude_detected_at":   true,
+	"gemini_session_id":    true,
+	"gemini_detected_at":   true,
+	"codex_session_id":     true,
+	"codex_detected_at":    true,
+	"opencode_session_id":  true,
+	"opencode_detected_at": true,
+	"loaded_mcp_names":     true,
+	"expanded":             true,
+}
+
+// IsGroup reports whether the change is to a group
+func (c JournalChange) IsGroup() bool {
+	return c.GroupBefore != nil || c.GroupAfter != nil
+}
+
+// String describes the change, e.g. `moved "api" to work`
+func (c JournalChange) String() string {
+	if c.IsGroup() {
+		switch c.Op {
+		case JournalCreate:
+			return fmt.Sprintf("created group %q", c.GroupAfter.Name)
+		case JournalDelete:
+			return fmt.Sprintf("deleted group %q", c.GroupBefore.Name)
+		case JournalRename:
+			return fmt.Sprintf("renamed group %q to %q", c.GroupBefore.Name, c.GroupAfter.Name)
+		default:
+			return fmt.Sprintf("changed group %q", c.GroupAfter.Name)
+		}
+	}
+	switch c.Op {
+	case JournalCreate:
+		return fmt.Sprintf("created %q", c.After.Title)
+	case JournalDelete:
+		return fmt.Sprintf("deleted %q", c.Before.Title)
+	case JournalRename:
+		return fmt.Sprintf("renamed %q to %q", c.Before.Title, c.After.Title)
+	case JournalMove:
+		return fmt.Sprintf("moved %q to %s", c.After.Title, c.After.GroupPath)
+	default:
+		return fmt.Sprintf("changed %q", c.After.Title)
+	}
+}
+
+// Summary describes the entry by its main change, e.g. `deleted group "work"
+// (+3 more changes)`
+func (e *JournalEntry) Summary() string {
+	if len(e.Changes) == 0 {
+		return "no changes"
+	}
+	main := e.Changes[0]
+	for _, c := range e.Changes[1:] {
+		if changeRank(c) < changeRank(main) {
+			main = c
+		}
+	}
+	summary := main.String()
+	if more := len(e.Changes) - 1; more == 1 {
+		summary += " (+1 more change)"
+	} else if more > 1 {
+		summary += fmt.Sprintf(" (+%d more changes)", more)
+	}
+	return summary
+}
+
+// changeRank orders changes by how well they describe an operation: a group
+// delete moves its sessions, a session created in a new group creates it
+func changeRank(c JournalChange) int {
+	rank := 4
+	switch c.Op {
+	case JournalDelete:
+		rank = 0
+	case JournalCreate:
+		rank = 1
+	}
+	if c.IsGroup() && rank < 4 {
+		rank += 2
+	}
+	return rank
+}
+
+// journalPath returns the path of the profile's journal
+func (s *Storage) journalPath() string {
+	return filepath.Join(filepath.Dir(s.path), "journal.jsonl")
+}
+
+// readJournal reads the journal, skipping lines that can't be parsed
+func (s *Storage) readJournal() ([]*JournalEntry, error) {
+	f, err := os.Open(s.journalPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []*JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, &entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// Journal returns the journaled operations, oldest first
+func (s *Storage) Journal() ([]*JournalEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readJournal()
+}
+
+// journalMaxEntries is how many operations undo can go back. The journal is
+// trimmed to them once it holds twice as many entries.
+const journalMaxEntries = 200
+
+// recordChanges appends an entry to the journal, under the storage lock
+func (s *Storage) recordChanges(changes []JournalChange, undoes int64) error {
+	// The journal is only read again when another process wrote to it
+	size := int64(0)
+	if info, err := os.Stat(s.journalPath()); err == nil {
+		size = info.Size()
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to stat journal: %w", err)
+	}
+	if s.journalSeq == 0 || size != s.journalSize {
+		entries, err := s.readJournal()
+		if err != nil {
+			return err
+		}
+		s.journalSeq, s.journalEntries = 0, len(entries)
+		if len(entries) > 0 {
+			s.journalSeq = entries[len(entries)-1].Seq
+		}
+	}
+
+	entry := JournalEntry{Seq: s.journalSeq + 1, Time: time.Now(), Undoes: undoes, Changes: changes}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	f, err := os.OpenFile(s.journalPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	s.journalSeq = entry.Seq
+	s.journalSize = size + int64(len(line)) + 1
+	s.journalEntries++
+
+	if s.journalEntries > 2*journalMaxEntries {
+		return s.trimJournal()
+	}
+	return nil
+}
+
+// trimJournal rewrites the journal with only what undo still needs: the
+// last journalMaxEntries operations that haven't been undone. The newest
+// entry is always kept so Seq keeps counting from it.
+func (s *Storage) trimJournal() error {
+	entries, err := s.readJournal()
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	undone := make(map[int64]bool)
+	for _, e := range entries {
+		if e.Undoes != 0 {
+			undone[e.Undoes] = true
+		}
+	}
+	keep := make(map[int64]bool)
+	for i := len(entries) - 1; i >= 0 && len(keep) < journalMaxEntries; i-- {
+		if e := entries[i]; e.Undoes == 0 && !undone[e.Seq] {
+			keep[e.Seq] = true
+		}
+	}
+	keep[entries[len(entries)-1].Seq] = true
+
+	var data []byte
+	kept := 0
+	for _, e := range entries {
+		if !keep[e.Seq] {
+			continue
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal journal entry: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+		kept++
+	}
+
+	tmpPath := s.journalPath() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.journalPath()); err != nil {
+		return fmt.Errorf("failed to replace journal: %w", err)
+	}
+	s.journalSize = int64(len(data))
+	s.journalEntries = kept
+	return nil
+}
+
+// diffStorage returns the operations that turn prev into data: sessions
+// first, then groups, each in data's order with deletes last
+func diffStorage(prev, data *StorageData) []JournalChange {
+	var changes []JournalChange
+
+	prevInstances := recordsByKey(prev.Instances, func(d *InstanceData) string { return d.ID })
+	dataInstances := recordsByKey(data.Instances, func(d *InstanceData) string { return d.ID })
+	for _, d := range data.Instances {
+		p := prevInstances[d.ID]
+		if p == nil {
+			changes = append(changes, JournalChange{Op: JournalCreate, After: d})
+			continue
+		}
+		fields := changedFields(p, d)
+		if len(fields) == 0 {
+			continue
+		}
+		op := JournalSet
+		if fields["group_path"] {
+			op = JournalMove
+		} else if fields["title"] {
+			op = JournalRename
+		}
+		changes = append(changes, JournalChange{Op: op, Before: p, After: d})
+	}
+	for _, p := range prev.Instances {
+		if dataInstances[p.ID] == nil {
+			changes = append(changes, JournalChange{Op: JournalDelete, Before: p})
+		}
+	}
+
+	prevGroups := recordsByKey(prev.Groups, func(g *GroupData) string { return g.Path })
+	dataGroups := recordsByKey(data.Groups, func(g *GroupData) string { return g.Path })
+	for _, g := range data.Groups {
+		p := prevGroups[g.Path]
+		if p == nil {
+			changes = append(changes, JournalChange{Op: JournalCreate, GroupAfter: g})
+			continue
+		}
+		fields := changedFields(p, g)
+		if len(fields) == 0 {
+			continue
+		}
+		op := JournalSet
+		if fields["name"] {
+			op = JournalRename
+		}
+		changes = append(changes, JournalChange{Op: op, GroupBefore: p, GroupAfter: g})
+	}
+	for _, p := range prev.Groups {
+		if dataGroups[p.Path] == nil {
+			changes = append(changes, JournalChange{Op: JournalDelete, GroupBefore: p})
+		}
+	}
+
+	return changes
+}
+
+// changedFields returns the fields (by JSON name) that differ between two
+// records, leaving out journalIgnoredFields
+func changedFields(a, b any) map[string]bool {
+	changed := make(map[string]bool)
+	fa, fb := jsonFields(a), jsonFields(b)
+	for k, v := range fa {
+		if !journalIgnoredFields[k] && string(v) != string(fb[k]) {
+			changed[k] = true
+		}
+	}
+	for k := range fb {
+		if _, ok := fa[k]; !ok && !journalIgnoredFields[k] {
+			changed[k] = true
+		}
+	}
+	return changed
+}
+
+// Undo reverts the last n operations that haven't been undone, newest first,
+// and returns them. Reverting is journaled like any other change (it can't
+// itself be undone), and sessions it removes go to the trash.
+func (s *Storage) Undo(n int) ([]*JournalEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, err := s.readJournal()
+	if err != nil {
+		return nil, err
+	}
+	undone := make(map[int64]bool)
+	for _, e := range entries {
+		if e.Undoes != 0 {
+			undone[e.Undoes] = true
+		}
+	}
+	var targets []*JournalEntry
+	for i := len(entries) - 1; i >= 0 && len(targets) < n; i-- {
+		if e := entries[i]; e.Undoes == 0 && !undone[e.Seq] {
+			targets = append(targets, e)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, ErrNothingToUndo
+	}
+
+	for i, e := range targets {
+		disk := s.readLocked()
+		if disk == nil {
+			return targets[:i], fmt.Errorf("storage file is unreadable")
+		}
+		if err := s.write(disk, revertEntry(disk, e), e.Seq); err != nil {
+			return targets[:i], err
+		}
+	}
+	return targets, nil
+}
+
+// revertEntry returns data with e's changes reverted. Records changed since
+// keep their other changes; data itself is left as it is.
+func revertEntry(data *StorageData, e *JournalEntry) *StorageData {
+	reverted := &StorageData{
+		Instances: append([]*InstanceData(nil), data.Instances...),
+		Groups:    append([]*GroupData(nil), data.Groups...),
+	}
+	for i := len(e.Changes) - 1; i >= 0; i-- {
+		c := e.Changes[i]
+		if c.IsGroup() {
+			reverted.Groups = revertChange(reverted.Groups, c.GroupBefore, c.GroupAfter,
+				func(g *GroupData) string { return g.Path },
+				func(g *GroupData) *int64 { return &g.Revision })
+		} else {
+			reverted.Instances = revertChange(reverted.Instances, c.Before, c.After,
+				func(d *InstanceData) string { return d.ID },
+				func(d *InstanceData) *int64 { return &d.Revision })
+		}
+	}
+	return reverted
+}
+
+// revertChange reverts one record's change from before to after: removes a
+// created record, restores a deleted one, or sets the fields that changed
+// back to before's values
+func revertChange[T any](records []*T, before, after *T, key func(*T) string, revision func(*T) *int64) []*T {
+	k := ""
+	if before != nil {
+		k = key(before)
+	} else {
+		k = key(after)
+	}
+	index := -1
+	for i, r := range records {
+		if key(r) == k {
+			index = i
+			break
+		}
+	}
+
+	switch {
+	case before == nil:
+		if index >= 0 {
+			records = append(records[:index:index], records[index+1:]...)
+		}
+	case after == nil:
+		if index < 0 {
+			restored := *before
+			*revision(&restored)++
+			records = append(records, &restored)
+		}
+	case index >= 0:
+		current := records[index]
+		r := mergeFields(after, before, current, journalIgnoredFields)
+		*revision(r) = *revision(current)
+		if !sameJSON(r, current) {
+			*revision(r)++
+		}
+		records[index] = r
+	}
+	return records
+}
@@ -0,0 +1,226 @@
+package session
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func newJournalTestStorage(t *testing.T) *Storage {
+	t.Helper()
+	useConfigHome(t)
+	s := &Storage{path: filepath.Join(t.TempDir(), "sessions.json"), profile: "_test"}
+	instances := []*Instance{
+		{ID: "a", Title: "A", ProjectPath: "/tmp/a", GroupPath: "work", Command: "claude", Tool: "claude", Status: StatusIdle, ClaudeSessionID: "claude-a"},
+		{ID: "b", Title: "B", ProjectPath: "/tmp/b", GroupPath: "work", Command: "claude", Tool: "claude", Status: StatusIdle},
+	}
+	if err := s.SaveWithGroups(instances, NewGroupTree(instances)); err != nil {
+		t.Fatalf("SaveWithGroups failed: %v", err)
+	}
+	return s
+}
+
+func savedInstances(t *testing.T, s *Storage) map[string]*InstanceData {
+	t.Helper()
+	data, err := s.loadFromFile(s.path)
+	if err != nil {
+		t.Fatalf("loadFromFile failed: %v", err)
+	}
+	return recordsByKey(data.Instances, func(d *InstanceData) string { return d.ID })
+}
+
+func TestJournalUndoDeleteAndTrash(t *testing.T) {
+	s := newJournalTestStorage(t)
+
+	instances, groups, err := s.LoadWithGroups()
+	if err != nil {
+		t.Fatalf("LoadWithGroups failed: %v", err)
+	}
+	kept := instances[1:]
+	if err := s.SaveWithGroups(kept, NewGroupTreeWithGroups(kept, groups)); err != nil {
+		t.Fatalf("SaveWithGroups failed: %v", err)
+	}
+
+	trash, err := s.Trash()
+	if err != nil {
+		t.Fatalf("Trash failed: %v", err)
+	}
+	if len(trash) != 1 || trash[0].Session.ID != "a" || trash[0].Session.ClaudeSessionID != "claude-a" {
+		t.Fatalf("trash = %+v, want session a with its Claude session ID", trash)
+	}
+
+	entries, err := s.Undo(1)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if got := entries[0].Summary(); got != `deleted "A"` {
+		t.Errorf("Summary() = %q, want %q", got, `deleted "A"`)
+	}
+	if a := savedInstances(t, s)["a"]; a == nil || a.ClaudeSessionID != "claude-a" {
+		t.Errorf("session a not restored with its Claude session ID: %+v", a)
+	}
+	if trash, _ := s.Trash(); len(trash) != 0 {
+		t.Errorf("trash after undo = %d sessions, want 0", len(trash))
+	}
+
+	// The seeding create is all that's left; undoing it trashes both sessions
+	if _, err := s.Undo(1); err != nil {
+		t.Fatalf("second Undo failed: %v", err)
+	}
+	if saved := savedInstances(t, s); len(saved) != 0 {
+		t.Errorf("sessions after undoing creation = %d, want 0", len(saved))
+	}
+	if _, err := s.Undo(1); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("third Undo error = %v, want ErrNothingToUndo", err)
+	}
+
+	if _, err := s.RestoreFromTrash("B"); err != nil {
+		t.Fatalf("RestoreFromTrash failed: %v", err)
+	}
+	if saved := savedInstances(t, s); saved["b"] == nil {
+		t.Error("session b not restored from trash")
+	}
+	if _, err := s.RestoreFromTrash("B"); err == nil {
+		t.Error("restoring a session twice should fail")
+	}
+	if n, err := s.PurgeTrash(""); err != nil || n != 1 {
+		t.Errorf("PurgeTrash = %d, %v, want 1 session purged", n, err)
+	}
+}
+
+func TestRestoreFromTrashWithoutParent(t *testing.T) {
+	s := newJournalTestStorage(t)
+
+	// Fork c from a, then undo the fork and the creation of a
+	instances, groups, err := s.LoadWithGroups()
+	if err != nil {
+		t.Fatalf("LoadWithGroups failed: %v", err)
+	}
+	child := &Instance{ID: "c", Title: "C", ProjectPath: "/tmp/a", GroupPath: "work", Command: "claude", Tool: "claude", Status: StatusIdle, ParentSessionID: "a"}
+	instances = append(instances, child)
+	if err := s.SaveWithGroups(instances, NewGroupTreeWithGroups(instances, groups)); err != nil {
+		t.Fatalf("SaveWithGroups failed: %v", err)
+	}
+	if _, err := s.Undo(2); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	restored, err := s.RestoreFromTrash("C")
+	if err != nil {
+		t.Fatalf("RestoreFromTrash failed: %v", err)
+	}
+	if restored.ParentSessionID != "" || savedInstances(t, s)["c"].ParentSessionID != "" {
+		t.Errorf("restored fork still points at its missing parent %q", restored.ParentSessionID)
+	}
+}
+
+func TestJournalUndoKeepsLaterChanges(t *testing.T) {
+	s := newJournalTestStorage(t)
+
+	instances, groups, err := s.LoadWithGroups()
+	if err != nil {
+		t.Fatalf("LoadWithGroups failed: %v", err)
+	}
+	instances[0].Title = "A renamed"
+	instances[0].Status = StatusRunning // Not an operation by itself
+	if err := s.SaveWithGroups(instances, NewGroupTreeWithGroups(instances, groups)); err != nil {
+		t.Fatalf("SaveWithGroups failed: %v", err)
+	}
+	instances[0].Command = "claude --verbose"
+	instances[0].GroupPath = "play"
+	if err := s.SaveWithGroups(instances, NewGroupTreeWithGroups(instances, groups)); err != nil {
+		t.Fatalf("SaveWithGroups failed: %v", err)
+	}
+
+	journal, err := s.Journal()
+	if err != nil {
+		t.Fatalf("Journal failed: %v", err)
+	}
+	if len(journal) != 3 {
+		t.Fatalf("journal has %d entries, want 3 (create, rename, move)", len(journal))
+	}
+	if op := journal[1].Changes[0].Op; op != JournalRename {
+		t.Errorf("second entry op = %q, want %q", op, JournalRename)
+	}
+	if op := journal[2].Changes[0].Op; op != JournalMove {
+		t.Errorf("third entry op = %q, want %q", op, JournalMove)
+	}
+
+	// Undo the rename only: the later command change and move stay
+	data, _ := s.loadFromFile(s.path)
+	if err := s.write(data, revertEntry(data, journal[1]), journal[1].Seq); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	a := savedInstances(t, s)["a"]
+	if a.Title != "A" || a.Command != "claude --verbose" || a.GroupPath != "play" || a.Status != StatusRunning {
+		t.Errorf("after undoing rename: title=%q command=%q group=%q status=%q", a.Title, a.Command, a.GroupPath, a.Status)
+	}
+
+	// Undo skips the already undone rename
+	entries, err := s.Undo(2)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Seq != journal[2].Seq || entries[1].Seq != journal[0].Seq {
+		t.Errorf("Undo(2) undid the wrong entries: %+v", entries)
+	}
+}
+
+func TestJournalSeqAndTrim(t *testing.T) {
+	s := newJournalTestStorage(t)
+	change := []JournalChange{{Op: JournalRename, Before: &InstanceData{ID: "a", Title: "A"}, After: &InstanceData{ID: "a", Title: "B"}}}
+
+	// Another process appending is noticed through the journal's size
+	other := &Storage{path: s.path, profile: s.profile}
+	for i := 0; i < 3; i++ {
+		if err := s.recordChanges(change, 0); err != nil {
+			t.Fatalf("recordChanges failed: %v", err)
+		}
+		if err := other.recordChanges(change, 0); err != nil {
+			t.Fatalf("recordChanges failed: %v", err)
+		}
+	}
+	journal, err := s.Journal()
+	if err != nil {
+		t.Fatalf("Journal failed: %v", err)
+	}
+	for i, e := range journal {
+		if e.Seq != int64(i+1) {
+			t.Fatalf("entry %d has Seq %d, want %d", i, e.Seq, i+1)
+		}
+	}
+
+	// Undoing the newest entry makes it useless once trimmed
+	last := journal[len(journal)-1].Seq
+	if err := s.recordChanges(nil, last); err != nil {
+		t.Fatalf("recordChanges failed: %v", err)
+	}
+	for trimmed := false; !trimmed; {
+		before := s.journalEntries
+		if err := s.recordChanges(change, 0); err != nil {
+			t.Fatalf("recordChanges failed: %v", err)
+		}
+		trimmed = s.journalEntries < before
+	}
+
+	journal, err = s.Journal()
+	if err != nil {
+		t.Fatalf("Journal failed: %v", err)
+	}
+	if len(journal) != journalMaxEntries {
+		t.Fatalf("journal has %d entries after trimming, want %d", len(journal), journalMaxEntries)
+	}
+	for _, e := range journal {
+		if e.Seq == last || e.Undoes != 0 {
+			t.Errorf("entry %d (undoes %d) should have been trimmed", e.Seq, e.Undoes)
+		}
+	}
+	next := journal[len(journal)-1].Seq + 1
+	if err := other.recordChanges(change, 0); err != nil {
+		t.Fatalf("recordChanges failed: %v", err)
+	}
+	journal, _ = s.Journal()
+	if got := journal[len(journal)-1].Seq; got != next {
+		t.Errorf("Seq after trimming = %d, want %d", got, next)
+	}
+}
@@ -17,7 +17,8 @@ type MCPServerConfig struct {
 	Command string            `json:"command,omitempty"`
 	Args    []string          `json:"args,omitempty"`
 	Env     map[string]string `json:"env,omitempty"`
-	URL     string            `json:"url,omitempty"` // For HTTP transport
+	URL     string            `json:"url,omitempty"`     // For HTTP transport
+	HTTPURL string            `json:"httpUrl,omitempty"` // Gemini's Streamable HTTP transport
 }
 
 // waitForSocketReady waits for an MCP socket to become ready, with timeout
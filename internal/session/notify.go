@@ -0,0 +1,294 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// notifyTimeout bounds each command and webhook delivery
+const notifyTimeout = 10 * time.Second
+
+// StatusEvent describes a session status transition
+type StatusEvent struct {
+	SessionID string    `json:"session_id"`
+	Title     string    `json:"title"`
+	Group     string    `json:"group"`
+	Path      string    `json:"path"`
+	Tool      string    `json:"tool"`
+	From      Status    `json:"from"`
+	To        Status    `json:"to"`
+	Time      time.Time `json:"time"`
+}
+
+// Message returns a short human-readable description of the new status
+func (e StatusEvent) Message() string {
+	switch e.To {
+	case StatusWaiting:
+		return "Waiting for input"
+	case StatusApproval:
+		return "Needs approval"
+	case StatusError:
+		return "Stopped with an error"
+	case StatusRunning:
+		return "Running"
+	case StatusIdle:
+		return "Idle"
+	default:
+		return string(e.To)
+	}
+}
+
+// Notifier delivers status transitions to the channels configured in
+// [notifications]. Deliveries run in the background so status polling never
+// waits on a slow webhook.
+type Notifier struct {
+	mu       sync.Mutex
+	lastSent map[string]time.Time // session ID -> last delivery (debounce)
+
+	// Hooks, replaced in tests
+	settings func() NotificationSettings
+	now      func() time.Time
+	desktop  func(title, body string) error
+	terminal func(title, body string) error
+	client   *http.Client
+}
+
+// NewNotifier creates a notifier that reads the [notifications] settings on
+// every event, so a config reload applies without a restart
+func NewNotifier() *Notifier {
+	return &Notifier{
+		lastSent: make(map[string]time.Time),
+		settings: GetNotificationSettings,
+		now:      time.Now,
+		desktop:  sendDesktopNotification,
+		terminal: sendTerminalNotification,
+		client:   &http.Client{Timeout: notifyTimeout},
+	}
+}
+
+// statusNotifier receives transitions observed by Instance.UpdateStatus.
+// Only the TUI installs one: short-lived CLI commands would otherwise notify
+// about transitions that happened while nobody was polling.
+var statusNotifier atomic.Pointer[Notifier]
+
+// SetStatusNotifier installs the notifier for status transitions (nil disables)
+func SetStatusNotifier(n *Notifier) {
+	statusNotifier.Store(n)
+}
+
+// notifyStatusChange forwards a transition to the installed notifier
+func notifyStatusChange(inst *Instance, from, to Status) {
+	n := statusNotifier.Load()
+	if n == nil {
+		return
+	}
+	n.Notify(StatusEvent{
+		SessionID: inst.ID,
+		Title:     inst.Title,
+		Group:     inst.GroupPath,
+		Path:      inst.ProjectPath,
+		Tool:      inst.Tool,
+		From:      from,
+		To:        to,
+		Time:      time.Now(),
+	})
+}
+
+// Notify delivers an event if the session's group rules ask for it. It
+// reports whether a delivery was started.
+func (n *Notifier) Notify(event StatusEvent) bool {
+	rule := n.settings().ForGroup(event.Group)
+	if !rule.Enabled || !rule.matches(event.To) {
+		return false
+	}
+
+	now := n.now()
+	if rule.inQuietHours(now) {
+		return false
+	}
+
+	n.mu.Lock()
+	if last, ok := n.lastSent[event.SessionID]; ok && now.Sub(last) < time.Duration(rule.DebounceSeconds)*time.Second {
+		n.mu.Unlock()
+		return false
+	}
+	n.lastSent[event.SessionID] = now
+	n.mu.Unlock()
+
+	go n.deliver(rule, event)
+	return true
+}
+
+// matches reports whether entering status should notify
+func (r NotificationRule) matches(status Status) bool {
+	for _, s := range r.On {
+		if Status(strings.ToLower(s)) == status {
+			return true
+		}
+	}
+	return false
+}
+
+// inQuietHours reports whether t falls in the rule's quiet hours. Ranges may
+// wrap past midnight; an invalid range is ignored.
+func (r NotificationRule) inQuietHours(t time.Time) bool {
+	if r.QuietHours == "" || r.QuietHours == "off" {
+		return false
+	}
+	start, end, err := parseQuietHours(r.QuietHours)
+	if err != nil {
+		log.Printf("notify: ignoring quiet_hours: %v", err)
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// parseQuietHours parses "HH:MM-HH:MM" into minutes since midnight
+func parseQuietHours(value string) (start, end int, err error) {
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid range %q (want HH:MM-HH:MM)", value)
+	}
+	parse := func(s string) (int, error) {
+		t, err := time.Parse("15:04", strings.TrimSpace(s))
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %q in %q", s, value)
+		}
+		return t.Hour()*60 + t.Minute(), nil
+	}
+	if start, err = parse(from); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parse(to); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// deliver sends the event to every channel of the rule, logging failures
+func (n *Notifier) deliver(rule NotificationRule, event StatusEvent) {
+	title := "agent-deck: " + event.Title
+	body := event.Message()
+
+	if rule.Desktop {
+		if err := n.desktop(title, body); err != nil {
+			log.Printf("notify: desktop notification failed: %v", err)
+		}
+	}
+	if rule.Terminal {
+		if err := n.terminal(title, body); err != nil {
+			log.Printf("notify: terminal notification failed: %v", err)
+		}
+	}
+	if rule.Command != "" {
+		if err := runNotifyCommand(rule.Command, event); err != nil {
+			log.Printf("notify: command failed: %v", err)
+		}
+	}
+	if rule.Webhook != "" {
+		if err := n.postWebhook(rule.Webhook, event); err != nil {
+			log.Printf("notify: webhook failed: %v", err)
+		}
+	}
+}
+
+// postWebhook POSTs the event as JSON
+func (n *Notifier) postWebhook(url string, event StatusEvent) error {
+	payload, err := json.Marshal(struct {
+		Event string `json:"event"`
+		StatusEvent
+		Message string `json:"message"`
+	}{"status_changed", event, event.Message()})
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
+
+// runNotifyCommand runs the user's command through sh with the event in
+// AGENTDECK_* environment variables
+func runNotifyCommand(command string, event StatusEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"AGENTDECK_SESSION_ID="+event.SessionID,
+		"AGENTDECK_SESSION_TITLE="+event.Title,
+		"AGENTDECK_GROUP="+event.Group,
+		"AGENTDECK_PATH="+event.Path,
+		"AGENTDECK_TOOL="+event.Tool,
+		"AGENTDECK_STATUS="+string(event.To),
+		"AGENTDECK_PREVIOUS_STATUS="+string(event.From),
+		"AGENTDECK_MESSAGE="+event.Message(),
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// sendDesktopNotification uses notify-send on Linux and osascript on macOS
+func sendDesktopNotification(title, body string) error {
+	if runtime.GOOS == "darwin" {
+		script := fmt.Sprintf("display notification %q with title %q", body, title)
+		return exec.Command("osascript", "-e", script).Run()
+	}
+	if _, err := exec.LookPath("notify-send"); err != nil {
+		return fmt.Errorf("notify-send not found in PATH")
+	}
+	return exec.Command("notify-send", "--app-name=agent-deck", title, body).Run()
+}
+
+// sendTerminalNotification writes an OSC 777 notification to the controlling
+// terminal (supported by kitty, WezTerm, foot, Ghostty, ...). Inside tmux the
+// sequence is wrapped for passthrough, which needs `allow-passthrough on`.
+func sendTerminalNotification(title, body string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+
+	_, err = io.WriteString(tty, osc777(title, body, os.Getenv("TMUX") != ""))
+	return err
+}
+
+// osc777 builds the notification escape sequence
+func osc777(title, body string, tmuxPassthrough bool) string {
+	// ';' separates the fields and control characters would end the sequence
+	clean := strings.NewReplacer(";", ",", "\x07", "", "\x1b", "")
+	seq := fmt.Sprintf("\x1b]777;notify;%s;%s\x07", clean.Replace(title), clean.Replace(body))
+	if tmuxPassthrough {
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
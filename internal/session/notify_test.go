@@ -0,0 +1,187 @@
+package session
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestNotificationSettingsForGroup(t *testing.T) {
+	configContent := `
+[notifications]
+enabled = true
+webhook = "http://example.com/all"
+quiet_hours = "22:00-07:00"
+
+[notifications.groups."work"]
+webhook = "http://example.com/work"
+quiet_hours = "off"
+
+[notifications.groups."work/experiments"]
+enabled = false
+`
+	var config UserConfig
+	if _, err := toml.Decode(configContent, &config); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	settings := config.Notifications
+
+	if rule := settings.ForGroup("personal"); !rule.Enabled || rule.Webhook != "http://example.com/all" || rule.QuietHours != "22:00-07:00" {
+		t.Errorf("personal should use the global rule, got %+v", rule)
+	}
+	if rule := settings.ForGroup("work/backend"); !rule.Enabled || rule.Webhook != "http://example.com/work" || rule.QuietHours != "off" {
+		t.Errorf("work/backend should inherit the work override, got %+v", rule)
+	}
+	if rule := settings.ForGroup("work/experiments"); rule.Enabled || rule.Webhook != "http://example.com/work" {
+		t.Errorf("work/experiments should be disabled, got %+v", rule)
+	}
+}
+
+func TestNotificationRuleQuietHours(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.Local)
+	}
+	tests := []struct {
+		quiet string
+		t     time.Time
+		want  bool
+	}{
+		{"22:00-07:00", at(23, 30), true},
+		{"22:00-07:00", at(6, 59), true},
+		{"22:00-07:00", at(7, 0), false},
+		{"22:00-07:00", at(12, 0), false},
+		{"12:00-13:00", at(12, 30), true},
+		{"12:00-13:00", at(13, 30), false},
+		{"off", at(23, 30), false},
+		{"late", at(23, 30), false}, // Invalid ranges are ignored
+	}
+	for _, tt := range tests {
+		rule := NotificationRule{QuietHours: tt.quiet}
+		if got := rule.inQuietHours(tt.t); got != tt.want {
+			t.Errorf("inQuietHours(%q, %s) = %v, want %v", tt.quiet, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestNotifierWebhook(t *testing.T) {
+	received := make(chan map[string]any, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		received <- payload
+	}))
+	defer server.Close()
+
+	n := NewNotifier()
+	n.settings = func() NotificationSettings {
+		return NotificationSettings{NotificationRule: NotificationRule{
+			Enabled:         true,
+			On:              []string{"waiting"},
+			Webhook:         server.URL,
+			DebounceSeconds: 30,
+		}}
+	}
+	n.desktop = func(title, body string) error {
+		t.Error("desktop notifications were not configured")
+		return nil
+	}
+
+	event := StatusEvent{SessionID: "abc123", Title: "api", Group: "work", From: StatusRunning, To: StatusWaiting}
+	if !n.Notify(event) {
+		t.Fatal("expected the event to be delivered")
+	}
+
+	select {
+	case payload := <-received:
+		if payload["event"] != "status_changed" || payload["session_id"] != "abc123" ||
+			payload["from"] != "running" || payload["to"] != "waiting" || payload["message"] != "Waiting for input" {
+			t.Errorf("unexpected payload: %v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+
+	// Debounced: same session again within the window
+	if n.Notify(event) {
+		t.Error("second event within the debounce window should be dropped")
+	}
+	// Statuses not listed in "on" never notify
+	if n.Notify(StatusEvent{SessionID: "other", From: StatusWaiting, To: StatusRunning}) {
+		t.Error("running is not in the on list")
+	}
+}
+
+func TestNotifierQuietHours(t *testing.T) {
+	n := NewNotifier()
+	n.desktop = func(title, body string) error { return nil }
+	n.now = func() time.Time { return time.Date(2024, 1, 1, 23, 0, 0, 0, time.Local) }
+	n.settings = func() NotificationSettings {
+		return NotificationSettings{
+			NotificationRule: NotificationRule{
+				Enabled:    true,
+				On:         []string{"approval"},
+				Desktop:    true,
+				QuietHours: "22:00-07:00",
+			},
+			Groups: map[string]NotificationOverride{
+				"oncall": {QuietHours: stringPtr("off")},
+			},
+		}
+	}
+
+	if n.Notify(StatusEvent{SessionID: "a", Group: "personal", To: StatusApproval}) {
+		t.Error("quiet hours should suppress the notification")
+	}
+	if !n.Notify(StatusEvent{SessionID: "b", Group: "oncall", To: StatusApproval}) {
+		t.Error("the oncall group turns quiet hours off")
+	}
+}
+
+func TestGetNotificationSettingsDefaults(t *testing.T) {
+	var config UserConfig
+	if _, err := toml.Decode("[notifications]\nenabled = true\n", &config); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	userConfigCacheMu.Lock()
+	userConfigCache = &config
+	userConfigCacheMu.Unlock()
+	defer func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = nil
+		userConfigCacheMu.Unlock()
+	}()
+
+	settings := GetNotificationSettings()
+	if !settings.Enabled || !settings.Desktop {
+		t.Errorf("desktop should be the default channel, got %+v", settings.NotificationRule)
+	}
+	if strings.Join(settings.On, ",") != "waiting,approval,error" {
+		t.Errorf("On = %v", settings.On)
+	}
+	if settings.DebounceSeconds != 30 {
+		t.Errorf("DebounceSeconds = %d, want 30", settings.DebounceSeconds)
+	}
+}
+
+func TestOSC777(t *testing.T) {
+	if got := osc777("agent-deck: a;b", "Needs approval", false); got != "\x1b]777;notify;agent-deck: a,b;Needs approval\x07" {
+		t.Errorf("osc777 = %q", got)
+	}
+	if got := osc777("t", "b", true); got != "\x1bPtmux;\x1b\x1b]777;notify;t;b\x07\x1b\\" {
+		t.Errorf("osc777 in tmux = %q", got)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
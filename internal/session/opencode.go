@@ -0,0 +1,226 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GetOpenCodeStorageDir returns OpenCode's storage directory
+// Format: $XDG_DATA_HOME/opencode/storage (default ~/.local/share/opencode/storage)
+func GetOpenCodeStorageDir() string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, _ := os.UserHomeDir()
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "opencode", "storage")
+}
+
+// OpenCodeSessionInfo holds the metadata of an OpenCode session
+// Stored in storage/session/<project_id>/<session_id>.json
+type OpenCodeSessionInfo struct {
+	SessionID string
+	Title     string
+	Directory string
+	Created   time.Time
+	Updated   time.Time
+}
+
+// openCodeTime is OpenCode's {"created": ms, "updated": ms} block
+type openCodeTime struct {
+	Created   int64 `json:"created"`
+	Updated   int64 `json:"updated"`
+	Completed int64 `json:"completed"`
+}
+
+// parseOpenCodeSessionFile reads a session file. Child sessions (spawned
+// by OpenCode's task tool) are reported as errors: they can't be resumed on
+// their own.
+func parseOpenCodeSessionFile(path string) (OpenCodeSessionInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return OpenCodeSessionInfo{}, err
+	}
+	var session struct {
+		ID        string       `json:"id"`
+		ParentID  string       `json:"parentID"`
+		Title     string       `json:"title"`
+		Directory string       `json:"directory"`
+		Time      openCodeTime `json:"time"`
+	}
+	if err := json.Unmarshal(data, &session); err != nil {
+		return OpenCodeSessionInfo{}, fmt.Errorf("failed to parse session: %w", err)
+	}
+	if session.ParentID != "" {
+		return OpenCodeSessionInfo{}, fmt.Errorf("%s is a child session", session.ID)
+	}
+	return OpenCodeSessionInfo{
+		SessionID: session.ID,
+		Title:     session.Title,
+		Directory: session.Directory,
+		Created:   time.UnixMilli(session.Time.Created),
+		Updated:   time.UnixMilli(session.Time.Updated),
+	}, nil
+}
+
+// ListOpenCodeSessions returns the top-level OpenCode sessions of a project
+// directory, oldest first
+func ListOpenCodeSessions(projectPath string) ([]OpenCodeSessionInfo, error) {
+	files, err := filepath.Glob(filepath.Join(GetOpenCodeStorageDir(), "session", "*", "ses_*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	want := normalizePath(projectPath)
+	var sessions []OpenCodeSessionInfo
+	for _, file := range files {
+		info, err := parseOpenCodeSessionFile(file)
+		if err != nil || normalizePath(info.Directory) != want {
+			continue // Skip malformed files, child sessions and other projects
+		}
+		sessions = append(sessions, info)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Created.Before(sessions[j].Created)
+	})
+	return sessions, nil
+}
+
+// FindOpenCodeSession returns the first OpenCode session started in
+// projectPath at or after since, or nil if there is none yet
+func FindOpenCodeSession(projectPath string, since time.Time, exclude map[string]bool) *OpenCodeSessionInfo {
+	sessions, err := ListOpenCodeSessions(projectPath)
+	if err != nil {
+		return nil
+	}
+	// OpenCode may start a moment before agent-deck records the start time
+	since = since.Add(-5 * time.Second)
+	for idx := range sessions {
+		if !sessions[idx].Created.Before(since) && !exclude[sessions[idx].SessionID] {
+			return &sessions[idx]
+		}
+	}
+	return nil
+}
+
+// getOpenCodeLastResponse extracts the last assistant message from OpenCode's storage
+func (i *Instance) getOpenCodeLastResponse() (*ResponseOutput, error) {
+	if i.OpenCodeSessionID == "" {
+		return nil, fmt.Errorf("no OpenCode session ID available for this instance")
+	}
+	return parseOpenCodeLastAssistantMessage(GetOpenCodeStorageDir(), i.OpenCodeSessionID)
+}
+
+// openCodeMessage is a message file: storage/message/<session_id>/<message_id>.json
+type openCodeMessage struct {
+	ID   string       `json:"id"`
+	Role string       `json:"role"`
+	Time openCodeTime `json:"time"`
+}
+
+// listOpenCodeMessages returns the messages of a session, oldest first
+func listOpenCodeMessages(storageDir, sessionID string) []openCodeMessage {
+	files, _ := filepath.Glob(filepath.Join(storageDir, "message", sessionID, "msg_*.json"))
+
+	var messages []openCodeMessage
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var msg openCodeMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	// Message IDs sort by creation; the timestamp breaks ties across clients
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].Time.Created != messages[j].Time.Created {
+			return messages[i].Time.Created < messages[j].Time.Created
+		}
+		return messages[i].ID < messages[j].ID
+	})
+	return messages
+}
+
+// parseOpenCodeLastAssistantMessage reads the last assistant message of a
+// session. Messages live in storage/message/<session_id>/<message_id>.json and
+// their text in storage/part/<message_id>/<part_id>.json.
+func parseOpenCodeLastAssistantMessage(storageDir, sessionID string) (*ResponseOutput, error) {
+	messages := listOpenCodeMessages(storageDir, sessionID)
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("session messages not found for ID: %s", sessionID)
+	}
+
+	// The newest message may still be streaming or hold only tool calls
+	for idx := len(messages) - 1; idx >= 0; idx-- {
+		msg := messages[idx]
+		if msg.Role != "assistant" {
+			continue
+		}
+		text := openCodeMessageText(storageDir, msg.ID)
+		if text == "" {
+			continue
+		}
+		var timestamp string
+		if msg.Time.Created > 0 {
+			timestamp = time.UnixMilli(msg.Time.Created).UTC().Format(time.RFC3339)
+		}
+		return &ResponseOutput{
+			Tool:      "opencode",
+			Role:      "assistant",
+			Content:   text,
+			Timestamp: timestamp,
+			SessionID: sessionID,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no assistant response found in session")
+}
+
+// openCodeMessageText joins the text parts of a message in order
+func openCodeMessageText(storageDir, messageID string) string {
+	files, _ := filepath.Glob(filepath.Join(storageDir, "part", messageID, "prt_*.json"))
+	sort.Strings(files) // Part IDs sort by creation
+
+	var texts []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var part struct {
+			Type      string `json:"type"`
+			Text      string `json:"text"`
+			Synthetic bool   `json:"synthetic"`
+		}
+		if err := json.Unmarshal(data, &part); err != nil {
+			continue
+		}
+		if part.Type == "text" && !part.Synthetic && strings.TrimSpace(part.Text) != "" {
+			texts = append(texts, strings.TrimSpace(part.Text))
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// UpdateOpenCodeSession discovers the OpenCode session from its storage
+// directory: the first top-level session created in the project after the
+// agent-deck session started. Scans are throttled while the ID is unknown;
+// once found it is kept.
+func (i *Instance) UpdateOpenCodeSession(excludeIDs map[string]bool) {
+	if i.Tool != "opencode" || i.OpenCodeSessionID != "" || !i.sessionScanDue() {
+		return
+	}
+	if info := FindOpenCodeSession(i.ProjectPath, i.sessionStartTime(), excludeIDs); info != nil {
+		i.OpenCodeSessionID = info.SessionID
+		i.OpenCodeDetectedAt = time.Now()
+	}
+}
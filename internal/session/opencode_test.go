@@ -0,0 +1,136 @@
+package session
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const openCodeFixtureID = "ses_6f3a1c2b4ffe7Zq1vYhW3kQ9aB"
+
+func useOpenCodeFixtures(t *testing.T) {
+	t.Helper()
+	dir, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_DATA_HOME", dir)
+}
+
+func TestListOpenCodeSessions(t *testing.T) {
+	useOpenCodeFixtures(t)
+
+	sessions, err := ListOpenCodeSessions("/home/dev/webapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The child (subagent) session is not listed
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2: %+v", len(sessions), sessions)
+	}
+	if sessions[0].Title != "Earlier session" || sessions[1].SessionID != openCodeFixtureID {
+		t.Errorf("sessions not sorted oldest first: %+v", sessions)
+	}
+	if sessions[1].Title != "Add a health check endpoint" {
+		t.Errorf("Title = %q", sessions[1].Title)
+	}
+}
+
+func TestFindOpenCodeSession(t *testing.T) {
+	useOpenCodeFixtures(t)
+	started := time.UnixMilli(1760606000000)
+
+	info := FindOpenCodeSession("/home/dev/webapp", started, nil)
+	if info == nil || info.SessionID != openCodeFixtureID {
+		t.Fatalf("FindOpenCodeSession = %+v, want %s", info, openCodeFixtureID)
+	}
+	if info := FindOpenCodeSession("/home/dev/webapp", started, map[string]bool{openCodeFixtureID: true}); info != nil {
+		t.Errorf("excluded session returned: %+v", info)
+	}
+	if info := FindOpenCodeSession("/home/dev/webapp", started.Add(time.Hour), nil); info != nil {
+		t.Errorf("earlier session returned: %+v", info)
+	}
+	if info := FindOpenCodeSession("/home/dev/api", started, nil); info != nil {
+		t.Errorf("session of another project returned: %+v", info)
+	}
+}
+
+func TestParseOpenCodeLastAssistantMessage(t *testing.T) {
+	storageDir := filepath.Join("testdata", "opencode", "storage")
+
+	resp, err := parseOpenCodeLastAssistantMessage(storageDir, openCodeFixtureID)
+	if err != nil {
+		t.Fatalf("parseOpenCodeLastAssistantMessage: %v", err)
+	}
+	// The newest assistant message has no text yet, so the one before it is
+	// returned with its text parts joined and tool parts skipped
+	want := "I'll add the route next to the index handler.\n\nAdded `GET /healthz` in `main.go`. It returns `200 ok`."
+	if resp.Content != want {
+		t.Errorf("Content = %q, want %q", resp.Content, want)
+	}
+	if resp.Timestamp != "2025-10-16T09:13:22Z" {
+		t.Errorf("Timestamp = %q", resp.Timestamp)
+	}
+	if resp.Tool != "opencode" || resp.SessionID != openCodeFixtureID {
+		t.Errorf("Tool/SessionID = %q/%q", resp.Tool, resp.SessionID)
+	}
+
+	if _, err := parseOpenCodeLastAssistantMessage(storageDir, "ses_missing"); err == nil {
+		t.Error("expected an error for an unknown session")
+	}
+}
+
+func TestOpenCodeInstanceResume(t *testing.T) {
+	useOpenCodeFixtures(t)
+
+	inst := NewInstanceWithTool("opencode", "/home/dev/webapp", "opencode")
+	inst.Command = "opencode --model anthropic/claude-sonnet-4-5"
+	inst.CreatedAt = time.UnixMilli(1760606000000)
+
+	inst.adapter().DetectSessionID(inst)
+	if inst.OpenCodeSessionID != openCodeFixtureID {
+		t.Fatalf("OpenCodeSessionID = %q, want %q", inst.OpenCodeSessionID, openCodeFixtureID)
+	}
+	want := "opencode --model anthropic/claude-sonnet-4-5 --session " + openCodeFixtureID
+	if cmd := inst.adapter().ResumeCommand(inst); cmd != want {
+		t.Errorf("ResumeCommand = %q, want %q", cmd, want)
+	}
+	if !inst.CanRestart() {
+		t.Error("opencode session with an ID should be restartable")
+	}
+
+	// Wrapped commands are not reused for resuming
+	inst.Command = "cd web && opencode"
+	if cmd := inst.adapter().ResumeCommand(inst); cmd != "opencode --session "+openCodeFixtureID {
+		t.Errorf("ResumeCommand = %q", cmd)
+	}
+
+	resp, err := inst.GetLastResponse()
+	if err != nil {
+		t.Fatalf("GetLastResponse: %v", err)
+	}
+	if resp.SessionID != openCodeFixtureID {
+		t.Errorf("response SessionID = %q", resp.SessionID)
+	}
+}
+
+func TestDedupDiscoveredSessionIDs(t *testing.T) {
+	older := NewInstanceWithTool("a", "/home/dev/webapp", "opencode")
+	older.CreatedAt = time.Now().Add(-time.Hour)
+	older.OpenCodeSessionID = openCodeFixtureID
+	newer := NewInstanceWithTool("b", "/home/dev/webapp", "opencode")
+	newer.OpenCodeSessionID = openCodeFixtureID
+	newer.OpenCodeDetectedAt = time.Now()
+
+	UpdateClaudeSessionsWithDedup([]*Instance{newer, older})
+
+	if older.OpenCodeSessionID != openCodeFixtureID {
+		t.Error("the older session should keep its ID")
+	}
+	if newer.OpenCodeSessionID != "" || !newer.OpenCodeDetectedAt.IsZero() {
+		t.Error("the newer session's duplicate ID should be cleared")
+	}
+	if !newer.claimedElsewhere[openCodeFixtureID] {
+		t.Error("the cleared ID should be excluded from the next scan")
+	}
+}
@@ -35,17 +35,8 @@ func InitializeGlobalPool(ctx context.Context, config *UserConfig, sessions []*I
 
 	log.Printf("[Pool] Pool enabled, creating pool...")
 
-	// Create pool config
-	poolConfig := &mcppool.PoolConfig{
-		Enabled:       config.MCPPool.Enabled,
-		PoolAll:       config.MCPPool.PoolAll,
-		ExcludeMCPs:   config.MCPPool.ExcludeMCPs,
-		PoolMCPs:      config.MCPPool.PoolMCPs,
-		FallbackStdio: config.MCPPool.FallbackStdio,
-	}
-
 	// Create pool
-	pool, err := mcppool.NewPool(ctx, poolConfig)
+	pool, err := mcppool.NewPool(ctx, NewPoolConfig(config))
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +87,28 @@ func InitializeGlobalPool(ctx context.Context, config *UserConfig, sessions []*I
 	return pool, nil
 }
 
+// NewPoolConfig builds the pool configuration from the [mcp_pool] settings
+func NewPoolConfig(config *UserConfig) *mcppool.PoolConfig {
+	poolConfig := &mcppool.PoolConfig{
+		Enabled:       config.MCPPool.Enabled,
+		PoolAll:       config.MCPPool.PoolAll,
+		ExcludeMCPs:   config.MCPPool.ExcludeMCPs,
+		PoolMCPs:      config.MCPPool.PoolMCPs,
+		FallbackStdio: config.MCPPool.FallbackStdio,
+		HTTPEnabled:   config.MCPPool.HTTPEnabled,
+		PortStart:     config.MCPPool.PortStart,
+		PortEnd:       config.MCPPool.PortEnd,
+		RecordMCPs:    config.MCPPool.RecordMCPs,
+	}
+	if poolConfig.PortStart <= 0 {
+		poolConfig.PortStart = 8001
+	}
+	if poolConfig.PortEnd < poolConfig.PortStart {
+		poolConfig.PortEnd = poolConfig.PortStart + 49
+	}
+	return poolConfig
+}
+
 // GetGlobalPool returns the global pool instance (may be nil if disabled)
 func GetGlobalPool() *mcppool.Pool {
 	globalPoolMu.RLock()
@@ -116,3 +129,23 @@ func ShutdownGlobalPool() error {
 
 	return nil
 }
+
+// GetPooledHTTPURL returns the Streamable HTTP endpoint of a pooled MCP, either
+// from this process's pool or one published by another agent-deck instance.
+// Returns "" if the MCP isn't exposed over HTTP.
+func GetPooledHTTPURL(name string) string {
+	if pool := GetGlobalPool(); pool != nil {
+		if pool.ShouldPool(name) && pool.IsRunning(name) {
+			if url := pool.GetURL(name); url != "" {
+				return url
+			}
+		}
+	}
+
+	// CLI mode or a proxy discovered from another instance: use its published state
+	state := mcppool.ReadProxyState(name)
+	if state != nil && state.Status == mcppool.StatusRunning.String() {
+		return state.HTTPURL
+	}
+	return ""
+}
@@ -0,0 +1,1112 @@
+package session
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The on-disk index behind GlobalSearchIndex lives in ~/.agent-deck/search.
+// meta.json lists the indexed transcripts (with how far each has been read)
+// and the segment files. A segment is an immutable inverted index over a
+// batch of messages: new messages are buffered in memory and flushed as a new
+// segment, and segments are merged once there are too many. Message text is
+// not copied; postings point back into the transcripts.
+//
+// Only the process holding the lock file writes the index. Others search
+// what is on disk and keep what they index themselves in memory.
+
+const (
+	searchIndexVersion = 1
+
+	// maxPendingMessages is the number of buffered messages that triggers a
+	// flush to a new segment
+	maxPendingMessages = 20000
+
+	// maxSegments is the number of segments that triggers a merge
+	maxSegments = 8
+
+	// sparseInterval is how many dictionary entries share one in-memory
+	// lookup entry
+	sparseInterval = 64
+
+	// maxPrefixTerms caps the terms a word expands to in a segment
+	maxPrefixTerms = 1000
+
+	// maxPhraseChecks caps the messages read to verify a phrase
+	maxPhraseChecks = 2000
+)
+
+// Message roles stored in postings
+const (
+	roleOther uint8 = iota
+	roleUser
+	roleAssistant
+)
+
+var roleNames = map[string]uint8{"user": roleUser, "assistant": roleAssistant}
+
+// indexedDoc is an indexed transcript. Its FileTracker records how far the
+// file has been read, so appends are indexed incrementally.
+type indexedDoc struct {
+	FileTracker
+	ID        uint32 `json:"id"`
+	Tool      string `json:"tool"`
+	SessionID string `json:"session_id"`
+	CWD       string `json:"cwd,omitempty"`
+	Summary   string `json:"summary,omitempty"`
+}
+
+// indexMeta is the content of meta.json
+type indexMeta struct {
+	Version  int           `json:"version"`
+	NextDoc  uint32        `json:"next_doc"`
+	NextSeg  int           `json:"next_segment"`
+	Segments []string      `json:"segments"`
+	Docs     []*indexedDoc `json:"docs"`
+	Deleted  []uint32      `json:"deleted,omitempty"` // Docs whose messages are still in segments
+}
+
+// posting is one message a term appears in
+type posting struct {
+	msg  uint32 // Message number within the segment
+	doc  uint32
+	role uint8
+	time int64 // Unix seconds
+}
+
+// messageRef locates a message's line in its transcript
+type messageRef struct {
+	doc    uint32
+	role   uint8
+	time   int64
+	offset int64
+	length uint32
+}
+
+// segment is the read side shared by on-disk and in-memory segments
+type segment interface {
+	// postings calls fn for the postings of the term, or of every term
+	// starting with it unless exact
+	postings(term string, exact bool, fn func(posting)) error
+	message(n uint32) (messageRef, error)
+}
+
+// memSegment buffers newly indexed messages until they are flushed
+type memSegment struct {
+	terms    map[string][]posting
+	messages []messageRef
+}
+
+func newMemSegment() *memSegment {
+	return &memSegment{terms: make(map[string][]posting)}
+}
+
+// add indexes one message
+func (m *memSegment) add(ref messageRef, text string) {
+	n := uint32(len(m.messages))
+	m.messages = append(m.messages, ref)
+	seen := make(map[string]bool)
+	for _, token := range searchTokens(text) {
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+		m.terms[token] = append(m.terms[token], posting{msg: n, doc: ref.doc, role: ref.role, time: ref.time})
+	}
+}
+
+func (m *memSegment) postings(term string, exact bool, fn func(posting)) error {
+	if exact {
+		for _, p := range m.terms[term] {
+			fn(p)
+		}
+		return nil
+	}
+	expanded := 0
+	for t, list := range m.terms {
+		if !strings.HasPrefix(t, term) {
+			continue
+		}
+		if expanded++; expanded > maxPrefixTerms {
+			break
+		}
+		for _, p := range list {
+			fn(p)
+		}
+	}
+	return nil
+}
+
+func (m *memSegment) message(n uint32) (messageRef, error) {
+	if int(n) >= len(m.messages) {
+		return messageRef{}, fmt.Errorf("message %d out of range", n)
+	}
+	return m.messages[n], nil
+}
+
+// Segment file layout:
+//
+//	magic | messages (msgRecordSize each) | postings | dictionary | footer
+//
+// Dictionary entries are sorted by term: uvarint length, term, uvarint
+// postings offset, uvarint postings length. Postings are uvarint message
+// delta, uvarint doc, role byte, varint time. The footer holds the message
+// count and the postings, dictionary and dictionary end offsets (uint64s,
+// plus one reserved), then the magic again.
+const (
+	segmentMagic  = "ADSEG1\n\x00"
+	msgRecordSize = 32
+	footerSize    = 5*8 + len(segmentMagic)
+)
+
+// diskSegment is an immutable segment file
+type diskSegment struct {
+	name      string
+	f         *os.File
+	msgCount  uint32
+	postStart int64
+	dictStart int64
+	dictEnd   int64
+	sparse    []sparseEntry
+}
+
+// sparseEntry is every sparseInterval-th dictionary entry, for lookups
+type sparseEntry struct {
+	term   string
+	offset int64
+}
+
+// openSegment opens a segment file and builds its sparse dictionary
+func openSegment(dir, name string) (*diskSegment, error) {
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	footer := make([]byte, footerSize)
+	if info.Size() < int64(len(segmentMagic)+footerSize) {
+		f.Close()
+		return nil, fmt.Errorf("segment %s is truncated", name)
+	}
+	if _, err := f.ReadAt(footer, info.Size()-int64(footerSize)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if string(footer[40:]) != segmentMagic {
+		f.Close()
+		return nil, fmt.Errorf("segment %s is corrupt", name)
+	}
+	s := &diskSegment{
+		name:      name,
+		f:         f,
+		msgCount:  uint32(binary.LittleEndian.Uint64(footer[0:])),
+		postStart: int64(binary.LittleEndian.Uint64(footer[8:])),
+		dictStart: int64(binary.LittleEndian.Uint64(footer[16:])),
+		dictEnd:   int64(binary.LittleEndian.Uint64(footer[24:])),
+	}
+
+	it := s.dictionary(s.dictStart)
+	for n := 0; ; n++ {
+		offset := it.offset
+		term, _, _, ok := it.next()
+		if !ok {
+			break
+		}
+		if n%sparseInterval == 0 {
+			s.sparse = append(s.sparse, sparseEntry{term: term, offset: offset})
+		}
+	}
+	if it.err != nil {
+		f.Close()
+		return nil, fmt.Errorf("segment %s: %w", name, it.err)
+	}
+	return s, nil
+}
+
+// dictIterator reads dictionary entries sequentially
+type dictIterator struct {
+	r      *bufio.Reader
+	offset int64
+	end    int64
+	err    error
+}
+
+func (s *diskSegment) dictionary(from int64) *dictIterator {
+	return &dictIterator{
+		r:      bufio.NewReaderSize(io.NewSectionReader(s.f, from, s.dictEnd-from), 32*1024),
+		offset: from,
+		end:    s.dictEnd,
+	}
+}
+
+// next returns the next term and the location of its postings
+func (it *dictIterator) next() (term string, postOff, postLen int64, ok bool) {
+	if it.err != nil || it.offset >= it.end {
+		return "", 0, 0, false
+	}
+	fields := make([]uint64, 3)
+	var termBytes []byte
+	for i := range fields {
+		v, n, err := readUvarint(it.r)
+		if err != nil {
+			it.err = err
+			return "", 0, 0, false
+		}
+		it.offset += int64(n)
+		fields[i] = v
+		if i == 0 {
+			termBytes = make([]byte, v)
+			if _, err := io.ReadFull(it.r, termBytes); err != nil {
+				it.err = err
+				return "", 0, 0, false
+			}
+			it.offset += int64(v)
+		}
+	}
+	return string(termBytes), int64(fields[1]), int64(fields[2]), true
+}
+
+// readUvarint reads a uvarint and returns the bytes it used
+func readUvarint(r io.ByteReader) (uint64, int, error) {
+	var v uint64
+	var shift uint
+	for n := 1; ; n++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, n, err
+		}
+		if b < 0x80 {
+			return v | uint64(b)<<shift, n, nil
+		}
+		v |= uint64(b&0x7f) << shift
+		shift += 7
+		if shift > 63 {
+			return 0, n, errors.New("uvarint overflow")
+		}
+	}
+}
+
+func (s *diskSegment) postings(term string, exact bool, fn func(posting)) error {
+	// Start at the last sparse entry before the term
+	i := sort.Search(len(s.sparse), func(i int) bool { return s.sparse[i].term >= term })
+	if i > 0 {
+		i--
+	}
+	if len(s.sparse) == 0 {
+		return nil
+	}
+
+	it := s.dictionary(s.sparse[i].offset)
+	expanded := 0
+	for {
+		t, off, length, ok := it.next()
+		if !ok {
+			return it.err
+		}
+		if t < term {
+			continue
+		}
+		if exact && t != term || !exact && !strings.HasPrefix(t, term) {
+			return nil
+		}
+		if err := s.readPostings(off, length, fn); err != nil {
+			return err
+		}
+		if expanded++; exact || expanded >= maxPrefixTerms {
+			return nil
+		}
+	}
+}
+
+// readPostings decodes a postings list
+func (s *diskSegment) readPostings(off, length int64, fn func(posting)) error {
+	buf := make([]byte, length)
+	if _, err := s.f.ReadAt(buf, s.postStart+off); err != nil {
+		return err
+	}
+	var msg uint32
+	for len(buf) > 0 {
+		delta, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return fmt.Errorf("segment %s: corrupt postings", s.name)
+		}
+		buf = buf[n:]
+		doc, n := binary.Uvarint(buf)
+		if n <= 0 || len(buf) < n+1 {
+			return fmt.Errorf("segment %s: corrupt postings", s.name)
+		}
+		role := buf[n]
+		buf = buf[n+1:]
+		t, n := binary.Varint(buf)
+		if n <= 0 {
+			return fmt.Errorf("segment %s: corrupt postings", s.name)
+		}
+		buf = buf[n:]
+		msg += uint32(delta)
+		fn(posting{msg: msg, doc: uint32(doc), role: role, time: t})
+	}
+	return nil
+}
+
+func (s *diskSegment) message(n uint32) (messageRef, error) {
+	if n >= s.msgCount {
+		return messageRef{}, fmt.Errorf("message %d out of range", n)
+	}
+	buf := make([]byte, msgRecordSize)
+	if _, err := s.f.ReadAt(buf, int64(len(segmentMagic))+int64(n)*msgRecordSize); err != nil {
+		return messageRef{}, err
+	}
+	return decodeMessageRef(buf), nil
+}
+
+func encodeMessageRef(buf []byte, ref messageRef) {
+	binary.LittleEndian.PutUint32(buf[0:], ref.doc)
+	buf[4] = ref.role
+	binary.LittleEndian.PutUint64(buf[8:], uint64(ref.time))
+	binary.LittleEndian.PutUint64(buf[16:], uint64(ref.offset))
+	binary.LittleEndian.PutUint32(buf[24:], ref.length)
+}
+
+func decodeMessageRef(buf []byte) messageRef {
+	return messageRef{
+		doc:    binary.LittleEndian.Uint32(buf[0:]),
+		role:   buf[4],
+		time:   int64(binary.LittleEndian.Uint64(buf[8:])),
+		offset: int64(binary.LittleEndian.Uint64(buf[16:])),
+		length: binary.LittleEndian.Uint32(buf[24:]),
+	}
+}
+
+// segmentWriter writes a segment file: messages first, then terms in order
+type segmentWriter struct {
+	path     string
+	f        *os.File
+	w        *bufio.Writer
+	dict     *os.File // Dictionary entries, appended to the segment at the end
+	dw       *bufio.Writer
+	msgCount uint32
+	postLen  int64
+	started  bool // Postings started; no more messages
+	buf      []byte
+}
+
+func newSegmentWriter(path string) (*segmentWriter, error) {
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, err
+	}
+	dict, err := os.CreateTemp(filepath.Dir(path), "dict-*.tmp")
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	sw := &segmentWriter{
+		path: path,
+		f:    f,
+		w:    bufio.NewWriterSize(f, 256*1024),
+		dict: dict,
+		dw:   bufio.NewWriterSize(dict, 64*1024),
+		buf:  make([]byte, msgRecordSize),
+	}
+	_, err = sw.w.WriteString(segmentMagic)
+	return sw, err
+}
+
+func (sw *segmentWriter) addMessage(ref messageRef) error {
+	clear(sw.buf)
+	encodeMessageRef(sw.buf, ref)
+	sw.msgCount++
+	_, err := sw.w.Write(sw.buf)
+	return err
+}
+
+// addTerm writes a term's postings (sorted by message)
+func (sw *segmentWriter) addTerm(term string, postings []posting) error {
+	var enc []byte
+	var prev uint32
+	for _, p := range postings {
+		enc = binary.AppendUvarint(enc, uint64(p.msg-prev))
+		enc = binary.AppendUvarint(enc, uint64(p.doc))
+		enc = append(enc, p.role)
+		enc = binary.AppendVarint(enc, p.time)
+		prev = p.msg
+	}
+	if _, err := sw.w.Write(enc); err != nil {
+		return err
+	}
+
+	var entry []byte
+	entry = binary.AppendUvarint(entry, uint64(len(term)))
+	entry = append(entry, term...)
+	entry = binary.AppendUvarint(entry, uint64(sw.postLen))
+	entry = binary.AppendUvarint(entry, uint64(len(enc)))
+	sw.postLen += int64(len(enc))
+	_, err := sw.dw.Write(entry)
+	return err
+}
+
+// finish appends the dictionary and footer and moves the file into place
+func (sw *segmentWriter) finish() error {
+	defer os.Remove(sw.dict.Name())
+	defer sw.dict.Close()
+
+	postStart := int64(len(segmentMagic)) + int64(sw.msgCount)*msgRecordSize
+	dictStart := postStart + sw.postLen
+	if err := sw.dw.Flush(); err != nil {
+		return sw.abort(err)
+	}
+	if _, err := sw.dict.Seek(0, io.SeekStart); err != nil {
+		return sw.abort(err)
+	}
+	dictLen, err := io.Copy(sw.w, sw.dict)
+	if err != nil {
+		return sw.abort(err)
+	}
+
+	footer := make([]byte, footerSize)
+	binary.LittleEndian.PutUint64(footer[0:], uint64(sw.msgCount))
+	binary.LittleEndian.PutUint64(footer[8:], uint64(postStart))
+	binary.LittleEndian.PutUint64(footer[16:], uint64(dictStart))
+	binary.LittleEndian.PutUint64(footer[24:], uint64(dictStart+dictLen))
+	copy(footer[40:], segmentMagic)
+	if _, err := sw.w.Write(footer); err != nil {
+		return sw.abort(err)
+	}
+	if err := sw.w.Flush(); err != nil {
+		return sw.abort(err)
+	}
+	if err := sw.f.Sync(); err != nil {
+		return sw.abort(err)
+	}
+	if err := sw.f.Close(); err != nil {
+		return sw.abort(err)
+	}
+	return os.Rename(sw.path+".tmp", sw.path)
+}
+
+func (sw *segmentWriter) abort(err error) error {
+	sw.f.Close()
+	os.Remove(sw.path + ".tmp")
+	return err
+}
+
+// searchStore is the on-disk index: documents, segments and the buffer of
+// messages not yet flushed
+type searchStore struct {
+	dir      string
+	lock     *os.File // Held by the writing process (nil: read-only)
+	writable bool
+
+	mu       sync.RWMutex // Guards everything below
+	docs     map[string]*indexedDoc
+	docsByID map[uint32]*indexedDoc
+	deleted  map[uint32]bool
+	nextDoc  uint32
+	nextSeg  int
+	segments []*diskSegment
+	mem      *memSegment
+
+	mergeMu sync.Mutex // One merge at a time
+
+	// Reads an indexed message back to check phrases (nil: accept the
+	// messages containing every word)
+	messageText func(doc *indexedDoc, ref messageRef) (string, bool)
+}
+
+// openSearchStore opens (or creates) the index in dir. The first process to
+// open it becomes the writer.
+func openSearchStore(dir string) (*searchStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create search index directory: %w", err)
+	}
+	s := &searchStore{
+		dir:      dir,
+		docs:     make(map[string]*indexedDoc),
+		docsByID: make(map[uint32]*indexedDoc),
+		deleted:  make(map[uint32]bool),
+		mem:      newMemSegment(),
+	}
+
+	lock, err := os.OpenFile(filepath.Join(dir, "lock"), os.O_CREATE|os.O_RDWR, 0600)
+	if err == nil {
+		if tryLockFile(lock) == nil {
+			s.lock, s.writable = lock, true
+		} else {
+			lock.Close()
+		}
+	}
+
+	// The writer may replace segments while a reader opens them: retry
+	for attempt := 0; ; attempt++ {
+		err = s.load()
+		if err == nil || attempt == 2 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err != nil {
+		log.Printf("GlobalSearch: rebuilding search index: %v", err)
+		s.reset()
+	}
+	if s.writable {
+		s.removeOrphans()
+	}
+	return s, nil
+}
+
+// load reads meta.json and opens the segments
+func (s *searchStore) load() error {
+	s.reset()
+	data, err := os.ReadFile(filepath.Join(s.dir, "meta.json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var meta indexMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return err
+	}
+	if meta.Version != searchIndexVersion {
+		return fmt.Errorf("index version %d, want %d", meta.Version, searchIndexVersion)
+	}
+
+	for _, name := range meta.Segments {
+		seg, err := openSegment(s.dir, name)
+		if err != nil {
+			return err
+		}
+		s.segments = append(s.segments, seg)
+	}
+	for _, doc := range meta.Docs {
+		s.docs[doc.Path] = doc
+		s.docsByID[doc.ID] = doc
+	}
+	for _, id := range meta.Deleted {
+		s.deleted[id] = true
+	}
+	s.nextDoc, s.nextSeg = meta.NextDoc, meta.NextSeg
+	return nil
+}
+
+// reset empties the in-memory state
+func (s *searchStore) reset() {
+	for _, seg := range s.segments {
+		seg.f.Close()
+	}
+	s.segments = nil
+	s.docs = make(map[string]*indexedDoc)
+	s.docsByID = make(map[uint32]*indexedDoc)
+	s.deleted = make(map[uint32]bool)
+	s.nextDoc, s.nextSeg = 0, 0
+	s.mem = newMemSegment()
+}
+
+// removeOrphans deletes segment files meta.json doesn't reference (left by
+// a crash during a flush or merge)
+func (s *searchStore) removeOrphans() {
+	live := make(map[string]bool, len(s.segments))
+	for _, seg := range s.segments {
+		live[seg.name] = true
+	}
+	entries, _ := os.ReadDir(s.dir)
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasSuffix(name, ".tmp") || strings.HasPrefix(name, "seg-") && !live[name] {
+			os.Remove(filepath.Join(s.dir, name))
+		}
+	}
+}
+
+// writeMeta saves meta.json atomically. Callers hold s.mu.
+func (s *searchStore) writeMeta() error {
+	meta := indexMeta{
+		Version: searchIndexVersion,
+		NextDoc: s.nextDoc,
+		NextSeg: s.nextSeg,
+		Docs:    make([]*indexedDoc, 0, len(s.docs)),
+	}
+	for _, seg := range s.segments {
+		meta.Segments = append(meta.Segments, seg.name)
+	}
+	for _, doc := range s.docs {
+		meta.Docs = append(meta.Docs, doc)
+	}
+	sort.Slice(meta.Docs, func(i, j int) bool { return meta.Docs[i].ID < meta.Docs[j].ID })
+	for id := range s.deleted {
+		meta.Deleted = append(meta.Deleted, id)
+	}
+	sort.Slice(meta.Deleted, func(i, j int) bool { return meta.Deleted[i] < meta.Deleted[j] })
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, "meta.json")
+	if err := os.WriteFile(path+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// doc returns the indexed document for a transcript
+func (s *searchStore) doc(path string) *indexedDoc {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.docs[path]
+}
+
+// removeDoc drops a transcript from the index; its messages are skipped
+// until the next merge
+func (s *searchStore) removeDoc(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if doc, ok := s.docs[path]; ok {
+		delete(s.docs, path)
+		s.deleted[doc.ID] = true
+	}
+}
+
+// addMessages indexes messages read from a transcript and records how far it
+// has been read. doc is the document the messages continue; nil starts a new
+// one (replacing any previous document for the path).
+func (s *searchStore) addMessages(doc *indexedDoc, update indexedDoc, messages []transcriptMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.docs[update.Path]
+	if !ok || current != doc {
+		if ok {
+			s.deleted[current.ID] = true
+		}
+		doc = &indexedDoc{ID: s.nextDoc}
+		s.nextDoc++
+		s.docsByID[doc.ID] = doc
+	}
+
+	// What the earlier part of the transcript told us stays
+	update.ID = doc.ID
+	if doc.SessionID != "" {
+		update.SessionID = doc.SessionID
+	}
+	if doc.CWD != "" {
+		update.CWD = doc.CWD
+	}
+	if doc.Summary != "" {
+		update.Summary = doc.Summary
+	}
+	*doc = update
+	s.docs[doc.Path] = doc
+
+	for _, m := range messages {
+		ref := messageRef{doc: doc.ID, role: m.role, time: m.time.Unix(), offset: m.offset, length: uint32(m.length)}
+		s.mem.add(ref, m.text)
+	}
+}
+
+// liveDocs returns a copy of every indexed transcript
+func (s *searchStore) liveDocs() []indexedDoc {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	docs := make([]indexedDoc, 0, len(s.docs))
+	for _, doc := range s.docs {
+		docs = append(docs, *doc)
+	}
+	return docs
+}
+
+// dataSize returns the total size of the indexed transcripts
+func (s *searchStore) dataSize() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var total int64
+	for _, doc := range s.docs {
+		total += doc.LastSize
+	}
+	return total
+}
+
+// pending returns the number of buffered messages
+func (s *searchStore) pending() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.mem.messages)
+}
+
+// flush writes the buffered messages to a new segment and saves meta.json.
+// Read-only stores keep them in memory.
+func (s *searchStore) flush() error {
+	if !s.writable {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.mem.messages) > 0 {
+		name := fmt.Sprintf("seg-%06d.idx", s.nextSeg)
+		sw, err := newSegmentWriter(filepath.Join(s.dir, name))
+		if err != nil {
+			return err
+		}
+		for _, ref := range s.mem.messages {
+			if err := sw.addMessage(ref); err != nil {
+				return sw.abort(err)
+			}
+		}
+		terms := make([]string, 0, len(s.mem.terms))
+		for term := range s.mem.terms {
+			terms = append(terms, term)
+		}
+		sort.Strings(terms)
+		for _, term := range terms {
+			if err := sw.addTerm(term, s.mem.terms[term]); err != nil {
+				return sw.abort(err)
+			}
+		}
+		if err := sw.finish(); err != nil {
+			return err
+		}
+		seg, err := openSegment(s.dir, name)
+		if err != nil {
+			return err
+		}
+		s.nextSeg++
+		s.segments = append(s.segments, seg)
+		s.mem = newMemSegment()
+	}
+	return s.writeMeta()
+}
+
+// needsMerge reports whether there are enough segments to merge
+func (s *searchStore) needsMerge() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.writable && len(s.segments) >= maxSegments
+}
+
+// merge combines all segments into one, dropping the messages of removed
+// transcripts. Searches keep using the old segments until it is done.
+func (s *searchStore) merge() error {
+	if !s.writable {
+		return nil
+	}
+	s.mergeMu.Lock()
+	defer s.mergeMu.Unlock()
+
+	s.mu.Lock()
+	segments := append([]*diskSegment(nil), s.segments...)
+	deleted := make(map[uint32]bool, len(s.deleted))
+	for id := range s.deleted {
+		deleted[id] = true
+	}
+	name := fmt.Sprintf("seg-%06d.idx", s.nextSeg)
+	s.nextSeg++
+	s.mu.Unlock()
+	if len(segments) < 2 && len(deleted) == 0 {
+		return nil
+	}
+
+	sw, err := newSegmentWriter(filepath.Join(s.dir, name))
+	if err != nil {
+		return err
+	}
+
+	// Renumber the messages kept
+	remap := make([][]int64, len(segments))
+	var next int64
+	for i, seg := range segments {
+		remap[i] = make([]int64, seg.msgCount)
+		for n := uint32(0); n < seg.msgCount; n++ {
+			ref, err := seg.message(n)
+			if err != nil {
+				return sw.abort(err)
+			}
+			if deleted[ref.doc] {
+				remap[i][n] = -1
+				continue
+			}
+			if err := sw.addMessage(ref); err != nil {
+				return sw.abort(err)
+			}
+			remap[i][n] = next
+			next++
+		}
+	}
+
+	// Merge the dictionaries
+	iters := make([]*dictIterator, len(segments))
+	heads := make([]struct {
+		term     string
+		off, len int64
+		ok       bool
+	}, len(segments))
+	for i, seg := range segments {
+		iters[i] = seg.dictionary(seg.dictStart)
+		heads[i].term, heads[i].off, heads[i].len, heads[i].ok = iters[i].next()
+	}
+	for {
+		term, found := "", false
+		for _, h := range heads {
+			if h.ok && (!found || h.term < term) {
+				term, found = h.term, true
+			}
+		}
+		if !found {
+			break
+		}
+		var merged []posting
+		for i, h := range heads {
+			if !h.ok || h.term != term {
+				continue
+			}
+			err := segments[i].readPostings(h.off, h.len, func(p posting) {
+				if n := remap[i][p.msg]; n >= 0 {
+					p.msg = uint32(n)
+					merged = append(merged, p)
+				}
+			})
+			if err != nil {
+				return sw.abort(err)
+			}
+			heads[i].term, heads[i].off, heads[i].len, heads[i].ok = iters[i].next()
+		}
+		if len(merged) > 0 {
+			if err := sw.addTerm(term, merged); err != nil {
+				return sw.abort(err)
+			}
+		}
+	}
+	for _, it := range iters {
+		if it.err != nil {
+			return sw.abort(it.err)
+		}
+	}
+	if err := sw.finish(); err != nil {
+		return err
+	}
+	seg, err := openSegment(s.dir, name)
+	if err != nil {
+		return err
+	}
+
+	// Swap the merged segments for the new one
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	merged := make(map[*diskSegment]bool, len(segments))
+	for _, old := range segments {
+		merged[old] = true
+	}
+	kept := []*diskSegment{seg}
+	for _, current := range s.segments {
+		if !merged[current] {
+			kept = append(kept, current)
+		}
+	}
+	s.segments = kept
+	for id := range deleted {
+		delete(s.deleted, id)
+		if doc := s.docsByID[id]; doc != nil && s.docs[doc.Path] != doc {
+			delete(s.docsByID, id)
+		}
+	}
+	if err := s.writeMeta(); err != nil {
+		return err
+	}
+	for _, old := range segments {
+		old.f.Close()
+		os.Remove(filepath.Join(s.dir, old.name))
+	}
+	return nil
+}
+
+// close flushes pending messages and releases the lock
+func (s *searchStore) close() {
+	if err := s.flush(); err != nil {
+		log.Printf("GlobalSearch: failed to save search index: %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, seg := range s.segments {
+		seg.f.Close()
+	}
+	s.segments = nil
+	if s.lock != nil {
+		s.lock.Close()
+		s.lock = nil
+	}
+}
+
+// docCount returns the number of indexed transcripts
+func (s *searchStore) docCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.docs)
+}
+
+// searchHit is a transcript matching a query
+type searchHit struct {
+	doc  indexedDoc
+	hits int // Matching messages, summed over the clauses
+}
+
+// search runs a query and returns the matching transcripts, best first
+func (s *searchStore) search(q SearchQuery, limit int) []searchHit {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Conversation-level filters
+	allowed := func(id uint32) bool {
+		if s.deleted[id] {
+			return false
+		}
+		doc := s.docsByID[id]
+		if doc == nil {
+			return false
+		}
+		if len(q.Tools) > 0 && !containsString(q.Tools, strings.ToLower(doc.Tool)) {
+			return false
+		}
+		if len(q.Projects) > 0 {
+			cwd := strings.ToLower(doc.CWD)
+			for _, project := range q.Projects {
+				if strings.Contains(cwd, project) {
+					return true
+				}
+			}
+			return false
+		}
+		return true
+	}
+
+	var scores map[uint32]int
+	if len(q.Clauses) == 0 {
+		// Filters only: every conversation active in the time range
+		scores = make(map[uint32]int)
+		for id, doc := range s.docsByID {
+			if allowed(id) && q.matchesTime(doc.LastMod.Unix()) {
+				scores[id] = 0
+			}
+		}
+	}
+	for _, clause := range q.Clauses {
+		counts := s.matchClause(clause, q, allowed)
+		if scores == nil {
+			scores = counts
+			continue
+		}
+		for id, score := range scores {
+			if n, ok := counts[id]; ok {
+				scores[id] = score + n
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]searchHit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, searchHit{doc: *s.docsByID[id], hits: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].hits != hits[j].hits {
+			return hits[i].hits > hits[j].hits
+		}
+		return hits[i].doc.LastMod.After(hits[j].doc.LastMod)
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// matchClause counts the messages matching a clause per transcript
+func (s *searchStore) matchClause(clause SearchClause, q SearchQuery, allowed func(uint32) bool) map[uint32]int {
+	counts := make(map[uint32]int)
+	role, hasRole := roleNames[clause.Role]
+	keep := func(p posting) bool {
+		return (!hasRole || p.role == role) && q.matchesTime(p.time) && allowed(p.doc)
+	}
+
+	segments := make([]segment, 0, len(s.segments)+1)
+	for _, seg := range s.segments {
+		segments = append(segments, seg)
+	}
+	segments = append(segments, s.mem)
+
+	checks := 0
+	for _, seg := range segments {
+		if !clause.Phrase {
+			seen := make(map[uint32]bool)
+			_ = seg.postings(clause.tokens[0], false, func(p posting) {
+				if !seen[p.msg] && keep(p) {
+					seen[p.msg] = true
+					counts[p.doc]++
+				}
+			})
+			continue
+		}
+
+		// Phrases: messages with every word, then check the text
+		var candidates map[uint32]posting
+		for i, token := range clause.tokens {
+			found := make(map[uint32]posting)
+			last := i == len(clause.tokens)-1
+			_ = seg.postings(token, !last, func(p posting) {
+				if candidates == nil && keep(p) {
+					found[p.msg] = p
+				} else if _, ok := candidates[p.msg]; ok {
+					found[p.msg] = p
+				}
+			})
+			candidates = found
+			if len(candidates) == 0 {
+				break
+			}
+		}
+		for n, p := range candidates {
+			if checks++; checks > maxPhraseChecks {
+				break
+			}
+			ref, err := seg.message(n)
+			if err != nil {
+				continue
+			}
+			if s.messageText == nil {
+				counts[p.doc]++
+				continue
+			}
+			doc := s.docsByID[p.doc]
+			if text, ok := s.messageText(doc, ref); ok && strings.Contains(strings.ToLower(text), clause.Text) {
+				counts[p.doc]++
+			}
+		}
+	}
+	return counts
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package session
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxTermLen caps the length of indexed terms (longer runs are cut)
+const maxTermLen = 40
+
+// SearchQuery is a parsed global search query. Every clause must match in the
+// same conversation; filters narrow the conversations and messages searched.
+//
+//	"react hooks" project:agent-deck user:migrate after:2025-01-01 tool:claude
+type SearchQuery struct {
+	Clauses  []SearchClause
+	Projects []string  // project: conversations whose directory contains one of these
+	Tools    []string  // tool: conversations of one of these tools
+	After    time.Time // after: only messages at or after this time
+	Before   time.Time // before: only messages before this time
+}
+
+// SearchClause is a word or quoted phrase of a query. Words match terms
+// starting with them; phrases (and words like "foo-bar") must appear as is.
+type SearchClause struct {
+	Text   string // Lowercased text as typed
+	Phrase bool
+	Role   string // "user" or "assistant" (user:, assistant:), "" for any
+	tokens []string
+}
+
+// ParseSearchQuery parses a global search query. Unknown key: prefixes and
+// invalid dates are searched as plain text.
+func ParseSearchQuery(query string) SearchQuery {
+	var q SearchQuery
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		var key, value string
+		var quoted bool
+		key, value, quoted, rest = nextQueryPart(rest)
+
+		switch key {
+		case "project":
+			q.Projects = append(q.Projects, strings.ToLower(value))
+			continue
+		case "tool":
+			q.Tools = append(q.Tools, strings.ToLower(value))
+			continue
+		case "after", "before":
+			if t, ok := parseSearchDate(value); ok {
+				if key == "after" {
+					q.After = t
+				} else {
+					q.Before = t
+				}
+				continue
+			}
+			value, quoted = key+":"+value, false
+			key = ""
+		case "user", "assistant", "":
+		default:
+			value, quoted = key+":"+value, false
+			key = ""
+		}
+
+		tokens := searchTokens(value)
+		if len(tokens) == 0 {
+			continue
+		}
+		q.Clauses = append(q.Clauses, SearchClause{
+			Text:   strings.ToLower(value),
+			Phrase: quoted || len(tokens) > 1,
+			Role:   key,
+			tokens: tokens,
+		})
+	}
+	return q
+}
+
+// nextQueryPart splits the next `key:value`, `key:"a b"`, `"a b"` or word
+// off a query
+func nextQueryPart(s string) (key, value string, quoted bool, rest string) {
+	if i := strings.IndexAny(s, ": \""); i > 0 && s[i] == ':' {
+		key, s = strings.ToLower(s[:i]), s[i+1:]
+	}
+	if strings.HasPrefix(s, "\"") {
+		s = s[1:]
+		end := strings.IndexByte(s, '"')
+		if end == -1 {
+			end = len(s)
+		}
+		value, rest = s[:end], s[min(end+1, len(s)):]
+		return key, value, true, strings.TrimSpace(rest)
+	}
+	end := strings.IndexByte(s, ' ')
+	if end == -1 {
+		end = len(s)
+	}
+	return key, s[:end], false, strings.TrimSpace(s[end:])
+}
+
+// parseSearchDate parses a before:/after: value: a date (2025-01-31) or an
+// age in days or weeks (7d, 2w)
+func parseSearchDate(value string) (time.Time, bool) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, true
+	}
+	if len(value) >= 2 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && n >= 0 {
+			switch value[len(value)-1] {
+			case 'd':
+				return time.Now().AddDate(0, 0, -n), true
+			case 'w':
+				return time.Now().AddDate(0, 0, -7*n), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// IsEmpty reports whether the query has neither text nor filters
+func (q *SearchQuery) IsEmpty() bool {
+	return len(q.Clauses) == 0 && len(q.Projects) == 0 && len(q.Tools) == 0 &&
+		q.After.IsZero() && q.Before.IsZero()
+}
+
+// HighlightText returns the text of the query without its filters, for
+// highlighting results
+func (q *SearchQuery) HighlightText() string {
+	texts := make([]string, len(q.Clauses))
+	for i, c := range q.Clauses {
+		texts[i] = c.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+// matchesTime reports whether a message time passes the before:/after: filters
+func (q *SearchQuery) matchesTime(unix int64) bool {
+	if !q.After.IsZero() && unix < q.After.Unix() {
+		return false
+	}
+	if !q.Before.IsZero() && unix >= q.Before.Unix() {
+		return false
+	}
+	return true
+}
+
+// searchTokens splits text into lowercase index terms: runs of letters and
+// digits of at least two bytes, cut at maxTermLen
+func searchTokens(text string) []string {
+	var tokens []string
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(field) < 2 {
+			continue
+		}
+		token := strings.ToLower(field)
+		if len(token) > maxTermLen {
+			token = strings.ToValidUTF8(token[:maxTermLen], "")
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
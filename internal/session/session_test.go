@@ -82,13 +82,14 @@ func TestFilterByQuery(t *testing.T) {
 	instances := []*Instance{
 		{Title: "devops-claude", ProjectPath: "/home/user/devops", Tool: "claude"},
 		{Title: "frontend-shell", ProjectPath: "/home/user/frontend", Tool: "shell"},
-		{Title: "backend-opencode", ProjectPath: "/home/user/backend", Tool: "opencode"},
+		{Title: "backend-opencode", ProjectPath: "/home/user/backend", Tool: "opencode", Status: StatusApproval},
 	}
 
 	tests := []struct {
 		query    string
 		expected int
 	}{
+		{"approval", 1},
 		{"devops", 1},
 		{"claude", 1},
 		{"frontend", 1},
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -59,6 +60,7 @@ type StorageData struct {
 // InstanceData represents the serializable session data
 type InstanceData struct {
 	ID              string    `json:"id"`
+	Revision        int64     `json:"revision,omitempty"` // Incremented by every save that changes the record
 	Title           string    `json:"title"`
 	ProjectPath     string    `json:"project_path"`
 	GroupPath       string    `json:"group_path"`
@@ -74,12 +76,32 @@ type InstanceData struct {
 	ClaudeSessionID  string    `json:"claude_session_id,omitempty"`
 	ClaudeDetectedAt time.Time `json:"claude_detected_at,omitempty"`
 
+	// Per-session Claude flags (model, permission mode, ...)
+	ClaudeOptions *ClaudeOptions `json:"claude_options,omitempty"`
+
+	// Environment variables set for the session's processes
+	Env map[string]string `json:"env,omitempty"`
+
+	// Prompt sent on first start (see Instance.InitialPrompt)
+	InitialPrompt string `json:"initial_prompt,omitempty"`
+
 	// Gemini session (persisted for resume after app restart)
 	GeminiSessionID  string    `json:"gemini_session_id,omitempty"`
 	GeminiDetectedAt time.Time `json:"gemini_detected_at,omitempty"`
 
+	// Codex and OpenCode conversations (persisted for resume after app restart)
+	CodexSessionID     string    `json:"codex_session_id,omitempty"`
+	CodexDetectedAt    time.Time `json:"codex_detected_at,omitempty"`
+	OpenCodeSessionID  string    `json:"opencode_session_id,omitempty"`
+	OpenCodeDetectedAt time.Time `json:"opencode_detected_at,omitempty"`
+
 	// MCP tracking (persisted for sync status display)
 	LoadedMCPNames []string `json:"loaded_mcp_names,omitempty"`
+
+	// Git worktree created for the session (see Instance.SetupWorktree)
+	WorktreePath   string `json:"worktree_path,omitempty"`
+	WorktreeBranch string `json:"worktree_branch,omitempty"`
+	WorktreeRepo   string `json:"worktree_repo,omitempty"`
 }
 
 // GroupData represents serializable group data
@@ -88,14 +110,32 @@ type GroupData struct {
 	Path     string `json:"path"`
 	Expanded bool   `json:"expanded"`
 	Order    int    `json:"order"`
+	Revision int64  `json:"revision,omitempty"` // Incremented by every save that changes the group
 }
 
 // Storage handles persistence of session data
 // Thread-safe with mutex protection for concurrent access
+//
+// Several processes share a profile's file (the TUI, CLI commands, scripts),
+// each loading it, changing some sessions and saving. Saves are serialized
+// with an advisory lock and merged into the file as it is on disk: only the
+// records and fields this process changed since it loaded (or last saved)
+// are written, so concurrent changes to other sessions or fields survive.
 type Storage struct {
 	path    string
 	profile string     // The profile this storage is for
 	mu      sync.Mutex // Protects all file operations
+
+	// What this process last loaded or saved, by ID and group path: the base
+	// its changes are measured from (nil before the first load or save)
+	baseInstances map[string]*InstanceData
+	baseGroups    map[string]*GroupData
+
+	// The journal as this process last wrote it: its last Seq, size and
+	// number of entries (re-read when another process changed its size)
+	journalSeq     int64
+	journalSize    int64
+	journalEntries int
 }
 
 // NewStorage creates a new storage instance using the default profile.
@@ -159,8 +199,32 @@ func (s *Storage) Path() string {
 	return s.path
 }
 
-// cleanupTempFiles removes any leftover .tmp files from previous crashes
+// lock takes the profile's storage lock (sessions.json.lock), held while a
+// save reads, merges and writes the file. The returned function releases it.
+func (s *Storage) lock() (func(), error) {
+	f, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage lock: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock storage: %w", err)
+	}
+	return func() { f.Close() }, nil
+}
+
+// cleanupTempFiles removes any leftover .tmp files from previous crashes.
+// A temp file is only left over if no other process is saving.
 func (s *Storage) cleanupTempFiles() {
+	lockFileHandle, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return
+	}
+	defer lockFileHandle.Close()
+	if tryLockFile(lockFileHandle) != nil {
+		return // Another process is saving
+	}
+
 	tmpPath := s.path + ".tmp"
 	if _, err := os.Stat(tmpPath); err == nil {
 		if err := os.Remove(tmpPath); err != nil {
@@ -179,60 +243,83 @@ func (s *Storage) Save(instances []*Instance) error {
 
 // SaveWithGroups persists instances and groups to JSON file
 // Uses atomic write pattern with:
-// - Mutex for thread safety
+// - Mutex for thread safety, file lock for other processes
+// - Merge with changes saved by other processes (see Storage)
 // - Rolling backups (3 generations)
 // - fsync for durability
 // - Data validation
+//
+// A nil groupTree leaves the saved groups as they are.
 func (s *Storage) SaveWithGroups(instances []*Instance, groupTree *GroupTree) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Convert instances to serializable format
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ours := StorageData{Instances: instancesToData(instances)}
+	if groupTree != nil {
+		ours.Groups = groupsToData(groupTree)
+	}
+
+	// Merge into the file as other processes left it. Without a readable
+	// file there is nothing to merge with, and everything of ours is written.
+	disk := s.readLocked()
+	baseInstances, baseGroups := s.baseInstances, s.baseGroups
+	if disk == nil {
+		baseInstances, baseGroups = nil, nil
+	}
+	var diskData StorageData
+	if disk != nil {
+		diskData = *disk
+	}
 	data := StorageData{
-		Instances: make([]*InstanceData, len(instances)),
-		UpdatedAt: time.Now(),
+		Instances: mergeInstances(baseInstances, ours.Instances, diskData.Instances),
+		Groups:    diskData.Groups,
+	}
+	if groupTree != nil {
+		data.Groups = mergeGroups(baseGroups, ours.Groups, diskData.Groups)
 	}
 
-	for i, inst := range instances {
-		tmuxName := ""
-		if inst.tmuxSession != nil {
-			tmuxName = inst.tmuxSession.Name
-		}
-		data.Instances[i] = &InstanceData{
-			ID:               inst.ID,
-			Title:            inst.Title,
-			ProjectPath:      inst.ProjectPath,
-			GroupPath:        inst.GroupPath,
-			ParentSessionID:  inst.ParentSessionID,
-			Command:          inst.Command,
-			Tool:             inst.Tool,
-			Status:           inst.Status,
-			CreatedAt:        inst.CreatedAt,
-			LastAccessedAt:   inst.LastAccessedAt,
-			TmuxSession:      tmuxName,
-			ClaudeSessionID:  inst.ClaudeSessionID,
-			ClaudeDetectedAt: inst.ClaudeDetectedAt,
-			GeminiSessionID:  inst.GeminiSessionID,
-			GeminiDetectedAt: inst.GeminiDetectedAt,
-			LoadedMCPNames:   inst.LoadedMCPNames,
-		}
+	if err := s.write(disk, &data, 0); err != nil {
+		return err
 	}
 
-	// Save groups (including empty ones)
+	// Later saves are measured from what this process has now written
+	s.baseInstances = recordsByKey(ours.Instances, func(d *InstanceData) string { return d.ID })
 	if groupTree != nil {
-		data.Groups = make([]*GroupData, 0, len(groupTree.GroupList))
-		for _, g := range groupTree.GroupList {
-			data.Groups = append(data.Groups, &GroupData{
-				Name:     g.Name,
-				Path:     g.Path,
-				Expanded: g.Expanded,
-				Order:    g.Order,
-			})
-		}
+		s.baseGroups = recordsByKey(ours.Groups, func(g *GroupData) string { return g.Path })
 	}
 
+	return nil
+}
+
+// readLocked reads the file for a change made under the storage lock. It
+// returns empty data if there is no file yet, and nil if it can't be read.
+func (s *Storage) readLocked() *StorageData {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return &StorageData{}
+	}
+	data, err := s.loadFromFile(s.path)
+	if err != nil {
+		log.Printf("Warning: storage file unreadable (%v), overwriting it", err)
+		return nil
+	}
+	return data
+}
+
+// write replaces the file with data, under the storage lock. The changes
+// from prev (what the file held, nil if unknown) are appended to the journal,
+// and deleted sessions moved to the trash; undoes is the journal entry the
+// changes revert, if any.
+func (s *Storage) write(prev, data *StorageData, undoes int64) error {
+	data.UpdatedAt = time.Now()
+
 	// Validate data before saving
-	if err := validateStorageData(&data); err != nil {
+	if err := validateStorageData(data); err != nil {
 		return fmt.Errorf("data validation failed: %w", err)
 	}
 
@@ -274,9 +361,202 @@ func (s *Storage) SaveWithGroups(instances []*Instance, groupTree *GroupTree) er
 		return fmt.Errorf("failed to finalize save: %w", err)
 	}
 
+	// The save is done; failing to record it only loses the ability to undo
+	if prev != nil {
+		changes := diffStorage(prev, data)
+		if len(changes) > 0 || undoes != 0 {
+			if err := s.recordChanges(changes, undoes); err != nil {
+				log.Printf("Warning: failed to record changes: %v", err)
+			}
+		}
+		if err := s.updateTrash(changes); err != nil {
+			log.Printf("Warning: failed to update trash: %v", err)
+		}
+	}
+
 	return nil
 }
 
+// instancesToData converts instances to their saved form
+func instancesToData(instances []*Instance) []*InstanceData {
+	data := make([]*InstanceData, len(instances))
+	for i, inst := range instances {
+		tmuxName := ""
+		if inst.tmuxSession != nil {
+			tmuxName = inst.tmuxSession.Name
+		}
+		data[i] = &InstanceData{
+			ID:                 inst.ID,
+			Title:              inst.Title,
+			ProjectPath:        inst.ProjectPath,
+			GroupPath:          inst.GroupPath,
+			ParentSessionID:    inst.ParentSessionID,
+			Command:            inst.Command,
+			Tool:               inst.Tool,
+			Status:             inst.Status,
+			CreatedAt:          inst.CreatedAt,
+			LastAccessedAt:     inst.LastAccessedAt,
+			TmuxSession:        tmuxName,
+			ClaudeSessionID:    inst.ClaudeSessionID,
+			ClaudeDetectedAt:   inst.ClaudeDetectedAt,
+			ClaudeOptions:      inst.ClaudeOptions,
+			Env:                inst.Env,
+			InitialPrompt:      inst.InitialPrompt,
+			GeminiSessionID:    inst.GeminiSessionID,
+			GeminiDetectedAt:   inst.GeminiDetectedAt,
+			CodexSessionID:     inst.CodexSessionID,
+			CodexDetectedAt:    inst.CodexDetectedAt,
+			OpenCodeSessionID:  inst.OpenCodeSessionID,
+			OpenCodeDetectedAt: inst.OpenCodeDetectedAt,
+			LoadedMCPNames:     inst.LoadedMCPNames,
+			WorktreePath:       inst.WorktreePath,
+			WorktreeBranch:     inst.WorktreeBranch,
+			WorktreeRepo:       inst.WorktreeRepo,
+		}
+	}
+	return data
+}
+
+// groupsToData converts a group tree's groups (including empty ones) to their
+// saved form
+func groupsToData(groupTree *GroupTree) []*GroupData {
+	data := make([]*GroupData, 0, len(groupTree.GroupList))
+	for _, g := range groupTree.GroupList {
+		data = append(data, &GroupData{
+			Name:     g.Name,
+			Path:     g.Path,
+			Expanded: g.Expanded,
+			Order:    g.Order,
+		})
+	}
+	return data
+}
+
+// recordsByKey indexes saved records
+func recordsByKey[T any](records []*T, key func(*T) string) map[string]*T {
+	m := make(map[string]*T, len(records))
+	for _, r := range records {
+		m[key(r)] = r
+	}
+	return m
+}
+
+// mergeInstances merges this process's sessions into the saved ones (see
+// mergeRecords)
+func mergeInstances(base map[string]*InstanceData, ours, disk []*InstanceData) []*InstanceData {
+	return mergeRecords(base, ours, disk,
+		func(d *InstanceData) string { return d.ID },
+		func(d *InstanceData) *int64 { return &d.Revision })
+}
+
+// mergeGroups merges this process's groups into the saved ones (see
+// mergeRecords)
+func mergeGroups(base map[string]*GroupData, ours, disk []*GroupData) []*GroupData {
+	return mergeRecords(base, ours, disk,
+		func(g *GroupData) string { return g.Path },
+		func(g *GroupData) *int64 { return &g.Revision })
+}
+
+// mergeRecords applies the changes this process made since base (what it
+// loaded or last saved) to the records on disk:
+//   - records added here are added, records removed here are removed
+//   - records added by other processes are kept, records they removed stay
+//     removed
+//   - for records in both, the fields changed here are taken from ours, the
+//     others from disk
+//
+// Records whose saved form changes get the next revision. Our order is kept,
+// with records added elsewhere at the end.
+func mergeRecords[T any](base map[string]*T, ours, disk []*T, key func(*T) string, revision func(*T) *int64) []*T {
+	onDisk := recordsByKey(disk, key)
+	inOurs := make(map[string]bool, len(ours))
+
+	merged := make([]*T, 0, len(ours)+len(disk))
+	for _, r := range ours {
+		k := key(r)
+		inOurs[k] = true
+		b, d := base[k], onDisk[k]
+		switch {
+		case d == nil && b != nil:
+			continue // Removed by another process
+		case d == nil:
+			*revision(r) = 1 // Added here
+			merged = append(merged, r)
+		case b == nil:
+			merged = append(merged, withRevision(r, d, revision))
+		default:
+			merged = append(merged, withRevision(mergeFields(b, r, d, nil), d, revision))
+		}
+	}
+	for _, d := range disk {
+		k := key(d)
+		if !inOurs[k] && base[k] == nil {
+			merged = append(merged, d) // Added by another process
+		}
+	}
+	return merged
+}
+
+// withRevision sets r's revision: disk's if r saves the same, else the next
+func withRevision[T any](r, disk *T, revision func(*T) *int64) *T {
+	diskRevision := *revision(disk)
+	*revision(r) = diskRevision
+	if !sameJSON(r, disk) {
+		*revision(r) = diskRevision + 1
+	}
+	return r
+}
+
+// mergeFields returns disk with the fields ours changed from base applied,
+// comparing fields by their JSON form and leaving out those in skip
+func mergeFields[T any](base, ours, disk *T, skip map[string]bool) *T {
+	b, o, d := jsonFields(base), jsonFields(ours), jsonFields(disk)
+	for k, v := range o {
+		if !skip[k] && !bytes.Equal(v, b[k]) {
+			d[k] = v
+		}
+	}
+	for k := range b {
+		if _, ok := o[k]; !ok && !skip[k] {
+			delete(d, k) // Cleared here (omitempty)
+		}
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return ours
+	}
+	var merged T
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return ours
+	}
+	return &merged
+}
+
+// jsonFields returns a record's fields in their JSON form
+func jsonFields(v any) map[string]json.RawMessage {
+	fields := make(map[string]json.RawMessage)
+	if data, err := json.Marshal(v); err == nil {
+		_ = json.Unmarshal(data, &fields)
+	}
+	delete(fields, "revision")
+	return fields
+}
+
+// sameJSON reports whether two records save the same (revisions aside)
+func sameJSON(a, b any) bool {
+	fa, fb := jsonFields(a), jsonFields(b)
+	if len(fa) != len(fb) {
+		return false
+	}
+	for k, v := range fa {
+		if !bytes.Equal(v, fb[k]) {
+			return false
+		}
+	}
+	return true
+}
+
 // validateStorageData checks data integrity before saving
 func validateStorageData(data *StorageData) error {
 	if data == nil {
@@ -395,7 +675,15 @@ func (s *Storage) LoadWithGroups() ([]*Instance, []*GroupData, error) {
 		// Instead, we'll just write directly
 	}
 
-	return s.convertToInstances(data)
+	instances, groups, err := s.convertToInstances(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Changes are measured from what was loaded, as this process would save it
+	s.baseInstances = recordsByKey(instancesToData(instances), func(d *InstanceData) string { return d.ID })
+	s.baseGroups = recordsByKey(groups, func(g *GroupData) string { return g.Path })
+	return instances, groups, nil
 }
 
 // loadFromFile reads and parses a storage file
@@ -503,22 +791,32 @@ func (s *Storage) convertToInstances(data *StorageData) ([]*Instance, []*GroupDa
 		projectPath := expandTilde(instData.ProjectPath)
 
 		inst := &Instance{
-			ID:               instData.ID,
-			Title:            instData.Title,
-			ProjectPath:      projectPath,
-			GroupPath:        groupPath,
-			ParentSessionID:  instData.ParentSessionID,
-			Command:          instData.Command,
-			Tool:             instData.Tool,
-			Status:           instData.Status,
-			CreatedAt:        instData.CreatedAt,
-			LastAccessedAt:   instData.LastAccessedAt,
-			ClaudeSessionID:  instData.ClaudeSessionID,
-			ClaudeDetectedAt: instData.ClaudeDetectedAt,
-			GeminiSessionID:  instData.GeminiSessionID,
-			GeminiDetectedAt: instData.GeminiDetectedAt,
-			LoadedMCPNames:   instData.LoadedMCPNames,
-			tmuxSession:      tmuxSess,
+			ID:                 instData.ID,
+			Title:              instData.Title,
+			ProjectPath:        projectPath,
+			GroupPath:          groupPath,
+			ParentSessionID:    instData.ParentSessionID,
+			Command:            instData.Command,
+			Tool:               instData.Tool,
+			Status:             instData.Status,
+			CreatedAt:          instData.CreatedAt,
+			LastAccessedAt:     instData.LastAccessedAt,
+			ClaudeSessionID:    instData.ClaudeSessionID,
+			ClaudeDetectedAt:   instData.ClaudeDetectedAt,
+			ClaudeOptions:      instData.ClaudeOptions,
+			Env:                instData.Env,
+			InitialPrompt:      instData.InitialPrompt,
+			GeminiSessionID:    instData.GeminiSessionID,
+			GeminiDetectedAt:   instData.GeminiDetectedAt,
+			CodexSessionID:     instData.CodexSessionID,
+			CodexDetectedAt:    instData.CodexDetectedAt,
+			OpenCodeSessionID:  instData.OpenCodeSessionID,
+			OpenCodeDetectedAt: instData.OpenCodeDetectedAt,
+			LoadedMCPNames:     instData.LoadedMCPNames,
+			WorktreePath:       instData.WorktreePath,
+			WorktreeBranch:     instData.WorktreeBranch,
+			WorktreeRepo:       instData.WorktreeRepo,
+			tmuxSession:        tmuxSess,
 		}
 
 		// Update status immediately to prevent flickering on startup
@@ -583,6 +881,8 @@ func statusToString(s Status) string {
 		return "waiting"
 	case StatusIdle:
 		return "idle"
+	case StatusApproval:
+		return "approval"
 	case StatusError:
 		return "waiting" // Treat errors as needing attention
 	default:
@@ -1,8 +1,11 @@
 package session
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -103,3 +106,142 @@ func TestGetUpdatedAtNoFile(t *testing.T) {
 		t.Errorf("Expected IsNotExist error, got: %v", err)
 	}
 }
+
+// TestStorageMergesConcurrentChanges verifies that two storages sharing a file
+// keep each other's changes to different fields, sessions and groups.
+func TestStorageMergesConcurrentChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	seed := &Storage{path: path, profile: "_test"}
+	if err := seed.SaveWithGroups([]*Instance{
+		{ID: "a", Title: "A", ProjectPath: "/tmp/a", GroupPath: "g", Command: "claude", Tool: "claude", Status: StatusIdle},
+		{ID: "b", Title: "B", ProjectPath: "/tmp/b", GroupPath: "g", Command: "claude", Tool: "claude", Status: StatusIdle},
+	}, nil); err != nil {
+		t.Fatalf("seed save failed: %v", err)
+	}
+
+	first := &Storage{path: path, profile: "_test"}
+	second := &Storage{path: path, profile: "_test"}
+	firstInstances, _, err := first.LoadWithGroups()
+	if err != nil {
+		t.Fatalf("first load failed: %v", err)
+	}
+	secondInstances, _, err := second.LoadWithGroups()
+	if err != nil {
+		t.Fatalf("second load failed: %v", err)
+	}
+
+	// First renames a and removes b; second changes a's command and adds c
+	firstInstances[0].Title = "A renamed"
+	if err := first.SaveWithGroups(firstInstances[:1], nil); err != nil {
+		t.Fatalf("first save failed: %v", err)
+	}
+	secondInstances[0].Command = "claude --verbose"
+	secondInstances = append(secondInstances, &Instance{
+		ID: "c", Title: "C", ProjectPath: "/tmp/c", GroupPath: "g", Command: "claude", Tool: "claude", Status: StatusIdle,
+	})
+	if err := second.SaveWithGroups(secondInstances, nil); err != nil {
+		t.Fatalf("second save failed: %v", err)
+	}
+
+	data, err := seed.loadFromFile(path)
+	if err != nil {
+		t.Fatalf("loadFromFile failed: %v", err)
+	}
+	byID := recordsByKey(data.Instances, func(d *InstanceData) string { return d.ID })
+	if len(byID) != 2 || byID["a"] == nil || byID["c"] == nil {
+		t.Fatalf("saved sessions = %v, want a and c", byID)
+	}
+	if byID["a"].Title != "A renamed" || byID["a"].Command != "claude --verbose" {
+		t.Errorf("a = %q / %q, want both changes kept", byID["a"].Title, byID["a"].Command)
+	}
+	if byID["a"].Revision != 3 {
+		t.Errorf("a revision = %d, want 3 (seeded, then changed twice)", byID["a"].Revision)
+	}
+	if byID["c"].Revision != 1 {
+		t.Errorf("c revision = %d, want 1", byID["c"].Revision)
+	}
+
+	// Saving unchanged data keeps revisions as they are
+	if err := second.SaveWithGroups(secondInstances, nil); err != nil {
+		t.Fatalf("repeat save failed: %v", err)
+	}
+	if data, _ = seed.loadFromFile(path); data.Instances[0].Revision != 3 {
+		t.Errorf("revision after unchanged save = %d, want 3", data.Instances[0].Revision)
+	}
+}
+
+// TestStorageMultiProcessSaves runs several processes that keep loading,
+// changing their own session and saving the same file, and checks that no
+// process loses another's updates.
+func TestStorageMultiProcessSaves(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns processes")
+	}
+	path := filepath.Join(t.TempDir(), "sessions.json")
+
+	const processes = 4
+	cmds := make([]*exec.Cmd, processes)
+	for i := range cmds {
+		cmds[i] = exec.Command(os.Args[0], "-test.run=^TestStorageHelperProcess$")
+		cmds[i].Env = append(os.Environ(), fmt.Sprintf("AGENTDECK_STORAGE_HELPER=%s:%d", path, i))
+		if err := cmds[i].Start(); err != nil {
+			t.Fatalf("failed to start helper %d: %v", i, err)
+		}
+	}
+	for i, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("helper %d failed: %v", i, err)
+		}
+	}
+
+	s := &Storage{path: path, profile: "_test"}
+	data, err := s.loadFromFile(path)
+	if err != nil {
+		t.Fatalf("loadFromFile failed: %v", err)
+	}
+	byID := recordsByKey(data.Instances, func(d *InstanceData) string { return d.ID })
+	for i := 0; i < processes; i++ {
+		id := fmt.Sprintf("p%d", i)
+		want := fmt.Sprintf("%s-%d", id, storageHelperRounds-1)
+		if byID[id] == nil {
+			t.Errorf("session %s lost", id)
+		} else if byID[id].Title != want {
+			t.Errorf("session %s title = %q, want %q", id, byID[id].Title, want)
+		}
+	}
+}
+
+const storageHelperRounds = 20
+
+// TestStorageHelperProcess is run as a separate process by
+// TestStorageMultiProcessSaves.
+func TestStorageHelperProcess(t *testing.T) {
+	spec := os.Getenv("AGENTDECK_STORAGE_HELPER")
+	if spec == "" {
+		return
+	}
+	sep := strings.LastIndex(spec, ":")
+	path, id := spec[:sep], "p"+spec[sep+1:]
+
+	s := &Storage{path: path, profile: "_test"}
+	for round := 0; round < storageHelperRounds; round++ {
+		instances, _, err := s.LoadWithGroups()
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		var own *Instance
+		for _, inst := range instances {
+			if inst.ID == id {
+				own = inst
+			}
+		}
+		if own == nil {
+			own = &Instance{ID: id, ProjectPath: "/tmp/" + id, GroupPath: "g", Command: "claude", Tool: "claude", Status: StatusIdle}
+			instances = append(instances, own)
+		}
+		own.Title = fmt.Sprintf("%s-%d", id, round)
+		if err := s.SaveWithGroups(instances, nil); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+	}
+}
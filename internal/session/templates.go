@@ -0,0 +1,214 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+)
+
+// TemplateDef is a named session configuration ([templates.NAME]) used by
+// `agent-deck add --template` and the template picker of the New dialog
+type TemplateDef struct {
+	// Description is shown by `template list` and the template picker
+	Description string `toml:"description,omitempty"`
+
+	// Tool is the agent to run ("claude", "gemini", a [tools.NAME] entry, ...)
+	Tool string `toml:"tool,omitempty"`
+
+	// Command overrides the tool's command (e.g. "claude --verbose")
+	Command string `toml:"command,omitempty"`
+
+	// Group is the group new sessions are created in
+	Group string `toml:"group,omitempty"`
+
+	// MCPs are [mcps.NAME] entries attached to the project
+	MCPs []string `toml:"mcps,omitempty"`
+
+	// Env holds environment variables set for the session's processes
+	Env map[string]string `toml:"env,omitempty"`
+
+	// Prompt is sent once the agent is ready. {{path}} and {{branch}} expand
+	// to the session's project path and git branch.
+	Prompt string `toml:"prompt,omitempty"`
+
+	// Claude holds the Claude flags of Claude sessions ([templates.NAME.claude])
+	Claude *ClaudeOptions `toml:"claude,omitempty"`
+}
+
+// GetTemplate returns a template from user config
+// Returns nil if the template is not defined
+func GetTemplate(name string) *TemplateDef {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return nil
+	}
+	if def, ok := config.Templates[name]; ok {
+		return &def
+	}
+	return nil
+}
+
+// GetTemplateNames returns the sorted template names from config.toml
+func GetTemplateNames() []string {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return nil
+	}
+	names := make([]string, 0, len(config.Templates))
+	for name := range config.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ToolName returns the tool a template runs: its tool, else the one its
+// command runs ("shell" when it has neither)
+func (t *TemplateDef) ToolName() string {
+	if t.Tool != "" {
+		return t.Tool
+	}
+	if t.Command != "" {
+		return DetectToolFromCommand(t.Command)
+	}
+	return "shell"
+}
+
+// StartCommand returns the command a template runs: its command, else its
+// tool's ([tools.NAME] command, or the built-in tool's name)
+func (t *TemplateDef) StartCommand() string {
+	if t.Command != "" {
+		return t.Command
+	}
+	if t.Tool == "" || t.Tool == "shell" {
+		return ""
+	}
+	if def := GetToolDef(t.Tool); def != nil && def.Command != "" {
+		return def.Command
+	}
+	return t.Tool
+}
+
+// Validate checks that the template's MCPs exist and its Claude flags are valid
+func (t *TemplateDef) Validate() error {
+	available := GetAvailableMCPs()
+	for _, name := range t.MCPs {
+		if _, ok := available[name]; !ok {
+			return fmt.Errorf("MCP '%s' not found in config.toml", name)
+		}
+	}
+	if t.Claude != nil && t.Claude.PermissionMode != "" && !contains(ClaudePermissionModes, t.Claude.PermissionMode) {
+		return fmt.Errorf("invalid permission mode %q (valid: %s)", t.Claude.PermissionMode, strings.Join(ClaudePermissionModes, ", "))
+	}
+	return nil
+}
+
+// ApplyTemplate configures a new session from a template: tool, command,
+// environment, Claude options and initial prompt. Call it once the project
+// path is final (after SetupWorktree) so the prompt placeholders expand to
+// it. The group and MCPs are left to the caller.
+func (i *Instance) ApplyTemplate(t *TemplateDef) {
+	i.Tool = t.ToolName()
+	i.Command = t.StartCommand()
+	i.Env = maps.Clone(t.Env)
+	if i.Tool == "claude" {
+		i.ClaudeOptions = t.Claude.Clone()
+	}
+	i.InitialPrompt = i.ExpandTemplatePrompt(t.Prompt)
+}
+
+// ExpandTemplatePrompt replaces {{path}} and {{branch}} in a template prompt
+// with the session's project path and git branch
+func (i *Instance) ExpandTemplatePrompt(prompt string) string {
+	if !strings.Contains(prompt, "{{") {
+		return prompt
+	}
+	branch := i.WorktreeBranch
+	if branch == "" {
+		branch = git.CurrentBranch(i.ProjectPath)
+	}
+	return strings.NewReplacer("{{path}}", i.ProjectPath, "{{branch}}", branch).Replace(prompt)
+}
+
+// TemplateFromInstance captures a session's configuration as a template.
+// Built-in agents are saved by tool only: their command may resume the
+// session's own conversation.
+func TemplateFromInstance(i *Instance) TemplateDef {
+	t := TemplateDef{
+		Tool:  i.Tool,
+		Group: i.GroupPath,
+		Env:   maps.Clone(i.Env),
+	}
+	if _, generic := GetToolAdapter(i.Tool).(genericAdapter); generic && i.Command != t.StartCommand() {
+		t.Command = i.Command
+	}
+	if t.Tool == "shell" {
+		t.Tool = ""
+	}
+	if i.Tool == "claude" {
+		t.Claude = i.ClaudeOptions.Clone()
+	}
+	if mcpInfo := i.GetMCPInfo(); mcpInfo != nil {
+		t.MCPs = mcpInfo.Local()
+	}
+	return t
+}
+
+// SaveTemplate adds a template to config.toml. The [templates.NAME] table is
+// appended, so the rest of the file (comments included) is left untouched.
+func SaveTemplate(name string, t TemplateDef) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("template name is required")
+	}
+	if GetTemplate(name) != nil {
+		return fmt.Errorf("template '%s' already exists in config.toml", name)
+	}
+
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(map[string]map[string]TemplateDef{"templates": {name: t}}); err != nil {
+		return fmt.Errorf("failed to encode template: %w", err)
+	}
+	// Drop the [templates] header: the table may already be defined
+	table := strings.TrimPrefix(buf.String(), "[templates]\n")
+
+	configPath, err := GetUserConfigPath()
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += "\n" + table
+
+	// Refuse to write a config that no longer parses
+	var check UserConfig
+	if _, err := toml.Decode(content, &check); err != nil {
+		return fmt.Errorf("config.toml would not parse after adding the template: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	_, err = ReloadUserConfig()
+	return err
+}
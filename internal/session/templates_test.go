@@ -0,0 +1,144 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useConfigHome points the user config at an empty home directory for the
+// duration of a test
+func useConfigHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	// Other tools keep their state under the home directory too
+	t.Setenv("CODEX_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	userConfigCacheMu.Lock()
+	userConfigCache = nil
+	userConfigCacheMu.Unlock()
+	t.Cleanup(func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = nil
+		userConfigCacheMu.Unlock()
+	})
+	return home
+}
+
+func TestTemplateCommand(t *testing.T) {
+	useConfigHome(t)
+
+	tests := []struct {
+		tmpl    TemplateDef
+		tool    string
+		wantCmd string
+	}{
+		{TemplateDef{Tool: "claude"}, "claude", "claude"},
+		{TemplateDef{Tool: "claude", Command: "claude --verbose"}, "claude", "claude --verbose"},
+		{TemplateDef{Command: "gemini"}, "gemini", "gemini"},
+		{TemplateDef{}, "shell", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.tmpl.ToolName(); got != tt.tool {
+			t.Errorf("ToolName() of %+v = %q, want %q", tt.tmpl, got, tt.tool)
+		}
+		if got := tt.tmpl.StartCommand(); got != tt.wantCmd {
+			t.Errorf("StartCommand() of %+v = %q, want %q", tt.tmpl, got, tt.wantCmd)
+		}
+	}
+}
+
+func TestApplyTemplate(t *testing.T) {
+	useConfigHome(t)
+
+	tmpl := &TemplateDef{
+		Tool:   "claude",
+		Env:    map[string]string{"GIT_PAGER": "cat"},
+		Prompt: "Review {{branch}} in {{path}}",
+		Claude: &ClaudeOptions{Model: "opus"},
+	}
+
+	inst := NewInstance("review", "/tmp/project")
+	inst.WorktreeBranch = "agent-deck/review"
+	inst.ApplyTemplate(tmpl)
+
+	if inst.Tool != "claude" || inst.Command != "claude" {
+		t.Errorf("Tool/Command = %q/%q", inst.Tool, inst.Command)
+	}
+	if inst.InitialPrompt != "Review agent-deck/review in /tmp/project" {
+		t.Errorf("InitialPrompt = %q", inst.InitialPrompt)
+	}
+	if inst.ClaudeOptions == tmpl.Claude || inst.ClaudeOptions.Model != "opus" {
+		t.Error("ClaudeOptions should be a copy of the template's")
+	}
+	inst.Env["GIT_PAGER"] = "less"
+	if tmpl.Env["GIT_PAGER"] != "cat" {
+		t.Error("Env should be a copy of the template's")
+	}
+}
+
+func TestSaveTemplate(t *testing.T) {
+	home := useConfigHome(t)
+	configPath := filepath.Join(home, ".agent-deck", UserConfigFileName)
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	existing := "# my settings\ndefault_tool = \"claude\"\n\n[templates.first]\ntool = \"gemini\""
+	if err := os.WriteFile(configPath, []byte(existing), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	inst := NewInstanceWithGroupAndTool("api", "/tmp/api", "work/api", "claude")
+	inst.Command = `claude --resume "$session_id"`
+	inst.Env = map[string]string{"API_ENV": "staging"}
+	dangerous := false
+	inst.ClaudeOptions = &ClaudeOptions{PermissionMode: "plan", DangerousMode: &dangerous}
+
+	tmpl := TemplateFromInstance(inst)
+	if tmpl.Command != "" {
+		t.Errorf("built-in tools should be saved by tool only, got command %q", tmpl.Command)
+	}
+	tmpl.Prompt = "Check {{path}}"
+	if err := SaveTemplate("api", tmpl); err != nil {
+		t.Fatalf("SaveTemplate: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	if !strings.HasPrefix(string(data), existing) {
+		t.Errorf("existing config should be kept as is:\n%s", data)
+	}
+
+	saved := GetTemplate("api")
+	if saved == nil {
+		t.Fatalf("template not found after save:\n%s", data)
+	}
+	if saved.Tool != "claude" || saved.Group != "work/api" || saved.Env["API_ENV"] != "staging" || saved.Prompt != "Check {{path}}" {
+		t.Errorf("saved template = %+v", saved)
+	}
+	if saved.Claude == nil || saved.Claude.PermissionMode != "plan" || saved.Claude.DangerousMode == nil || *saved.Claude.DangerousMode {
+		t.Errorf("saved Claude options = %+v", saved.Claude)
+	}
+	if names := GetTemplateNames(); strings.Join(names, ",") != "api,first" {
+		t.Errorf("GetTemplateNames() = %v", names)
+	}
+
+	if err := SaveTemplate("api", tmpl); err == nil {
+		t.Error("saving over an existing template should fail")
+	}
+}
+
+func TestTemplateValidate(t *testing.T) {
+	useConfigHome(t)
+
+	if err := (&TemplateDef{MCPs: []string{"missing"}}).Validate(); err == nil {
+		t.Error("unknown MCPs should be rejected")
+	}
+	if err := (&TemplateDef{Claude: &ClaudeOptions{PermissionMode: "yolo"}}).Validate(); err == nil {
+		t.Error("invalid permission modes should be rejected")
+	}
+	if err := (&TemplateDef{Tool: "claude"}).Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// ToolAdapter describes how agent-deck drives one agent CLI: how to start,
+// resume and fork it, where its conversation ID comes from, how to read its
+// last response, how its MCPs are configured and how its status is detected.
+//
+// Built-in adapters register themselves in init(); any other tool name gets
+// the generic adapter, configured by its [tools.NAME] entry. Adding support
+// for a new agent CLI is one adapter file.
+type ToolAdapter interface {
+	// Name is the tool name stored in Instance.Tool
+	Name() string
+
+	// Icon is the built-in icon ([tools.NAME] icon takes precedence)
+	Icon() string
+
+	// DisplayName is the tool's name as shown to users, e.g. "Claude Code"
+	DisplayName() string
+
+	// Color is the tool's brand colour as a hex string ("" for none)
+	Color() string
+
+	// MatchesCommand reports whether a command line or tmux session name
+	// runs this tool
+	MatchesCommand(command string) bool
+
+	// StartCommand builds the command that starts a new session
+	StartCommand(i *Instance) string
+
+	// ResumeCommand builds the command that resumes the session's
+	// conversation, or "" when there is none to resume
+	ResumeCommand(i *Instance) string
+
+	// RestartInPlace reports whether a running session is restarted by
+	// respawning its pane with the resume command. Otherwise Restart starts a
+	// new tmux session.
+	RestartInPlace() bool
+
+	// CanFork reports whether the session's conversation can be forked now
+	CanFork(i *Instance) bool
+
+	// ForkCommand builds the command that starts fork, a new session forked
+	// from i's conversation (running in fork.ProjectPath)
+	ForkCommand(i, fork *Instance) (string, error)
+
+	// SessionID returns the tool's conversation ID ("" if unknown)
+	SessionID(i *Instance) string
+
+	// SetSessionID sets the tool's conversation ID, e.g. to resume one found
+	// by the global search
+	SetSessionID(i *Instance, id string) error
+
+	// DetectSessionID refreshes the conversation ID, e.g. from the tmux
+	// environment set by the start command
+	DetectSessionID(i *Instance)
+
+	// LastResponse returns the last assistant response of the session
+	LastResponse(i *Instance) (*ResponseOutput, error)
+
+	// Transcript reads the session's whole conversation
+	Transcript(i *Instance) (*Transcript, error)
+
+	// SupportsMCP reports whether agent-deck manages the tool's MCPs
+	SupportsMCP() bool
+
+	// ProjectMCPs reports whether MCPs can also be enabled for one project
+	// (.mcp.json), rather than only in the tool's global settings
+	ProjectMCPs() bool
+
+	// MCPInfo returns the MCPs configured for a project (nil if unsupported)
+	MCPInfo(projectPath string) *MCPInfo
+
+	// WriteMCPConfig enables exactly the named MCPs for a project
+	WriteMCPConfig(projectPath string, names []string) error
+
+	// StatusDetector returns the built-in status detector, or nil to let the
+	// tmux session pick one from the tool it detects
+	StatusDetector() tmux.StatusDetector
+
+	// ApprovalKeys returns the keys that answer a permission prompt
+	ApprovalKeys(approve bool) []string
+}
+
+var (
+	toolAdapters   []ToolAdapter
+	toolAdaptersMu sync.RWMutex
+)
+
+func init() {
+	// tmux identifies the tool a pane runs through the same registry
+	tmux.SetCommandToolDetector(DetectToolFromCommand)
+}
+
+// RegisterToolAdapter adds a built-in adapter. Adapters are matched against
+// commands in registration order.
+func RegisterToolAdapter(adapter ToolAdapter) {
+	toolAdaptersMu.Lock()
+	defer toolAdaptersMu.Unlock()
+	for idx, existing := range toolAdapters {
+		if existing.Name() == adapter.Name() {
+			toolAdapters[idx] = adapter
+			return
+		}
+	}
+	toolAdapters = append(toolAdapters, adapter)
+}
+
+// GetToolAdapter returns the adapter for a tool name. Names without a
+// built-in adapter (shell, [tools.NAME] entries) get the generic adapter.
+func GetToolAdapter(toolName string) ToolAdapter {
+	toolAdaptersMu.RLock()
+	defer toolAdaptersMu.RUnlock()
+	for _, adapter := range toolAdapters {
+		if adapter.Name() == toolName {
+			return adapter
+		}
+	}
+	return newGenericAdapter(toolName)
+}
+
+// DetectToolFromCommand returns the tool a command line (or tmux session
+// name) runs: a built-in adapter matching it, else a [tools.NAME] entry named
+// like its executable, else "shell"
+func DetectToolFromCommand(command string) string {
+	toolAdaptersMu.RLock()
+	for _, adapter := range toolAdapters {
+		if adapter.MatchesCommand(command) {
+			toolAdaptersMu.RUnlock()
+			return adapter.Name()
+		}
+	}
+	toolAdaptersMu.RUnlock()
+
+	if fields := strings.Fields(command); len(fields) > 0 {
+		if name := filepath.Base(fields[0]); GetToolDef(name) != nil {
+			return name
+		}
+	}
+	return "shell"
+}
+
+// commandContains reports whether a lowercased command contains any of the
+// given names
+func commandContains(command string, names ...string) bool {
+	command = strings.ToLower(command)
+	for _, name := range names {
+		if strings.Contains(command, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// adapter returns the adapter for the session's tool. A shell or custom tool
+// session that captured a Claude conversation is driven as Claude.
+func (i *Instance) adapter() ToolAdapter {
+	adapter := GetToolAdapter(i.Tool)
+	if _, generic := adapter.(genericAdapter); generic && i.ClaudeSessionID != "" {
+		return GetToolAdapter("claude")
+	}
+	return adapter
+}
+
+// ResumeConversation makes the session resume an existing conversation of
+// its tool when started, e.g. one found by the global search. Only the
+// conversation ID is stored: the start command resumes from it, and Command
+// stays the tool's base command.
+func (i *Instance) ResumeConversation(sessionID string) error {
+	adapter := i.adapter()
+	if err := adapter.SetSessionID(i, sessionID); err != nil {
+		return err
+	}
+	if i.Command == "" {
+		i.Command = adapter.Name()
+	}
+	return nil
+}
+
+// sessionScanInterval throttles discovering conversation IDs from tool state
+// directories (Codex, OpenCode) during status polling
+const sessionScanInterval = 10 * time.Second
+
+// sessionScanDue reports whether a conversation ID scan may run now
+func (i *Instance) sessionScanDue() bool {
+	if time.Since(i.lastSessionScan) < sessionScanInterval {
+		return false
+	}
+	i.lastSessionScan = time.Now()
+	return true
+}
+
+// sessionStartTime returns when the tool was last started, for matching the
+// conversation it created
+func (i *Instance) sessionStartTime() time.Time {
+	if !i.lastStartTime.IsZero() {
+		return i.lastStartTime
+	}
+	return i.CreatedAt
+}
+
+// baseAdapter provides the behaviour of tools agent-deck knows nothing about:
+// run the command as-is, read responses from the terminal, no fork or MCPs.
+// Built-in adapters embed it and override what their CLI supports.
+type baseAdapter struct {
+	name    string
+	icon    string
+	color   string // Brand colour, e.g. "#ff9e64"
+	matches []string // Lowercase substrings identifying the tool in a command
+}
+
+func (a baseAdapter) Name() string { return a.name }
+
+func (a baseAdapter) Icon() string {
+	if a.icon == "" {
+		return "🐚"
+	}
+	return a.icon
+}
+
+func (a baseAdapter) Color() string { return a.color }
+
+func (a baseAdapter) DisplayName() string {
+	if a.name == "" {
+		return "Shell"
+	}
+	return strings.ToUpper(a.name[:1]) + a.name[1:]
+}
+
+func (a baseAdapter) MatchesCommand(command string) bool {
+	return commandContains(command, a.matches...)
+}
+
+func (a baseAdapter) StartCommand(i *Instance) string { return i.Command }
+
+func (a baseAdapter) ResumeCommand(i *Instance) string { return "" }
+
+func (a baseAdapter) RestartInPlace() bool { return false }
+
+func (a baseAdapter) CanFork(i *Instance) bool { return false }
+
+func (a baseAdapter) ForkCommand(i, fork *Instance) (string, error) {
+	return "", fmt.Errorf("%s does not support forking", a.name)
+}
+
+func (a baseAdapter) SessionID(i *Instance) string { return "" }
+
+func (a baseAdapter) DetectSessionID(i *Instance) {}
+
+func (a baseAdapter) LastResponse(i *Instance) (*ResponseOutput, error) {
+	return i.getTerminalLastResponse(func(content string) (*ResponseOutput, error) {
+		return parseGenericOutput(content, i.Tool)
+	})
+}
+
+func (a baseAdapter) Transcript(i *Instance) (*Transcript, error) {
+	return nil, fmt.Errorf("%s transcripts are not supported", a.name)
+}
+
+func (a baseAdapter) SetSessionID(i *Instance, id string) error {
+	return fmt.Errorf("cannot resume %s conversations", a.name)
+}
+
+func (a baseAdapter) SupportsMCP() bool { return false }
+
+func (a baseAdapter) ProjectMCPs() bool { return false }
+
+func (a baseAdapter) MCPInfo(projectPath string) *MCPInfo { return nil }
+
+func (a baseAdapter) WriteMCPConfig(projectPath string, names []string) error {
+	return fmt.Errorf("%s does not support MCPs", a.name)
+}
+
+func (a baseAdapter) StatusDetector() tmux.StatusDetector { return nil }
+
+func (a baseAdapter) ApprovalKeys(approve bool) []string {
+	if approve {
+		return []string{"y", "Enter"}
+	}
+	return []string{"n", "Enter"}
+}
@@ -0,0 +1,143 @@
+package session
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestGetToolAdapter(t *testing.T) {
+	for _, name := range []string{"claude", "gemini", "codex", "opencode", "cursor", "aider"} {
+		if got := GetToolAdapter(name).Name(); got != name {
+			t.Errorf("GetToolAdapter(%q).Name() = %q", name, got)
+		}
+	}
+
+	// Unknown tools get the generic adapter under their own name
+	adapter := GetToolAdapter("my-agent")
+	if _, ok := adapter.(genericAdapter); !ok || adapter.Name() != "my-agent" {
+		t.Errorf("GetToolAdapter(my-agent) = %T %q, want generic", adapter, adapter.Name())
+	}
+	if adapter.SupportsMCP() || adapter.CanFork(NewInstance("x", "/tmp")) {
+		t.Error("generic adapter should not support MCPs or forking")
+	}
+	if _, err := adapter.ForkCommand(NewInstance("x", "/tmp"), NewInstance("y", "/tmp")); err == nil {
+		t.Error("generic ForkCommand should fail")
+	}
+
+	if !GetToolAdapter("claude").SupportsMCP() || !GetToolAdapter("gemini").SupportsMCP() {
+		t.Error("claude and gemini MCPs are managed by agent-deck")
+	}
+
+	// Only Claude has per-project MCPs and restarts by respawning its pane
+	for _, name := range []string{"claude", "gemini", "codex", "opencode"} {
+		adapter := GetToolAdapter(name)
+		isClaude := name == "claude"
+		if adapter.ProjectMCPs() != isClaude || adapter.RestartInPlace() != isClaude {
+			t.Errorf("%s: ProjectMCPs() = %v, RestartInPlace() = %v, want %v", name,
+				adapter.ProjectMCPs(), adapter.RestartInPlace(), isClaude)
+		}
+	}
+	if GetToolAdapter("claude").Color() == "" || GetToolAdapter("shell").Color() != "" {
+		t.Error("claude has a brand colour, shells don't")
+	}
+	for name, want := range map[string]string{"claude": "Claude Code", "gemini": "Gemini", "opencode": "OpenCode", "shell": "Shell"} {
+		if got := GetToolAdapter(name).DisplayName(); got != want {
+			t.Errorf("GetToolAdapter(%q).DisplayName() = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestDetectToolFromCommand(t *testing.T) {
+	var config UserConfig
+	if _, err := toml.Decode("[tools.my-agent]\ncommand = \"my-agent --fast\"\n", &config); err != nil {
+		t.Fatal(err)
+	}
+	userConfigCacheMu.Lock()
+	userConfigCache = &config
+	userConfigCacheMu.Unlock()
+	defer func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = nil
+		userConfigCacheMu.Unlock()
+	}()
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"claude", "claude"},
+		{"claude --model opus", "claude"},
+		{"npx @google/gemini-cli", "gemini"},
+		{"open-code", "opencode"},
+		{"codex --full-auto", "codex"},
+		{"cursor-agent", "cursor"},
+		{"aider --model sonnet", "aider"},
+		{"/usr/local/bin/my-agent --fast", "my-agent"},
+		{"vim", "shell"},
+		{"", "shell"},
+	}
+	for _, tt := range tests {
+		if got := DetectToolFromCommand(tt.command); got != tt.want {
+			t.Errorf("DetectToolFromCommand(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestInstanceAdapter(t *testing.T) {
+	gemini := NewInstanceWithTool("g", "/tmp", "gemini")
+	gemini.Command = "gemini"
+	gemini.GeminiSessionID = "abcd1234-5678"
+	if cmd := gemini.adapter().StartCommand(gemini); cmd != "gemini --resume abcd1234-5678" {
+		t.Errorf("gemini StartCommand = %q", cmd)
+	}
+	if cmd := gemini.adapter().ResumeCommand(gemini); !strings.Contains(cmd, "GEMINI_SESSION_ID abcd1234-5678") {
+		t.Errorf("gemini ResumeCommand should publish the session ID, got %q", cmd)
+	}
+	if !gemini.CanRestart() || gemini.CanFork() {
+		t.Error("gemini sessions with an ID restart but don't fork")
+	}
+
+	// A shell that captured a Claude conversation is driven as Claude
+	shell := NewInstance("s", "/tmp")
+	if shell.adapter().Name() != "shell" {
+		t.Errorf("adapter = %q, want shell", shell.adapter().Name())
+	}
+	shell.ClaudeSessionID = "abc-123"
+	shell.ClaudeDetectedAt = time.Now()
+	if shell.adapter().Name() != "claude" || !shell.CanFork() {
+		t.Error("shell with a Claude session ID should fork as Claude")
+	}
+}
+
+func TestResumeConversation(t *testing.T) {
+	tests := map[string]string{
+		"gemini":   "gemini --resume abc",
+		"codex":    "codex resume abc",
+		"opencode": "opencode --session abc",
+		"claude":   "claude --resume abc",
+	}
+	for tool, want := range tests {
+		inst := NewInstanceWithTool("x", "/tmp", tool)
+		if err := inst.ResumeConversation("abc"); err != nil {
+			t.Errorf("%s: ResumeConversation: %v", tool, err)
+			continue
+		}
+		if inst.adapter().SessionID(inst) != "abc" {
+			t.Errorf("%s: session ID = %q, want abc", tool, inst.adapter().SessionID(inst))
+		}
+		// Command stays the tool's; starting builds the resume command
+		if inst.Command != tool {
+			t.Errorf("%s: Command = %q, want the base command", tool, inst.Command)
+		}
+		if cmd := inst.adapter().StartCommand(inst); !strings.Contains(cmd, want) {
+			t.Errorf("%s: StartCommand = %q, want %q", tool, cmd, want)
+		}
+	}
+
+	if err := NewInstanceWithTool("x", "/tmp", "shell").ResumeConversation("abc"); err == nil {
+		t.Error("resuming a shell conversation should fail")
+	}
+}
@@ -0,0 +1,82 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+func init() {
+	RegisterToolAdapter(claudeAdapter{baseAdapter{name: "claude", icon: "🤖", color: "#ff9e64", matches: []string{"claude"}}})
+}
+
+// claudeAdapter drives Claude Code. The conversation ID is captured by the
+// start command and published in the tmux environment (CLAUDE_SESSION_ID).
+type claudeAdapter struct {
+	baseAdapter
+}
+
+func (a claudeAdapter) DisplayName() string { return "Claude Code" }
+
+func (a claudeAdapter) StartCommand(i *Instance) string {
+	return i.buildClaudeCommand(i.Command)
+}
+
+func (a claudeAdapter) ResumeCommand(i *Instance) string {
+	if i.ClaudeSessionID == "" {
+		return ""
+	}
+	return i.buildClaudeResumeCommand()
+}
+
+func (a claudeAdapter) RestartInPlace() bool { return true }
+
+// CanFork requires a recently detected session ID, so the fork starts from
+// the conversation the session is actually in
+func (a claudeAdapter) CanFork(i *Instance) bool {
+	return i.ClaudeSessionID != "" && time.Since(i.ClaudeDetectedAt) < 5*time.Minute
+}
+
+func (a claudeAdapter) ForkCommand(i, fork *Instance) (string, error) {
+	if !a.CanFork(i) {
+		return "", fmt.Errorf("cannot fork: no active Claude session")
+	}
+	return i.forkCommand(fork), nil
+}
+
+func (a claudeAdapter) SessionID(i *Instance) string { return i.ClaudeSessionID }
+
+func (a claudeAdapter) SetSessionID(i *Instance, id string) error {
+	i.ClaudeSessionID, i.ClaudeDetectedAt = id, time.Now()
+	return nil
+}
+
+func (a claudeAdapter) DetectSessionID(i *Instance) { i.UpdateClaudeSession(nil) }
+
+func (a claudeAdapter) LastResponse(i *Instance) (*ResponseOutput, error) {
+	return i.getClaudeLastResponse()
+}
+
+func (a claudeAdapter) Transcript(i *Instance) (*Transcript, error) { return i.getClaudeTranscript() }
+
+func (a claudeAdapter) SupportsMCP() bool { return true }
+
+func (a claudeAdapter) ProjectMCPs() bool { return true }
+
+func (a claudeAdapter) MCPInfo(projectPath string) *MCPInfo { return GetMCPInfo(projectPath) }
+
+func (a claudeAdapter) WriteMCPConfig(projectPath string, names []string) error {
+	return WriteMCPJsonFromConfig(projectPath, names)
+}
+
+func (a claudeAdapter) StatusDetector() tmux.StatusDetector { return tmux.BuiltinDetector(a.name) }
+
+// ApprovalKeys: "1. Yes" is the first option of every dialog; Esc rejects and
+// asks Claude to wait for instructions
+func (a claudeAdapter) ApprovalKeys(approve bool) []string {
+	if approve {
+		return []string{"1"}
+	}
+	return []string{"Escape"}
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+func init() {
+	RegisterToolAdapter(codexAdapter{baseAdapter{name: "codex", icon: "💻", color: "#7dcfff", matches: []string{"codex"}}})
+}
+
+// codexAdapter drives OpenAI's Codex CLI. The conversation ID is discovered
+// from the rollout files in ~/.codex/sessions and resumed with `codex resume`.
+type codexAdapter struct {
+	baseAdapter
+}
+
+// StartCommand resumes the known conversation, so a stopped session comes
+// back where it left off
+func (a codexAdapter) StartCommand(i *Instance) string {
+	if resume := a.ResumeCommand(i); resume != "" {
+		return resume
+	}
+	return i.Command
+}
+
+func (a codexAdapter) ResumeCommand(i *Instance) string {
+	if i.CodexSessionID == "" {
+		return ""
+	}
+	return fmt.Sprintf("codex resume %s", i.CodexSessionID)
+}
+
+func (a codexAdapter) SessionID(i *Instance) string { return i.CodexSessionID }
+
+func (a codexAdapter) SetSessionID(i *Instance, id string) error {
+	i.CodexSessionID, i.CodexDetectedAt = id, time.Now()
+	return nil
+}
+
+func (a codexAdapter) DetectSessionID(i *Instance) { i.UpdateCodexSession(i.claimedElsewhere) }
+
+// LastResponse reads the rollout file, or the terminal while the session ID
+// is still unknown
+func (a codexAdapter) LastResponse(i *Instance) (*ResponseOutput, error) {
+	if i.CodexSessionID == "" {
+		return i.getTerminalLastResponse(parseCodexOutput)
+	}
+	return i.getCodexLastResponse()
+}
+
+func (a codexAdapter) StatusDetector() tmux.StatusDetector { return tmux.BuiltinDetector(a.name) }
+
+func (a codexAdapter) ApprovalKeys(approve bool) []string {
+	if approve {
+		return []string{"y"}
+	}
+	return []string{"n"}
+}
@@ -0,0 +1,71 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+func init() {
+	RegisterToolAdapter(geminiAdapter{baseAdapter{name: "gemini", icon: "✨", color: "#bb9af7", matches: []string{"gemini"}}})
+}
+
+// geminiAdapter drives Gemini CLI. The conversation ID is captured by the
+// start command and published in the tmux environment (GEMINI_SESSION_ID).
+// MCPs live in Gemini's global settings.json. Gemini cannot fork.
+type geminiAdapter struct {
+	baseAdapter
+}
+
+func (a geminiAdapter) StartCommand(i *Instance) string {
+	return i.buildGeminiCommand(i.Command)
+}
+
+// ResumeCommand also sets GEMINI_SESSION_ID so detection works after a restart
+func (a geminiAdapter) ResumeCommand(i *Instance) string {
+	if i.GeminiSessionID == "" {
+		return ""
+	}
+	return fmt.Sprintf("tmux set-environment GEMINI_SESSION_ID %s && gemini --resume %s",
+		i.GeminiSessionID, i.GeminiSessionID)
+}
+
+func (a geminiAdapter) SessionID(i *Instance) string { return i.GeminiSessionID }
+
+func (a geminiAdapter) SetSessionID(i *Instance, id string) error {
+	i.GeminiSessionID, i.GeminiDetectedAt = id, time.Now()
+	return nil
+}
+
+func (a geminiAdapter) DetectSessionID(i *Instance) { i.UpdateGeminiSession(nil) }
+
+// LastResponse reads the session file, or the terminal while the session ID
+// is still unknown
+func (a geminiAdapter) LastResponse(i *Instance) (*ResponseOutput, error) {
+	if i.GeminiSessionID == "" {
+		return i.getTerminalLastResponse(parseGeminiOutput)
+	}
+	return i.getGeminiLastResponse()
+}
+
+func (a geminiAdapter) Transcript(i *Instance) (*Transcript, error) { return i.getGeminiTranscript() }
+
+func (a geminiAdapter) SupportsMCP() bool { return true }
+
+func (a geminiAdapter) MCPInfo(projectPath string) *MCPInfo { return GetGeminiMCPInfo(projectPath) }
+
+// WriteMCPConfig writes Gemini's global settings; Gemini has no per-project MCPs
+func (a geminiAdapter) WriteMCPConfig(projectPath string, names []string) error {
+	return WriteGeminiMCPSettings(names)
+}
+
+func (a geminiAdapter) StatusDetector() tmux.StatusDetector { return tmux.BuiltinDetector(a.name) }
+
+// ApprovalKeys: "Yes, allow once" is preselected
+func (a geminiAdapter) ApprovalKeys(approve bool) []string {
+	if approve {
+		return []string{"Enter"}
+	}
+	return []string{"Escape"}
+}
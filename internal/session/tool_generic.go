@@ -0,0 +1,19 @@
+package session
+
+func init() {
+	// Recognized by name only, no deeper integration
+	RegisterToolAdapter(genericAdapter{baseAdapter{name: "cursor", icon: "📝", color: "#7aa2f7", matches: []string{"cursor"}}})
+	RegisterToolAdapter(genericAdapter{baseAdapter{name: "aider", color: "#f7768e", matches: []string{"aider"}}})
+}
+
+// genericAdapter drives shells and tools defined only by a [tools.NAME]
+// entry: the command runs as-is, responses are read from the terminal and
+// status comes from the entry's patterns or the built-in heuristics.
+type genericAdapter struct {
+	baseAdapter
+}
+
+// newGenericAdapter returns the adapter for a tool without a built-in one
+func newGenericAdapter(name string) ToolAdapter {
+	return genericAdapter{baseAdapter{name: name}}
+}
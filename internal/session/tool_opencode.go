@@ -0,0 +1,63 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+func init() {
+	RegisterToolAdapter(opencodeAdapter{baseAdapter{name: "opencode", icon: "🌐", matches: []string{"opencode", "open-code", "open code"}}})
+}
+
+// opencodeAdapter drives OpenCode. The session ID is discovered from
+// OpenCode's storage directory and resumed with --session.
+type opencodeAdapter struct {
+	baseAdapter
+}
+
+func (a opencodeAdapter) DisplayName() string { return "OpenCode" }
+
+// StartCommand resumes the known session, so a stopped session comes back
+// where it left off
+func (a opencodeAdapter) StartCommand(i *Instance) string {
+	if resume := a.ResumeCommand(i); resume != "" {
+		return resume
+	}
+	return i.Command
+}
+
+// ResumeCommand keeps the user's flags (model, agent, ...) when the command
+// runs OpenCode directly
+func (a opencodeAdapter) ResumeCommand(i *Instance) string {
+	if i.OpenCodeSessionID == "" {
+		return ""
+	}
+	base := "opencode"
+	if a.MatchesCommand(i.Command) && !strings.ContainsAny(i.Command, ";&|") {
+		base = i.Command
+	}
+	return fmt.Sprintf("%s --session %s", base, i.OpenCodeSessionID)
+}
+
+func (a opencodeAdapter) SessionID(i *Instance) string { return i.OpenCodeSessionID }
+
+func (a opencodeAdapter) SetSessionID(i *Instance, id string) error {
+	i.OpenCodeSessionID, i.OpenCodeDetectedAt = id, time.Now()
+	return nil
+}
+
+func (a opencodeAdapter) DetectSessionID(i *Instance) { i.UpdateOpenCodeSession(i.claimedElsewhere) }
+
+// LastResponse reads OpenCode's storage, or the terminal while the session
+// ID is still unknown
+func (a opencodeAdapter) LastResponse(i *Instance) (*ResponseOutput, error) {
+	if i.OpenCodeSessionID == "" {
+		return a.baseAdapter.LastResponse(i)
+	}
+	return i.getOpenCodeLastResponse()
+}
+
+func (a opencodeAdapter) StatusDetector() tmux.StatusDetector { return tmux.BuiltinDetector(a.name) }
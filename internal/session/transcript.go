@@ -0,0 +1,379 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+)
+
+// Transcript is a whole conversation of a session, for reading it in the
+// TUI and sharing it (see Render)
+type Transcript struct {
+	Tool      string              `json:"tool"`
+	SessionID string              `json:"session_id"` // The tool's conversation ID
+	Title     string              `json:"title,omitempty"`
+	CWD       string              `json:"cwd,omitempty"`
+	Path      string              `json:"path"` // Transcript file
+	Messages  []TranscriptMessage `json:"messages"`
+}
+
+// TranscriptMessage is a message of a conversation. Assistant messages carry
+// the tool calls they made, with their results.
+type TranscriptMessage struct {
+	Role      string               `json:"role"` // "user" or "assistant"
+	Timestamp string               `json:"timestamp,omitempty"`
+	Text      string               `json:"text,omitempty"`
+	ToolCalls []TranscriptToolCall `json:"tool_calls,omitempty"`
+}
+
+// TranscriptToolCall is a tool the assistant called
+type TranscriptToolCall struct {
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name"`
+	Input  string `json:"input,omitempty"` // Arguments as JSON
+	Output string `json:"output,omitempty"`
+	Error  bool   `json:"error,omitempty"`
+}
+
+// Transcript formats
+const (
+	TranscriptMarkdown = "md"
+	TranscriptHTML     = "html"
+	TranscriptJSON     = "json"
+)
+
+// GetTranscript reads the session's whole conversation
+func (i *Instance) GetTranscript() (*Transcript, error) {
+	t, err := i.adapter().Transcript(i)
+	if err != nil {
+		return nil, err
+	}
+	t.Title = i.Title
+	if t.CWD == "" {
+		t.CWD = i.ProjectPath
+	}
+	return t, nil
+}
+
+// getClaudeTranscript reads the session's Claude conversation
+func (i *Instance) getClaudeTranscript() (*Transcript, error) {
+	sessionFile, err := i.claudeSessionFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(sessionFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	t := parseClaudeTranscript(data)
+	t.Path = sessionFile
+	if t.SessionID == "" {
+		t.SessionID = i.ClaudeSessionID
+	}
+	return t, nil
+}
+
+// parseClaudeTranscript parses a Claude JSONL file. Claude writes a record
+// per content block, so consecutive records of one API message are joined;
+// tool results (sent back as user records) are attached to their calls.
+func parseClaudeTranscript(data []byte) *Transcript {
+	t := &Transcript{Tool: "claude"}
+	type callRef struct{ msg, call int }
+	calls := make(map[string]callRef) // Tool use ID -> call
+	lastMessageID := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	// Handle large lines
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 10*1024*1024)
+
+	for scanner.Scan() {
+		record, msg, ok := decodeClaudeLine(scanner.Bytes())
+		if !ok {
+			continue // Skip malformed lines
+		}
+		if t.SessionID == "" {
+			t.SessionID = record.SessionID
+		}
+		if t.CWD == "" {
+			t.CWD = record.CWD
+		}
+		if record.IsMeta || msg == nil || (msg.Role != "user" && msg.Role != "assistant") {
+			continue
+		}
+
+		m := TranscriptMessage{Role: msg.Role, Timestamp: record.Timestamp}
+		var blocks []claudeContentBlock
+		m.Text, blocks = msg.content()
+		for _, block := range blocks {
+			switch block.Type {
+			case "tool_use":
+				m.ToolCalls = append(m.ToolCalls, TranscriptToolCall{
+					ID:    block.ID,
+					Name:  block.Name,
+					Input: string(block.Input),
+				})
+			case "tool_result":
+				if ref, ok := calls[block.ToolUseID]; ok {
+					call := &t.Messages[ref.msg].ToolCalls[ref.call]
+					call.Output = claudeToolResultText(block.Content)
+					call.Error = block.IsError
+				}
+			}
+		}
+		if m.Text == "" && len(m.ToolCalls) == 0 {
+			continue // Only thinking or tool results
+		}
+
+		// Join the records of one assistant message
+		n := len(t.Messages)
+		if msg.Role == "assistant" && msg.ID != "" && msg.ID == lastMessageID && n > 0 {
+			prev := &t.Messages[n-1]
+			if m.Text != "" {
+				prev.Text = strings.TrimLeft(prev.Text+"\n\n"+m.Text, "\n")
+			}
+			prev.ToolCalls = append(prev.ToolCalls, m.ToolCalls...)
+		} else {
+			t.Messages = append(t.Messages, m)
+		}
+		lastMessageID = msg.ID
+
+		last := len(t.Messages) - 1
+		for j, call := range t.Messages[last].ToolCalls {
+			calls[call.ID] = callRef{last, j}
+		}
+	}
+	return t
+}
+
+// claudeToolResultText returns the text of a tool result: a string or text
+// blocks
+func claudeToolResultText(content json.RawMessage) string {
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		return text
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(content, &blocks) != nil {
+		return ""
+	}
+	var texts []string
+	for _, block := range blocks {
+		if block.Type == "text" {
+			texts = append(texts, block.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// getGeminiTranscript reads the session's Gemini conversation
+func (i *Instance) getGeminiTranscript() (*Transcript, error) {
+	sessionFile, err := i.geminiSessionFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(sessionFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	t, err := parseGeminiTranscript(data)
+	if err != nil {
+		return nil, err
+	}
+	t.Path = sessionFile
+	return t, nil
+}
+
+// parseGeminiTranscript parses a Gemini JSON session file
+// VERIFIED: Message type is "gemini" (NOT role: "assistant")
+func parseGeminiTranscript(data []byte) (*Transcript, error) {
+	session, err := decodeGeminiSessionFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Transcript{Tool: "gemini", SessionID: session.SessionID}
+	for _, msg := range session.Messages {
+		m := TranscriptMessage{Timestamp: msg.Timestamp, Text: geminiContentText(msg.Content)}
+		switch msg.Type {
+		case "user":
+			m.Role = "user"
+		case "gemini":
+			m.Role = "assistant"
+		default:
+			continue
+		}
+		for _, call := range msg.ToolCalls {
+			var output string
+			_ = json.Unmarshal(call.ResultDisplay, &output) // Diffs are objects
+			m.ToolCalls = append(m.ToolCalls, TranscriptToolCall{
+				ID:     call.ID,
+				Name:   call.Name,
+				Input:  string(call.Args),
+				Output: output,
+				Error:  call.Status == "error",
+			})
+		}
+		if m.Text == "" && len(m.ToolCalls) == 0 {
+			continue
+		}
+		t.Messages = append(t.Messages, m)
+	}
+	return t, nil
+}
+
+// Render formats the transcript as Markdown, a standalone HTML page or JSON
+func (t *Transcript) Render(format string) (string, error) {
+	switch format {
+	case TranscriptMarkdown, "markdown":
+		return t.markdown(), nil
+	case TranscriptHTML:
+		var sb strings.Builder
+		if err := transcriptHTMLTemplate.Execute(&sb, t); err != nil {
+			return "", err
+		}
+		return sb.String(), nil
+	case TranscriptJSON:
+		data, err := json.MarshalIndent(t, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	default:
+		return "", fmt.Errorf("unknown transcript format %q (md, html, json)", format)
+	}
+}
+
+// Heading returns the transcript's title line
+func (t *Transcript) Heading() string {
+	if t.Title != "" {
+		return t.Title
+	}
+	return fmt.Sprintf("%s conversation %s", t.Tool, t.SessionID)
+}
+
+// markdown renders the transcript for code reviews: tool calls are
+// collapsed <details> blocks, as GitHub renders them
+func (t *Transcript) markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", t.Heading())
+	fmt.Fprintf(&sb, "*%s · %s", t.Tool, t.SessionID)
+	if t.CWD != "" {
+		fmt.Fprintf(&sb, " · `%s`", t.CWD)
+	}
+	sb.WriteString("*\n")
+
+	for _, m := range t.Messages {
+		fmt.Fprintf(&sb, "\n## %s", TranscriptRoleLabel(m.Role))
+		if ts := FormatTranscriptTime(m.Timestamp); ts != "" {
+			fmt.Fprintf(&sb, " · %s", ts)
+		}
+		sb.WriteString("\n\n")
+		if m.Text != "" {
+			sb.WriteString(strings.TrimSpace(m.Text) + "\n")
+		}
+		for _, call := range m.ToolCalls {
+			summary := call.Name
+			if call.Error {
+				summary += " (failed)"
+			}
+			fmt.Fprintf(&sb, "\n<details>\n<summary>🔧 %s</summary>\n\n", template.HTMLEscapeString(summary))
+			if input := indentJSON(call.Input); input != "" {
+				sb.WriteString(fenced("json", input))
+			}
+			if call.Output != "" {
+				sb.WriteString("\n" + fenced("", call.Output))
+			}
+			sb.WriteString("\n</details>\n")
+		}
+	}
+	return sb.String()
+}
+
+// TranscriptRoleLabel returns how a role is shown in transcripts
+func TranscriptRoleLabel(role string) string {
+	if role == "user" {
+		return "👤 User"
+	}
+	return "🤖 Assistant"
+}
+
+// FormatTranscriptTime formats a message timestamp for display, in local
+// time ("" if none)
+func FormatTranscriptTime(timestamp string) string {
+	ts, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return ""
+	}
+	return ts.Local().Format("2006-01-02 15:04")
+}
+
+// indentJSON pretty-prints tool arguments ("" for none)
+func indentJSON(raw string) string {
+	if raw == "" || raw == "null" || raw == "{}" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if json.Indent(&buf, []byte(raw), "", "  ") != nil {
+		return raw
+	}
+	return buf.String()
+}
+
+// fenced wraps text in a code fence longer than any backtick run in it
+func fenced(lang, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + fence + "\n"
+}
+
+var transcriptHTMLTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"role": TranscriptRoleLabel,
+	"time": FormatTranscriptTime,
+	"json": indentJSON,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Heading}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 56rem; margin: 2rem auto; padding: 0 1rem; color: #24292f; }
+header p { color: #57606a; }
+.message { border-left: 4px solid #d0d7de; margin: 1.5rem 0; padding: 0.25rem 1rem; }
+.user { border-color: #2da44e; }
+.assistant { border-color: #0969da; }
+.role { font-weight: 600; }
+.time { color: #57606a; font-size: 0.85em; margin-left: 0.5rem; }
+.text { white-space: pre-wrap; }
+details { background: #f6f8fa; border-radius: 6px; margin: 0.5rem 0; padding: 0.5rem 0.75rem; }
+details.error summary { color: #cf222e; }
+pre { overflow-x: auto; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Heading}}</h1>
+<p>{{.Tool}} · {{.SessionID}}{{if .CWD}} · <code>{{.CWD}}</code>{{end}}</p>
+</header>
+{{range .Messages}}<section class="message {{.Role}}">
+<p><span class="role">{{role .Role}}</span>{{with time .Timestamp}}<span class="time">{{.}}</span>{{end}}</p>
+{{if .Text}}<div class="text">{{.Text}}</div>
+{{end}}{{range .ToolCalls}}<details{{if .Error}} class="error"{{end}}>
+<summary>🔧 {{.Name}}{{if .Error}} (failed){{end}}</summary>
+{{with json .Input}}<pre><code>{{.}}</code></pre>
+{{end}}{{if .Output}}<pre><code>{{.Output}}</code></pre>
+{{end}}</details>
+{{end}}</section>
+{{end}}</body>
+</html>
+`))
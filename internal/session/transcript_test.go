@@ -0,0 +1,90 @@
+package session
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const claudeTranscriptFixture = `{"sessionId":"abc-123","cwd":"/src/api","type":"user","timestamp":"2025-03-01T10:00:00Z","message":{"role":"user","content":"List the <files>"}}
+{"sessionId":"abc-123","type":"user","isMeta":true,"message":{"role":"user","content":"Caveat: local command output"}}
+{"sessionId":"abc-123","type":"assistant","timestamp":"2025-03-01T10:00:05Z","message":{"id":"msg_1","role":"assistant","content":[{"type":"thinking","thinking":"..."}]}}
+{"sessionId":"abc-123","type":"assistant","timestamp":"2025-03-01T10:00:06Z","message":{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"Let me look."}]}}
+{"sessionId":"abc-123","type":"assistant","timestamp":"2025-03-01T10:00:06Z","message":{"id":"msg_1","role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"ls"}}]}}
+{"sessionId":"abc-123","type":"user","timestamp":"2025-03-01T10:00:07Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":[{"type":"text","text":"main.go\n` + "```" + `"}]}]}}
+{"sessionId":"abc-123","type":"assistant","timestamp":"2025-03-01T10:00:09Z","message":{"id":"msg_2","role":"assistant","content":[{"type":"text","text":"There is one file."}]}}
+`
+
+func TestParseClaudeTranscript(t *testing.T) {
+	tr := parseClaudeTranscript([]byte(claudeTranscriptFixture))
+
+	if tr.SessionID != "abc-123" || tr.CWD != "/src/api" {
+		t.Errorf("SessionID/CWD = %q/%q", tr.SessionID, tr.CWD)
+	}
+	if len(tr.Messages) != 3 {
+		t.Fatalf("Expected 3 messages (meta, thinking and tool results skipped), got %d: %+v", len(tr.Messages), tr.Messages)
+	}
+	m := tr.Messages[1]
+	if m.Role != "assistant" || m.Text != "Let me look." || len(m.ToolCalls) != 1 {
+		t.Fatalf("Records of one message should be joined, got %+v", m)
+	}
+	call := m.ToolCalls[0]
+	if call.Name != "Bash" || call.Input != `{"command":"ls"}` || call.Output != "main.go\n```" {
+		t.Errorf("Unexpected tool call: %+v", call)
+	}
+}
+
+func TestParseGeminiTranscript(t *testing.T) {
+	data := `{"sessionId":"gem-1","messages":[
+		{"type":"user","content":"Run the tests","timestamp":"2025-03-01T10:00:00.000Z"},
+		{"type":"info","content":"Switched model"},
+		{"type":"gemini","content":"Running them.","toolCalls":[{"id":"c1","name":"run_shell_command","args":{"command":"go test"},"status":"error","resultDisplay":"FAIL"}]}]}`
+
+	tr, err := parseGeminiTranscript([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.SessionID != "gem-1" || len(tr.Messages) != 2 {
+		t.Fatalf("Unexpected transcript: %+v", tr)
+	}
+	m := tr.Messages[1]
+	if m.Role != "assistant" || len(m.ToolCalls) != 1 || !m.ToolCalls[0].Error || m.ToolCalls[0].Output != "FAIL" {
+		t.Errorf("Unexpected assistant message: %+v", m)
+	}
+}
+
+func TestTranscriptRender(t *testing.T) {
+	tr := parseClaudeTranscript([]byte(claudeTranscriptFixture))
+	tr.Title = "api"
+
+	md, err := tr.Render(TranscriptMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# api\n", "## 👤 User", "List the <files>", "<summary>🔧 Bash</summary>", "\"command\": \"ls\"", "````\nmain.go\n```\n````"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown should contain %q:\n%s", want, md)
+		}
+	}
+
+	page, err := tr.Render(TranscriptHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(page, "List the &lt;files&gt;") || !strings.Contains(page, "<details>") {
+		t.Errorf("HTML should escape text and collapse tool calls:\n%s", page)
+	}
+
+	data, err := tr.Render(TranscriptJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Transcript
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil || len(decoded.Messages) != 3 {
+		t.Errorf("JSON should round-trip, got %v: %s", err, data)
+	}
+
+	if _, err := tr.Render("pdf"); err == nil {
+		t.Error("Unknown format should fail")
+	}
+}
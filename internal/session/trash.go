@@ -0,0 +1,213 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TrashedSession is a deleted session kept for restoring, with its tool
+// session IDs so a restart resumes the conversation
+type TrashedSession struct {
+	Session   *InstanceData `json:"session"`
+	DeletedAt time.Time     `json:"deleted_at"`
+}
+
+// trashData is the trash file (trash.json next to sessions.json)
+type trashData struct {
+	Sessions []*TrashedSession `json:"sessions"`
+}
+
+// trashPath returns the path of the profile's trash
+func (s *Storage) trashPath() string {
+	return filepath.Join(filepath.Dir(s.path), "trash.json")
+}
+
+// readTrash reads the trash without the sessions past the retention period,
+// newest first
+func (s *Storage) readTrash() ([]*TrashedSession, error) {
+	jsonData, err := os.ReadFile(s.trashPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+	var data trashData
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse trash: %w", err)
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -GetTrashSettings().RetentionDays)
+	items := make([]*TrashedSession, 0, len(data.Sessions))
+	for _, item := range data.Sessions {
+		if item.Session != nil && item.DeletedAt.After(cutoff) {
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// writeTrash replaces the trash, under the storage lock
+func (s *Storage) writeTrash(items []*TrashedSession) error {
+	jsonData, err := json.MarshalIndent(trashData{Sessions: items}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trash: %w", err)
+	}
+	tmpPath := s.trashPath() + ".tmp"
+	if err := os.WriteFile(tmpPath, jsonData, 0600); err != nil {
+		return fmt.Errorf("failed to write trash: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.trashPath()); err != nil {
+		return fmt.Errorf("failed to write trash: %w", err)
+	}
+	return nil
+}
+
+// updateTrash moves deleted sessions to the trash and takes recreated ones
+// out of it, under the storage lock
+func (s *Storage) updateTrash(changes []JournalChange) error {
+	deleted := make(map[string]*InstanceData)
+	created := make(map[string]bool)
+	for _, c := range changes {
+		switch {
+		case c.IsGroup():
+		case c.Op == JournalDelete:
+			deleted[c.Before.ID] = c.Before
+		case c.Op == JournalCreate:
+			created[c.After.ID] = true
+		}
+	}
+	if len(deleted) == 0 && len(created) == 0 {
+		return nil
+	}
+
+	items, err := s.readTrash()
+	if err != nil {
+		items = nil // Start over rather than keep failing
+	}
+	kept := make([]*TrashedSession, 0, len(items)+len(deleted))
+	now := time.Now()
+	for _, c := range changes {
+		if !c.IsGroup() && c.Op == JournalDelete {
+			kept = append(kept, &TrashedSession{Session: c.Before, DeletedAt: now})
+		}
+	}
+	for _, item := range items {
+		if deleted[item.Session.ID] == nil && !created[item.Session.ID] {
+			kept = append(kept, item)
+		}
+	}
+	return s.writeTrash(kept)
+}
+
+// Trash returns the deleted sessions that can still be restored, newest first
+func (s *Storage) Trash() ([]*TrashedSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readTrash()
+}
+
+// findTrashed returns the newest trashed session matching identifier (ID,
+// ID prefix or title)
+func findTrashed(items []*TrashedSession, identifier string) *TrashedSession {
+	for _, item := range items {
+		if item.Session.ID == identifier {
+			return item
+		}
+	}
+	for _, item := range items {
+		if strings.HasPrefix(item.Session.ID, identifier) || item.Session.Title == identifier {
+			return item
+		}
+	}
+	return nil
+}
+
+// RestoreFromTrash puts a trashed session (by ID, ID prefix or title) back
+// and returns it. Its tmux session is gone, so it needs a restart.
+func (s *Storage) RestoreFromTrash(identifier string) (*InstanceData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	items, err := s.readTrash()
+	if err != nil {
+		return nil, err
+	}
+	item := findTrashed(items, identifier)
+	if item == nil {
+		return nil, fmt.Errorf("no session in trash matches %q", identifier)
+	}
+
+	disk := s.readLocked()
+	if disk == nil {
+		return nil, fmt.Errorf("storage file is unreadable")
+	}
+	parentExists := false
+	for _, inst := range disk.Instances {
+		if inst.ID == item.Session.ID {
+			return nil, fmt.Errorf("session %s already exists", inst.ID)
+		}
+		parentExists = parentExists || inst.ID == item.Session.ParentSessionID
+	}
+
+	restored := *item.Session
+	restored.Revision++
+	if !parentExists {
+		restored.ParentSessionID = "" // The parent is gone too: restore as a top-level session
+	}
+	data := &StorageData{
+		Instances: append(append([]*InstanceData(nil), disk.Instances...), &restored),
+		Groups:    disk.Groups,
+	}
+	if err := s.write(disk, data, 0); err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+// PurgeTrash permanently deletes a trashed session (by ID, ID prefix or
+// title), or all of them if identifier is empty, and returns how many
+func (s *Storage) PurgeTrash(identifier string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	items, err := s.readTrash()
+	if err != nil {
+		return 0, err
+	}
+	if identifier == "" {
+		return len(items), s.writeTrash(nil)
+	}
+
+	item := findTrashed(items, identifier)
+	if item == nil {
+		return 0, fmt.Errorf("no session in trash matches %q", identifier)
+	}
+	kept := make([]*TrashedSession, 0, len(items)-1)
+	for _, other := range items {
+		if other != item {
+			kept = append(kept, other)
+		}
+	}
+	return 1, s.writeTrash(kept)
+}
@@ -1,12 +1,17 @@
 package session
 
 import (
+	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
 )
 
 // UserConfigFileName is the TOML config file for user preferences
@@ -40,6 +45,23 @@ type UserConfig struct {
 
 	// Updates defines auto-update settings
 	Updates UpdateSettings `toml:"updates"`
+
+	// Notifications defines desktop/webhook notifications for status changes
+	Notifications NotificationSettings `toml:"notifications"`
+
+	// Templates defines named session configurations ([templates.NAME])
+	// for `agent-deck add --template` and the New dialog
+	Templates map[string]TemplateDef `toml:"templates"`
+
+	// Trash defines how long deleted sessions stay restorable
+	Trash TrashSettings `toml:"trash"`
+}
+
+// TrashSettings defines the trash of deleted sessions (agent-deck trash)
+type TrashSettings struct {
+	// RetentionDays is how long a deleted session can be restored
+	// Default: 30
+	RetentionDays int `toml:"retention_days"`
 }
 
 // MCPPoolSettings defines HTTP MCP pool configuration
@@ -77,6 +99,17 @@ type MCPPoolSettings struct {
 
 	// ExcludeMCPs excludes specific MCPs from pool when pool_all = true
 	ExcludeMCPs []string `toml:"exclude_mcps"`
+
+	// HTTPEnabled also exposes each pooled stdio MCP as a Streamable HTTP endpoint
+	// on a port from PortStart..PortEnd, for clients that only speak HTTP MCP
+	// (Gemini sessions use it automatically) (default: false)
+	HTTPEnabled bool `toml:"http_enabled"`
+
+	// RecordMCPs lists pooled MCPs whose JSON-RPC traffic is recorded to
+	// ~/.agent-deck/logs/mcppool/<name>_traffic.jsonl for `agent-deck mcp trace`
+	// ("*" records all). Recording can also be toggled at runtime with
+	// `agent-deck mcp trace --record <name>`.
+	RecordMCPs []string `toml:"record_mcps"`
 }
 
 // LogSettings defines log file management configuration
@@ -114,6 +147,101 @@ type UpdateSettings struct {
 	NotifyInCLI bool `toml:"notify_in_cli"`
 }
 
+// NotificationSettings defines notifications for session status changes
+type NotificationSettings struct {
+	NotificationRule
+
+	// Groups overrides the rule per group path, e.g. [notifications.groups."work"]
+	// An override also applies to the group's subgroups
+	Groups map[string]NotificationOverride `toml:"groups"`
+}
+
+// NotificationRule decides which transitions notify and how
+type NotificationRule struct {
+	// Enabled turns notifications on (default: false)
+	Enabled bool `toml:"enabled"`
+
+	// On lists the statuses that notify when a session enters them
+	// Default: ["waiting", "approval", "error"]
+	On []string `toml:"on"`
+
+	// Desktop sends notify-send (Linux) / osascript (macOS) notifications
+	// Default: true when no other channel is configured
+	Desktop bool `toml:"desktop"`
+
+	// Terminal writes an OSC 777 notification to the terminal running the TUI
+	Terminal bool `toml:"terminal"`
+
+	// Command is run with sh -c; the event is passed in AGENTDECK_* variables
+	Command string `toml:"command"`
+
+	// Webhook receives the event as a JSON POST
+	Webhook string `toml:"webhook"`
+
+	// DebounceSeconds is the minimum time between notifications for one
+	// session (default: 30)
+	DebounceSeconds int `toml:"debounce_seconds"`
+
+	// QuietHours suppresses notifications in a local time range, e.g. "22:00-07:00"
+	QuietHours string `toml:"quiet_hours"`
+}
+
+// NotificationOverride changes a NotificationRule for one group. Unset fields
+// inherit the parent group's (or global) value; quiet_hours = "off" clears
+// inherited quiet hours.
+type NotificationOverride struct {
+	Enabled         *bool    `toml:"enabled"`
+	On              []string `toml:"on"`
+	Desktop         *bool    `toml:"desktop"`
+	Terminal        *bool    `toml:"terminal"`
+	Command         *string  `toml:"command"`
+	Webhook         *string  `toml:"webhook"`
+	DebounceSeconds *int     `toml:"debounce_seconds"`
+	QuietHours      *string  `toml:"quiet_hours"`
+}
+
+// ForGroup returns the rule for sessions in groupPath, applying overrides
+// from the top-level group down
+func (s NotificationSettings) ForGroup(groupPath string) NotificationRule {
+	rule := s.NotificationRule
+	if groupPath == "" {
+		return rule
+	}
+
+	parts := strings.Split(groupPath, "/")
+	for n := 1; n <= len(parts); n++ {
+		override, ok := s.Groups[strings.Join(parts[:n], "/")]
+		if !ok {
+			continue
+		}
+		if override.Enabled != nil {
+			rule.Enabled = *override.Enabled
+		}
+		if len(override.On) > 0 {
+			rule.On = override.On
+		}
+		if override.Desktop != nil {
+			rule.Desktop = *override.Desktop
+		}
+		if override.Terminal != nil {
+			rule.Terminal = *override.Terminal
+		}
+		if override.Command != nil {
+			rule.Command = *override.Command
+		}
+		if override.Webhook != nil {
+			rule.Webhook = *override.Webhook
+		}
+		if override.DebounceSeconds != nil {
+			rule.DebounceSeconds = *override.DebounceSeconds
+		}
+		if override.QuietHours != nil {
+			rule.QuietHours = *override.QuietHours
+		}
+	}
+	return rule
+}
+
 // ClaudeSettings defines Claude Code configuration
 type ClaudeSettings struct {
 	// ConfigDir is the path to Claude's config directory
@@ -130,19 +258,20 @@ type GlobalSearchSettings struct {
 	// Enabled enables/disables global search feature (default: true when loaded via LoadUserConfig)
 	Enabled bool `toml:"enabled"`
 
-	// Tier controls search strategy: "auto", "instant", "balanced", "disabled"
+	// Tier controls how many conversations are kept loaded for results:
+	// "auto", "instant", "balanced", "disabled". The index itself is on disk.
 	// auto: Auto-detect based on data size (recommended)
-	// instant: Force full in-memory (fast, uses more RAM)
-	// balanced: Force LRU cache mode (slower, capped RAM)
+	// instant: Cache up to 100MB of conversations (fast, uses more RAM)
+	// balanced: Cache up to MemoryLimitMB (slower, capped RAM)
 	// disabled: Disable global search entirely
 	Tier string `toml:"tier"`
 
-	// MemoryLimitMB caps memory usage for search index (default: 100)
+	// MemoryLimitMB caps memory used by loaded conversations (default: 100)
 	// Only applies to balanced tier
 	MemoryLimitMB int `toml:"memory_limit_mb"`
 
-	// RecentDays limits search to sessions from last N days (0 = all)
-	// Reduces index size for users with long history (default: 90)
+	// RecentDays limits results to messages from the last N days unless the
+	// query has an after: filter (0 = all). Older history is still indexed.
 	RecentDays int `toml:"recent_days"`
 
 	// IndexRateLimit limits files indexed per second during background indexing
@@ -160,6 +289,37 @@ type ToolDef struct {
 
 	// BusyPatterns are strings that indicate the tool is busy
 	BusyPatterns []string `toml:"busy_patterns"`
+
+	// Status configures regex-based status detection ([tools.NAME.status])
+	Status ToolStatusDef `toml:"status"`
+}
+
+// ToolStatusDef configures how a tool's state is read from its terminal.
+// Patterns are regular expressions matched against each of the last LastLines
+// non-empty lines (trailing whitespace stripped), so ^ and $ anchor to a line.
+// They are checked in the order permission, busy, error, waiting; when none
+// match, the built-in heuristics (spinners, "esc to interrupt", ...) and
+// activity tracking decide.
+type ToolStatusDef struct {
+	// Busy patterns mean the tool is working (running)
+	Busy []string `toml:"busy"`
+
+	// Waiting patterns mean the tool is waiting for input
+	Waiting []string `toml:"waiting"`
+
+	// Permission patterns mean the tool is asking to approve an action
+	Permission []string `toml:"permission"`
+
+	// Error patterns mean the tool hit an error
+	Error []string `toml:"error"`
+
+	// LastLines limits matching to the bottom of the pane (default: 10)
+	LastLines int `toml:"last_lines"`
+
+	// ApproveKeys and DenyKeys are the tmux keys `session approve/deny` send
+	// to answer a permission prompt (default: ["y", "Enter"] / ["n", "Enter"])
+	ApproveKeys []string `toml:"approve_keys"`
+	DenyKeys    []string `toml:"deny_keys"`
 }
 
 // MCPDef defines an MCP server configuration for the MCP Manager
@@ -263,6 +423,9 @@ func ReloadUserConfig() (*UserConfig, error) {
 	userConfigCacheMu.Lock()
 	userConfigCache = nil
 	userConfigCacheMu.Unlock()
+	toolDetectorCacheMu.Lock()
+	toolDetectorCache = nil
+	toolDetectorCacheMu.Unlock()
 	return LoadUserConfig()
 }
 
@@ -287,23 +450,7 @@ func GetToolIcon(toolName string) string {
 		return def.Icon
 	}
 
-	// Built-in icons
-	switch toolName {
-	case "claude":
-		return "🤖"
-	case "gemini":
-		return "✨"
-	case "opencode":
-		return "🌐"
-	case "codex":
-		return "💻"
-	case "cursor":
-		return "📝"
-	case "shell":
-		return "🐚"
-	default:
-		return "🐚"
-	}
+	return GetToolAdapter(toolName).Icon()
 }
 
 // GetToolBusyPatterns returns busy patterns for a tool (custom + built-in)
@@ -319,6 +466,59 @@ func GetToolBusyPatterns(toolName string) []string {
 	return patterns
 }
 
+// Compiled status detectors per tool (nil entries: built-in detection)
+var (
+	toolDetectorCache   map[string]tmux.StatusDetector
+	toolDetectorCacheMu sync.Mutex
+)
+
+// GetToolStatusDetector returns the status detector configured for a tool via
+// busy_patterns and [tools.NAME.status], or nil if the tool has none (the tmux
+// session then uses its built-in detector). Unmatched content falls through to
+// the tool adapter's detector. Invalid patterns are logged and the tool falls
+// back to built-in detection.
+func GetToolStatusDetector(toolName string) tmux.StatusDetector {
+	toolDetectorCacheMu.Lock()
+	defer toolDetectorCacheMu.Unlock()
+	if d, ok := toolDetectorCache[toolName]; ok {
+		return d
+	}
+	if toolDetectorCache == nil {
+		toolDetectorCache = make(map[string]tmux.StatusDetector)
+	}
+
+	var detector tmux.StatusDetector
+	if def := GetToolDef(toolName); def != nil {
+		status := def.Status
+		// busy_patterns are plain substrings
+		busy := append([]string(nil), status.Busy...)
+		for _, pattern := range def.BusyPatterns {
+			busy = append(busy, regexp.QuoteMeta(pattern))
+		}
+		if len(busy)+len(status.Waiting)+len(status.Permission)+len(status.Error) > 0 {
+			fallback := GetToolAdapter(toolName).StatusDetector()
+			if fallback == nil {
+				fallback = tmux.BuiltinDetector(toolName)
+			}
+			d, err := tmux.NewRegexDetector(tmux.RegexDetectorConfig{
+				Busy:       busy,
+				Waiting:    status.Waiting,
+				Permission: status.Permission,
+				Error:      status.Error,
+				LastLines:  status.LastLines,
+				Fallback:   fallback,
+			})
+			if err != nil {
+				log.Printf("Warning: ignoring status patterns for tool %q: %v", toolName, err)
+			} else {
+				detector = d
+			}
+		}
+	}
+	toolDetectorCache[toolName] = detector
+	return detector
+}
+
 // GetDefaultTool returns the user's preferred default tool for new sessions
 // Returns empty string if not configured (defaults to shell)
 func GetDefaultTool() string {
@@ -359,6 +559,20 @@ func GetLogSettings() LogSettings {
 	return settings
 }
 
+// GetTrashSettings returns trash settings with defaults applied
+func GetTrashSettings() TrashSettings {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return TrashSettings{RetentionDays: 30}
+	}
+
+	settings := config.Trash
+	if settings.RetentionDays <= 0 {
+		settings.RetentionDays = 30
+	}
+	return settings
+}
+
 // GetUpdateSettings returns update settings with defaults applied
 func GetUpdateSettings() UpdateSettings {
 	config, err := LoadUserConfig()
@@ -387,6 +601,29 @@ func GetUpdateSettings() UpdateSettings {
 	return settings
 }
 
+// GetNotificationSettings returns notification settings with defaults applied
+func GetNotificationSettings() NotificationSettings {
+	config, err := LoadUserConfig()
+	if err != nil || config == nil {
+		return NotificationSettings{}
+	}
+
+	settings := config.Notifications
+
+	// Apply defaults for unset values
+	if len(settings.On) == 0 {
+		settings.On = []string{string(StatusWaiting), string(StatusApproval), string(StatusError)}
+	}
+	if !settings.Desktop && !settings.Terminal && settings.Command == "" && settings.Webhook == "" {
+		settings.Desktop = true
+	}
+	if settings.DebounceSeconds <= 0 {
+		settings.DebounceSeconds = 30
+	}
+
+	return settings
+}
+
 // CreateExampleConfig creates an example config file if none exists
 func CreateExampleConfig() error {
 	configPath, err := GetUserConfigPath()
@@ -425,6 +662,11 @@ max_lines = 10000
 # Remove log files for sessions that no longer exist (default: true)
 remove_orphans = true
 
+# Deleted sessions stay restorable with agent-deck trash restore
+[trash]
+# Days a deleted session is kept (default: 30)
+retention_days = 30
+
 # Update settings
 # Controls automatic update checking and installation
 [updates]
@@ -437,6 +679,28 @@ check_interval_hours = 24
 # Show update notification in CLI commands, not just TUI (default: true)
 notify_in_cli = true
 
+# Notifications when a session changes status while the TUI is running
+# [notifications]
+# enabled = true
+# Statuses that notify (default: waiting, approval, error)
+# on = ["waiting", "approval", "error"]
+# Channels: desktop (notify-send/osascript, default when none is set),
+# terminal (OSC 777), command (sh -c, with AGENTDECK_* variables), webhook (JSON POST)
+# desktop = true
+# terminal = true
+# command = "say \"$AGENTDECK_SESSION_TITLE $AGENTDECK_MESSAGE\""
+# webhook = "https://hooks.example.com/agent-deck"
+# Minimum seconds between notifications for one session (default: 30)
+# debounce_seconds = 30
+# quiet_hours = "22:00-07:00"
+#
+# Per-group overrides (also apply to subgroups)
+# [notifications.groups."work"]
+# webhook = "https://hooks.slack.com/services/..."
+# quiet_hours = "off"
+# [notifications.groups."experiments"]
+# enabled = false
+
 # ============================================================================
 # MCP Server Definitions
 # ============================================================================
@@ -504,12 +768,52 @@ notify_in_cli = true
 #   command      - The shell command to run
 #   icon         - Emoji/symbol shown in the UI
 #   busy_patterns - Strings that indicate the tool is processing
+#   [tools.NAME.status] - Regexes for busy, waiting, permission and error
+#                   states, matched per line (^/$ anchor to a line) against
+#                   the last last_lines non-empty lines of the pane
 
 # Example: Add a custom AI tool
 # [tools.my-ai]
 # command = "my-ai-assistant"
 # icon = "🧠"
 # busy_patterns = ["thinking...", "processing..."]
+#
+# [tools.my-ai.status]
+# waiting = ['^my-ai>$']
+# permission = ['Allow this action\? \[y/n\]']
+# error = ['^Error:']
+# last_lines = 15
+# approve_keys = ["y", "Enter"]
+# deny_keys = ["n", "Enter"]
+
+# ============================================================================
+# Session Templates
+# ============================================================================
+# Named session setups for 'agent-deck add --template NAME' and the New
+# dialog (n, then t to pick a template). Each template can have:
+#   description - Shown by 'agent-deck template list'
+#   tool        - "claude", "gemini", "opencode", "codex" or a custom tool
+#   command     - Overrides the tool's command
+#   group       - Group new sessions are created in
+#   mcps        - [mcps.NAME] entries attached to the project
+#   env         - Environment variables for the session
+#   prompt      - Sent once the agent is ready; {{path}} and {{branch}} expand
+#                 to the project path and its git branch
+#   [templates.NAME.claude] - model, permission_mode, add_dirs,
+#                 allowed_tools and dangerous_mode for Claude sessions
+
+# Example: Code review template
+# [templates.review]
+# description = "Review the current branch"
+# tool = "claude"
+# group = "reviews"
+# mcps = ["github"]
+# env = { GIT_PAGER = "cat" }
+# prompt = "Review the changes on {{branch}} in {{path}} against main."
+#
+# [templates.review.claude]
+# model = "opus"
+# permission_mode = "plan"
 
 # Example: Add GitHub Copilot CLI
 # [tools.copilot]
@@ -3,9 +3,11 @@ package session
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/BurntSushi/toml"
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
 )
 
 func TestUserConfig_ClaudeConfigDir(t *testing.T) {
@@ -149,3 +151,112 @@ tier = "disabled"
 		t.Errorf("Expected tier 'disabled', got %q", config.GlobalSearch.Tier)
 	}
 }
+
+func TestGetToolStatusDetector(t *testing.T) {
+	configContent := `
+[tools.my-agent]
+command = "my-agent"
+busy_patterns = ["Thinking..."]
+
+[tools.my-agent.status]
+waiting = ['^my-agent>$']
+permission = ['Allow\? \[y/n\]']
+error = ['^Error:']
+last_lines = 5
+
+[tools.broken.status]
+error = ['(unclosed']
+
+[tools.plain]
+command = "plain"
+`
+	var config UserConfig
+	if _, err := toml.Decode(configContent, &config); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if config.Tools["my-agent"].Status.LastLines != 5 {
+		t.Errorf("Status.LastLines = %d, want 5", config.Tools["my-agent"].Status.LastLines)
+	}
+
+	userConfigCacheMu.Lock()
+	userConfigCache = &config
+	userConfigCacheMu.Unlock()
+	toolDetectorCacheMu.Lock()
+	toolDetectorCache = nil
+	toolDetectorCacheMu.Unlock()
+	defer func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = nil
+		userConfigCacheMu.Unlock()
+		toolDetectorCacheMu.Lock()
+		toolDetectorCache = nil
+		toolDetectorCacheMu.Unlock()
+	}()
+
+	d := GetToolStatusDetector("my-agent")
+	if d == nil {
+		t.Fatal("expected a detector for my-agent")
+	}
+	tests := []struct {
+		content string
+		want    tmux.SessionState
+	}{
+		{"Thinking... (3s)\n", tmux.StateBusy}, // busy_patterns match literally
+		{"done\nmy-agent>\n", tmux.StateWaiting},
+		{"delete it?\nAllow? [y/n]\n", tmux.StatePermission},
+		{"Error: no credits\nmy-agent>\n", tmux.StateError},
+		{"⠋ loading\n", tmux.StateBusy}, // Built-in heuristics still apply
+	}
+	for _, tt := range tests {
+		if got := d.Detect(tt.content); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+
+	if d := GetToolStatusDetector("broken"); d != nil {
+		t.Error("invalid patterns should fall back to built-in detection")
+	}
+	if d := GetToolStatusDetector("plain"); d != nil {
+		t.Error("tools without patterns should use built-in detection")
+	}
+	if d := GetToolStatusDetector("claude"); d != nil {
+		t.Error("unconfigured built-in tools should use built-in detection")
+	}
+}
+
+func TestApprovalKeys(t *testing.T) {
+	configContent := `
+[tools.my-agent.status]
+permission = ['Allow\? \[y/n\]']
+approve_keys = ["a"]
+`
+	var config UserConfig
+	if _, err := toml.Decode(configContent, &config); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	userConfigCacheMu.Lock()
+	userConfigCache = &config
+	userConfigCacheMu.Unlock()
+	defer func() {
+		userConfigCacheMu.Lock()
+		userConfigCache = nil
+		userConfigCacheMu.Unlock()
+	}()
+
+	tests := []struct {
+		tool    string
+		approve bool
+		want    string
+	}{
+		{"claude", true, "1"},
+		{"claude", false, "Escape"},
+		{"my-agent", true, "a"},
+		{"my-agent", false, "n Enter"}, // deny_keys not set: generic default
+		{"shell", true, "y Enter"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(ApprovalKeys(tt.tool, tt.approve), " "); got != tt.want {
+			t.Errorf("ApprovalKeys(%q, %v) = %q, want %q", tt.tool, tt.approve, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,552 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Workspace is a declarative description of a profile's groups and sessions,
+// read by `agent-deck apply -f deck.toml` and written by `agent-deck export`.
+// Sessions are matched to existing ones by title.
+type Workspace struct {
+	Groups   []WorkspaceGroup   `toml:"groups,omitempty"`
+	Sessions []WorkspaceSession `toml:"sessions,omitempty"`
+}
+
+// WorkspaceGroup is a [[groups]] entry. Groups used by sessions don't need
+// one; it only sets a display name or collapses the group.
+type WorkspaceGroup struct {
+	Path      string `toml:"path"`
+	Name      string `toml:"name,omitempty"` // Defaults to the last path segment
+	Collapsed bool   `toml:"collapsed,omitempty"`
+}
+
+// WorkspaceSession is a [[sessions]] entry
+type WorkspaceSession struct {
+	Title string `toml:"title"`
+	Path  string `toml:"path"`
+
+	// Group defaults to the parent's group for sub-sessions, else to the
+	// session's current group (new sessions: derived from the path)
+	Group string `toml:"group,omitempty"`
+
+	// Parent is the title of the parent session (makes this a sub-session)
+	Parent string `toml:"parent,omitempty"`
+
+	Tool    string            `toml:"tool,omitempty"`
+	Command string            `toml:"command,omitempty"`
+	MCPs    []string          `toml:"mcps,omitempty"` // Left as is when omitted
+	Env     map[string]string `toml:"env,omitempty"`
+	Claude  *ClaudeOptions    `toml:"claude,omitempty"`
+}
+
+// template returns the session's configuration as a template, so workspace
+// sessions share the tool/command resolution of `add --template`
+func (s *WorkspaceSession) template() *TemplateDef {
+	return &TemplateDef{
+		Tool:    s.Tool,
+		Command: s.Command,
+		MCPs:    s.MCPs,
+		Env:     s.Env,
+		Claude:  s.Claude,
+	}
+}
+
+// LoadWorkspace reads a workspace file. Relative session paths are resolved
+// against the file's directory and unknown keys are rejected, so typos don't
+// silently drop settings.
+func LoadWorkspace(path string) (*Workspace, error) {
+	var w Workspace
+	md, err := toml.DecodeFile(path, &w)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return nil, fmt.Errorf("%s: unknown keys: %s", path, strings.Join(keys, ", "))
+	}
+
+	baseDir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	for i := range w.Sessions {
+		s := &w.Sessions[i]
+		if s.Path == "" {
+			continue
+		}
+		s.Path = expandTilde(s.Path)
+		if !filepath.IsAbs(s.Path) {
+			s.Path = filepath.Join(baseDir, s.Path)
+		}
+		s.Path = filepath.Clean(s.Path)
+	}
+	for i := range w.Groups {
+		w.Groups[i].Path = strings.Trim(w.Groups[i].Path, "/")
+	}
+	for i := range w.Sessions {
+		w.Sessions[i].Group = strings.Trim(w.Sessions[i].Group, "/")
+	}
+
+	if err := w.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &w, nil
+}
+
+// Validate checks that titles are unique, parents exist one level deep and
+// every session's MCPs and Claude flags are valid
+func (w *Workspace) Validate() error {
+	seenGroups := make(map[string]bool, len(w.Groups))
+	for _, g := range w.Groups {
+		if g.Path == "" {
+			return fmt.Errorf("group without a path")
+		}
+		if seenGroups[g.Path] {
+			return fmt.Errorf("group '%s' is listed twice", g.Path)
+		}
+		seenGroups[g.Path] = true
+	}
+
+	byTitle := make(map[string]*WorkspaceSession, len(w.Sessions))
+	for i := range w.Sessions {
+		s := &w.Sessions[i]
+		if s.Title == "" {
+			return fmt.Errorf("session #%d has no title", i+1)
+		}
+		if _, exists := byTitle[s.Title]; exists {
+			return fmt.Errorf("session title '%s' is used twice (titles identify sessions)", s.Title)
+		}
+		if s.Path == "" {
+			return fmt.Errorf("session '%s' has no path", s.Title)
+		}
+		byTitle[s.Title] = s
+	}
+
+	for i := range w.Sessions {
+		s := &w.Sessions[i]
+		if s.Parent != "" {
+			parent, ok := byTitle[s.Parent]
+			if !ok {
+				return fmt.Errorf("session '%s': parent '%s' is not in the workspace", s.Title, s.Parent)
+			}
+			if parent == s || parent.Parent != "" {
+				return fmt.Errorf("session '%s': parent '%s' cannot be a sub-session (single level only)", s.Title, s.Parent)
+			}
+		}
+		if err := s.template().Validate(); err != nil {
+			return fmt.Errorf("session '%s': %w", s.Title, err)
+		}
+	}
+	return nil
+}
+
+// Encode writes the workspace as TOML
+func (w *Workspace) Encode(out io.Writer) error {
+	enc := toml.NewEncoder(out)
+	enc.Indent = ""
+	return enc.Encode(w)
+}
+
+// ExportWorkspace describes a profile's groups and sessions as a Workspace.
+// Paths under the home directory are written with ~ so the file can be
+// applied on other machines.
+func ExportWorkspace(instances []*Instance, tree *GroupTree) *Workspace {
+	w := &Workspace{}
+
+	for _, g := range tree.GroupList {
+		if g.Path == DefaultGroupPath {
+			continue
+		}
+		wg := WorkspaceGroup{Path: g.Path, Collapsed: !g.Expanded}
+		if g.Name != defaultGroupName(g.Path) {
+			wg.Name = g.Name
+		}
+		w.Groups = append(w.Groups, wg)
+	}
+
+	titles := make(map[string]string, len(instances))
+	for _, inst := range instances {
+		titles[inst.ID] = inst.Title
+	}
+
+	// Follow the tree's order; sessions in unknown groups go last
+	ordered := tree.GetAllInstances()
+	for _, inst := range instances {
+		if !slices.Contains(ordered, inst) {
+			ordered = append(ordered, inst)
+		}
+	}
+
+	home, _ := os.UserHomeDir()
+	for _, inst := range ordered {
+		t := TemplateFromInstance(inst)
+		path := inst.ProjectPath
+		if home != "" && strings.HasPrefix(path, home+string(filepath.Separator)) {
+			path = "~" + strings.TrimPrefix(path, home)
+		}
+		w.Sessions = append(w.Sessions, WorkspaceSession{
+			Title:   inst.Title,
+			Path:    path,
+			Group:   inst.GroupPath,
+			Parent:  titles[inst.ParentSessionID],
+			Tool:    t.Tool,
+			Command: t.Command,
+			MCPs:    t.MCPs,
+			Env:     t.Env,
+			Claude:  t.Claude,
+		})
+	}
+	return w
+}
+
+// defaultGroupName is the name a group gets when a workspace doesn't set one
+func defaultGroupName(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// Workspace change actions
+const (
+	WorkspaceCreate = "create"
+	WorkspaceUpdate = "update"
+	WorkspaceRemove = "remove"
+)
+
+// WorkspaceChange is one step of a WorkspacePlan
+type WorkspaceChange struct {
+	Action string   // WorkspaceCreate, WorkspaceUpdate or WorkspaceRemove
+	Kind   string   // "group" or "session"
+	Name   string   // Group path or session title
+	Fields []string // What an update changes ("group", "tool", "env", ...)
+
+	group   *WorkspaceGroup
+	session *WorkspaceSession
+	inst    *Instance
+}
+
+// WorkspacePlan is what applying a workspace to a profile changes
+type WorkspacePlan struct {
+	Changes []WorkspaceChange
+
+	// Unmanaged lists the sessions and groups that are not in the workspace.
+	// They are kept unless the plan was made with prune.
+	Unmanaged []string
+
+	// sessionGroups holds the resolved group of every workspace session
+	sessionGroups map[string]string
+}
+
+// HasChanges reports whether applying the plan changes anything
+func (p *WorkspacePlan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// PlanWorkspace compares a workspace with a profile's sessions and group
+// tree. With prune, sessions and groups missing from the workspace are
+// removed; otherwise they are listed in Unmanaged.
+func PlanWorkspace(w *Workspace, instances []*Instance, tree *GroupTree, prune bool) (*WorkspacePlan, error) {
+	plan := &WorkspacePlan{sessionGroups: make(map[string]string, len(w.Sessions))}
+
+	existing := make(map[string]*Instance, len(instances))
+	titles := make(map[string]string, len(instances))
+	for _, inst := range instances {
+		if other, dup := existing[inst.Title]; dup {
+			return nil, fmt.Errorf("sessions %s and %s are both titled '%s'; rename one to apply a workspace",
+				other.ID[:8], inst.ID[:8], inst.Title)
+		}
+		existing[inst.Title] = inst
+		titles[inst.ID] = inst.Title
+	}
+
+	// Resolve every session's group: explicit, else the parent's, else the
+	// current one, else the default of a new session in that path. Parents
+	// are resolved first.
+	specs := make(map[string]*WorkspaceSession, len(w.Sessions))
+	for i := range w.Sessions {
+		specs[w.Sessions[i].Title] = &w.Sessions[i]
+	}
+	resolveGroup := func(s *WorkspaceSession) string {
+		if s.Group != "" {
+			return s.Group
+		}
+		if inst, ok := existing[s.Title]; ok {
+			return inst.GroupPath
+		}
+		return extractGroupPath(s.Path)
+	}
+	for _, s := range specs {
+		if s.Parent == "" {
+			plan.sessionGroups[s.Title] = resolveGroup(s)
+		}
+	}
+	for _, s := range specs {
+		if s.Parent != "" {
+			if s.Group != "" {
+				plan.sessionGroups[s.Title] = s.Group
+			} else {
+				plan.sessionGroups[s.Title] = plan.sessionGroups[s.Parent]
+			}
+		}
+	}
+
+	// Groups: explicit entries plus every group (and parent) sessions use
+	wanted := make(map[string]*WorkspaceGroup)
+	for i := range w.Groups {
+		wanted[w.Groups[i].Path] = &w.Groups[i]
+	}
+	for _, group := range plan.sessionGroups {
+		for path := group; path != ""; path = getParentPath(path) {
+			if _, ok := wanted[path]; !ok {
+				wanted[path] = nil
+			}
+		}
+	}
+	wantedPaths := slices.Sorted(maps.Keys(wanted))
+	for _, path := range wantedPaths {
+		g := wanted[path]
+		current, exists := tree.Groups[path]
+		if !exists {
+			if path != DefaultGroupPath {
+				plan.Changes = append(plan.Changes, WorkspaceChange{Action: WorkspaceCreate, Kind: "group", Name: path, group: g})
+			}
+			continue
+		}
+		if g == nil {
+			continue
+		}
+		var fields []string
+		name := g.Name
+		if name == "" {
+			name = defaultGroupName(path)
+		}
+		if current.Name != name {
+			fields = append(fields, "name")
+		}
+		if current.Expanded == g.Collapsed {
+			fields = append(fields, "collapsed")
+		}
+		if len(fields) > 0 {
+			plan.Changes = append(plan.Changes, WorkspaceChange{Action: WorkspaceUpdate, Kind: "group", Name: path, Fields: fields, group: g})
+		}
+	}
+
+	// Sessions: create the missing ones, update the ones that differ
+	for i := range w.Sessions {
+		s := &w.Sessions[i]
+		inst, exists := existing[s.Title]
+		if !exists || inst.ProjectPath != s.Path {
+			if info, err := os.Stat(s.Path); err != nil || !info.IsDir() {
+				return nil, fmt.Errorf("session '%s': path %s is not a directory", s.Title, s.Path)
+			}
+		}
+		if !exists {
+			plan.Changes = append(plan.Changes, WorkspaceChange{Action: WorkspaceCreate, Kind: "session", Name: s.Title, session: s})
+			continue
+		}
+		if fields := sessionChanges(inst, s, plan.sessionGroups[s.Title], titles[inst.ParentSessionID]); len(fields) > 0 {
+			plan.Changes = append(plan.Changes, WorkspaceChange{Action: WorkspaceUpdate, Kind: "session", Name: s.Title, Fields: fields, session: s, inst: inst})
+		}
+	}
+
+	// Leftovers: removed with prune, reported otherwise
+	for _, inst := range instances {
+		if _, ok := specs[inst.Title]; ok {
+			continue
+		}
+		if prune {
+			plan.Changes = append(plan.Changes, WorkspaceChange{Action: WorkspaceRemove, Kind: "session", Name: inst.Title, inst: inst})
+		} else {
+			plan.Unmanaged = append(plan.Unmanaged, "session "+inst.Title)
+		}
+	}
+	for _, g := range tree.GroupList {
+		if _, ok := wanted[g.Path]; ok || g.Path == DefaultGroupPath {
+			continue
+		}
+		if prune {
+			plan.Changes = append(plan.Changes, WorkspaceChange{Action: WorkspaceRemove, Kind: "group", Name: g.Path})
+		} else {
+			plan.Unmanaged = append(plan.Unmanaged, "group "+g.Path)
+		}
+	}
+
+	return plan, nil
+}
+
+// sessionChanges lists the fields of inst that differ from the workspace
+// session s. Settings the workspace omits (command, MCPs) are left alone.
+func sessionChanges(inst *Instance, s *WorkspaceSession, group, parentTitle string) []string {
+	var fields []string
+	if inst.ProjectPath != s.Path {
+		fields = append(fields, "path")
+	}
+	if inst.GroupPath != group {
+		fields = append(fields, "group")
+	}
+	if parentTitle != s.Parent {
+		fields = append(fields, "parent")
+	}
+
+	t := s.template()
+	currentTool := inst.Tool
+	if currentTool == "" {
+		currentTool = "shell"
+	}
+	if t.ToolName() != currentTool {
+		fields = append(fields, "tool")
+	} else if s.Command != "" && s.Command != inst.Command {
+		fields = append(fields, "command")
+	}
+
+	if !maps.Equal(s.Env, inst.Env) {
+		fields = append(fields, "env")
+	}
+	if t.ToolName() == "claude" && !claudeOptionsEqual(s.Claude, inst.ClaudeOptions) {
+		fields = append(fields, "claude")
+	}
+
+	if s.MCPs != nil {
+		var current []string
+		if mcpInfo := GetMCPInfo(s.Path); mcpInfo != nil {
+			current = mcpInfo.Local()
+		}
+		want := slices.Clone(s.MCPs)
+		sort.Strings(want)
+		sort.Strings(current)
+		if !slices.Equal(want, current) {
+			fields = append(fields, "mcps")
+		}
+	}
+	return fields
+}
+
+// claudeOptionsEqual compares Claude options, treating nil and empty alike
+func claudeOptionsEqual(a, b *ClaudeOptions) bool {
+	if a.IsZero() || b.IsZero() {
+		return a.IsZero() && b.IsZero()
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Apply carries out the plan on a profile's sessions and group tree (as
+// passed to PlanWorkspace) and returns the new session list. New sessions are
+// not started; removed ones are stopped. Running sessions pick up tool,
+// command, environment and Claude flag changes on their next restart.
+func (p *WorkspacePlan) Apply(instances []*Instance, tree *GroupTree) ([]*Instance, error) {
+	// Groups first so sessions land in properly named groups
+	for _, c := range p.Changes {
+		if c.Kind != "group" || c.Action == WorkspaceRemove {
+			continue
+		}
+		group := tree.ensureGroupPath(c.Name)
+		if c.group != nil {
+			group.Name = c.group.Name
+			if group.Name == "" {
+				group.Name = defaultGroupName(c.Name)
+			}
+			group.Expanded = !c.group.Collapsed
+			tree.Expanded[c.Name] = group.Expanded
+		}
+	}
+
+	// Create new sessions, then configure new and changed ones together so
+	// parents can be resolved among the new sessions too
+	type pending struct {
+		inst   *Instance
+		spec   *WorkspaceSession
+		fields []string
+	}
+	var updates []pending
+	removed := make(map[*Instance]bool)
+	for _, c := range p.Changes {
+		if c.Kind != "session" {
+			continue
+		}
+		switch c.Action {
+		case WorkspaceCreate:
+			inst := NewInstance(c.session.Title, c.session.Path)
+			instances = append(instances, inst)
+			updates = append(updates, pending{inst, c.session, nil})
+		case WorkspaceUpdate:
+			updates = append(updates, pending{c.inst, c.session, c.Fields})
+		case WorkspaceRemove:
+			removed[c.inst] = true
+		}
+	}
+
+	byTitle := make(map[string]*Instance, len(instances))
+	for _, inst := range instances {
+		byTitle[inst.Title] = inst
+	}
+	for _, u := range updates {
+		fields := u.fields
+		if fields == nil {
+			fields = sessionChanges(u.inst, u.spec, p.sessionGroups[u.spec.Title], "")
+		}
+		t := u.spec.template()
+		for _, field := range fields {
+			switch field {
+			case "path":
+				u.inst.ProjectPath = u.spec.Path
+			case "group":
+				u.inst.GroupPath = p.sessionGroups[u.spec.Title]
+			case "parent":
+				u.inst.ClearParent()
+				if parent, ok := byTitle[u.spec.Parent]; ok {
+					u.inst.SetParent(parent.ID)
+				}
+			case "tool":
+				u.inst.Tool = t.ToolName()
+				u.inst.Command = t.StartCommand()
+			case "command":
+				u.inst.Command = u.spec.Command
+			case "env":
+				u.inst.Env = maps.Clone(u.spec.Env)
+			case "claude":
+				u.inst.ClaudeOptions = u.spec.Claude.Clone()
+			case "mcps":
+				if err := WriteMCPJsonFromConfig(u.spec.Path, u.spec.MCPs); err != nil {
+					return nil, fmt.Errorf("session '%s': failed to write MCPs: %w", u.spec.Title, err)
+				}
+			}
+		}
+	}
+
+	// Stop and drop removed sessions; their worktrees are kept
+	if len(removed) > 0 {
+		kept := make([]*Instance, 0, len(instances))
+		for _, inst := range instances {
+			if !removed[inst] {
+				kept = append(kept, inst)
+				continue
+			}
+			if inst.Exists() {
+				if err := inst.Kill(); err != nil {
+					log.Printf("Warning: failed to stop session '%s': %v", inst.Title, err)
+				}
+			}
+		}
+		instances = kept
+	}
+	for _, c := range p.Changes {
+		if c.Kind == "group" && c.Action == WorkspaceRemove {
+			tree.DeleteGroup(c.Name)
+		}
+	}
+
+	tree.SyncWithInstances(instances)
+	return instances, nil
+}
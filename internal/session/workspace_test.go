@@ -0,0 +1,171 @@
+package session
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeWorkspace(t *testing.T, content string) *Workspace {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "deck.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := LoadWorkspace(path)
+	if err != nil {
+		t.Fatalf("LoadWorkspace: %v", err)
+	}
+	return w
+}
+
+func TestWorkspaceApply(t *testing.T) {
+	useConfigHome(t)
+	api, web := t.TempDir(), t.TempDir()
+
+	existing := NewInstanceWithGroup("api", api, "old")
+	stale := NewInstanceWithGroup("stale", web, "old")
+	instances := []*Instance{existing, stale}
+	tree := NewGroupTreeWithGroups(instances, nil)
+
+	w := writeWorkspace(t, `
+[[groups]]
+path = "work/backend"
+name = "Backend"
+
+[[sessions]]
+title = "api"
+path = "`+api+`"
+group = "work/backend"
+tool = "claude"
+env = { API_ENV = "staging" }
+
+[sessions.claude]
+model = "opus"
+
+[[sessions]]
+title = "api tests"
+path = "`+api+`"
+parent = "api"
+`)
+
+	plan, err := PlanWorkspace(w, instances, tree, false)
+	if err != nil {
+		t.Fatalf("PlanWorkspace: %v", err)
+	}
+	var summary []string
+	for _, c := range plan.Changes {
+		summary = append(summary, c.Action+" "+c.Kind+" "+c.Name+" "+strings.Join(c.Fields, ","))
+	}
+	want := []string{
+		"create group work ",
+		"create group work/backend ",
+		"update session api group,tool,env,claude",
+		"create session api tests ",
+	}
+	if strings.Join(summary, "\n") != strings.Join(want, "\n") {
+		t.Errorf("plan =\n%s\nwant\n%s", strings.Join(summary, "\n"), strings.Join(want, "\n"))
+	}
+	if strings.Join(plan.Unmanaged, ",") != "session stale,group old" {
+		t.Errorf("Unmanaged = %v", plan.Unmanaged)
+	}
+
+	instances, err = plan.Apply(instances, tree)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if len(instances) != 3 {
+		t.Fatalf("got %d sessions, want 3", len(instances))
+	}
+	if existing.GroupPath != "work/backend" || existing.Tool != "claude" || existing.Command != "claude" ||
+		existing.Env["API_ENV"] != "staging" || existing.ClaudeOptions.Model != "opus" {
+		t.Errorf("api not updated: %+v", existing)
+	}
+	sub := instances[2]
+	if sub.ParentSessionID != existing.ID || sub.GroupPath != "work/backend" {
+		t.Errorf("sub-session parent/group = %q/%q", sub.ParentSessionID, sub.GroupPath)
+	}
+	if g := tree.Groups["work/backend"]; g == nil || g.Name != "Backend" || len(g.Sessions) != 2 {
+		t.Errorf("work/backend group = %+v", g)
+	}
+
+	// Applying again changes nothing
+	plan, err = PlanWorkspace(w, instances, tree, false)
+	if err != nil {
+		t.Fatalf("PlanWorkspace: %v", err)
+	}
+	if plan.HasChanges() {
+		t.Errorf("second apply should be a no-op, got %+v", plan.Changes)
+	}
+
+	// Pruning drops what the workspace doesn't list
+	plan, err = PlanWorkspace(w, instances, tree, true)
+	if err != nil {
+		t.Fatalf("PlanWorkspace: %v", err)
+	}
+	instances, err = plan.Apply(instances, tree)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if len(instances) != 2 || tree.Groups["old"] != nil {
+		t.Errorf("prune left %d sessions, old group %v", len(instances), tree.Groups["old"])
+	}
+}
+
+func TestWorkspaceExportRoundTrip(t *testing.T) {
+	home := useConfigHome(t)
+	project := filepath.Join(home, "src", "api")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := NewInstanceWithGroupAndTool("api", project, "work", "claude")
+	parent.Command = "claude"
+	parent.ClaudeOptions = &ClaudeOptions{PermissionMode: "plan"}
+	child := NewInstanceWithGroup("api shell", project, "work")
+	child.SetParent(parent.ID)
+	child.Env = map[string]string{"PAGER": "cat"}
+	instances := []*Instance{parent, child}
+	tree := NewGroupTreeWithGroups(instances, []*GroupData{{Name: "Work", Path: "work", Expanded: false}})
+
+	var buf bytes.Buffer
+	if err := ExportWorkspace(instances, tree).Encode(&buf); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{`path = "~/src/api"`, `parent = "api"`, `name = "Work"`, "collapsed = true", `permission_mode = "plan"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("export missing %q:\n%s", want, out)
+		}
+	}
+
+	w := writeWorkspace(t, out)
+	plan, err := PlanWorkspace(w, instances, tree, true)
+	if err != nil {
+		t.Fatalf("PlanWorkspace: %v", err)
+	}
+	if plan.HasChanges() {
+		t.Errorf("exported workspace should apply as a no-op, got %+v", plan.Changes)
+	}
+}
+
+func TestWorkspaceValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		w    Workspace
+	}{
+		{"duplicate title", Workspace{Sessions: []WorkspaceSession{{Title: "a", Path: "/tmp"}, {Title: "a", Path: "/tmp"}}}},
+		{"missing parent", Workspace{Sessions: []WorkspaceSession{{Title: "a", Path: "/tmp", Parent: "b"}}}},
+		{"nested parent", Workspace{Sessions: []WorkspaceSession{
+			{Title: "a", Path: "/tmp"}, {Title: "b", Path: "/tmp", Parent: "a"}, {Title: "c", Path: "/tmp", Parent: "b"},
+		}}},
+		{"missing path", Workspace{Sessions: []WorkspaceSession{{Title: "a"}}}},
+	}
+	for _, tt := range tests {
+		if err := tt.w.Validate(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/asheshgoplani/agent-deck/internal/tmux"
+)
+
+// HasWorktree returns true if the session runs in a worktree agent-deck created
+func (i *Instance) HasWorktree() bool {
+	return i.WorktreePath != ""
+}
+
+// SetupWorktree creates a git worktree for the session's project on a new
+// branch (generated from the title when empty) and moves the session into
+// it, keeping the same subdirectory of the checkout. Call before Start.
+func (i *Instance) SetupWorktree(branch string) error {
+	if i.HasWorktree() {
+		return fmt.Errorf("session already has a worktree at %s", i.WorktreePath)
+	}
+
+	top, err := git.TopLevel(i.ProjectPath)
+	if err != nil {
+		return fmt.Errorf("%s is not inside a git repository", i.ProjectPath)
+	}
+	wt, err := git.CreateWorktree(i.ProjectPath, i.Title, branch)
+	if err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	// git reports resolved paths (macOS: /tmp -> /private/tmp)
+	projectPath := i.ProjectPath
+	if resolved, err := filepath.EvalSymlinks(projectPath); err == nil {
+		projectPath = resolved
+	}
+	rel, err := filepath.Rel(top, projectPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = "."
+	}
+	sessionPath := filepath.Join(wt.Path, rel)
+
+	// .mcp.json is usually untracked, so the checkout wouldn't have it
+	src := filepath.Join(i.ProjectPath, ".mcp.json")
+	dst := filepath.Join(sessionPath, ".mcp.json")
+	if _, err := os.Stat(src); err == nil {
+		if _, err := os.Stat(dst); os.IsNotExist(err) {
+			_ = copyFile(src, dst)
+		}
+	}
+
+	i.ProjectPath = sessionPath
+	i.WorktreePath = wt.Path
+	i.WorktreeBranch = wt.Branch
+	i.WorktreeRepo = wt.Repo
+	i.tmuxSession = tmux.NewSession(i.Title, i.ProjectPath)
+	return nil
+}
+
+// RemoveWorktree deletes the session's worktree. It refuses when the
+// worktree has uncommitted changes; the branch is kept.
+func (i *Instance) RemoveWorktree() error {
+	if !i.HasWorktree() {
+		return nil
+	}
+	if err := git.RemoveWorktree(i.WorktreeRepo, i.WorktreePath); err != nil {
+		return err
+	}
+	i.WorktreePath = ""
+	i.WorktreeBranch = ""
+	i.WorktreeRepo = ""
+	return nil
+}
+
+var claudeProjectDirPattern = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// claudeProjectDir returns the directory Claude keeps a project's
+// transcripts in. Claude replaces every non-alphanumeric character of the
+// resolved path with '-'.
+func claudeProjectDir(projectPath string) string {
+	if resolved, err := filepath.EvalSymlinks(projectPath); err == nil {
+		projectPath = resolved
+	}
+	return filepath.Join(GetClaudeConfigDir(), "projects", claudeProjectDirPattern.ReplaceAllString(projectPath, "-"))
+}
+
+// copyClaudeSession makes a Claude conversation resumable from another
+// directory. Claude only finds sessions of the current project, so a fork
+// into a worktree needs the parent's transcript there first.
+func copyClaudeSession(sessionID, fromPath, toPath string) error {
+	src := filepath.Join(claudeProjectDir(fromPath), sessionID+".jsonl")
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("session file not found: %s", src)
+	}
+
+	dstDir := claudeProjectDir(toPath)
+	if err := os.MkdirAll(dstDir, 0700); err != nil {
+		return err
+	}
+	return copyFile(src, filepath.Join(dstDir, sessionID+".jsonl"))
+}
@@ -0,0 +1,133 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// initGitRepo creates a repository with one commit and a subdirectory
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := filepath.Join(t.TempDir(), "webapp")
+	if err := os.MkdirAll(filepath.Join(repo, "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "api", "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	return repo
+}
+
+func TestInstanceSetupWorktree(t *testing.T) {
+	repo := initGitRepo(t)
+	// Untracked .mcp.json should follow the session into the worktree
+	if err := os.WriteFile(filepath.Join(repo, "api", ".mcp.json"), []byte(`{"mcpServers":{}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inst := NewInstanceWithTool("Auth Refactor", filepath.Join(repo, "api"), "claude")
+	if err := inst.SetupWorktree(""); err != nil {
+		t.Fatalf("SetupWorktree: %v", err)
+	}
+	defer func() { _ = inst.RemoveWorktree() }()
+
+	if inst.WorktreeBranch != "agent-deck/auth-refactor" {
+		t.Errorf("WorktreeBranch = %q", inst.WorktreeBranch)
+	}
+	if inst.ProjectPath != filepath.Join(inst.WorktreePath, "api") {
+		t.Errorf("ProjectPath = %q, want the api/ subdirectory of %q", inst.ProjectPath, inst.WorktreePath)
+	}
+	if _, err := os.Stat(filepath.Join(inst.ProjectPath, ".mcp.json")); err != nil {
+		t.Errorf(".mcp.json was not copied: %v", err)
+	}
+	if inst.GetTmuxSession() == nil || inst.GetTmuxSession().WorkDir != inst.ProjectPath {
+		t.Error("tmux session should start in the worktree")
+	}
+	if err := inst.SetupWorktree(""); err == nil {
+		t.Error("a second SetupWorktree should fail")
+	}
+
+	// Dirty worktrees are kept (the copied .mcp.json is untracked)
+	if err := inst.RemoveWorktree(); err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Fatalf("RemoveWorktree on a dirty worktree: %v", err)
+	}
+	if err := os.Remove(filepath.Join(inst.ProjectPath, ".mcp.json")); err != nil {
+		t.Fatal(err)
+	}
+	worktreePath := inst.WorktreePath
+	if err := inst.RemoveWorktree(); err != nil {
+		t.Fatalf("RemoveWorktree: %v", err)
+	}
+	if inst.HasWorktree() {
+		t.Error("worktree fields should be cleared")
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Errorf("worktree directory still exists: %v", err)
+	}
+}
+
+func TestInstanceSetupWorktree_NotARepo(t *testing.T) {
+	inst := NewInstance("plain", t.TempDir())
+	if err := inst.SetupWorktree(""); err == nil {
+		t.Error("expected an error outside a git repository")
+	}
+	if inst.HasWorktree() {
+		t.Error("failed setup should not record a worktree")
+	}
+}
+
+func TestCreateForkedInstanceWithWorktree(t *testing.T) {
+	repo := initGitRepo(t)
+	configDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", configDir)
+
+	const sessionID = "0f6d8c1e-8a3b-4d2e-9f1a-2b3c4d5e6f70"
+	parentDir := claudeProjectDir(repo)
+	if err := os.MkdirAll(parentDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(parentDir, sessionID+".jsonl"), []byte("{}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := NewInstanceWithTool("webapp", repo, "claude")
+	parent.ClaudeSessionID = sessionID
+	parent.ClaudeDetectedAt = time.Now()
+
+	forked, cmd, err := parent.CreateForkedInstanceWithOptions("webapp try-sqlite", "", ForkOptions{Worktree: true, Branch: "try-sqlite"})
+	if err != nil {
+		t.Fatalf("CreateForkedInstanceWithOptions: %v", err)
+	}
+	defer func() { _ = forked.RemoveWorktree() }()
+
+	if forked.WorktreeBranch != "try-sqlite" || forked.ProjectPath != forked.WorktreePath {
+		t.Errorf("fork should run at the worktree root on try-sqlite, got %q on %q", forked.ProjectPath, forked.WorktreeBranch)
+	}
+	if parent.ProjectPath != repo {
+		t.Error("the parent must stay in its checkout")
+	}
+	if !strings.HasPrefix(cmd, "cd "+forked.ProjectPath+" && ") {
+		t.Errorf("fork command should run in the worktree: %s", cmd)
+	}
+	if _, err := os.Stat(filepath.Join(claudeProjectDir(forked.ProjectPath), sessionID+".jsonl")); err != nil {
+		t.Errorf("parent conversation not copied for the fork: %v", err)
+	}
+}
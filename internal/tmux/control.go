@@ -0,0 +1,489 @@
+package tmux
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Control-mode status engine
+//
+// Instead of running `tmux list-sessions` every tick (and `has-session` /
+// `display-message` on cache misses), a single long-lived control-mode client
+// (`tmux -C`) subscribes to a format that expands to the activity timestamp of
+// every session on the server. tmux re-evaluates subscriptions once per second
+// and only reports them when the value changes, so an idle server produces no
+// traffic at all and 50+ sessions cost one process.
+//
+// The client also consumes tmux's events: %sessions-changed and
+// %window-close (or %unlinked-window-close) make it list the sessions again
+// through the same client, so a killed session disappears from the cache at
+// once instead of on the next subscription update, and %output reports
+// activity as it happens. tmux only sends %output for panes of the session a
+// control client is attached to, so other sessions are tracked through the
+// subscription alone.
+//
+// The client attaches to an existing session with ignore-size and read-only,
+// so it never resizes windows and can't send input. When that session goes
+// away the client is detached (or moved, see %session-changed) and the engine
+// reconnects to another one. Without a usable tmux (older than 3.2, or no
+// sessions yet) the engine stays disconnected and callers keep polling.
+
+const (
+	// activitySubscription names the refresh-client -B subscription
+	activitySubscription = "agentdeck-activity"
+
+	// Separators in the subscription value (ASCII unit/record separators,
+	// which can't appear in session names typed by users)
+	activityFieldSep  = "\x1f"
+	activityRecordSep = "\x1e"
+)
+
+// activityFormat expands to "name\x1factivity\x1e" for every session, where
+// activity is the window_activity of the session's current window (updated by
+// pane output, unlike session_activity which only tracks client input)
+var activityFormat = "#{S:#{session_name}" + activityFieldSep + "#{window_activity}" + activityRecordSep + "}"
+
+// listSessionsCommand lists every session in the same "name\x1factivity" form,
+// one per line, as command output of the control client
+var listSessionsCommand = fmt.Sprintf("list-sessions -F '#{session_name}%s#{window_activity}'\n", activityFieldSep)
+
+var (
+	controlReconnectBase = 1 * time.Second
+	controlReconnectMax  = 30 * time.Second
+	controlIdleRetry     = 5 * time.Second // Retry interval while tmux has no sessions
+
+	// controlResubscribeInterval is how often the subscription is repeated
+	// until tmux confirms it with a first notification
+	controlResubscribeInterval = 1500 * time.Millisecond
+)
+
+// controlCacheLive is set while a StatusEngine keeps the session cache current,
+// which makes RefreshSessionCache a no-op and the cache valid regardless of age
+var controlCacheLive atomic.Bool
+
+// ControlEvent is one notification line from a tmux control-mode client,
+// e.g. "%sessions-changed" or "%subscription-changed name $1 @2 0 %3 : value"
+type ControlEvent struct {
+	Name  string   // Notification name without the leading %, e.g. "window-close"
+	Args  []string // Space-separated arguments
+	Value string   // For subscription-changed: the subscription's value
+}
+
+// ParseControlLine parses a control-mode notification line. Returns false for
+// lines that are not notifications (command output inside %begin/%end).
+func ParseControlLine(line string) (ControlEvent, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "%") {
+		return ControlEvent{}, false
+	}
+
+	var ev ControlEvent
+	header := line[1:]
+	if strings.HasPrefix(header, "subscription-changed ") {
+		// The value may contain spaces; it follows the " : " separator
+		if i := strings.Index(header, " : "); i >= 0 {
+			ev.Value = header[i+3:]
+			header = header[:i]
+		}
+	}
+	if strings.HasPrefix(header, "output ") || strings.HasPrefix(header, "extended-output ") {
+		// %output %pane data - keep the data as a single argument
+		parts := strings.SplitN(header, " ", 3)
+		ev.Name = parts[0]
+		ev.Args = parts[1:]
+		return ev, true
+	}
+
+	fields := strings.Fields(header)
+	if len(fields) == 0 {
+		return ControlEvent{}, false
+	}
+	ev.Name = fields[0]
+	ev.Args = fields[1:]
+	return ev, true
+}
+
+// parseActivitySnapshot parses the activity subscription value into
+// session name -> window_activity
+func parseActivitySnapshot(value string) map[string]int64 {
+	snapshot := make(map[string]int64)
+	for _, record := range strings.Split(value, activityRecordSep) {
+		name, ts, ok := strings.Cut(record, activityFieldSep)
+		if !ok || name == "" {
+			continue
+		}
+		activity, _ := strconv.ParseInt(strings.TrimSpace(ts), 10, 64)
+		snapshot[name] = activity
+	}
+	return snapshot
+}
+
+// StatusEngine keeps the session cache (existence and activity) up to date from
+// a tmux control-mode client and reports activity changes as they happen
+type StatusEngine struct {
+	// onActivity is called with the session name and its new activity
+	// timestamp whenever a session's activity changes
+	onActivity func(sessionName string, activity int64)
+
+	activity map[string]int64 // Last snapshot, for change detection
+	attached string           // Session the client is attached to (receives %output)
+
+	cmd       *exec.Cmd
+	connected bool
+	mu        sync.Mutex
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewStatusEngine creates a status engine. onActivity may be nil.
+func NewStatusEngine(onActivity func(sessionName string, activity int64)) *StatusEngine {
+	return &StatusEngine{
+		onActivity: onActivity,
+		activity:   make(map[string]int64),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start runs the control-mode client, reconnecting with backoff, until Close
+// is called (blocking). Call this in a goroutine. Returns immediately if the
+// installed tmux doesn't support the features the engine needs.
+func (e *StatusEngine) Start() {
+	if !controlModeSupported() {
+		log.Printf("[tmux] Control mode status engine unavailable (needs tmux 3.2+), polling instead")
+		return
+	}
+
+	delay := controlReconnectBase
+	for {
+		target := controlAttachTarget()
+		if target == "" {
+			// Nothing to attach to yet; the first session will give us one
+			if !e.sleep(controlIdleRetry) {
+				return
+			}
+			continue
+		}
+
+		startedAt := time.Now()
+		e.setAttached(target)
+		err := e.run(target)
+		e.setConnected(false)
+
+		select {
+		case <-e.done:
+			return
+		default:
+		}
+
+		if time.Since(startedAt) > time.Minute {
+			delay = controlReconnectBase
+		}
+		if err != nil {
+			debugLog("control mode client for %s ended: %v (retry in %v)", target, err, delay)
+		}
+		if !e.sleep(delay) {
+			return
+		}
+		delay *= 2
+		if delay > controlReconnectMax {
+			delay = controlReconnectMax
+		}
+	}
+}
+
+// Close stops the engine and its control-mode client
+func (e *StatusEngine) Close() error {
+	e.closeOnce.Do(func() {
+		close(e.done)
+		e.mu.Lock()
+		if e.cmd != nil && e.cmd.Process != nil {
+			_ = e.cmd.Process.Kill()
+		}
+		e.mu.Unlock()
+		e.setConnected(false)
+	})
+	return nil
+}
+
+// Connected reports whether the engine is currently receiving events
+func (e *StatusEngine) Connected() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.connected
+}
+
+func (e *StatusEngine) setAttached(name string) {
+	e.mu.Lock()
+	e.attached = name
+	e.mu.Unlock()
+}
+
+func (e *StatusEngine) setConnected(connected bool) {
+	e.mu.Lock()
+	e.connected = connected
+	e.mu.Unlock()
+	controlCacheLive.Store(connected)
+}
+
+// sleep waits for d, returning false if the engine was closed meanwhile
+func (e *StatusEngine) sleep(d time.Duration) bool {
+	select {
+	case <-e.done:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// run attaches a control-mode client to target and processes its events until
+// it exits
+func (e *StatusEngine) run(target string) error {
+	cmd := exec.Command("tmux", "-C", "attach-session", "-t", "="+target, "-f", "ignore-size,read-only")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	select {
+	case <-e.done:
+		e.mu.Unlock()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil
+	default:
+	}
+	e.cmd = cmd
+	e.mu.Unlock()
+
+	defer func() {
+		stdin.Close()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		e.mu.Lock()
+		e.cmd = nil
+		e.mu.Unlock()
+	}()
+
+	// Commands are written both here and from the event loop
+	var writeMu sync.Mutex
+	send := func(command string) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, err := io.WriteString(stdin, command)
+		return err
+	}
+
+	subscribe := fmt.Sprintf("refresh-client -B '%s::%s'\n", activitySubscription, activityFormat)
+	if err := send(subscribe); err != nil {
+		return err
+	}
+
+	// tmux (seen with 3.3a) can drop a subscription made right after
+	// attaching, so repeat it until the first snapshot arrives
+	subscribed := make(chan struct{})
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		ticker := time.NewTicker(controlResubscribeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-subscribed:
+				return
+			case <-stopped:
+				return
+			case <-ticker.C:
+				if send(subscribe) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	relist := func() { _ = send(listSessionsCommand) }
+	return e.readEvents(stdout, relist, func() { once.Do(func() { close(subscribed) }) })
+}
+
+// readEvents processes control-mode output until the client exits. relist is
+// called when sessions or windows went away, to have the client list the
+// sessions again; onSnapshot after each activity snapshot.
+func (e *StatusEngine) readEvents(r io.Reader, relist func(), onSnapshot func()) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	inBlock := false
+	var block []string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Command output is wrapped in %begin ... %end (or %error). Only the
+		// output of list-sessions has records in it.
+		switch {
+		case strings.HasPrefix(line, "%begin "):
+			inBlock = true
+			block = block[:0]
+			continue
+		case strings.HasPrefix(line, "%end "):
+			inBlock = false
+			if len(block) > 0 {
+				e.applySnapshot(parseActivitySnapshot(strings.Join(block, activityRecordSep)))
+			}
+			continue
+		case strings.HasPrefix(line, "%error "):
+			inBlock = false
+			return fmt.Errorf("tmux rejected a control command")
+		case inBlock:
+			if strings.Contains(line, activityFieldSep) {
+				block = append(block, line)
+			}
+			continue
+		}
+
+		ev, ok := ParseControlLine(line)
+		if !ok {
+			continue
+		}
+		switch ev.Name {
+		case "subscription-changed":
+			if len(ev.Args) > 0 && ev.Args[0] == activitySubscription {
+				e.applySnapshot(parseActivitySnapshot(ev.Value))
+				onSnapshot()
+			}
+		case "output", "extended-output":
+			e.observeOutput()
+		case "sessions-changed", "window-close", "unlinked-window-close":
+			relist()
+		case "session-changed":
+			// %session-changed $id name: the client now shows another session
+			if len(ev.Args) > 1 {
+				e.setAttached(strings.Join(ev.Args[1:], " "))
+			}
+		case "exit":
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// observeOutput records pane output in the attached session as activity,
+// ahead of the subscription's next update
+func (e *StatusEngine) observeOutput() {
+	now := time.Now().Unix()
+
+	e.mu.Lock()
+	name := e.attached
+	old, known := e.activity[name]
+	changed := known && old != now
+	if changed {
+		e.activity[name] = now
+	}
+	e.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	setSessionActivityInCache(name, now)
+	if e.onActivity != nil {
+		e.onActivity(name, now)
+	}
+}
+
+// applySnapshot replaces the session cache and reports sessions whose activity changed
+func (e *StatusEngine) applySnapshot(snapshot map[string]int64) {
+	select {
+	case <-e.done:
+		return
+	default:
+	}
+
+	e.mu.Lock()
+	previous := e.activity
+	for name, activity := range snapshot {
+		// %output may have seen activity the snapshot doesn't have yet
+		if old := previous[name]; old > activity {
+			snapshot[name] = old
+		}
+	}
+	replaceSessionCache(snapshot)
+	e.activity = snapshot
+	e.connected = true
+	e.mu.Unlock()
+	controlCacheLive.Store(true)
+
+	if e.onActivity == nil {
+		return
+	}
+	for name, activity := range snapshot {
+		if old, known := previous[name]; known && old != activity {
+			e.onActivity(name, activity)
+		}
+	}
+}
+
+// controlAttachTarget picks a session for the control client to attach to,
+// preferring agent-deck's own sessions. Returns "" if tmux has no sessions.
+func controlAttachTarget() string {
+	output, err := exec.Command("tmux", "list-sessions", "-F", "#{session_name}").Output()
+	if err != nil {
+		return ""
+	}
+	names := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for _, name := range names {
+		if strings.HasPrefix(name, SessionPrefix) {
+			return name
+		}
+	}
+	return names[0]
+}
+
+// controlModeSupported reports whether tmux supports attach flags and
+// subscriptions (3.2+)
+func controlModeSupported() bool {
+	output, err := exec.Command("tmux", "-V").Output()
+	if err != nil {
+		return false
+	}
+	major, minor, ok := parseTmuxVersion(string(output))
+	return ok && (major > 3 || (major == 3 && minor >= 2))
+}
+
+// parseTmuxVersion parses `tmux -V` output such as "tmux 3.3a" or "tmux next-3.4"
+func parseTmuxVersion(output string) (major, minor int, ok bool) {
+	version := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(output), "tmux"))
+	version = strings.TrimPrefix(version, "next-")
+	majorStr, rest, found := strings.Cut(version, ".")
+	if !found {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(majorStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	end := 0
+	for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+		end++
+	}
+	minor, err = strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
@@ -0,0 +1,281 @@
+package tmux
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseControlLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		ok    bool
+		name  string
+		args  []string
+		value string
+	}{
+		{line: "%sessions-changed", ok: true, name: "sessions-changed", args: []string{}},
+		{line: "%window-close @3", ok: true, name: "window-close", args: []string{"@3"}},
+		{line: "%session-changed $0 agentdeck_a", ok: true, name: "session-changed", args: []string{"$0", "agentdeck_a"}},
+		{line: "%output %1 hello world\\015\\012", ok: true, name: "output", args: []string{"%1", "hello world\\015\\012"}},
+		{
+			line:  "%subscription-changed act $0 - - - : a b : c",
+			ok:    true,
+			name:  "subscription-changed",
+			args:  []string{"act", "$0", "-", "-", "-"},
+			value: "a b : c",
+		},
+		{line: "some command output", ok: false},
+		{line: "%", ok: false},
+	}
+
+	for _, tt := range tests {
+		ev, ok := ParseControlLine(tt.line)
+		assert.Equal(t, tt.ok, ok, tt.line)
+		if !tt.ok {
+			continue
+		}
+		assert.Equal(t, tt.name, ev.Name, tt.line)
+		assert.Equal(t, tt.args, ev.Args, tt.line)
+		assert.Equal(t, tt.value, ev.Value, tt.line)
+	}
+}
+
+func TestParseActivitySnapshot(t *testing.T) {
+	value := "agentdeck_a\x1f1700000000\x1emy session\x1f1700000005\x1e"
+	assert.Equal(t, map[string]int64{
+		"agentdeck_a": 1700000000,
+		"my session":  1700000005,
+	}, parseActivitySnapshot(value))
+
+	assert.Empty(t, parseActivitySnapshot(""))
+}
+
+func TestParseTmuxVersion(t *testing.T) {
+	tests := []struct {
+		output       string
+		major, minor int
+		ok           bool
+	}{
+		{"tmux 3.3a\n", 3, 3, true},
+		{"tmux 3.2", 3, 2, true},
+		{"tmux next-3.5", 3, 5, true},
+		{"tmux 2.9a", 2, 9, true},
+		{"tmux master", 0, 0, false},
+	}
+	for _, tt := range tests {
+		major, minor, ok := parseTmuxVersion(tt.output)
+		assert.Equal(t, tt.ok, ok, tt.output)
+		assert.Equal(t, tt.major, major, tt.output)
+		assert.Equal(t, tt.minor, minor, tt.output)
+	}
+}
+
+func TestStatusEngine_ApplySnapshot(t *testing.T) {
+	t.Cleanup(func() {
+		controlCacheLive.Store(false)
+		resetSessionCache()
+	})
+
+	type change struct {
+		name     string
+		activity int64
+	}
+	var changes []change
+	engine := NewStatusEngine(func(name string, activity int64) {
+		changes = append(changes, change{name, activity})
+	})
+
+	engine.applySnapshot(map[string]int64{"agentdeck_a": 100, "agentdeck_b": 200})
+	assert.Empty(t, changes, "the first snapshot is a baseline")
+	assert.True(t, engine.Connected())
+
+	engine.applySnapshot(map[string]int64{"agentdeck_a": 101, "agentdeck_b": 200, "agentdeck_c": 300})
+	assert.Equal(t, []change{{"agentdeck_a", 101}}, changes)
+
+	// The engine keeps the cache valid without polling
+	sessionCacheMu.Lock()
+	sessionCacheTime = time.Now().Add(-time.Minute)
+	sessionCacheMu.Unlock()
+
+	exists, valid := sessionExistsFromCache("agentdeck_c")
+	assert.True(t, valid)
+	assert.True(t, exists)
+	activity, valid := sessionActivityFromCache("agentdeck_a")
+	assert.True(t, valid)
+	assert.Equal(t, int64(101), activity)
+
+	_ = engine.Close()
+	_, valid = sessionExistsFromCache("agentdeck_c")
+	assert.False(t, valid, "stale cache is invalid again once the engine stops")
+}
+
+func TestStatusEngine_ReadEvents(t *testing.T) {
+	t.Cleanup(func() {
+		controlCacheLive.Store(false)
+		resetSessionCache()
+	})
+
+	var changes []string
+	engine := NewStatusEngine(func(name string, activity int64) {
+		changes = append(changes, name)
+	})
+	engine.setAttached("agentdeck_a")
+
+	relists := 0
+	snapshots := 0
+	events := strings.Join([]string{
+		"%begin 1 1 0",
+		"%end 1 1 0",
+		"%subscription-changed " + activitySubscription + " $1 @1 0 %1 : agentdeck_a\x1f100\x1eagentdeck_b\x1f200\x1e",
+		"%output %1 hello",
+		"%sessions-changed",
+		"%begin 2 2 0",
+		"agentdeck_a\x1f100",
+		"%end 2 2 0",
+		"%unlinked-window-close @2",
+		"%session-changed $2 agentdeck_c",
+		"%exit",
+	}, "\n")
+
+	err := engine.readEvents(strings.NewReader(events), func() { relists++ }, func() { snapshots++ })
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, snapshots)
+	assert.Equal(t, 2, relists, "removed sessions and windows should list the sessions again")
+	assert.Equal(t, []string{"agentdeck_a"}, changes, "output in the attached session is activity")
+
+	// The listing replaced the cache: agentdeck_b is gone
+	exists, valid := sessionExistsFromCache("agentdeck_b")
+	assert.True(t, valid)
+	assert.False(t, exists)
+
+	engine.mu.Lock()
+	assert.Equal(t, "agentdeck_c", engine.attached)
+	engine.mu.Unlock()
+}
+
+func TestSession_DetectReusedWithoutActivity(t *testing.T) {
+	controlCacheLive.Store(true)
+	t.Cleanup(func() { controlCacheLive.Store(false) })
+
+	s := NewSession("reuse", "/tmp")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ensureStateTrackerLocked()
+	s.stateTracker.lastActivityTimestamp = 100
+	s.stateTracker.lastDetected = StatePermission
+	s.stateTracker.lastCaptureTime = time.Unix(102, 0)
+
+	// Captured after the last activity: no capture (which would fail, there
+	// is no such tmux session)
+	state, err := s.detectSinceLocked(100)
+	require.NoError(t, err)
+	assert.Equal(t, StatePermission, state)
+
+	// Captured within the second of the last activity: capture again
+	s.stateTracker.lastCaptureTime = time.Unix(100, 500)
+	_, err = s.detectSinceLocked(100)
+	assert.Error(t, err)
+}
+
+func TestReplaceSessionCache_KeepsNewlyRegistered(t *testing.T) {
+	t.Cleanup(func() { resetSessionCache() })
+
+	registerSessionInCache("agentdeck_new")
+	// A snapshot taken before the session was created must not drop it
+	replaceSessionCache(map[string]int64{"agentdeck_old": 1})
+	exists, valid := sessionExistsFromCache("agentdeck_new")
+	assert.True(t, valid)
+	assert.True(t, exists)
+
+	forgetSessionInCache("agentdeck_new")
+	exists, _ = sessionExistsFromCache("agentdeck_new")
+	assert.False(t, exists)
+}
+
+func TestSession_ObserveActivity(t *testing.T) {
+	s := NewSession("observe", "/tmp")
+
+	// Uninitialized trackers are left to GetStatus
+	s.ObserveActivity(100)
+	assert.Nil(t, s.stateTracker)
+
+	s.mu.Lock()
+	s.ensureStateTrackerLocked()
+	s.stateTracker.lastActivityTimestamp = 100
+	s.stateTracker.acknowledged = true
+	s.mu.Unlock()
+
+	s.ObserveActivity(101) // Starts the spike window
+	assert.Equal(t, 1, s.stateTracker.activityChangeCount)
+	assert.True(t, s.stateTracker.acknowledged, "a single change is a spike")
+
+	s.ObserveActivity(102) // Second change within 1s: sustained
+	assert.False(t, s.stateTracker.acknowledged)
+	assert.Equal(t, "active", s.lastStableStatus)
+	assert.Equal(t, int64(102), s.stateTracker.lastActivityTimestamp)
+}
+
+func TestStatusEngine_Integration(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	if !controlModeSupported() {
+		t.Skip("tmux 3.2+ required for control mode subscriptions")
+	}
+	t.Cleanup(func() { resetSessionCache() })
+
+	// Other tests create and kill sessions, which can detach the client;
+	// reconnect quickly instead of backing off for seconds
+	base, max := controlReconnectBase, controlReconnectMax
+	controlReconnectBase, controlReconnectMax = 50*time.Millisecond, 200*time.Millisecond
+	t.Cleanup(func() { controlReconnectBase, controlReconnectMax = base, max })
+
+	name := fmt.Sprintf("%sctltest_%d", SessionPrefix, time.Now().UnixNano())
+	require.NoError(t, exec.Command("tmux", "new-session", "-d", "-s", name, "sh").Run())
+	t.Cleanup(func() { _ = exec.Command("tmux", "kill-session", "-t", name).Run() })
+
+	activity := make(chan int64, 10)
+	engine := NewStatusEngine(func(sessionName string, ts int64) {
+		if sessionName == name {
+			activity <- ts
+		}
+	})
+	go engine.Start()
+	t.Cleanup(func() { _ = engine.Close() })
+
+	require.Eventually(t, func() bool {
+		exists, valid := sessionExistsFromCache(name)
+		return engine.Connected() && valid && exists
+	}, 10*time.Second, 50*time.Millisecond, "engine should report the session")
+
+	// window_activity has one-second resolution; keep producing output until
+	// the timestamp moves
+	deadline := time.After(10 * time.Second)
+	for {
+		_ = exec.Command("tmux", "send-keys", "-t", name, "echo hi", "Enter").Run()
+		select {
+		case ts := <-activity:
+			assert.Greater(t, ts, int64(0))
+			return
+		case <-time.After(500 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no activity event from control mode")
+		}
+	}
+}
+
+// resetSessionCache clears the package-level session cache between tests
+func resetSessionCache() {
+	sessionCacheMu.Lock()
+	defer sessionCacheMu.Unlock()
+	sessionCacheData = nil
+	sessionCacheRegistered = nil
+	sessionCacheTime = time.Time{}
+}
@@ -11,6 +11,10 @@ const (
 	StateIdle    SessionState = "idle"    // No activity, waiting for user
 	StateBusy    SessionState = "busy"    // Actively working (output changing)
 	StateWaiting SessionState = "waiting" // Showing a prompt, needs input
+
+	StatePermission SessionState = "permission" // Asking to approve an action
+	StateError      SessionState = "error"      // Reported an error
+	StateUnknown    SessionState = ""           // Nothing recognized (see StatusDetector)
 )
 
 // =============================================================================
@@ -135,31 +139,7 @@ func (d *PromptDetector) hasClaudePrompt(content string) bool {
 	// ═══════════════════════════════════════════════════════════════════════
 	// WAITING indicators - Permission prompts (normal mode)
 	// ═══════════════════════════════════════════════════════════════════════
-	permissionPrompts := []string{
-		// From Claude Squad (most reliable indicator)
-		"No, and tell Claude what to do differently",
-		// Permission dialog options
-		"Yes, allow once",
-		"Yes, allow always",
-		"Allow once",
-		"Allow always",
-		// Box-drawing permission dialogs
-		"│ Do you want",
-		"│ Would you like",
-		"│ Allow",
-		// Selection indicators
-		"❯ Yes",
-		"❯ No",
-		"❯ Allow",
-		// Trust prompt on startup
-		"Do you trust the files in this folder?",
-		// MCP permission prompts
-		"Allow this MCP server",
-		// Tool permission prompts
-		"Run this command?",
-		"Execute this?",
-	}
-	for _, prompt := range permissionPrompts {
+	for _, prompt := range claudePermissionPrompts {
 		if strings.Contains(content, prompt) {
 			return true
 		}
@@ -257,6 +237,33 @@ func (d *PromptDetector) hasClaudePrompt(content string) bool {
 	return false
 }
 
+// claudePermissionPrompts are shown by Claude Code when it asks to approve an
+// action (normal mode)
+var claudePermissionPrompts = []string{
+	// From Claude Squad (most reliable indicator)
+	"No, and tell Claude what to do differently",
+	// Permission dialog options
+	"Yes, allow once",
+	"Yes, allow always",
+	"Allow once",
+	"Allow always",
+	// Box-drawing permission dialogs
+	"│ Do you want",
+	"│ Would you like",
+	"│ Allow",
+	// Selection indicators
+	"❯ Yes",
+	"❯ No",
+	"❯ Allow",
+	// Trust prompt on startup
+	"Do you trust the files in this folder?",
+	// MCP permission prompts
+	"Allow this MCP server",
+	// Tool permission prompts
+	"Run this command?",
+	"Execute this?",
+}
+
 // hasLineEndingWith checks if any recent line ends with the given suffix
 func (d *PromptDetector) hasLineEndingWith(content string, suffix string) bool {
 	lines := strings.Split(content, "\n")
@@ -0,0 +1,171 @@
+package tmux
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// StatusDetector reads a tool's state from its visible pane content.
+//
+// GetStatus consults the session's detector whenever the pane may have changed:
+// StateBusy marks the session active, StateWaiting and StatePermission end the
+// activity cooldown right away, StateIdle marks it idle, StateError marks it
+// errored until the pane changes again, and StateUnknown leaves the decision
+// to activity tracking.
+type StatusDetector interface {
+	Detect(content string) SessionState
+}
+
+// defaultDetectLines is how many trailing non-empty lines detectors look at
+const defaultDetectLines = 10
+
+// BuiltinDetector returns the built-in detector for a tool ("claude", "gemini",
+// "opencode", "codex"). Any other name gets the generic detector used for shells.
+func BuiltinDetector(tool string) StatusDetector {
+	return &builtinDetector{tool: strings.ToLower(tool)}
+}
+
+// builtinDetector wraps the hard-coded heuristics. Input prompts aren't
+// reported: the per-tool prompt patterns (e.g. OpenCode's always-visible mode
+// indicators) match too often to override activity tracking.
+type builtinDetector struct {
+	tool string
+}
+
+func (d *builtinDetector) Detect(content string) SessionState {
+	if busyIndicatorReason(content) != "" {
+		return StateBusy
+	}
+
+	var prompts []string
+	switch d.tool {
+	case "claude":
+		prompts = claudePermissionPrompts
+	case "gemini":
+		prompts = []string{"Yes, allow once"}
+	}
+	if len(prompts) > 0 {
+		recent := strings.Join(lastNonEmptyLines(content, 15), "\n")
+		for _, prompt := range prompts {
+			if strings.Contains(recent, prompt) {
+				return StatePermission
+			}
+		}
+	}
+	return StateUnknown
+}
+
+// RegexDetectorConfig configures a RegexDetector. Patterns are regular
+// expressions (RE2 syntax) matched against each of the last LastLines
+// non-empty lines with ANSI codes and trailing whitespace stripped, so ^ and $
+// anchor to a line.
+type RegexDetectorConfig struct {
+	Busy       []string
+	Waiting    []string
+	Permission []string
+	Error      []string
+
+	// LastLines limits matching to the bottom of the pane (default 10)
+	LastLines int
+
+	// Fallback decides when no pattern matches (nil: StateUnknown)
+	Fallback StatusDetector
+}
+
+// RegexDetector detects states from configured patterns. States are checked
+// in the order permission, busy, error, waiting, so a permission dialog wins
+// over a spinner and a spinner wins over an earlier error message.
+type RegexDetector struct {
+	busy       []*regexp.Regexp
+	waiting    []*regexp.Regexp
+	permission []*regexp.Regexp
+	errors     []*regexp.Regexp
+	lastLines  int
+	fallback   StatusDetector
+}
+
+// NewRegexDetector compiles a RegexDetector, reporting the first invalid pattern
+func NewRegexDetector(cfg RegexDetectorConfig) (*RegexDetector, error) {
+	d := &RegexDetector{
+		lastLines: cfg.LastLines,
+		fallback:  cfg.Fallback,
+	}
+	if d.lastLines <= 0 {
+		d.lastLines = defaultDetectLines
+	}
+
+	compile := func(state SessionState, patterns []string) ([]*regexp.Regexp, error) {
+		compiled := make([]*regexp.Regexp, 0, len(patterns))
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s pattern %q: %w", state, pattern, err)
+			}
+			compiled = append(compiled, re)
+		}
+		return compiled, nil
+	}
+
+	var err error
+	if d.busy, err = compile(StateBusy, cfg.Busy); err != nil {
+		return nil, err
+	}
+	if d.waiting, err = compile(StateWaiting, cfg.Waiting); err != nil {
+		return nil, err
+	}
+	if d.permission, err = compile(StatePermission, cfg.Permission); err != nil {
+		return nil, err
+	}
+	if d.errors, err = compile(StateError, cfg.Error); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Detect implements StatusDetector
+func (d *RegexDetector) Detect(content string) SessionState {
+	lines := lastNonEmptyLines(StripANSI(content), d.lastLines)
+
+	checks := []struct {
+		state    SessionState
+		patterns []*regexp.Regexp
+	}{
+		{StatePermission, d.permission},
+		{StateBusy, d.busy},
+		{StateError, d.errors},
+		{StateWaiting, d.waiting},
+	}
+	for _, check := range checks {
+		for _, re := range check.patterns {
+			for _, line := range lines {
+				if re.MatchString(line) {
+					return check.state
+				}
+			}
+		}
+	}
+
+	if d.fallback != nil {
+		return d.fallback.Detect(content)
+	}
+	return StateUnknown
+}
+
+// lastNonEmptyLines returns up to n trailing lines of content, skipping blank
+// lines (tmux pads the captured pane with them)
+func lastNonEmptyLines(content string, n int) []string {
+	lines := strings.Split(content, "\n")
+	var result []string
+	for i := len(lines) - 1; i >= 0 && len(result) < n; i-- {
+		line := strings.TrimRight(lines[i], " \t\r")
+		if strings.TrimSpace(line) != "" {
+			result = append(result, line)
+		}
+	}
+	// Restore top-to-bottom order
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
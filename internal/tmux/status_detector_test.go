@@ -0,0 +1,116 @@
+package tmux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinDetector(t *testing.T) {
+	tests := []struct {
+		tool    string
+		content string
+		want    SessionState
+	}{
+		{"claude", "Working on it\n✻ Thinking… (esc to interrupt)\n", StateBusy},
+		{"shell", "$ make\n⠋ compiling\n", StateBusy},
+		{"claude", "│ Do you want to make this edit?\n❯ Yes\n  No\n\n\n", StatePermission},
+		{"gemini", "Shell command\n● Yes, allow once\n", StatePermission},
+		{"shell", "Yes, allow once\n$ ", StateUnknown}, // Only tools that show such dialogs
+		{"claude", "Done.\n>\n", StateUnknown},         // Prompts are left to activity tracking
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, BuiltinDetector(tt.tool).Detect(tt.content), "%s: %q", tt.tool, tt.content)
+	}
+}
+
+func TestRegexDetector(t *testing.T) {
+	d, err := NewRegexDetector(RegexDetectorConfig{
+		Busy:       []string{`^\s*⏳ `},
+		Waiting:    []string{`^agent>$`},
+		Permission: []string{`Allow\? \[y/n\]`},
+		Error:      []string{`^Error:`},
+		LastLines:  3,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		content string
+		want    SessionState
+	}{
+		{"busy", "output\n  ⏳ running tests\n", StateBusy},
+		{"waiting", "All done\nagent> \n\n\n", StateWaiting},
+		{"permission", "rm -rf build\nAllow? [y/n]\n", StatePermission},
+		{"error", "Error: quota exceeded\nagent> ", StateError},
+		{"anchored to the line", "log: agent> done\n", StateUnknown},
+		{"ansi stripped", "\x1b[31mError:\x1b[0m boom\n", StateError},
+		{"permission wins over busy", "⏳ editing\nAllow? [y/n]\n", StatePermission},
+		{"busy wins over error", "Error: retrying\n⏳ retrying\n", StateBusy},
+		{"outside last lines", "Error: old\na\nb\nc\n", StateUnknown},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, d.Detect(tt.content), tt.name)
+	}
+}
+
+func TestRegexDetector_Fallback(t *testing.T) {
+	d, err := NewRegexDetector(RegexDetectorConfig{
+		Error:    []string{`^Error:`},
+		Fallback: BuiltinDetector("shell"),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, StateBusy, d.Detect("⠋ working\n"), "built-in heuristics still apply")
+	assert.Equal(t, StateUnknown, d.Detect("$ "))
+}
+
+func TestNewRegexDetector_InvalidPattern(t *testing.T) {
+	_, err := NewRegexDetector(RegexDetectorConfig{Waiting: []string{`(unclosed`}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "waiting")
+}
+
+func TestSession_ApplyDetectedState(t *testing.T) {
+	s := NewSession("detect", "/tmp")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.applyDetectedStateLocked(StateUnknown, "detect")
+	assert.False(t, ok)
+	assert.Nil(t, s.stateTracker, "unknown leaves initialization to activity tracking")
+
+	status, ok := s.applyDetectedStateLocked(StateBusy, "detect")
+	assert.True(t, ok)
+	assert.Equal(t, "active", status)
+
+	// A prompt ends the cooldown immediately instead of staying green
+	status, _ = s.applyDetectedStateLocked(StateWaiting, "detect")
+	assert.Equal(t, "waiting", status)
+
+	s.stateTracker.acknowledged = true
+	status, _ = s.applyDetectedStateLocked(StateWaiting, "detect")
+	assert.Equal(t, "idle", status)
+	status, _ = s.applyDetectedStateLocked(StatePermission, "detect")
+	assert.Equal(t, "approval", status, "a permission prompt needs attention even if seen")
+
+	status, _ = s.applyDetectedStateLocked(StateError, "detect")
+	assert.Equal(t, "error", status)
+}
+
+func TestSession_StatusDetector(t *testing.T) {
+	s := NewSession("detector", "/tmp")
+
+	s.mu.Lock()
+	s.detectedTool = "claude"
+	assert.Equal(t, BuiltinDetector("claude"), s.statusDetectorLocked())
+	s.mu.Unlock()
+
+	custom, err := NewRegexDetector(RegexDetectorConfig{Busy: []string{"x"}})
+	require.NoError(t, err)
+	s.SetStatusDetector(custom)
+	s.mu.Lock()
+	assert.Same(t, custom, s.statusDetectorLocked())
+	s.mu.Unlock()
+}
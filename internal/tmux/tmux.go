@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -33,12 +34,25 @@ var (
 	sessionCacheMu   sync.RWMutex
 	sessionCacheData map[string]int64 // session_name -> activity_timestamp (0 if not in cache)
 	sessionCacheTime time.Time
+
+	// sessionCacheRegistered holds sessions added by registerSessionInCache, so a
+	// snapshot taken just before they were created doesn't drop them again
+	sessionCacheRegistered map[string]time.Time
 )
 
+// registeredSessionGrace is how long a newly registered session survives
+// snapshots that don't include it yet
+const registeredSessionGrace = 3 * time.Second
+
 // RefreshSessionCache updates the cache of existing tmux sessions and their activity
 // Call this ONCE per tick, then use Session.Exists() and Session.GetWindowActivity()
 // which read from cache. This reduces 30+ subprocess spawns to just 1 per tick cycle.
 func RefreshSessionCache() {
+	// A StatusEngine keeps the cache current from tmux events; nothing to poll
+	if controlCacheLive.Load() {
+		return
+	}
+
 	// Get both session name AND activity timestamp in single call
 	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}\t#{session_activity}")
 	output, err := cmd.Output()
@@ -66,10 +80,57 @@ func RefreshSessionCache() {
 		newCache[name] = activity
 	}
 
+	replaceSessionCache(newCache)
+}
+
+// replaceSessionCache installs a fresh session -> activity snapshot, keeping
+// sessions registered moments ago that the snapshot doesn't know about yet
+func replaceSessionCache(snapshot map[string]int64) {
 	sessionCacheMu.Lock()
-	sessionCacheData = newCache
+	defer sessionCacheMu.Unlock()
+
+	if snapshot == nil {
+		snapshot = make(map[string]int64)
+	}
+	for name, registeredAt := range sessionCacheRegistered {
+		if _, ok := snapshot[name]; ok || time.Since(registeredAt) > registeredSessionGrace {
+			delete(sessionCacheRegistered, name)
+			continue
+		}
+		snapshot[name] = sessionCacheData[name]
+	}
+	sessionCacheData = snapshot
 	sessionCacheTime = time.Now()
-	sessionCacheMu.Unlock()
+}
+
+// setSessionActivityInCache records new activity for a session in the cache
+func setSessionActivityInCache(name string, activity int64) {
+	sessionCacheMu.Lock()
+	defer sessionCacheMu.Unlock()
+
+	if _, ok := sessionCacheData[name]; ok {
+		sessionCacheData[name] = activity
+	}
+}
+
+// forgetSessionInCache removes a killed session from the cache
+func forgetSessionInCache(name string) {
+	sessionCacheMu.Lock()
+	defer sessionCacheMu.Unlock()
+
+	delete(sessionCacheData, name)
+	delete(sessionCacheRegistered, name)
+}
+
+// sessionCacheValidLocked reports whether cached data can be trusted.
+// MUST be called with sessionCacheMu held.
+func sessionCacheValidLocked() bool {
+	if sessionCacheData == nil {
+		return false
+	}
+	// Cache is valid for 2 seconds (4 ticks at 500ms), or as long as a
+	// StatusEngine is keeping it current
+	return controlCacheLive.Load() || time.Since(sessionCacheTime) <= 2*time.Second
 }
 
 // RefreshExistingSessions is an alias for RefreshSessionCache for backwards compatibility
@@ -83,8 +144,7 @@ func sessionExistsFromCache(name string) (bool, bool) {
 	sessionCacheMu.RLock()
 	defer sessionCacheMu.RUnlock()
 
-	// Cache is valid for 2 seconds (4 ticks at 500ms)
-	if sessionCacheData == nil || time.Since(sessionCacheTime) > 2*time.Second {
+	if !sessionCacheValidLocked() {
 		return false, false // Cache invalid
 	}
 
@@ -106,6 +166,11 @@ func registerSessionInCache(name string) {
 
 	// Add session with current time as activity
 	sessionCacheData[name] = time.Now().Unix()
+
+	if sessionCacheRegistered == nil {
+		sessionCacheRegistered = make(map[string]time.Time)
+	}
+	sessionCacheRegistered[name] = time.Now()
 }
 
 // sessionActivityFromCache gets session activity timestamp from cache
@@ -114,8 +179,7 @@ func sessionActivityFromCache(name string) (int64, bool) {
 	sessionCacheMu.RLock()
 	defer sessionCacheMu.RUnlock()
 
-	// Cache is valid for 2 seconds (4 ticks at 500ms)
-	if sessionCacheData == nil || time.Since(sessionCacheTime) > 2*time.Second {
+	if !sessionCacheValidLocked() {
 		return 0, false // Cache invalid
 	}
 
@@ -329,6 +393,11 @@ type StateTracker struct {
 	// Non-blocking spike detection: track changes across tick cycles
 	activityCheckStart  time.Time // When we started tracking for sustained activity
 	activityChangeCount int       // How many timestamp changes seen in current window
+
+	// What the status detector read from the last capture, reused while a
+	// StatusEngine reports no activity since
+	lastDetected    SessionState
+	lastCaptureTime time.Time
 }
 
 // acknowledgeGracePeriod is how long after user detaches before content changes
@@ -352,6 +421,10 @@ type Session struct {
 	Command     string
 	Created     time.Time
 
+	// Env holds environment variables set for the session when it starts
+	// (tmux 3.0+; panes respawned later inherit them)
+	Env map[string]string
+
 	// mu protects all mutable fields below from concurrent access
 	mu sync.Mutex
 
@@ -364,6 +437,9 @@ type Session struct {
 	toolDetectedAt   time.Time
 	toolDetectExpiry time.Duration // How long before re-detecting (default 30s)
 
+	// Status detector set by the caller (nil: built-in detector for detectedTool)
+	detector StatusDetector
+
 	// Simple state tracking (hash-based)
 	stateTracker *StateTracker
 
@@ -384,6 +460,79 @@ func (s *Session) ensureStateTrackerLocked() {
 	}
 }
 
+// SetStatusDetector sets the detector GetStatus uses to read the tool's state
+// from the pane. nil restores the built-in detector for the detected tool.
+func (s *Session) SetStatusDetector(d StatusDetector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.detector = d
+}
+
+// statusDetectorLocked returns the detector to use for this session.
+// MUST be called with mu held.
+func (s *Session) statusDetectorLocked() StatusDetector {
+	if s.detector != nil {
+		return s.detector
+	}
+	return BuiltinDetector(s.detectedTool)
+}
+
+// applyDetectedStateLocked turns a detector's verdict into a status. Returns
+// false for StateUnknown, leaving the decision to activity tracking.
+// MUST be called with mu held.
+func (s *Session) applyDetectedStateLocked(state SessionState, shortName string) (string, bool) {
+	switch state {
+	case StateBusy, StateError, StateWaiting, StatePermission, StateIdle:
+	default:
+		return "", false
+	}
+
+	s.ensureStateTrackerLocked()
+	switch state {
+	case StateBusy:
+		s.stateTracker.lastChangeTime = time.Now()
+		s.stateTracker.acknowledged = false
+		s.lastStableStatus = "active"
+	case StateError:
+		s.lastStableStatus = "error"
+	default:
+		// The tool is asking for input (or done), so there's no need to wait
+		// out the activity cooldown or a pending spike window
+		s.stateTracker.lastChangeTime = time.Now().Add(-activityCooldown)
+		s.stateTracker.activityCheckStart = time.Time{}
+		s.stateTracker.activityChangeCount = 0
+		switch {
+		case state == StatePermission:
+			s.lastStableStatus = "approval"
+		case state == StateIdle, state == StateWaiting && s.stateTracker.acknowledged:
+			s.lastStableStatus = "idle"
+		default:
+			s.lastStableStatus = "waiting"
+		}
+	}
+	debugLog("%s: DETECTED %s → %s", shortName, state, s.lastStableStatus)
+	return s.lastStableStatus, true
+}
+
+// isStickyStatus reports whether a detected status holds until the pane changes
+// (rather than decaying to waiting/idle once the activity cooldown expires)
+func isStickyStatus(status string) bool {
+	return status == "error" || status == "approval"
+}
+
+// DetectState captures the pane and returns what the session's status
+// detector reads from it
+func (s *Session) DetectState() (SessionState, error) {
+	content, err := s.CapturePane()
+	if err != nil {
+		return StateUnknown, err
+	}
+	s.mu.Lock()
+	detector := s.statusDetectorLocked()
+	s.mu.Unlock()
+	return detector.Detect(content), nil
+}
+
 // LogFile returns the path to this session's pipe-pane log file
 // Logs are stored in ~/.agent-deck/logs/<session-name>.log
 func (s *Session) LogFile() string {
@@ -465,7 +614,7 @@ func ReconnectSessionWithStatus(tmuxName, displayName, workDir, command string,
 		}
 		sess.lastStableStatus = "idle"
 
-	case "waiting", "active":
+	case "waiting", "active", "approval":
 		// Session needs attention - restore as YELLOW
 		// Active sessions will show green when content changes
 		sess.stateTracker = &StateTracker{
@@ -531,6 +680,16 @@ func sanitizeName(name string) string {
 	return re.ReplaceAllString(name, "-")
 }
 
+// sortedKeys returns the keys of an environment map in a stable order
+func sortedKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Start creates and starts a tmux session
 func (s *Session) Start(command string) error {
 	s.Command = command
@@ -549,7 +708,11 @@ func (s *Session) Start(command string) error {
 	}
 
 	// Create new tmux session in detached mode
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", s.Name, "-c", workDir)
+	args := []string{"new-session", "-d", "-s", s.Name, "-c", workDir}
+	for _, key := range sortedKeys(s.Env) {
+		args = append(args, "-e", key+"="+s.Env[key])
+	}
+	cmd := exec.Command("tmux", args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to create tmux session: %w (output: %s)", err, string(output))
@@ -776,6 +939,7 @@ func (s *Session) Kill() error {
 	os.Remove(logFile) // Ignore errors
 
 	// Kill the tmux session
+	forgetSessionInCache(s.Name)
 	cmd := exec.Command("tmux", "kill-session", "-t", s.Name)
 	return cmd.Run()
 }
@@ -898,6 +1062,16 @@ func (s *Session) HasUpdated() (bool, error) {
 	return false, nil
 }
 
+// commandToolDetector identifies the tool a command runs ("" or "shell" when
+// unknown). The session package installs its tool adapter registry here.
+var commandToolDetector func(command string) string
+
+// SetCommandToolDetector sets how DetectTool identifies a tool from the
+// session's command. Call it during initialization, before sessions are polled.
+func SetCommandToolDetector(detect func(command string) string) {
+	commandToolDetector = detect
+}
+
 // DetectTool detects which AI coding tool is running in the session
 // Uses caching to avoid re-detection on every call
 func (s *Session) DetectTool() string {
@@ -911,19 +1085,8 @@ func (s *Session) DetectTool() string {
 	s.mu.Unlock()
 
 	// Detect tool from command first (most reliable)
-	if s.Command != "" {
-		cmdLower := strings.ToLower(s.Command)
-		var tool string
-		if strings.Contains(cmdLower, "claude") {
-			tool = "claude"
-		} else if strings.Contains(cmdLower, "gemini") {
-			tool = "gemini"
-		} else if strings.Contains(cmdLower, "opencode") || strings.Contains(cmdLower, "open code") {
-			tool = "opencode"
-		} else if strings.Contains(cmdLower, "codex") {
-			tool = "codex"
-		}
-		if tool != "" {
+	if s.Command != "" && commandToolDetector != nil {
+		if tool := commandToolDetector(s.Command); tool != "" && tool != "shell" {
 			s.mu.Lock()
 			s.detectedTool = tool
 			s.toolDetectedAt = time.Now()
@@ -1013,12 +1176,15 @@ func (s *Session) AcknowledgeWithSnapshot() {
 //	YELLOW (waiting) = Cooldown expired, NOT acknowledged (needs attention)
 //	GRAY (idle)      = Cooldown expired, acknowledged (user has seen it)
 //
+// plus "approval" (a permission prompt is showing) and "error" from the
+// session's StatusDetector, which hold until the pane changes.
+//
 // Key insight: Status bar updates cause single timestamp changes (spikes).
 // Real AI work causes multiple timestamp changes over 1 second (sustained).
 // This filters spikes to prevent false GREEN flashes.
 //
 // Logic:
-// 1. Check busy indicator (immediate GREEN if present)
+// 1. Ask the status detector (busy → GREEN, prompt → YELLOW/GRAY, approval, error)
 // 2. Get activity timestamp (fast ~4ms)
 // 3. If timestamp changed → check if sustained or spike
 //   - Sustained (1+ more changes in 1s) → GREEN
@@ -1069,22 +1235,22 @@ func (s *Session) GetStatus() (string, error) {
 	}
 
 	if needsBusyCheck {
-		// Release lock for slow CapturePane operation
-		s.mu.Unlock()
-		content, err := s.CapturePane()
-		s.mu.Lock()
-
-		if err == nil && s.hasBusyIndicator(content) {
-			s.ensureStateTrackerLocked()
-			s.stateTracker.lastChangeTime = time.Now()
-			s.stateTracker.acknowledged = false
-			s.stateTracker.lastActivityTimestamp = currentTS
-			s.lastStableStatus = "active"
-			debugLog("%s: BUSY INDICATOR → active", shortName)
-			return "active", nil
+		state, err := s.detectSinceLocked(currentTS)
+		if err == nil {
+			if status, ok := s.applyDetectedStateLocked(state, shortName); ok {
+				s.stateTracker.lastActivityTimestamp = currentTS
+				return status, nil
+			}
 		}
 	}
 
+	// A detected error or permission prompt sticks until the pane shows
+	// activity again
+	if isStickyStatus(s.lastStableStatus) && s.stateTracker != nil &&
+		s.stateTracker.lastActivityTimestamp == currentTS {
+		return s.lastStableStatus, nil
+	}
+
 	// Initialize on first call
 	if s.stateTracker == nil {
 		s.stateTracker = &StateTracker{
@@ -1110,33 +1276,8 @@ func (s *Session) GetStatus() (string, error) {
 
 	// Activity timestamp changed → non-blocking spike detection across tick cycles
 	if s.stateTracker.lastActivityTimestamp != currentTS {
-		oldTS := s.stateTracker.lastActivityTimestamp
-		s.stateTracker.lastActivityTimestamp = currentTS
-
-		// Check if we're in a detection window
-		const spikeWindow = 1 * time.Second
-		now := time.Now()
-
-		if s.stateTracker.activityCheckStart.IsZero() || now.Sub(s.stateTracker.activityCheckStart) > spikeWindow {
-			// Start new detection window
-			s.stateTracker.activityCheckStart = now
-			s.stateTracker.activityChangeCount = 1
-			debugLog("%s: ACTIVITY_START ts=%d→%d count=1", shortName, oldTS, currentTS)
-		} else {
-			// Within detection window - count this change
-			s.stateTracker.activityChangeCount++
-			debugLog("%s: ACTIVITY_COUNT ts=%d→%d count=%d", shortName, oldTS, currentTS, s.stateTracker.activityChangeCount)
-
-			// 2+ changes within 1 second = sustained activity
-			if s.stateTracker.activityChangeCount >= 2 {
-				s.stateTracker.lastChangeTime = now
-				s.stateTracker.acknowledged = false
-				s.stateTracker.activityCheckStart = time.Time{} // Reset window
-				s.stateTracker.activityChangeCount = 0
-				s.lastStableStatus = "active"
-				debugLog("%s: SUSTAINED count=%d → active", shortName, s.stateTracker.activityChangeCount)
-				return "active", nil
-			}
+		if s.recordActivityLocked(currentTS, shortName) {
+			return "active", nil
 		}
 		// Not enough changes yet - continue with current status (don't block)
 	} else {
@@ -1180,6 +1321,91 @@ func (s *Session) GetStatus() (string, error) {
 	return "waiting", nil
 }
 
+// detectSinceLocked returns what the status detector reads from the pane. With
+// a StatusEngine running the activity timestamp is current, so if the pane
+// was captured after the second of its last activity it hasn't changed and
+// the previous result is reused instead of capturing again.
+// MUST be called with mu held; releases it while capturing.
+func (s *Session) detectSinceLocked(currentTS int64) (SessionState, error) {
+	if t := s.stateTracker; t != nil && controlCacheLive.Load() &&
+		t.lastActivityTimestamp == currentTS && t.lastCaptureTime.After(time.Unix(currentTS+1, 0)) {
+		return t.lastDetected, nil
+	}
+
+	// Release lock for slow CapturePane operation
+	capturedAt := time.Now()
+	s.mu.Unlock()
+	content, err := s.CapturePane()
+	s.mu.Lock()
+	if err != nil {
+		return StateUnknown, err
+	}
+
+	state := s.statusDetectorLocked().Detect(content)
+	if s.stateTracker != nil {
+		s.stateTracker.lastDetected = state
+		s.stateTracker.lastCaptureTime = capturedAt
+	}
+	return state, nil
+}
+
+// recordActivityLocked feeds a changed activity timestamp into spike detection.
+// Returns true when it confirms sustained activity (2+ changes within 1s),
+// which marks the session active.
+// MUST be called with mu held and stateTracker initialized.
+func (s *Session) recordActivityLocked(currentTS int64, shortName string) bool {
+	oldTS := s.stateTracker.lastActivityTimestamp
+	s.stateTracker.lastActivityTimestamp = currentTS
+
+	// Check if we're in a detection window
+	const spikeWindow = 1 * time.Second
+	now := time.Now()
+
+	if s.stateTracker.activityCheckStart.IsZero() || now.Sub(s.stateTracker.activityCheckStart) > spikeWindow {
+		// Start new detection window
+		s.stateTracker.activityCheckStart = now
+		s.stateTracker.activityChangeCount = 1
+		debugLog("%s: ACTIVITY_START ts=%d→%d count=1", shortName, oldTS, currentTS)
+		return false
+	}
+
+	// Within detection window - count this change
+	s.stateTracker.activityChangeCount++
+	debugLog("%s: ACTIVITY_COUNT ts=%d→%d count=%d", shortName, oldTS, currentTS, s.stateTracker.activityChangeCount)
+
+	// 2+ changes within 1 second = sustained activity
+	if s.stateTracker.activityChangeCount >= 2 {
+		s.stateTracker.lastChangeTime = now
+		s.stateTracker.acknowledged = false
+		s.stateTracker.activityCheckStart = time.Time{} // Reset window
+		s.stateTracker.activityChangeCount = 0
+		s.lastStableStatus = "active"
+		debugLog("%s: SUSTAINED → active", shortName)
+		return true
+	}
+	return false
+}
+
+// ObserveActivity feeds an activity timestamp pushed by a StatusEngine into the
+// state tracker, so spike detection sees every change as it happens instead of
+// only what the next poll samples. Sessions not yet initialized by GetStatus
+// are left alone.
+func (s *Session) ObserveActivity(activity int64) {
+	shortName := s.DisplayName
+	if len(shortName) > 12 {
+		shortName = shortName[:12]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stateTracker == nil || s.stateTracker.lastActivityTimestamp == 0 ||
+		s.stateTracker.lastActivityTimestamp == activity {
+		return
+	}
+	s.recordActivityLocked(activity, shortName)
+}
+
 // getStatusFallback uses content-hash based detection as fallback
 // when activity timestamp detection fails
 func (s *Session) getStatusFallback() (string, error) {
@@ -1196,16 +1422,6 @@ func (s *Session) getStatusFallback() (string, error) {
 		return "inactive", nil
 	}
 
-	if s.hasBusyIndicator(content) {
-		s.mu.Lock()
-		defer s.mu.Unlock()
-		s.ensureStateTrackerLocked()
-		s.stateTracker.lastChangeTime = time.Now()
-		s.stateTracker.acknowledged = false
-		s.lastStableStatus = "active"
-		return "active", nil
-	}
-
 	cleanContent := s.normalizeContent(content)
 	currentHash := s.hashContent(cleanContent)
 	if currentHash == "" {
@@ -1215,6 +1431,14 @@ func (s *Session) getStatusFallback() (string, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if status, ok := s.applyDetectedStateLocked(s.statusDetectorLocked().Detect(content), shortName); ok {
+		s.stateTracker.lastHash = currentHash
+		return status, nil
+	}
+	if isStickyStatus(s.lastStableStatus) && s.stateTracker != nil && s.stateTracker.lastHash == currentHash {
+		return s.lastStableStatus, nil
+	}
+
 	if s.stateTracker == nil {
 		s.stateTracker = &StateTracker{
 			lastHash:       currentHash,
@@ -1324,6 +1548,16 @@ func (s *Session) hasBusyIndicator(content string) bool {
 		shortName = shortName[:12]
 	}
 
+	if reason := busyIndicatorReason(content); reason != "" {
+		debugLog("%s: BUSY_REASON=%s", shortName, reason)
+		return true
+	}
+	return false
+}
+
+// busyIndicatorReason returns why content looks busy, or "" if it doesn't.
+// These heuristics are shared by all built-in status detectors.
+func busyIndicatorReason(content string) string {
 	// Get last 10 lines for analysis
 	lines := strings.Split(content, "\n")
 	start := len(lines) - 10
@@ -1343,8 +1577,7 @@ func (s *Session) hasBusyIndicator(content string) bool {
 
 	for _, indicator := range busyIndicators {
 		if strings.Contains(recentContent, indicator) {
-			debugLog("%s: BUSY_REASON=text_indicator matched=%q", shortName, indicator)
-			return true
+			return fmt.Sprintf("text_indicator matched=%q", indicator)
 		}
 	}
 
@@ -1354,8 +1587,7 @@ func (s *Session) hasBusyIndicator(content string) bool {
 	if strings.Contains(recentContent, "tokens") {
 		for _, word := range claudeWhimsicalWords {
 			if strings.Contains(recentContent, word) {
-				debugLog("%s: BUSY_REASON=%s+tokens pattern", shortName, word)
-				return true
+				return fmt.Sprintf("%s+tokens pattern", word)
 			}
 		}
 	}
@@ -1375,8 +1607,7 @@ func (s *Session) hasBusyIndicator(content string) bool {
 	for lineIdx, line := range last5 {
 		for _, spinner := range spinnerChars {
 			if strings.Contains(line, spinner) {
-				debugLog("%s: BUSY_REASON=spinner char=%q line=%d content=%q", shortName, spinner, lineIdx, truncateForLog(line, 50))
-				return true
+				return fmt.Sprintf("spinner char=%q line=%d content=%q", spinner, lineIdx, truncateForLog(line, 50))
 			}
 		}
 	}
@@ -1399,13 +1630,12 @@ func (s *Session) hasBusyIndicator(content string) bool {
 		for lineIdx, line := range last5 {
 			lineLower := strings.ToLower(strings.TrimSpace(line))
 			if strings.HasPrefix(lineLower, indicator) {
-				debugLog("%s: BUSY_REASON=working_indicator matched=%q line=%d content=%q", shortName, indicator, lineIdx, truncateForLog(line, 50))
-				return true
+				return fmt.Sprintf("working_indicator matched=%q line=%d content=%q", indicator, lineIdx, truncateForLog(line, 50))
 			}
 		}
 	}
 
-	return false
+	return ""
 }
 
 // truncateForLog truncates a string for logging purposes
@@ -1586,12 +1816,32 @@ func (s *Session) SendKeys(keys string) error {
 	return cmd.Run()
 }
 
+// PasteText pastes text into the session as a bracketed paste, so agents
+// take multi-line text as one input instead of submitting every line
+func (s *Session) PasteText(text string) error {
+	buffer := "agent-deck-" + s.Name
+	load := exec.Command("tmux", "load-buffer", "-b", buffer, "-")
+	load.Stdin = strings.NewReader(text)
+	if err := load.Run(); err != nil {
+		return err
+	}
+	// -p: bracketed paste, -d: delete the buffer afterwards
+	return exec.Command("tmux", "paste-buffer", "-p", "-d", "-b", buffer, "-t", s.Name).Run()
+}
+
 // SendEnter sends an Enter key to the tmux session
 func (s *Session) SendEnter() error {
 	cmd := exec.Command("tmux", "send-keys", "-t", s.Name, "Enter")
 	return cmd.Run()
 }
 
+// SendKeyNames sends tmux key names (e.g. "Enter", "Escape", "y") to the
+// session, unlike SendKeys which sends literal text
+func (s *Session) SendKeyNames(keys ...string) error {
+	args := append([]string{"send-keys", "-t", s.Name}, keys...)
+	return exec.Command("tmux", args...).Run()
+}
+
 // SendCtrlC sends Ctrl+C (interrupt signal) to the tmux session
 func (s *Session) SendCtrlC() error {
 	cmd := exec.Command("tmux", "send-keys", "-t", s.Name, "C-c")
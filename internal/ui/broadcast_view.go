@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// BroadcastView shows a prompt sent to many sessions and their replies as
+// they come in
+type BroadcastView struct {
+	visible bool
+	width   int
+	height  int
+
+	target  string // Group name, or "N sessions"
+	prompt  string
+	started time.Time
+	done    bool
+
+	ids     []string                           // Sessions asked, in list order
+	titles  map[string]string                  // Session ID -> title
+	results map[string]session.BroadcastResult // Session ID -> reply, once in
+
+	lines  []transcriptLine
+	scroll int
+}
+
+// NewBroadcastView creates a new broadcast view
+func NewBroadcastView() *BroadcastView {
+	return &BroadcastView{}
+}
+
+// Show opens the view on a broadcast that was just started
+func (v *BroadcastView) Show(target, prompt string, ids, titles []string) {
+	v.visible = true
+	v.target = target
+	v.prompt = prompt
+	v.started = time.Now()
+	v.done = false
+	v.ids = ids
+	v.titles = make(map[string]string, len(ids))
+	for idx, id := range ids {
+		v.titles[id] = titles[idx]
+	}
+	v.results = make(map[string]session.BroadcastResult, len(ids))
+	v.scroll = 0
+	v.rebuild()
+}
+
+// AddResult records a session's reply
+func (v *BroadcastView) AddResult(result session.BroadcastResult) {
+	if v.results == nil {
+		return
+	}
+	v.results[result.SessionID] = result
+	v.rebuild()
+}
+
+// Finish marks the broadcast as done
+func (v *BroadcastView) Finish() {
+	v.done = true
+}
+
+// Hide hides the view
+func (v *BroadcastView) Hide() {
+	v.visible = false
+	v.results = nil
+	v.lines = nil
+}
+
+// IsVisible returns whether the view is visible
+func (v *BroadcastView) IsVisible() bool {
+	return v.visible
+}
+
+// IsDone returns whether every session has replied (or failed)
+func (v *BroadcastView) IsDone() bool {
+	return v.done
+}
+
+// SetSize sets the dimensions of the view
+func (v *BroadcastView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+	if v.visible {
+		v.rebuild()
+	}
+}
+
+// contentWidth is the width available to reply lines
+func (v *BroadcastView) contentWidth() int {
+	return max(v.width-8, 20)
+}
+
+// pageHeight is the number of lines shown at once
+func (v *BroadcastView) pageHeight() int {
+	return max(v.height-12, 5)
+}
+
+// Update handles scrolling (Esc is handled by Home, which also cancels)
+func (v *BroadcastView) Update(msg tea.KeyMsg) (*BroadcastView, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		v.scrollBy(-1)
+	case "down", "j":
+		v.scrollBy(1)
+	case "pgup", "ctrl+u":
+		v.scrollBy(-v.pageHeight())
+	case "pgdown", "ctrl+d", " ":
+		v.scrollBy(v.pageHeight())
+	case "g", "home":
+		v.scroll = 0
+	case "G", "end":
+		v.scrollBy(len(v.lines))
+	}
+	return v, nil
+}
+
+func (v *BroadcastView) scrollBy(delta int) {
+	v.scroll = max(min(v.scroll+delta, len(v.lines)-v.pageHeight()), 0)
+}
+
+// rebuild lays the replies that came in out in wrapped lines
+func (v *BroadcastView) rebuild() {
+	v.lines = nil
+	width := v.contentWidth()
+	for _, id := range v.ids {
+		result, ok := v.results[id]
+		if !ok {
+			continue
+		}
+		header := fmt.Sprintf("%s · %s · %s", result.Title, result.Status, result.Duration.Round(time.Second))
+		v.lines = append(v.lines, transcriptLine{text: header, kind: lineAssistant, call: -1})
+		text := result.Reply
+		if result.Error != "" {
+			text = strings.TrimSpace(text + "\n⚠ " + result.Error)
+		}
+		for _, line := range strings.Split(text, "\n") {
+			for _, w := range wrapTranscriptLine(line, width-2) {
+				v.lines = append(v.lines, transcriptLine{text: "  " + w, kind: lineText, call: -1})
+			}
+		}
+		v.lines = append(v.lines, transcriptLine{call: -1})
+	}
+	v.scrollBy(0)
+}
+
+// View renders the view
+func (v *BroadcastView) View() string {
+	if !v.visible {
+		return ""
+	}
+
+	var b strings.Builder
+	dim := lipgloss.NewStyle().Foreground(ColorComment)
+
+	// Header: prompt and one line per session
+	b.WriteString(globalSearchHeaderStyle.Render("📣 Broadcast to "+v.target) + "\n")
+	b.WriteString(dim.Render(truncateTranscriptText(v.prompt, v.contentWidth())) + "\n\n")
+
+	var statusLine []string
+	for _, id := range v.ids {
+		result, ok := v.results[id]
+		switch {
+		case !ok:
+			statusLine = append(statusLine, lipgloss.NewStyle().Foreground(ColorYellow).
+				Render(fmt.Sprintf("… %s %s", v.titles[id], time.Since(v.started).Round(time.Second))))
+		case result.Error != "":
+			statusLine = append(statusLine, lipgloss.NewStyle().Foreground(ColorRed).Render("✕ "+result.Title))
+		default:
+			statusLine = append(statusLine, lipgloss.NewStyle().Foreground(ColorGreen).Render("✓ "+result.Title))
+		}
+	}
+	b.WriteString(lipgloss.NewStyle().Width(v.contentWidth()).Render(strings.Join(statusLine, "  ")) + "\n\n")
+
+	// Replies
+	assistantStyle := lipgloss.NewStyle().Foreground(ColorCyan).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(ColorText)
+	end := min(v.scroll+v.pageHeight(), len(v.lines))
+	for i := v.scroll; i < end; i++ {
+		style := textStyle
+		if v.lines[i].kind == lineAssistant {
+			style = assistantStyle
+		}
+		b.WriteString(style.Render(v.lines[i].text) + "\n")
+	}
+	for i := end - v.scroll; i < v.pageHeight(); i++ {
+		b.WriteString("\n")
+	}
+
+	status := fmt.Sprintf("%d/%d done", len(v.results), len(v.ids))
+	if !v.done {
+		status += " · waiting for the rest"
+	}
+	position := fmt.Sprintf("%d-%d/%d", min(v.scroll+1, len(v.lines)), end, len(v.lines))
+	padding := max(v.contentWidth()-len(status)-len(position), 1)
+	b.WriteString(dim.Render(status+strings.Repeat(" ", padding)+position) + "\n")
+
+	closeHint := "[Esc] Close"
+	if !v.done {
+		closeHint = "[Esc] Stop waiting"
+	}
+	b.WriteString(dim.Render("[↑↓] Scroll  [PgUp/PgDn] Page  [g/G] Top/End  " + closeHint))
+
+	box := lipgloss.NewStyle().
+		Width(v.contentWidth()+2).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(ColorCyan).
+		Padding(0, 1).
+		Render(b.String())
+	return centerInScreen(box, v.width, v.height)
+}
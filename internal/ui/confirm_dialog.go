@@ -14,14 +14,25 @@ type ConfirmType int
 const (
 	ConfirmDeleteSession ConfirmType = iota
 	ConfirmDeleteGroup
+	ConfirmDeleteSessions
+	ConfirmStopSessions
+	ConfirmRestartSessions
 )
 
+// maxConfirmTitles is how many session titles a bulk confirmation lists
+const maxConfirmTitles = 8
+
 // ConfirmDialog handles confirmation for destructive actions
 type ConfirmDialog struct {
 	visible     bool
 	confirmType ConfirmType
-	targetID    string // Session ID or group path
-	targetName  string // Display name
+	targetID    string   // Session ID or group path
+	targetName  string   // Display name
+	worktree    string   // Session's git worktree (offered for removal)
+	worktrees   []string // Git worktrees of a bulk delete's sessions (offered for removal)
+	targetIDs   []string // Session IDs (bulk actions)
+	targetNames []string // Session titles (bulk actions)
+	running     int      // How many of the sessions are running (bulk actions)
 	width       int
 	height      int
 }
@@ -37,6 +48,14 @@ func (c *ConfirmDialog) ShowDeleteSession(sessionID, sessionName string) {
 	c.confirmType = ConfirmDeleteSession
 	c.targetID = sessionID
 	c.targetName = sessionName
+	c.worktree = ""
+}
+
+// ShowDeleteSessionWithWorktree shows confirmation for deleting a session
+// that runs in its own git worktree, offering to remove the worktree too
+func (c *ConfirmDialog) ShowDeleteSessionWithWorktree(sessionID, sessionName, worktreePath string) {
+	c.ShowDeleteSession(sessionID, sessionName)
+	c.worktree = worktreePath
 }
 
 // ShowDeleteGroup shows confirmation for group deletion
@@ -47,11 +66,35 @@ func (c *ConfirmDialog) ShowDeleteGroup(groupPath, groupName string) {
 	c.targetName = groupName
 }
 
+// ShowSessions shows confirmation for a bulk action (ConfirmDeleteSessions,
+// ConfirmStopSessions or ConfirmRestartSessions) on several sessions
+func (c *ConfirmDialog) ShowSessions(confirmType ConfirmType, ids, names []string, running int) {
+	c.visible = true
+	c.confirmType = confirmType
+	c.targetIDs = ids
+	c.targetNames = names
+	c.running = running
+	c.worktree = ""
+	c.worktrees = nil
+}
+
+// ShowDeleteSessions shows confirmation for deleting several sessions,
+// offering to remove the git worktrees some of them run in
+func (c *ConfirmDialog) ShowDeleteSessions(ids, names []string, running int, worktrees []string) {
+	c.ShowSessions(ConfirmDeleteSessions, ids, names, running)
+	c.worktrees = worktrees
+}
+
 // Hide hides the dialog
 func (c *ConfirmDialog) Hide() {
 	c.visible = false
 	c.targetID = ""
 	c.targetName = ""
+	c.worktree = ""
+	c.worktrees = nil
+	c.targetIDs = nil
+	c.targetNames = nil
+	c.running = 0
 }
 
 // IsVisible returns whether the dialog is visible
@@ -64,6 +107,21 @@ func (c *ConfirmDialog) GetTargetID() string {
 	return c.targetID
 }
 
+// GetTargetIDs returns the session IDs of a bulk action being confirmed
+func (c *ConfirmDialog) GetTargetIDs() []string {
+	return c.targetIDs
+}
+
+// GetWorktree returns the worktree offered for removal ("" if none)
+func (c *ConfirmDialog) GetWorktree() string {
+	return c.worktree
+}
+
+// GetWorktrees returns the worktrees a bulk delete offers to remove
+func (c *ConfirmDialog) GetWorktrees() []string {
+	return c.worktrees
+}
+
 // GetConfirmType returns the type of confirmation
 func (c *ConfirmDialog) GetConfirmType() ConfirmType {
 	return c.confirmType
@@ -89,17 +147,42 @@ func (c *ConfirmDialog) View() string {
 
 	// Build warning message based on action type
 	var title, warning, details string
+	action := "Delete"
 
 	switch c.confirmType {
 	case ConfirmDeleteSession:
 		title = "⚠️  Delete Session?"
 		warning = fmt.Sprintf("This will PERMANENTLY KILL the tmux session:\n\n  \"%s\"", c.targetName)
-		details = "• The tmux session will be terminated\n• Any running processes will be killed\n• Terminal history will be lost\n• This cannot be undone"
+		details = "• The tmux session will be terminated\n• Any running processes will be killed\n• Terminal history will be lost\n• Ctrl+Z restores the session (not its terminal)"
+		if c.worktree != "" {
+			details += fmt.Sprintf("\n\nGit worktree %s:\n• y keeps it, w removes it (refused if it has\n  uncommitted changes; the branch is kept)", c.worktree)
+		}
 
 	case ConfirmDeleteGroup:
 		title = "⚠️  Delete Group?"
 		warning = fmt.Sprintf("This will delete the group:\n\n  \"%s\"", c.targetName)
 		details = "• All sessions will be MOVED to 'default' group\n• Sessions will NOT be killed\n• The group structure will be lost"
+
+	case ConfirmDeleteSessions:
+		title = fmt.Sprintf("⚠️  Delete %s?", sessionCount(len(c.targetIDs)))
+		warning = "This will KILL the tmux sessions of:\n\n" + c.titleList()
+		details = fmt.Sprintf("• %d running, their processes will be killed\n• Terminal history will be lost\n• Ctrl+Z restores the sessions (not their terminals)", c.running)
+		if len(c.worktrees) > 0 {
+			details += fmt.Sprintf("\n\nGit worktrees:\n%s\n• y keeps them, w removes them (one with uncommitted\n  changes is kept, with its session; branches are kept)",
+				listLines(c.worktrees))
+		}
+
+	case ConfirmStopSessions:
+		title = fmt.Sprintf("⚠️  Stop %s?", sessionCount(len(c.targetIDs)))
+		warning = "This will stop:\n\n" + c.titleList()
+		details = fmt.Sprintf("• %d running, their processes will be killed\n• The sessions stay in the list\n• R restarts them, resuming the conversation", c.running)
+		action = "Stop"
+
+	case ConfirmRestartSessions:
+		title = fmt.Sprintf("⚠️  Restart %s?", sessionCount(len(c.targetIDs)))
+		warning = "This will restart:\n\n" + c.titleList()
+		details = fmt.Sprintf("• %d running, their agents will be interrupted\n• Conversations resume where the tool supports it", c.running)
+		action = "Restart"
 	}
 
 	// Styles
@@ -121,7 +204,7 @@ func (c *ConfirmDialog) View() string {
 		Background(ColorRed).
 		Padding(0, 2).
 		Bold(true).
-		Render("y Delete")
+		Render("y " + action)
 
 	buttonNo := lipgloss.NewStyle().
 		Foreground(ColorBg).
@@ -134,13 +217,25 @@ func (c *ConfirmDialog) View() string {
 		Foreground(ColorTextDim).
 		Render("(Esc to cancel)")
 
+	buttons := []string{buttonYes, "  "}
+	if c.worktree != "" || len(c.worktrees) > 0 {
+		buttonWorktree := lipgloss.NewStyle().
+			Foreground(ColorBg).
+			Background(ColorRed).
+			Padding(0, 2).
+			Bold(true).
+			Render("w + Worktree")
+		buttons = append(buttons, buttonWorktree, "  ")
+	}
+	buttons = append(buttons, buttonNo, "  ", escHint)
+
 	// Build content
 	content := lipgloss.JoinVertical(lipgloss.Left,
 		titleStyle.Render(title),
 		warningStyle.Render(warning),
 		detailsStyle.Render(details),
 		"",
-		lipgloss.JoinHorizontal(lipgloss.Center, buttonYes, "  ", buttonNo, "  ", escHint),
+		lipgloss.JoinHorizontal(lipgloss.Center, buttons...),
 	)
 
 	// Dialog box
@@ -187,3 +282,34 @@ func (c *ConfirmDialog) View() string {
 
 	return dialogBox
 }
+
+// titleList lists the titles of a bulk action's sessions, up to
+// maxConfirmTitles of them
+func (c *ConfirmDialog) titleList() string {
+	quoted := make([]string, len(c.targetNames))
+	for i, name := range c.targetNames {
+		quoted[i] = fmt.Sprintf("\"%s\"", name)
+	}
+	return listLines(quoted)
+}
+
+// listLines lists items one per line, indented, up to maxConfirmTitles of them
+func listLines(items []string) string {
+	var lines []string
+	for i, item := range items {
+		if i == maxConfirmTitles {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(items)-i))
+			break
+		}
+		lines = append(lines, "  "+item)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sessionCount formats a number of sessions, e.g. "1 session" or "3 sessions"
+func sessionCount(n int) string {
+	if n == 1 {
+		return "1 session"
+	}
+	return fmt.Sprintf("%d sessions", n)
+}
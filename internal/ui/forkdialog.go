@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"github.com/asheshgoplani/agent-deck/internal/git"
+	"github.com/asheshgoplani/agent-deck/internal/session"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -15,6 +17,14 @@ type ForkDialog struct {
 	width       int
 	height      int
 	projectPath string
+	canWorktree bool // projectPath is in a git repository
+	worktree    bool // Fork into a new git worktree
+
+	// Claude options, shown for Claude forks (see SetClaudeOptions)
+	claude           bool
+	claudeFlagsInput textinput.Model
+	skipPermissions  bool
+	defaultSkip      bool // [claude] dangerous_mode, stored only when overridden
 }
 
 // NewForkDialog creates a new fork dialog
@@ -29,9 +39,15 @@ func NewForkDialog() *ForkDialog {
 	groupInput.CharLimit = 64
 	groupInput.Width = 40
 
+	claudeFlagsInput := textinput.New()
+	claudeFlagsInput.Placeholder = "--model opus --permission-mode plan"
+	claudeFlagsInput.CharLimit = 256
+	claudeFlagsInput.Width = 40
+
 	return &ForkDialog{
-		nameInput:  nameInput,
-		groupInput: groupInput,
+		nameInput:        nameInput,
+		groupInput:       groupInput,
+		claudeFlagsInput: claudeFlagsInput,
 	}
 }
 
@@ -41,8 +57,41 @@ func (d *ForkDialog) Show(originalName, projectPath, groupPath string) {
 	d.projectPath = projectPath
 	d.nameInput.SetValue(originalName + " (fork)")
 	d.groupInput.SetValue(groupPath)
+	d.canWorktree = git.IsRepo(projectPath)
+	d.worktree = false
+	d.claude = false
 	d.focusIndex = 0
-	d.nameInput.Focus()
+	d.updateFocus()
+}
+
+// SetClaudeOptions shows the Claude options of a Claude fork, pre-filled with
+// the parent's flags and effective skip-permissions setting. defaultSkip is
+// the [claude] dangerous_mode setting.
+func (d *ForkDialog) SetClaudeOptions(opts *session.ClaudeOptions, skipPermissions, defaultSkip bool) {
+	d.claude = true
+	d.claudeFlagsInput.SetValue(opts.String())
+	d.skipPermissions = skipPermissions
+	d.defaultSkip = defaultSkip
+}
+
+// GetClaudeOptions returns the fork's Claude options: nil to inherit the
+// parent's (not a Claude fork), else the options entered in the dialog
+func (d *ForkDialog) GetClaudeOptions() (*session.ClaudeOptions, error) {
+	if !d.claude {
+		return nil, nil
+	}
+	opts, err := session.ParseClaudeFlags(d.claudeFlagsInput.Value())
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &session.ClaudeOptions{} // Cleared in the dialog
+	}
+	if d.skipPermissions != d.defaultSkip {
+		skip := d.skipPermissions
+		opts.DangerousMode = &skip
+	}
+	return opts, nil
 }
 
 // Hide hides the dialog
@@ -62,6 +111,38 @@ func (d *ForkDialog) GetValues() (name, group string) {
 	return d.nameInput.Value(), d.groupInput.Value()
 }
 
+// UseWorktree returns whether the fork should get its own git worktree
+func (d *ForkDialog) UseWorktree() bool {
+	return d.canWorktree && d.worktree
+}
+
+// fieldCount returns the number of focusable fields
+func (d *ForkDialog) fieldCount() int {
+	count := 2
+	if d.canWorktree {
+		count++
+	}
+	if d.claude {
+		count += 2
+	}
+	return count
+}
+
+// field returns the name of the field at a focus index
+func (d *ForkDialog) field(index int) string {
+	fields := []string{"name", "group"}
+	if d.canWorktree {
+		fields = append(fields, "worktree")
+	}
+	if d.claude {
+		fields = append(fields, "claude-flags", "skip-permissions")
+	}
+	if index < 0 || index >= len(fields) {
+		return ""
+	}
+	return fields[index]
+}
+
 // SetSize sets the dialog dimensions
 func (d *ForkDialog) SetSize(width, height int) {
 	d.width = width
@@ -78,14 +159,25 @@ func (d *ForkDialog) Update(msg tea.Msg) (*ForkDialog, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "tab", "down":
-			d.focusIndex = (d.focusIndex + 1) % 2
+			d.focusIndex = (d.focusIndex + 1) % d.fieldCount()
 			d.updateFocus()
+			return d, nil
 		case "shift+tab", "up":
 			d.focusIndex = (d.focusIndex - 1)
 			if d.focusIndex < 0 {
-				d.focusIndex = 1
+				d.focusIndex = d.fieldCount() - 1
 			}
 			d.updateFocus()
+			return d, nil
+		case " ", "left", "right":
+			switch d.field(d.focusIndex) {
+			case "worktree":
+				d.worktree = !d.worktree
+				return d, nil
+			case "skip-permissions":
+				d.skipPermissions = !d.skipPermissions
+				return d, nil
+			}
 		case "esc":
 			d.Hide()
 			return d, nil
@@ -97,22 +189,29 @@ func (d *ForkDialog) Update(msg tea.Msg) (*ForkDialog, tea.Cmd) {
 	}
 
 	var cmd tea.Cmd
-	if d.focusIndex == 0 {
+	switch d.field(d.focusIndex) {
+	case "name":
 		d.nameInput, cmd = d.nameInput.Update(msg)
-	} else {
+	case "group":
 		d.groupInput, cmd = d.groupInput.Update(msg)
+	case "claude-flags":
+		d.claudeFlagsInput, cmd = d.claudeFlagsInput.Update(msg)
 	}
 
 	return d, cmd
 }
 
 func (d *ForkDialog) updateFocus() {
-	if d.focusIndex == 0 {
+	d.nameInput.Blur()
+	d.groupInput.Blur()
+	d.claudeFlagsInput.Blur()
+	switch d.field(d.focusIndex) {
+	case "name":
 		d.nameInput.Focus()
-		d.groupInput.Blur()
-	} else {
-		d.nameInput.Blur()
+	case "group":
 		d.groupInput.Focus()
+	case "claude-flags":
+		d.claudeFlagsInput.Focus()
 	}
 }
 
@@ -149,22 +248,50 @@ func (d *ForkDialog) View() string {
 		Width(dialogWidth)
 
 	// Build content
-	var nameLabel, groupLabel string
-	if d.focusIndex == 0 {
+	nameLabel := labelStyle.Render("  Name:")
+	groupLabel := labelStyle.Render("  Group:")
+	worktreeLabel := labelStyle.Render("  Git worktree:")
+	flagsLabel := labelStyle.Render("  Claude flags:")
+	skipLabel := labelStyle.Render("  Skip permissions:")
+	switch d.field(d.focusIndex) {
+	case "name":
 		nameLabel = activeLabelStyle.Render("▶ Name:")
-		groupLabel = labelStyle.Render("  Group:")
-	} else {
-		nameLabel = labelStyle.Render("  Name:")
+	case "group":
 		groupLabel = activeLabelStyle.Render("▶ Group:")
+	case "worktree":
+		worktreeLabel = activeLabelStyle.Render("▶ Git worktree:")
+	case "claude-flags":
+		flagsLabel = activeLabelStyle.Render("▶ Claude flags:")
+	case "skip-permissions":
+		skipLabel = activeLabelStyle.Render("▶ Skip permissions:")
 	}
 
 	content := titleStyle.Render("Fork Session") + "\n\n" +
 		nameLabel + "\n" +
 		d.nameInput.View() + "\n\n" +
 		groupLabel + "\n" +
-		d.groupInput.View() + "\n\n" +
-		lipgloss.NewStyle().Foreground(ColorComment).
-			Render("Enter create │ Esc cancel │ Tab next")
+		d.groupInput.View() + "\n\n"
+	help := "Enter create │ Esc cancel │ Tab next"
+	if d.canWorktree {
+		checkbox := "[ ] share the parent's checkout"
+		if d.worktree {
+			checkbox = "[x] new branch and worktree"
+		}
+		content += worktreeLabel + " " + labelStyle.Render(checkbox) + "\n\n"
+	}
+	if d.claude {
+		checkbox := "[ ] ask for permissions"
+		if d.skipPermissions {
+			checkbox = "[x] --dangerously-skip-permissions"
+		}
+		content += flagsLabel + "\n" +
+			d.claudeFlagsInput.View() + "\n\n" +
+			skipLabel + " " + labelStyle.Render(checkbox) + "\n\n"
+	}
+	if d.canWorktree || d.claude {
+		help += " │ Space toggle"
+	}
+	content += lipgloss.NewStyle().Foreground(ColorComment).Render(help)
 
 	dialog := boxStyle.Render(content)
 
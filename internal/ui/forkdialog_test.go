@@ -2,6 +2,10 @@ package ui
 
 import (
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
 )
 
 func TestNewForkDialog(t *testing.T) {
@@ -87,3 +91,51 @@ func TestForkDialog_EmptyProjectPath(t *testing.T) {
 		t.Errorf("Group = %s, want ''", group)
 	}
 }
+
+func TestForkDialog_WorktreeToggle(t *testing.T) {
+	d := NewForkDialog()
+	d.Show("My Session", t.TempDir(), "work")
+	if d.UseWorktree() {
+		t.Error("worktree should be off by default")
+	}
+
+	// Outside a git repository the option is not offered
+	d.focusIndex = 1
+	d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if d.focusIndex != 0 {
+		t.Errorf("focusIndex = %d, want 0 (no worktree field)", d.focusIndex)
+	}
+
+	d.canWorktree = true
+	d.focusIndex = 2
+	d.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if !d.UseWorktree() {
+		t.Error("space should enable the worktree option")
+	}
+}
+
+func TestForkDialog_ClaudeOptions(t *testing.T) {
+	d := NewForkDialog()
+	d.Show("My Session", t.TempDir(), "work")
+	if opts, err := d.GetClaudeOptions(); opts != nil || err != nil {
+		t.Errorf("non-Claude fork should inherit options, got %+v, %v", opts, err)
+	}
+
+	d.SetClaudeOptions(&session.ClaudeOptions{Model: "opus"}, false, true)
+	if got := d.claudeFlagsInput.Value(); got != "--model opus" {
+		t.Errorf("flags = %q, want parent's flags", got)
+	}
+	opts, err := d.GetClaudeOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Differs from dangerous_mode, so it is stored on the fork
+	if opts.Model != "opus" || opts.DangerousMode == nil || *opts.DangerousMode {
+		t.Errorf("GetClaudeOptions() = %+v", opts)
+	}
+
+	d.claudeFlagsInput.SetValue("--permission-mode yolo")
+	if _, err := d.GetClaudeOptions(); err == nil {
+		t.Error("invalid flags should be reported")
+	}
+}
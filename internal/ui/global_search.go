@@ -39,6 +39,7 @@ var (
 // GlobalSearchResult wraps a search result for UI display
 type GlobalSearchResult struct {
 	SessionID   string
+	Tool        string // Tool that wrote the conversation
 	Summary     string
 	Snippet     string
 	Content     string    // Full conversation content for preview
@@ -72,7 +73,7 @@ type GlobalSearch struct {
 // NewGlobalSearch creates a new global search overlay
 func NewGlobalSearch() *GlobalSearch {
 	ti := textinput.New()
-	ti.Placeholder = "Search all Claude conversations..."
+	ti.Placeholder = "Search all conversations (\"phrase\", project:, tool:, user:, after:)..."
 	ti.Focus()
 	ti.CharLimit = 100
 	ti.Width = 60
@@ -232,39 +233,39 @@ func (gs *GlobalSearch) Update(msg tea.Msg) (*GlobalSearch, tea.Cmd) {
 
 // updateResults performs search and updates results
 func (gs *GlobalSearch) updateResults() {
-	gs.query = gs.input.Value() // Store for highlighting
-	query := gs.query
-	if gs.index == nil || query == "" {
+	query := gs.input.Value()
+	parsed := session.ParseSearchQuery(query)
+	gs.query = parsed.HighlightText() // Store for highlighting (without filters)
+	if gs.index == nil || parsed.IsEmpty() {
 		gs.results = nil
 		return
 	}
 
-	// Perform full-content search first (more comprehensive)
+	// Perform full-text search first (phrases, filters)
 	searchResults := gs.index.Search(query)
 
-	// If no substring matches, fall back to fuzzy search (typo tolerance)
+	// If nothing matches, fall back to fuzzy search (typo tolerance)
 	if len(searchResults) == 0 {
 		searchResults = gs.index.FuzzySearch(query)
+		gs.query = query
 	}
 
-	// Convert to UI results (limit to 15 for split view)
-	gs.results = make([]*GlobalSearchResult, 0, min(len(searchResults), 15))
-	queryLower := strings.ToLower(query)
+	// Convert to UI results (limit to SearchResultLimit for split view)
+	gs.results = make([]*GlobalSearchResult, 0, min(len(searchResults), session.SearchResultLimit))
 	for i, sr := range searchResults {
-		if i >= 15 {
+		if i >= session.SearchResultLimit {
 			break
 		}
-		// Count occurrences of query in content (case-insensitive)
-		matchCount := strings.Count(strings.ToLower(sr.Entry.Content), queryLower)
 		gs.results = append(gs.results, &GlobalSearchResult{
 			SessionID:  sr.Entry.SessionID,
+			Tool:       sr.Entry.Tool,
 			Summary:    sr.Entry.Summary,
 			Snippet:    sr.Snippet,
 			Content:    sr.Entry.Content, // Full content for preview
 			CWD:        sr.Entry.CWD,
 			ModTime:    sr.Entry.ModTime,
 			Score:      sr.Score,
-			MatchCount: matchCount,
+			MatchCount: len(sr.Matches),
 		})
 	}
 
@@ -359,6 +360,7 @@ func (gs *GlobalSearch) View() string {
 			if result.InAgentDeck {
 				prefix = "• "
 			}
+			title = ToolIcon(result.Tool) + " " + title
 
 			if i == gs.cursor {
 				// Selected item - highlight
@@ -396,6 +398,11 @@ func (gs *GlobalSearch) View() string {
 			Foreground(ColorCyan).
 			Bold(true).
 			Render("📄 Preview")
+		if result.Tool != "" {
+			previewHeader += lipgloss.NewStyle().
+				Foreground(ColorComment).
+				Render(" · " + ToolIcon(result.Tool) + " " + result.Tool)
+		}
 		rightPane.WriteString(previewHeader + "\n")
 
 		// Show CWD
@@ -626,15 +633,15 @@ func (gs *GlobalSearch) highlightMatches(text, query string) string {
 
 // MarkInAgentDeck marks which results are already in Agent Deck
 func (gs *GlobalSearch) MarkInAgentDeck(instances []*session.Instance) {
-	idMap := make(map[string]string) // sessionID -> instanceID
+	idMap := make(map[string]string) // tool + sessionID -> instanceID
 	for _, inst := range instances {
-		if inst.ClaudeSessionID != "" {
-			idMap[inst.ClaudeSessionID] = inst.ID
+		if id := session.GetToolAdapter(inst.Tool).SessionID(inst); id != "" {
+			idMap[inst.Tool+":"+id] = inst.ID
 		}
 	}
 
 	for _, result := range gs.results {
-		if instID, ok := idMap[result.SessionID]; ok {
+		if instID, ok := idMap[result.Tool+":"+result.SessionID]; ok {
 			result.InAgentDeck = true
 			result.InstanceID = instID
 		}
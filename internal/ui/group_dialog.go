@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -29,6 +30,7 @@ type GroupDialog struct {
 	parentName string   // Display name of parent group (for subgroup creation)
 	groupNames []string // Available groups (for move)
 	selected   int      // Selected group index (for move)
+	moveCount  int      // Number of sessions being moved (for move)
 	sessionID  string   // Session ID being renamed (for rename session)
 }
 
@@ -80,6 +82,13 @@ func (g *GroupDialog) ShowMove(groups []string) {
 	g.mode = GroupDialogMove
 	g.groupNames = groups
 	g.selected = 0
+	g.moveCount = 1
+}
+
+// ShowMoveSessions shows the dialog for moving several sessions to a group
+func (g *GroupDialog) ShowMoveSessions(groups []string, count int) {
+	g.ShowMove(groups)
+	g.moveCount = count
 }
 
 // ShowRenameSession shows the dialog for renaming a session
@@ -224,6 +233,9 @@ func (g *GroupDialog) View() string {
 		content = g.nameInput.View()
 	case GroupDialogMove:
 		title = "Move to Group"
+		if g.moveCount > 1 {
+			title = fmt.Sprintf("Move %d Sessions to Group", g.moveCount)
+		}
 		var items []string
 		for i, name := range g.groupNames {
 			if i == g.selected {
@@ -86,8 +86,20 @@ func (h *HelpOverlay) View() string {
 				{"Shift+M", "MCP Manager (Claude)"},
 				{"u", "Mark unread"},
 				{"K / J", "Reorder up/down"},
-				{"f", "Quick fork (Claude only)"},
-				{"F", "Fork with options (Claude only)"},
+				{"f", "Quick fork"},
+				{"F", "Fork with options"},
+				{"v", "View transcript"},
+				{"x", "Stop session"},
+				{"s", "Send message"},
+			},
+		},
+		{
+			title: "MULTI-SELECT",
+			items: [][2]string{
+				{"Space", "Mark session / group"},
+				{"V", "Mark a range"},
+				{"Esc", "Clear marks"},
+				{"x R s M m d b", "Act on marked"},
 			},
 		},
 		{
@@ -96,6 +108,7 @@ func (h *HelpOverlay) View() string {
 				{"g", "New group"},
 				{"e", "Rename group"},
 				{"Tab", "Toggle expand"},
+				{"b", "Broadcast, collect replies"},
 			},
 		},
 		{
@@ -103,6 +116,7 @@ func (h *HelpOverlay) View() string {
 			items: [][2]string{
 				{"/", "Open search"},
 				{"/waiting", "Filter waiting"},
+				{"/approval", "Filter approval"},
 				{"/running", "Filter running"},
 				{"/idle", "Filter idle"},
 			},
@@ -111,6 +125,7 @@ func (h *HelpOverlay) View() string {
 			title: "OTHER",
 			items: [][2]string{
 				{"Ctrl+R", "Reload from disk"},
+				{"Ctrl+Z", "Undo last operation"},
 				{"i", "Import tmux sessions"},
 				{"Ctrl+Q", "Detach from session"},
 				{"q", "Quit"},
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
 	"sort"
@@ -55,6 +56,9 @@ const (
 	// logMaintenanceInterval - how often to do full log maintenance (orphan cleanup, etc)
 	// Prevents runaway log growth that can crash the system
 	logMaintenanceInterval = 5 * time.Minute
+
+	// broadcastTimeout - how long a broadcast from the TUI waits for replies
+	broadcastTimeout = 30 * time.Minute
 )
 
 // UI spacing constants (2-char grid system)
@@ -112,14 +116,24 @@ type Home struct {
 	confirmDialog *ConfirmDialog // For confirming destructive actions
 	helpOverlay   *HelpOverlay   // For showing keyboard shortcuts
 	mcpDialog     *MCPDialog     // For managing MCPs
+	transcriptViewer *TranscriptViewer // For reading a session's conversation
+	messageDialog *MessageDialog // For sending messages to sessions
+	broadcastView *BroadcastView // For a prompt's replies from many sessions
 
 	// State
 	cursor        int            // Selected item index in flatItems
 	viewOffset    int            // First visible item index (for scrolling)
 	isAttaching   atomic.Bool   // Prevents View() output during attach (fixes Bubble Tea Issue #431) - atomic for thread safety
 	statusFilter  session.Status // Filter sessions by status ("" = all, or specific status)
+	markedSessions map[string]bool // Session IDs marked for bulk actions (space, V)
+	rangeAnchor    int             // flatItems index a V range selection starts at (-1 if none)
+	mcpBulkIDs     []string        // Marked sessions the open MCP dialog's changes apply to
+	broadcastCancel context.CancelFunc // Stops waiting for a running broadcast's replies
+	broadcastResults <-chan session.BroadcastResult // The shown broadcast's replies
 	err           error
 	errTime       time.Time // When error occurred (for auto-dismiss)
+	info          string    // Outcome of the last action (e.g. undo), shown like errors
+	infoTime      time.Time // When info was set (for auto-dismiss)
 	isReloading    bool      // Visual feedback during auto-reload
 	initialLoading bool      // True until first loadSessionsMsg received (shows splash screen)
 	reloadVersion  uint64    // Incremented on each reload to prevent stale background saves
@@ -147,7 +161,8 @@ type Home struct {
 	statusWorkerDone chan struct{}            // Signals worker has stopped
 
 	// Event-driven status detection (Priority 2)
-	logWatcher *tmux.LogWatcher
+	logWatcher   *tmux.LogWatcher
+	statusEngine *tmux.StatusEngine // tmux control-mode events; replaces per-tick polling
 
 	// File watcher for external changes (auto-reload)
 	storageWatcher *StorageWatcher
@@ -183,9 +198,9 @@ type Home struct {
 
 	// Cached status counts (invalidated on instance changes)
 	cachedStatusCounts struct {
-		running, waiting, idle, errored int
-		valid                           bool
-		timestamp                       time.Time // For time-based expiration
+		running, waiting, approval, idle, errored int
+		valid                                     bool
+		timestamp                                 time.Time // For time-based expiration
 	}
 
 	// Reusable string builder for View() to reduce allocations
@@ -215,6 +230,16 @@ type sessionCreatedMsg struct {
 	err      error
 }
 
+type transcriptLoadedMsg struct {
+	transcript *session.Transcript
+	err        error
+}
+
+type operationUndoneMsg struct {
+	entries []*session.JournalEntry
+	err     error
+}
+
 type sessionForkedMsg struct {
 	instance *session.Instance
 	sourceID string // ID of the source session that was forked (for cleanup)
@@ -289,7 +314,12 @@ func NewHomeWithProfile(profile string) *Home {
 		confirmDialog:     NewConfirmDialog(),
 		helpOverlay:       NewHelpOverlay(),
 		mcpDialog:         NewMCPDialog(),
+		transcriptViewer:  NewTranscriptViewer(),
+		messageDialog:     NewMessageDialog(),
+		broadcastView:     NewBroadcastView(),
 		cursor:            0,
+		markedSessions:    make(map[string]bool),
+		rangeAnchor:       -1,
 		initialLoading:    true, // Show splash until sessions load
 		ctx:               ctx,
 		cancel:            cancel,
@@ -332,6 +362,25 @@ func NewHomeWithProfile(profile string) *Home {
 		go h.logWatcher.Start()
 	}
 
+	// Initialize control-mode status engine: one tmux client reports activity for
+	// all sessions, so ticks no longer spawn tmux to refresh the session cache
+	h.statusEngine = tmux.NewStatusEngine(func(sessionName string, activity int64) {
+		h.instancesMu.RLock()
+		for _, inst := range h.instances {
+			if inst.GetTmuxSession() != nil && inst.GetTmuxSession().Name == sessionName {
+				go func(i *session.Instance) {
+					if tmuxSess := i.GetTmuxSession(); tmuxSess != nil {
+						tmuxSess.ObserveActivity(activity)
+					}
+					_ = i.UpdateStatus()
+				}(inst)
+				break
+			}
+		}
+		h.instancesMu.RUnlock()
+	})
+	go h.statusEngine.Start()
+
 	// Start background status worker (Priority 1C)
 	go h.statusWorker()
 
@@ -740,6 +789,12 @@ func (h *Home) clearError() {
 	h.errTime = time.Time{}
 }
 
+// setInfo shows what the last action did, until it is auto-dismissed
+func (h *Home) setInfo(info string) {
+	h.info = info
+	h.infoTime = time.Now()
+}
+
 // cleanupExpiredAnimations removes expired entries from an animation map
 // Returns list of IDs that were removed (for logging/debugging if needed)
 func (h *Home) cleanupExpiredAnimations(animMap map[string]time.Time, claudeTimeout, defaultTimeout time.Duration) []string {
@@ -752,9 +807,9 @@ func (h *Home) cleanupExpiredAnimations(animMap map[string]time.Time, claudeTime
 			continue
 		}
 		// Use appropriate timeout based on tool
-		// Claude and Gemini use longer timeout (MCP loading can be slow)
+		// Tools with MCPs (Claude, Gemini) use longer timeout (MCP loading can be slow)
 		timeout := defaultTimeout
-		if inst.Tool == "claude" || inst.Tool == "gemini" {
+		if inst.SupportsMCP() {
 			timeout = claudeTimeout
 		}
 		if time.Since(startTime) > timeout {
@@ -803,11 +858,11 @@ func (h *Home) hasActiveAnimation(sessionID string) bool {
 	}
 
 	// MUST match renderPreviewPane display logic exactly:
-	// - Claude and Gemini: 6s minimum, then check if ready, up to 15s total
+	// - Tools with MCPs (Claude, Gemini): 6s minimum, then check if ready, up to 15s total
 	// - Others: 3s fixed
 	timeSinceStart := time.Since(startTime)
 
-	if inst.Tool == "claude" || inst.Tool == "gemini" {
+	if inst.SupportsMCP() {
 		minAnimationTime := 6 * time.Second
 		maxAnimationTime := 15 * time.Second
 
@@ -827,14 +882,10 @@ func (h *Home) hasActiveAnimation(sessionID string) bool {
 				strings.Contains(previewContent, "> \n") ||
 				strings.Contains(previewContent, "esc to interrupt") ||
 				strings.Contains(previewContent, "⠋") || strings.Contains(previewContent, "⠙") ||
-				strings.Contains(previewContent, "Thinking")
-
-			// Gemini prompts (triangular prompt indicator)
-			if inst.Tool == "gemini" {
-				agentReady = agentReady ||
-					strings.Contains(previewContent, "▸") ||
-					strings.Contains(previewContent, "gemini>")
-			}
+				strings.Contains(previewContent, "Thinking") ||
+				// Gemini prompts (triangular prompt indicator)
+				strings.Contains(previewContent, "▸") ||
+				strings.Contains(previewContent, "gemini>")
 
 			// If agent not ready, animation is still showing (and should block)
 			// If agent IS ready, animation stops (and should allow attachment)
@@ -846,7 +897,7 @@ func (h *Home) hasActiveAnimation(sessionID string) bool {
 		return false
 	}
 
-	// Non-Claude/Gemini: block for 3 seconds
+	// Tools without MCPs: block for 3 seconds
 	if timeSinceStart < 3*time.Second {
 		return true
 	}
@@ -910,6 +961,123 @@ func (h *Home) getInstanceByID(id string) *session.Instance {
 	return h.instanceByID[id]
 }
 
+// isMarked returns true if the session at flatItems index is marked for bulk
+// actions, or inside the V range being selected
+func (h *Home) isMarked(id string, index int) bool {
+	if h.markedSessions[id] {
+		return true
+	}
+	if h.rangeAnchor < 0 {
+		return false
+	}
+	lo, hi := h.rangeAnchor, h.cursor
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return index >= lo && index <= hi
+}
+
+// toggleMark marks the session under the cursor, or every session in the
+// group under the cursor (and its subgroups), or unmarks them if they all are
+func (h *Home) toggleMark() {
+	if h.cursor >= len(h.flatItems) {
+		return
+	}
+	item := h.flatItems[h.cursor]
+	var ids []string
+	if item.Type == session.ItemTypeSession && item.Session != nil {
+		ids = append(ids, item.Session.ID)
+	} else if item.Type == session.ItemTypeGroup {
+		for _, inst := range h.instances {
+			if inst.GroupPath != item.Path && !strings.HasPrefix(inst.GroupPath, item.Path+"/") {
+				continue
+			}
+			if h.statusFilter == "" || inst.Status == h.statusFilter {
+				ids = append(ids, inst.ID)
+			}
+		}
+	}
+
+	allMarked := len(ids) > 0
+	for _, id := range ids {
+		if !h.markedSessions[id] {
+			allMarked = false
+			break
+		}
+	}
+	for _, id := range ids {
+		if allMarked {
+			delete(h.markedSessions, id)
+		} else {
+			h.markedSessions[id] = true
+		}
+	}
+}
+
+// toggleRange starts a V range selection at the cursor, or marks the sessions
+// between its start and the cursor
+func (h *Home) toggleRange() {
+	if h.rangeAnchor < 0 {
+		h.rangeAnchor = h.cursor
+		return
+	}
+	for i, item := range h.flatItems {
+		if item.Type == session.ItemTypeSession && item.Session != nil && h.isMarked(item.Session.ID, i) {
+			h.markedSessions[item.Session.ID] = true
+		}
+	}
+	h.rangeAnchor = -1
+}
+
+// clearMarks unmarks all sessions and cancels a V range selection
+func (h *Home) clearMarks() {
+	h.markedSessions = make(map[string]bool)
+	h.rangeAnchor = -1
+}
+
+// markedInstances returns the marked sessions that still exist, in list order
+func (h *Home) markedInstances() []*session.Instance {
+	var marked []*session.Instance
+	for _, inst := range h.instances {
+		if h.markedSessions[inst.ID] {
+			marked = append(marked, inst)
+		}
+	}
+	return marked
+}
+
+// bulkTargets returns the sessions a bulk action applies to: the marked ones,
+// or else the session under the cursor
+func (h *Home) bulkTargets() []*session.Instance {
+	if marked := h.markedInstances(); len(marked) > 0 {
+		return marked
+	}
+	if selected := h.getSelectedSession(); selected != nil {
+		return []*session.Instance{selected}
+	}
+	return nil
+}
+
+// runningInstances returns the sessions among insts whose tmux session exists
+func runningInstances(insts []*session.Instance) []*session.Instance {
+	var running []*session.Instance
+	for _, inst := range insts {
+		if inst.Exists() {
+			running = append(running, inst)
+		}
+	}
+	return running
+}
+
+// instanceIDsAndTitles returns the IDs and titles of insts
+func instanceIDsAndTitles(insts []*session.Instance) (ids, titles []string) {
+	for _, inst := range insts {
+		ids = append(ids, inst.ID)
+		titles = append(titles, inst.Title)
+	}
+	return ids, titles
+}
+
 // statusWorker runs in a background goroutine (Priority 1C)
 // It receives status update requests and processes them without blocking the UI
 func (h *Home) statusWorker() {
@@ -1128,6 +1296,55 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return h, nil
 
+	case operationUndoneMsg:
+		if msg.err != nil {
+			h.setError(fmt.Errorf("undo: %w", msg.err))
+		} else if len(msg.entries) > 0 {
+			h.clearError()
+			h.setInfo(fmt.Sprintf("Undone: %s (ctrl+z again to undo more)", msg.entries[0].Summary()))
+		}
+		if len(msg.entries) == 0 {
+			return h, nil
+		}
+
+		// Sessions removed by undoing their creation are stopped like deleted ones
+		var stop []*session.Instance
+		for _, entry := range msg.entries {
+			for _, c := range entry.Changes {
+				if c.IsGroup() || c.Op != session.JournalCreate {
+					continue
+				}
+				if inst := h.getInstanceByID(c.After.ID); inst != nil {
+					stop = append(stop, inst)
+				}
+			}
+		}
+		state := h.preserveState()
+		reload := func() tea.Msg {
+			for _, inst := range stop {
+				if inst.Exists() {
+					_ = inst.Kill()
+				}
+			}
+			instances, groups, err := h.storage.LoadWithGroups()
+			return loadSessionsMsg{
+				instances:    instances,
+				groups:       groups,
+				err:          err,
+				restoreState: &state,
+			}
+		}
+		return h, reload
+
+	case transcriptLoadedMsg:
+		if msg.err != nil {
+			h.setError(msg.err)
+			return h, nil
+		}
+		h.transcriptViewer.SetSize(h.width, h.height)
+		h.transcriptViewer.Show(msg.transcript)
+		return h, nil
+
 	case sessionCreatedMsg:
 		// CRITICAL FIX: Skip processing during reload to prevent state corruption
 		// If we modify h.instances during reload, the loadSessionsMsg will overwrite
@@ -1241,36 +1458,67 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return h, nil
 		}
 
+		if msg.worktreeErr != nil {
+			h.setError(fmt.Errorf("session kept: %w", msg.worktreeErr))
+			return h, nil
+		}
+
 		// Report kill error if any (session may still be running in tmux)
 		if msg.killErr != nil {
 			h.setError(fmt.Errorf("warning: tmux session may still be running: %w", msg.killErr))
 		}
 
-		// Find and remove from list
-		var deletedInstance *session.Instance
-		h.instancesMu.Lock()
-		for i, s := range h.instances {
-			if s.ID == msg.deletedID {
-				deletedInstance = s
-				h.instances = append(h.instances[:i], h.instances[i+1:]...)
-				break
-			}
+		h.removeSessions([]string{msg.deletedID})
+		return h, nil
+
+	case sessionsDeletedMsg:
+		// Skip processing during reload to prevent state corruption
+		if h.isReloading {
+			log.Printf("[RELOAD-DEBUG] sessionsDeletedMsg: skipping during reload")
+			return h, nil
 		}
-		delete(h.instanceByID, msg.deletedID)
-		h.instancesMu.Unlock()
-		// Invalidate status counts cache
-		h.cachedStatusCounts.valid = false
-		// Invalidate preview cache for deleted session
-		h.invalidatePreviewCache(msg.deletedID)
-		// Remove from group tree (preserves empty groups)
-		if deletedInstance != nil {
-			h.groupTree.RemoveSession(deletedInstance)
+		if len(msg.worktreeErrs) > 0 {
+			h.setError(fmt.Errorf("%s kept: %w", sessionCount(len(msg.worktreeErrs)), msg.worktreeErrs[0]))
+		} else if msg.killErrs > 0 {
+			h.setError(fmt.Errorf("warning: tmux sessions of %s may still be running", sessionCount(msg.killErrs)))
 		}
-		h.rebuildFlatItems()
-		// Update search items
-		h.search.SetItems(h.instances)
-		// Save both instances AND groups (critical fix: was losing groups!)
+		// One save for all of them, so ctrl+z undoes the whole delete
+		h.removeSessions(msg.deletedIDs)
+		return h, nil
+
+	case sessionsStoppedMsg:
 		h.saveInstances()
+		if len(msg.errs) > 0 {
+			h.setError(fmt.Errorf("stopped %s, %d failed: %w", sessionCount(msg.stopped), len(msg.errs), msg.errs[0]))
+		} else {
+			h.setError(fmt.Errorf("stopped %s", sessionCount(msg.stopped)))
+		}
+		return h, nil
+
+	case broadcastResultMsg:
+		// Replies of an earlier, cancelled broadcast are dropped
+		if msg.results == h.broadcastResults {
+			h.broadcastView.AddResult(msg.result)
+		}
+		return h, listenBroadcast(msg.results)
+
+	case broadcastDoneMsg:
+		if msg.results != h.broadcastResults {
+			return h, nil
+		}
+		h.broadcastView.Finish()
+		if h.broadcastCancel != nil {
+			h.broadcastCancel()
+			h.broadcastCancel = nil
+		}
+		return h, nil
+
+	case messagesSentMsg:
+		if len(msg.errs) > 0 {
+			h.setError(fmt.Errorf("sent to %s, %d failed: %w", sessionCount(msg.sent), len(msg.errs), msg.errs[0]))
+		} else {
+			h.setError(fmt.Errorf("sent to %s", sessionCount(msg.sent)))
+		}
 		return h, nil
 
 	case sessionRestartedMsg:
@@ -1414,6 +1662,9 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if h.err != nil && !h.errTime.IsZero() && time.Since(h.errTime) > 5*time.Second {
 			h.clearError()
 		}
+		if h.info != "" && time.Since(h.infoTime) > 5*time.Second {
+			h.info = ""
+		}
 
 		// PERFORMANCE: Detect when navigation has settled (300ms since last up/down)
 		// This allows background updates to resume after rapid navigation stops
@@ -1526,6 +1777,16 @@ func (h *Home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if h.mcpDialog.IsVisible() {
 			return h.handleMCPDialogKey(msg)
 		}
+		if h.transcriptViewer.IsVisible() {
+			h.transcriptViewer, _ = h.transcriptViewer.Update(msg)
+			return h, nil
+		}
+		if h.messageDialog.IsVisible() {
+			return h.handleMessageDialogKey(msg)
+		}
+		if h.broadcastView.IsVisible() {
+			return h.handleBroadcastViewKey(msg)
+		}
 
 		// Main view keys
 		return h.handleMainKey(msg)
@@ -1607,7 +1868,7 @@ func (h *Home) handleGlobalSearchSelection(result *GlobalSearchResult) tea.Cmd {
 	// Check if session already exists in Agent Deck
 	h.instancesMu.RLock()
 	for _, inst := range h.instances {
-		if inst.ClaudeSessionID == result.SessionID {
+		if inst.Tool == result.Tool && session.GetToolAdapter(inst.Tool).SessionID(inst) == result.SessionID {
 			h.instancesMu.RUnlock()
 			// Jump to existing session
 			h.jumpToSession(inst)
@@ -1616,7 +1877,7 @@ func (h *Home) handleGlobalSearchSelection(result *GlobalSearchResult) tea.Cmd {
 	}
 	h.instancesMu.RUnlock()
 
-	// Create new session with this Claude session ID
+	// Create a new session resuming the conversation
 	return h.createSessionFromGlobalSearch(result)
 }
 
@@ -1638,11 +1899,17 @@ func (h *Home) jumpToSession(inst *session.Instance) {
 	}
 }
 
-// createSessionFromGlobalSearch creates a new Agent Deck session from global search result
+// createSessionFromGlobalSearch creates a new Agent Deck session resuming the
+// conversation of a global search result, in the tool that wrote it
 func (h *Home) createSessionFromGlobalSearch(result *GlobalSearchResult) tea.Cmd {
 	return func() tea.Msg {
-		// Derive title from CWD or session ID
-		title := "Claude Session"
+		tool := result.Tool
+		if tool == "" {
+			tool = "claude"
+		}
+
+		// Derive title from CWD or tool
+		title := strings.ToUpper(tool[:1]) + tool[1:] + " Session"
 		projectPath := result.CWD
 		if result.CWD != "" {
 			parts := strings.Split(result.CWD, "/")
@@ -1651,39 +1918,19 @@ func (h *Home) createSessionFromGlobalSearch(result *GlobalSearchResult) tea.Cmd
 			}
 		}
 		if projectPath == "" {
+			// Gemini only finds a conversation from its project directory
+			if tool == "gemini" {
+				return sessionCreatedMsg{err: fmt.Errorf("cannot resume Gemini conversation: project directory unknown")}
+			}
 			projectPath = "."
 		}
 
-		// Create instance
-		inst := session.NewInstanceWithGroupAndTool(title, projectPath, h.getCurrentGroupPath(), "claude")
-		inst.ClaudeSessionID = result.SessionID
-
-		// Build resume command with config dir and dangerous mode
-		userConfig, _ := session.LoadUserConfig()
-		dangerousMode := false
-		configDir := ""
-		if userConfig != nil {
-			dangerousMode = userConfig.Claude.DangerousMode
-			configDir = userConfig.Claude.ConfigDir
-		}
-
-		// Build command - use CLAUDE_CONFIG_DIR env var (not CLI flag)
-		var cmdBuilder strings.Builder
-		if configDir != "" {
-			// Expand ~ to home directory
-			if strings.HasPrefix(configDir, "~") {
-				home, _ := os.UserHomeDir()
-				configDir = strings.Replace(configDir, "~", home, 1)
-			}
-			// Set env var before running claude
-			cmdBuilder.WriteString(fmt.Sprintf("CLAUDE_CONFIG_DIR=%s ", configDir))
-		}
-		cmdBuilder.WriteString("claude --resume ")
-		cmdBuilder.WriteString(result.SessionID)
-		if dangerousMode {
-			cmdBuilder.WriteString(" --dangerously-skip-permissions")
+		// Create instance resuming the conversation (with the tool's config
+		// dir and flags)
+		inst := session.NewInstanceWithGroupAndTool(title, projectPath, h.getCurrentGroupPath(), tool)
+		if err := inst.ResumeConversation(result.SessionID); err != nil {
+			return sessionCreatedMsg{err: err}
 		}
-		inst.Command = cmdBuilder.String()
 
 		// Start the session
 		if err := inst.Start(); err != nil {
@@ -1694,6 +1941,17 @@ func (h *Home) createSessionFromGlobalSearch(result *GlobalSearchResult) tea.Cmd
 	}
 }
 
+// loadTranscript reads a session's conversation for the transcript viewer
+func loadTranscript(inst *session.Instance) tea.Cmd {
+	return func() tea.Msg {
+		transcript, err := inst.GetTranscript()
+		if err != nil {
+			return transcriptLoadedMsg{err: fmt.Errorf("cannot show transcript: %w", err)}
+		}
+		return transcriptLoadedMsg{transcript: transcript}
+	}
+}
+
 // getCurrentGroupPath returns the group path of the currently selected item
 func (h *Home) getCurrentGroupPath() string {
 	if h.cursor >= 0 && h.cursor < len(h.flatItems) {
@@ -1721,9 +1979,12 @@ func (h *Home) handleNewDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Create session (enter works from any field)
 		name, path, command := h.newDialog.GetValues()
 		groupPath := h.newDialog.GetSelectedGroup()
+		worktree := h.newDialog.UseWorktree()
+		claudeOpts, _ := h.newDialog.GetClaudeOptions() // Checked by Validate
+		tmpl := h.newDialog.GetTemplate()
 		h.newDialog.Hide()
 		h.clearError() // Clear any previous validation error
-		return h, h.createSessionInGroup(name, path, command, groupPath)
+		return h, h.createSessionInGroup(name, path, command, groupPath, worktree, claudeOpts, tmpl)
 
 	case "esc":
 		h.newDialog.Hide()
@@ -1746,6 +2007,9 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if h.logWatcher != nil {
 			h.logWatcher.Close()
 		}
+		if h.statusEngine != nil {
+			h.statusEngine.Close()
+		}
 		// Close storage watcher
 		if h.storageWatcher != nil {
 			h.storageWatcher.Close()
@@ -1881,7 +2145,11 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return h, nil
 
 	case "m":
-		// Move session to different group
+		// Move marked sessions, or the session, to different group
+		if marked := h.markedInstances(); len(marked) > 0 {
+			h.groupDialog.ShowMoveSessions(h.groupTree.GetGroupNames(), len(marked))
+			return h, nil
+		}
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
 			if item.Type == session.ItemTypeSession {
@@ -1892,7 +2160,7 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "f":
 		// Quick fork session (same title with " (fork)" suffix)
-		// Only available when session has a valid Claude session ID
+		// Only available when the session can be forked (see Instance.CanFork)
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
 			if item.Type == session.ItemTypeSession && item.Session != nil && item.Session.CanFork() {
@@ -1903,7 +2171,7 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "F", "shift+f":
 		// Fork with dialog (customize title and group)
-		// Only available when session has a valid Claude session ID
+		// Only available when the session can be forked (see Instance.CanFork)
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
 			if item.Type == session.ItemTypeSession && item.Session != nil && item.Session.CanFork() {
@@ -1913,11 +2181,33 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return h, nil
 
 	case "M", "shift+m":
-		// MCP Manager - for Claude and Gemini sessions
+		// MCP Manager - for tools whose MCPs agent-deck manages (Claude, Gemini)
+		h.mcpBulkIDs = nil
+		if marked := h.markedInstances(); len(marked) > 0 {
+			// Changes go to every marked session of the first one's tool
+			var first *session.Instance
+			for _, inst := range marked {
+				if !inst.SupportsMCP() {
+					continue
+				}
+				if first == nil {
+					first = inst
+				}
+				if inst.Tool == first.Tool {
+					h.mcpBulkIDs = append(h.mcpBulkIDs, inst.ID)
+				}
+			}
+			if first != nil {
+				h.mcpDialog.SetSize(h.width, h.height)
+				if err := h.mcpDialog.Show(first.ProjectPath, first.ID, first.Tool); err != nil {
+					h.setError(err)
+				}
+			}
+			return h, nil
+		}
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
-			if item.Type == session.ItemTypeSession && item.Session != nil &&
-				(item.Session.Tool == "claude" || item.Session.Tool == "gemini") {
+			if item.Type == session.ItemTypeSession && item.Session != nil && item.Session.SupportsMCP() {
 				h.mcpDialog.SetSize(h.width, h.height)
 				if err := h.mcpDialog.Show(item.Session.ProjectPath, item.Session.ID, item.Session.Tool); err != nil {
 					h.setError(err)
@@ -1926,6 +2216,13 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return h, nil
 
+	case "v":
+		// View the selected session's conversation
+		if selected := h.getSelectedSession(); selected != nil {
+			return h, loadTranscript(selected)
+		}
+		return h, nil
+
 	case "g":
 		// Create new group (or subgroup if a group is selected)
 		if h.cursor < len(h.flatItems) {
@@ -2045,10 +2342,25 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "d":
 		// Show confirmation dialog before deletion (prevents accidental deletion)
+		if marked := h.markedInstances(); len(marked) > 0 {
+			ids, titles := instanceIDsAndTitles(marked)
+			var worktrees []string
+			for _, inst := range marked {
+				if inst.HasWorktree() {
+					worktrees = append(worktrees, inst.WorktreePath)
+				}
+			}
+			h.confirmDialog.ShowDeleteSessions(ids, titles, len(runningInstances(marked)), worktrees)
+			return h, nil
+		}
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
 			if item.Type == session.ItemTypeSession && item.Session != nil {
-				h.confirmDialog.ShowDeleteSession(item.Session.ID, item.Session.Title)
+				if item.Session.HasWorktree() {
+					h.confirmDialog.ShowDeleteSessionWithWorktree(item.Session.ID, item.Session.Title, item.Session.WorktreePath)
+				} else {
+					h.confirmDialog.ShowDeleteSession(item.Session.ID, item.Session.Title)
+				}
 			} else if item.Type == session.ItemTypeGroup && item.Path != session.DefaultGroupPath {
 				h.confirmDialog.ShowDeleteGroup(item.Path, item.Group.Name)
 			}
@@ -2074,6 +2386,22 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return h, nil
 
 	case "R":
+		// Restart marked sessions after confirming
+		if marked := h.markedInstances(); len(marked) > 0 {
+			var restartable []*session.Instance
+			for _, inst := range marked {
+				if inst.CanRestart() {
+					restartable = append(restartable, inst)
+				}
+			}
+			if len(restartable) == 0 {
+				h.setError(fmt.Errorf("none of the marked sessions can be restarted"))
+				return h, nil
+			}
+			ids, titles := instanceIDsAndTitles(restartable)
+			h.confirmDialog.ShowSessions(ConfirmRestartSessions, ids, titles, len(runningInstances(restartable)))
+			return h, nil
+		}
 		// Restart session (Shift+R - recreate tmux session with resume)
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
@@ -2087,6 +2415,81 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return h, nil
 
+	case " ":
+		// Mark session (or all sessions in group) for bulk actions
+		h.toggleMark()
+		return h, nil
+
+	case "V", "shift+v":
+		// Start a range selection, or mark the range
+		h.toggleRange()
+		return h, nil
+
+	case "esc":
+		// Clear marks and range selection
+		h.clearMarks()
+		return h, nil
+
+	case "x":
+		// Stop marked sessions (or the selected one) after confirming
+		running := runningInstances(h.bulkTargets())
+		if len(running) == 0 {
+			h.setError(fmt.Errorf("no running sessions to stop"))
+			return h, nil
+		}
+		ids, titles := instanceIDsAndTitles(running)
+		h.confirmDialog.ShowSessions(ConfirmStopSessions, ids, titles, len(running))
+		return h, nil
+
+	case "s":
+		// Send a message to marked sessions (or the selected one)
+		running := runningInstances(h.bulkTargets())
+		if len(running) == 0 {
+			h.setError(fmt.Errorf("no running sessions to send to"))
+			return h, nil
+		}
+		ids, titles := instanceIDsAndTitles(running)
+		h.messageDialog.SetSize(h.width, h.height)
+		h.messageDialog.Show(ids, sendTargetName(titles))
+		return h, nil
+
+	case "b":
+		// Broadcast a prompt to the marked sessions, or the group's, and
+		// collect the replies
+		var targets []*session.Instance
+		target := ""
+		if marked := h.markedInstances(); len(marked) > 0 {
+			targets = marked
+			target = sessionCount(len(marked))
+		} else if h.cursor < len(h.flatItems) && h.flatItems[h.cursor].Type == session.ItemTypeGroup {
+			item := h.flatItems[h.cursor]
+			for _, inst := range h.instances {
+				if inst.GroupPath == item.Path || strings.HasPrefix(inst.GroupPath, item.Path+"/") {
+					targets = append(targets, inst)
+				}
+			}
+			if item.Group != nil {
+				target = item.Group.Name
+			}
+		}
+		running := runningInstances(targets)
+		if len(running) == 0 {
+			h.setError(fmt.Errorf("select a group (or mark sessions) with running sessions to broadcast to"))
+			return h, nil
+		}
+		ids, _ := instanceIDsAndTitles(running)
+		h.messageDialog.SetSize(h.width, h.height)
+		h.messageDialog.ShowBroadcast(ids, target)
+		return h, nil
+
+	case "ctrl+z":
+		// Undo the last operation in the journal (from here or the CLI)
+		storage := h.storage
+		return h, func() tea.Msg {
+			entries, err := storage.Undo(1)
+			return operationUndoneMsg{entries: entries, err: err}
+		}
+
 	case "ctrl+r":
 		// Manual refresh (useful if watcher fails or for user preference)
 		state := h.preserveState()
@@ -2154,6 +2557,16 @@ func (h *Home) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		h.rebuildFlatItems()
 		return h, nil
+
+	case "%", "shift+5":
+		// Filter to sessions blocked on a permission prompt
+		if h.statusFilter == session.StatusApproval {
+			h.statusFilter = "" // Toggle off
+		} else {
+			h.statusFilter = session.StatusApproval
+		}
+		h.rebuildFlatItems()
+		return h, nil
 	}
 
 	return h, nil
@@ -2169,7 +2582,7 @@ func (h *Home) handleConfirmDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			sessionID := h.confirmDialog.GetTargetID()
 			if inst := h.getInstanceByID(sessionID); inst != nil {
 				h.confirmDialog.Hide()
-				return h, h.deleteSession(inst)
+				return h, h.deleteSession(inst, false)
 			}
 		case ConfirmDeleteGroup:
 			groupPath := h.confirmDialog.GetTargetID()
@@ -2179,10 +2592,54 @@ func (h *Home) handleConfirmDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			h.instancesMu.Unlock()
 			h.rebuildFlatItems()
 			h.saveInstances()
+		case ConfirmDeleteSessions, ConfirmStopSessions, ConfirmRestartSessions:
+			var insts []*session.Instance
+			for _, id := range h.confirmDialog.GetTargetIDs() {
+				if inst := h.getInstanceByID(id); inst != nil {
+					insts = append(insts, inst)
+				}
+			}
+			confirmType := h.confirmDialog.GetConfirmType()
+			h.confirmDialog.Hide()
+			switch confirmType {
+			case ConfirmDeleteSessions:
+				return h, h.deleteSessions(insts, false)
+			case ConfirmStopSessions:
+				return h, h.stopSessions(insts)
+			default:
+				var cmds []tea.Cmd
+				for _, inst := range insts {
+					// Track as resuming for animation (before async call starts)
+					h.resumingSessions[inst.ID] = time.Now()
+					cmds = append(cmds, h.restartSession(inst))
+				}
+				return h, tea.Batch(cmds...)
+			}
 		}
 		h.confirmDialog.Hide()
 		return h, nil
 
+	case "w", "W":
+		// Delete the sessions and their git worktrees
+		if h.confirmDialog.GetConfirmType() == ConfirmDeleteSession && h.confirmDialog.GetWorktree() != "" {
+			sessionID := h.confirmDialog.GetTargetID()
+			h.confirmDialog.Hide()
+			if inst := h.getInstanceByID(sessionID); inst != nil {
+				return h, h.deleteSession(inst, true)
+			}
+		}
+		if h.confirmDialog.GetConfirmType() == ConfirmDeleteSessions && len(h.confirmDialog.GetWorktrees()) > 0 {
+			var insts []*session.Instance
+			for _, id := range h.confirmDialog.GetTargetIDs() {
+				if inst := h.getInstanceByID(id); inst != nil {
+					insts = append(insts, inst)
+				}
+			}
+			h.confirmDialog.Hide()
+			return h, h.deleteSessions(insts, true)
+		}
+		return h, nil
+
 	case "n", "N", "esc":
 		// User cancelled
 		h.confirmDialog.Hide()
@@ -2213,6 +2670,18 @@ func (h *Home) handleMCPDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			log.Printf("[MCP-DEBUG] Apply() succeeded")
 
+			// Marked sessions: apply the same change to the others and restart them all
+			if len(h.mcpBulkIDs) > 1 {
+				targets := h.applyMCPToMarked()
+				h.mcpDialog.Hide()
+				var cmds []tea.Cmd
+				for _, inst := range targets {
+					h.mcpLoadingSessions[inst.ID] = time.Now()
+					cmds = append(cmds, h.restartSession(inst))
+				}
+				return h, tea.Batch(cmds...)
+			}
+
 			// Find the session by ID (stored when dialog opened - same as Shift+S uses)
 			sessionID := h.mcpDialog.GetSessionID()
 			log.Printf("[MCP-DEBUG] Looking for sessionID: %q", sessionID)
@@ -2248,6 +2717,82 @@ func (h *Home) handleMCPDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// applyMCPToMarked applies the MCP dialog's LOCAL attach/detach changes to the
+// projects of the other marked sessions (GLOBAL changes apply to all already)
+// and returns the marked sessions to restart
+func (h *Home) applyMCPToMarked() []*session.Instance {
+	attached, detached := h.mcpDialog.LocalDelta()
+	done := map[string]bool{h.mcpDialog.GetProjectPath(): true}
+	var targets []*session.Instance
+	for _, id := range h.mcpBulkIDs {
+		inst := h.getInstanceByID(id)
+		if inst == nil {
+			continue
+		}
+		targets = append(targets, inst)
+		if h.mcpDialog.GlobalOnly() || done[inst.ProjectPath] || len(attached)+len(detached) == 0 {
+			continue
+		}
+		done[inst.ProjectPath] = true
+
+		names := make(map[string]bool)
+		for _, name := range session.GetMCPInfo(inst.ProjectPath).Local() {
+			names[name] = true
+		}
+		for _, name := range attached {
+			names[name] = true
+		}
+		for _, name := range detached {
+			delete(names, name)
+		}
+		enabled := make([]string, 0, len(names))
+		for name := range names {
+			enabled = append(enabled, name)
+		}
+		sort.Strings(enabled)
+		if err := session.WriteMCPJsonFromConfig(inst.ProjectPath, enabled); err != nil {
+			h.setError(fmt.Errorf("%s: %w", inst.Title, err))
+			continue
+		}
+		session.ClearMCPCache(inst.ProjectPath)
+	}
+	return targets
+}
+
+// handleMessageDialogKey handles keys when the message dialog is visible
+func (h *Home) handleMessageDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		message := h.messageDialog.GetValue()
+		if message == "" {
+			h.setError(fmt.Errorf("message cannot be empty"))
+			return h, nil
+		}
+		var insts []*session.Instance
+		for _, id := range h.messageDialog.GetSessionIDs() {
+			if inst := h.getInstanceByID(id); inst != nil {
+				insts = append(insts, inst)
+			}
+		}
+		broadcast := h.messageDialog.IsBroadcast()
+		target := h.messageDialog.targetName
+		h.messageDialog.Hide()
+		h.clearError()
+		if broadcast {
+			return h, h.startBroadcast(insts, target, message)
+		}
+		return h, h.sendMessages(insts, message)
+	case "esc":
+		h.messageDialog.Hide()
+		h.clearError()
+		return h, nil
+	}
+
+	var cmd tea.Cmd
+	h.messageDialog, cmd = h.messageDialog.Update(msg)
+	return h, cmd
+}
+
 // handleGroupDialogKey handles keys when group dialog is visible
 func (h *Home) handleGroupDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -2286,7 +2831,21 @@ func (h *Home) handleGroupDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		case GroupDialogMove:
 			groupName := h.groupDialog.GetSelectedGroup()
-			if groupName != "" && h.cursor < len(h.flatItems) {
+			if marked := h.markedInstances(); groupName != "" && len(marked) > 0 {
+				for _, g := range h.groupTree.GroupList {
+					if g.Name == groupName {
+						for _, inst := range marked {
+							h.groupTree.MoveSessionToGroup(inst, g.Path)
+						}
+						h.instancesMu.Lock()
+						h.instances = h.groupTree.GetAllInstances()
+						h.instancesMu.Unlock()
+						h.rebuildFlatItems()
+						h.saveInstances()
+						break
+					}
+				}
+			} else if groupName != "" && h.cursor < len(h.flatItems) {
 				item := h.flatItems[h.cursor]
 				if item.Type == session.ItemTypeSession {
 					// Find the group path from name
@@ -2340,14 +2899,20 @@ func (h *Home) handleForkDialogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			h.setError(fmt.Errorf("session name cannot be empty"))
 			return h, nil
 		}
+		claudeOpts, err := h.forkDialog.GetClaudeOptions()
+		if err != nil {
+			h.setError(err)
+			return h, nil
+		}
 		h.clearError() // Clear any previous error
 
 		// Find the currently selected session
 		if h.cursor < len(h.flatItems) {
 			item := h.flatItems[h.cursor]
 			if item.Type == session.ItemTypeSession && item.Session != nil {
+				opts := session.ForkOptions{Worktree: h.forkDialog.UseWorktree(), Claude: claudeOpts}
 				h.forkDialog.Hide()
-				return h, h.forkSessionCmd(item.Session, title, groupPath)
+				return h, h.forkSessionCmd(item.Session, title, groupPath, opts)
 			}
 		}
 		h.forkDialog.Hide()
@@ -2436,8 +3001,11 @@ func (h *Home) getUsedClaudeSessionIDs() map[string]bool {
 	return usedIDs
 }
 
-// createSessionInGroup creates a new session in a specific group
-func (h *Home) createSessionInGroup(name, path, command, groupPath string) tea.Cmd {
+// createSessionInGroup creates a new session in a specific group, optionally
+// in a new git worktree of path's repository
+// tmpl, when set, adds the template's environment, MCPs and initial prompt
+// (the dialog already applied its command, group and Claude options).
+func (h *Home) createSessionInGroup(name, path, command, groupPath string, worktree bool, claudeOpts *session.ClaudeOptions, tmpl *session.TemplateDef) tea.Cmd {
 	return func() tea.Msg {
 		// Check tmux availability before creating session
 		if err := tmux.IsTmuxAvailable(); err != nil {
@@ -2446,17 +3014,7 @@ func (h *Home) createSessionInGroup(name, path, command, groupPath string) tea.C
 
 		// Determine tool from command for proper session initialization
 		// When tool is "claude", session ID will be detected from files after start
-		tool := "shell"
-		switch command {
-		case "claude":
-			tool = "claude"
-		case "gemini":
-			tool = "gemini"
-		case "aider":
-			tool = "aider"
-		case "codex":
-			tool = "codex"
-		}
+		tool := session.DetectToolFromCommand(command)
 
 		var inst *session.Instance
 		if groupPath != "" {
@@ -2465,6 +3023,29 @@ func (h *Home) createSessionInGroup(name, path, command, groupPath string) tea.C
 			inst = session.NewInstanceWithTool(name, path, tool)
 		}
 		inst.Command = command
+		inst.ClaudeOptions = claudeOpts // nil unless Claude was picked
+		if worktree {
+			if err := inst.SetupWorktree(""); err != nil {
+				return sessionCreatedMsg{err: err}
+			}
+		}
+		var prompt string
+		if tmpl != nil {
+			inst.Env = maps.Clone(tmpl.Env)
+			prompt = inst.ExpandTemplatePrompt(tmpl.Prompt)
+			if len(tmpl.MCPs) > 0 {
+				if err := session.WriteMCPJsonFromConfig(inst.ProjectPath, tmpl.MCPs); err != nil {
+					return sessionCreatedMsg{err: fmt.Errorf("failed to attach template MCPs: %w", err)}
+				}
+			}
+		}
+		if prompt != "" {
+			// Waits for the agent to be ready to send the prompt
+			if err := inst.StartWithMessage(prompt); err != nil {
+				return sessionCreatedMsg{err: err}
+			}
+			return sessionCreatedMsg{instance: inst}
+		}
 		if err := inst.Start(); err != nil {
 			return sessionCreatedMsg{err: err}
 		}
@@ -2480,7 +3061,7 @@ func (h *Home) quickForkSession(source *session.Instance) tea.Cmd {
 	// Use source title with " (fork)" suffix
 	title := source.Title + " (fork)"
 	groupPath := source.GroupPath
-	return h.forkSessionCmd(source, title, groupPath)
+	return h.forkSessionCmd(source, title, groupPath, session.ForkOptions{})
 }
 
 // forkSessionWithDialog opens the fork dialog to customize title and group
@@ -2490,12 +3071,19 @@ func (h *Home) forkSessionWithDialog(source *session.Instance) tea.Cmd {
 	}
 	// Pre-populate dialog with source session info
 	h.forkDialog.Show(source.Title, source.ProjectPath, source.GroupPath)
+	if source.RunsClaude() {
+		defaultSkip := false
+		if userConfig, err := session.LoadUserConfig(); err == nil && userConfig != nil {
+			defaultSkip = userConfig.Claude.DangerousMode
+		}
+		h.forkDialog.SetClaudeOptions(source.ClaudeOptions, source.ClaudeDangerousMode(), defaultSkip)
+	}
 	return nil
 }
 
 // forkSessionCmd creates a forked session with the given title and group
 // Shows immediate UI feedback by tracking the source session in forkingSessions
-func (h *Home) forkSessionCmd(source *session.Instance, title, groupPath string) tea.Cmd {
+func (h *Home) forkSessionCmd(source *session.Instance, title, groupPath string, opts session.ForkOptions) tea.Cmd {
 	if source == nil {
 		return nil
 	}
@@ -2515,20 +3103,20 @@ func (h *Home) forkSessionCmd(source *session.Instance, title, groupPath string)
 		}
 
 		// Use CreateForkedInstance to get the proper fork command
-		inst, _, err := source.CreateForkedInstance(title, groupPath)
+		inst, _, err := source.CreateForkedInstanceWithOptions(title, groupPath, opts)
 		if err != nil {
 			return sessionForkedMsg{err: fmt.Errorf("cannot create forked instance: %w", err), sourceID: sourceID}
 		}
 
-		// Start the forked session
-		if err := inst.Start(); err != nil {
+		// Start the forked session (seeded forks wait to hand over the transcript)
+		if err := inst.StartFork(); err != nil {
 			return sessionForkedMsg{err: err, sourceID: sourceID}
 		}
 
 		// Wait for Claude to create the new session file (fork creates new UUID)
 		// Give Claude up to 5 seconds to initialize and write the session file
 		// Pass usedIDs to prevent detecting an already-claimed session
-		if inst.Tool == "claude" {
+		if inst.RunsClaude() {
 			_ = inst.WaitForClaudeSessionWithExclude(5*time.Second, usedIDs)
 		}
 
@@ -2538,19 +3126,202 @@ func (h *Home) forkSessionCmd(source *session.Instance, title, groupPath string)
 
 // sessionDeletedMsg signals that a session was deleted
 type sessionDeletedMsg struct {
-	deletedID string
-	killErr   error // Error from Kill() if any
+	deletedID   string
+	killErr     error // Error from Kill() if any
+	worktreeErr error // Worktree removal refused: the session was NOT deleted
 }
 
-// deleteSession deletes a session
-func (h *Home) deleteSession(inst *session.Instance) tea.Cmd {
+// deleteSession deletes a session, and its git worktree if removeWorktree is set
+func (h *Home) deleteSession(inst *session.Instance, removeWorktree bool) tea.Cmd {
 	id := inst.ID
 	return func() tea.Msg {
+		// Remove the worktree first so a dirty one keeps its session too
+		if removeWorktree {
+			if err := inst.RemoveWorktree(); err != nil {
+				return sessionDeletedMsg{deletedID: id, worktreeErr: err}
+			}
+		}
 		killErr := inst.Kill()
 		return sessionDeletedMsg{deletedID: id, killErr: killErr}
 	}
 }
 
+// removeSessions removes deleted sessions from the list and saves once
+func (h *Home) removeSessions(ids []string) {
+	for _, id := range ids {
+		// Find and remove from list
+		var deletedInstance *session.Instance
+		h.instancesMu.Lock()
+		for i, s := range h.instances {
+			if s.ID == id {
+				deletedInstance = s
+				h.instances = append(h.instances[:i], h.instances[i+1:]...)
+				break
+			}
+		}
+		delete(h.instanceByID, id)
+		h.instancesMu.Unlock()
+		delete(h.markedSessions, id)
+		// Invalidate preview cache for deleted session
+		h.invalidatePreviewCache(id)
+		// Remove from group tree (preserves empty groups)
+		if deletedInstance != nil {
+			h.groupTree.RemoveSession(deletedInstance)
+		}
+	}
+	// Invalidate status counts cache
+	h.cachedStatusCounts.valid = false
+	h.rebuildFlatItems()
+	// Update search items
+	h.search.SetItems(h.instances)
+	// Save both instances AND groups (critical fix: was losing groups!)
+	h.saveInstances()
+}
+
+// sessionsDeletedMsg signals that several sessions were deleted at once
+type sessionsDeletedMsg struct {
+	deletedIDs   []string
+	killErrs     int     // How many Kill() calls failed
+	worktreeErrs []error // Worktree removals refused: those sessions were NOT deleted
+}
+
+// deleteSessions deletes several sessions, and their git worktrees if
+// removeWorktrees is set
+func (h *Home) deleteSessions(insts []*session.Instance, removeWorktrees bool) tea.Cmd {
+	return func() tea.Msg {
+		var msg sessionsDeletedMsg
+		for _, inst := range insts {
+			// Remove the worktree first so a dirty one keeps its session too
+			if removeWorktrees && inst.HasWorktree() {
+				if err := inst.RemoveWorktree(); err != nil {
+					msg.worktreeErrs = append(msg.worktreeErrs, fmt.Errorf("%s: %w", inst.Title, err))
+					continue
+				}
+			}
+			if err := inst.Kill(); err != nil {
+				msg.killErrs++
+			}
+			msg.deletedIDs = append(msg.deletedIDs, inst.ID)
+		}
+		return msg
+	}
+}
+
+// sessionsStoppedMsg signals that a bulk stop finished
+type sessionsStoppedMsg struct {
+	stopped int
+	errs    []error
+}
+
+// stopSessions kills the processes of sessions, keeping them in the list
+func (h *Home) stopSessions(insts []*session.Instance) tea.Cmd {
+	return func() tea.Msg {
+		var msg sessionsStoppedMsg
+		for _, inst := range insts {
+			if err := inst.Kill(); err != nil {
+				msg.errs = append(msg.errs, fmt.Errorf("%s: %w", inst.Title, err))
+				continue
+			}
+			msg.stopped++
+		}
+		return msg
+	}
+}
+
+// messagesSentMsg signals that a message was sent to sessions
+type messagesSentMsg struct {
+	sent int
+	errs []error
+}
+
+// sendMessages sends a message to sessions at once, without waiting for
+// their agents to be ready (a busy agent reads it when it's done)
+func (h *Home) sendMessages(insts []*session.Instance, message string) tea.Cmd {
+	return func() tea.Msg {
+		var msg messagesSentMsg
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, inst := range insts {
+			wg.Add(1)
+			go func(inst *session.Instance) {
+				defer wg.Done()
+				err := inst.SendMessage(message)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					msg.errs = append(msg.errs, fmt.Errorf("%s: %w", inst.Title, err))
+					return
+				}
+				msg.sent++
+			}(inst)
+		}
+		wg.Wait()
+		return msg
+	}
+}
+
+// broadcastResultMsg delivers a session's reply to a running broadcast
+type broadcastResultMsg struct {
+	result  session.BroadcastResult
+	results <-chan session.BroadcastResult
+}
+
+// broadcastDoneMsg signals that every session of a broadcast has replied
+type broadcastDoneMsg struct {
+	results <-chan session.BroadcastResult
+}
+
+// startBroadcast sends a prompt to sessions and opens the broadcast view,
+// which fills in as their replies come in
+func (h *Home) startBroadcast(insts []*session.Instance, target, prompt string) tea.Cmd {
+	if h.broadcastCancel != nil {
+		h.broadcastCancel()
+	}
+	ctx, cancel := context.WithTimeout(h.ctx, broadcastTimeout)
+	h.broadcastCancel = cancel
+
+	ids, titles := instanceIDsAndTitles(insts)
+	h.broadcastView.SetSize(h.width, h.height)
+	h.broadcastView.Show(target, prompt, ids, titles)
+
+	results := make(chan session.BroadcastResult, len(insts))
+	h.broadcastResults = results
+	go func() {
+		session.Broadcast(ctx, insts, prompt, func(result session.BroadcastResult) {
+			results <- result
+		})
+		close(results)
+	}()
+	return listenBroadcast(results)
+}
+
+// listenBroadcast waits for a broadcast's next reply
+func listenBroadcast(results <-chan session.BroadcastResult) tea.Cmd {
+	return func() tea.Msg {
+		result, ok := <-results
+		if !ok {
+			return broadcastDoneMsg{results: results}
+		}
+		return broadcastResultMsg{result: result, results: results}
+	}
+}
+
+// handleBroadcastViewKey handles keys when the broadcast view is visible
+func (h *Home) handleBroadcastViewKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		// Stop waiting: sessions still working are reported as timed out
+		if h.broadcastCancel != nil {
+			h.broadcastCancel()
+			h.broadcastCancel = nil
+		}
+		h.broadcastView.Hide()
+		return h, nil
+	}
+	h.broadcastView, _ = h.broadcastView.Update(msg)
+	return h, nil
+}
+
 // sessionRestartedMsg signals that a session was restarted
 type sessionRestartedMsg struct {
 	sessionID string
@@ -2690,13 +3461,13 @@ func (h *Home) importSessions() tea.Msg {
 // Cache expires after 500ms to balance freshness with performance
 // PERFORMANCE: Increased from 100ms to 500ms - status changes are rare
 // during UI interaction, and longer cache reduces View() overhead
-func (h *Home) countSessionStatuses() (running, waiting, idle, errored int) {
+func (h *Home) countSessionStatuses() (running, waiting, approval, idle, errored int) {
 	// Return cached values if valid and not expired
 	const cacheDuration = 500 * time.Millisecond
 	if h.cachedStatusCounts.valid &&
 		time.Since(h.cachedStatusCounts.timestamp) < cacheDuration {
 		return h.cachedStatusCounts.running, h.cachedStatusCounts.waiting,
-			h.cachedStatusCounts.idle, h.cachedStatusCounts.errored
+			h.cachedStatusCounts.approval, h.cachedStatusCounts.idle, h.cachedStatusCounts.errored
 	}
 
 	// Compute counts
@@ -2707,6 +3478,8 @@ func (h *Home) countSessionStatuses() (running, waiting, idle, errored int) {
 			running++
 		case session.StatusWaiting:
 			waiting++
+		case session.StatusApproval:
+			approval++
 		case session.StatusIdle:
 			idle++
 		case session.StatusError:
@@ -2718,17 +3491,18 @@ func (h *Home) countSessionStatuses() (running, waiting, idle, errored int) {
 	// Cache results with timestamp
 	h.cachedStatusCounts.running = running
 	h.cachedStatusCounts.waiting = waiting
+	h.cachedStatusCounts.approval = approval
 	h.cachedStatusCounts.idle = idle
 	h.cachedStatusCounts.errored = errored
 	h.cachedStatusCounts.valid = true
 	h.cachedStatusCounts.timestamp = time.Now()
-	return running, waiting, idle, errored
+	return running, waiting, approval, idle, errored
 }
 
 // renderFilterBar renders the quick filter pills
-// Format: [All] [● Running 2] [◐ Waiting 1] [○ Idle 5] [✕ Error 1]
+// Format: [All] [◆ Approval 1] [● Running 2] [◐ Waiting 1] [○ Idle 5] [✕ Error 1]
 func (h *Home) renderFilterBar() string {
-	running, waiting, idle, errored := h.countSessionStatuses()
+	running, waiting, approval, idle, errored := h.countSessionStatuses()
 
 	// Pill styling
 	activePillStyle := lipgloss.NewStyle().
@@ -2758,6 +3532,24 @@ func (h *Home) renderFilterBar() string {
 		pills = append(pills, inactivePillStyle.Render(allLabel))
 	}
 
+	// Approval pill (orange, only shown when something is blocked or filtered)
+	if approval > 0 || h.statusFilter == session.StatusApproval {
+		approvalLabel := fmt.Sprintf("◆ %d", approval)
+		if h.statusFilter == session.StatusApproval {
+			pills = append(pills, lipgloss.NewStyle().
+				Foreground(ColorBg).
+				Background(ColorOrange).
+				Bold(true).
+				Padding(0, 1).Render(approvalLabel))
+		} else {
+			pills = append(pills, lipgloss.NewStyle().
+				Foreground(ColorOrange).
+				Background(ColorSurface).
+				Bold(true).
+				Padding(0, 1).Render(approvalLabel))
+		}
+	}
+
 	// Running pill (green when active, dim if 0)
 	runningLabel := fmt.Sprintf("● %d", running)
 	if h.statusFilter == session.StatusRunning {
@@ -2828,7 +3620,7 @@ func (h *Home) renderFilterBar() string {
 
 	// Hint for keyboard shortcuts (shift+number to filter, 0 to clear)
 	hintStyle := lipgloss.NewStyle().Foreground(ColorComment).Faint(true)
-	hint := hintStyle.Render("  !@#$ filter • 0 all")
+	hint := hintStyle.Render("  !@#$% filter • 0 all")
 
 	// Join pills with spaces
 	filterRow := strings.Join(pills, " ") + hint
@@ -2845,6 +3637,9 @@ func (h *Home) updateSizes() {
 	h.newDialog.SetSize(h.width, h.height)
 	h.groupDialog.SetSize(h.width, h.height)
 	h.confirmDialog.SetSize(h.width, h.height)
+	h.transcriptViewer.SetSize(h.width, h.height)
+	h.messageDialog.SetSize(h.width, h.height)
+	h.broadcastView.SetSize(h.width, h.height)
 }
 
 // View renders the UI
@@ -2904,6 +3699,15 @@ func (h *Home) View() string {
 	if h.mcpDialog.IsVisible() {
 		return h.mcpDialog.View()
 	}
+	if h.transcriptViewer.IsVisible() {
+		return h.transcriptViewer.View()
+	}
+	if h.messageDialog.IsVisible() {
+		return h.messageDialog.View()
+	}
+	if h.broadcastView.IsVisible() {
+		return h.broadcastView.View()
+	}
 
 	// Reuse viewBuilder to reduce allocations (reset and pre-allocate)
 	h.viewBuilder.Reset()
@@ -2914,7 +3718,7 @@ func (h *Home) View() string {
 	// HEADER BAR
 	// ═══════════════════════════════════════════════════════════════════
 	// Calculate real session status counts for logo and stats
-	running, waiting, idle, errored := h.countSessionStatuses()
+	running, waiting, approval, idle, errored := h.countSessionStatuses()
 	logo := RenderLogoCompact(running, waiting, idle)
 
 	titleStyle := lipgloss.NewStyle().
@@ -2932,10 +3736,13 @@ func (h *Home) View() string {
 	title := titleStyle.Render(titleText)
 
 	// Status-based stats (more useful than group/session counts)
-	// Format: ● 2 running • ◐ 1 waiting • ○ 3 idle (• ✕ 1 error)
+	// Format: (◆ 1 approval •) ● 2 running • ◐ 1 waiting • ○ 3 idle (• ✕ 1 error)
 	var statsParts []string
 	statsSep := lipgloss.NewStyle().Foreground(ColorBorder).Render(" • ")
 
+	if approval > 0 {
+		statsParts = append(statsParts, ApprovalStyle.Render(fmt.Sprintf("◆ %d approval", approval)))
+	}
 	if running > 0 {
 		statsParts = append(statsParts, lipgloss.NewStyle().Foreground(ColorGreen).Render(fmt.Sprintf("● %d running", running)))
 	}
@@ -3070,6 +3877,9 @@ func (h *Home) View() string {
 		errMsg := ErrorStyle.Render("⚠ "+h.err.Error()) + dismissHint
 		b.WriteString("\n")
 		b.WriteString(errMsg)
+	} else if h.info != "" {
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorGreen).Render("✓ " + h.info))
 	}
 
 	if h.storageWarning != "" {
@@ -3396,7 +4206,26 @@ func (h *Home) renderHelpBar() string {
 	var secondaryHints []string // Edit actions (rename, move, delete)
 	var contextTitle string
 
-	if len(h.flatItems) == 0 {
+	if marked := len(h.markedInstances()); marked > 0 || h.rangeAnchor >= 0 {
+		contextTitle = fmt.Sprintf("%d selected", marked)
+		if h.rangeAnchor >= 0 {
+			contextTitle = "Range"
+		}
+		primaryHints = []string{
+			h.helpKey("Space", "Mark"),
+			h.helpKey("V", "Range"),
+			h.helpKey("x", "Stop"),
+			h.helpKey("R", "Restart"),
+			h.helpKey("s", "Send"),
+			h.helpKey("b", "Broadcast"),
+			h.helpKey("M", "MCP"),
+		}
+		secondaryHints = []string{
+			h.helpKey("m", "Move"),
+			h.helpKey("d", "Delete"),
+			h.helpKey("Esc", "Clear"),
+		}
+	} else if len(h.flatItems) == 0 {
 		contextTitle = "Empty"
 		primaryHints = []string{
 			h.helpKey("n", "New"),
@@ -3411,6 +4240,7 @@ func (h *Home) renderHelpBar() string {
 				h.helpKey("Tab", "Toggle"),
 				h.helpKey("n", "New"),
 				h.helpKey("g", "Subgroup"),
+				h.helpKey("b", "Broadcast"),
 			}
 			secondaryHints = []string{
 				h.helpKey("r", "Rename"),
@@ -3424,12 +4254,13 @@ func (h *Home) renderHelpBar() string {
 				h.helpKey("g", "Group"),
 				h.helpKey("R", "Restart"),
 			}
-			// Only show fork hints if session has a valid Claude session ID
+			// Only show fork hints if the session can be forked
 			if item.Session != nil && item.Session.CanFork() {
 				primaryHints = append(primaryHints, h.helpKey("f/F", "Fork"))
 			}
-			// Show MCP Manager hint for Claude sessions
-			if item.Session != nil && item.Session.Tool == "claude" {
+			primaryHints = append(primaryHints, h.helpKey("v", "View"))
+			// Show MCP Manager hint for sessions with managed MCPs
+			if item.Session != nil && item.Session.SupportsMCP() {
 				primaryHints = append(primaryHints, h.helpKey("M", "MCP"))
 			}
 			secondaryHints = []string{
@@ -3567,7 +4398,7 @@ func (h *Home) renderItem(b *strings.Builder, item session.Item, selected bool,
 	if item.Type == session.ItemTypeGroup {
 		h.renderGroupItem(b, item, selected, itemIndex)
 	} else {
-		h.renderSessionItem(b, item, selected)
+		h.renderSessionItem(b, item, selected, itemIndex)
 	}
 }
 
@@ -3614,16 +4445,22 @@ func (h *Home) renderGroupItem(b *strings.Builder, item session.Item, selected b
 	// Status indicators (compact, on same line) with cached styles
 	running := 0
 	waiting := 0
+	approval := 0
 	for _, sess := range group.Sessions {
 		switch sess.Status {
 		case session.StatusRunning:
 			running++
 		case session.StatusWaiting:
 			waiting++
+		case session.StatusApproval:
+			approval++
 		}
 	}
 
 	statusStr := ""
+	if approval > 0 {
+		statusStr += " " + GroupStatusApproval.Render(fmt.Sprintf("◆ %d", approval))
+	}
 	if running > 0 {
 		statusStr += " " + GroupStatusRunning.Render(fmt.Sprintf("● %d", running))
 	}
@@ -3650,7 +4487,7 @@ const (
 
 // renderSessionItem renders a single session item for the left panel
 // PERFORMANCE: Uses cached styles from styles.go to avoid allocations
-func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected bool) {
+func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected bool, itemIndex int) {
 	inst := item.Session
 
 	// Use cached tree connector style
@@ -3700,6 +4537,9 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 	case session.StatusWaiting:
 		statusIcon = "◐"
 		statusStyle = SessionStatusWaiting
+	case session.StatusApproval:
+		statusIcon = "◆"
+		statusStyle = SessionStatusApproval
 	case session.StatusIdle:
 		statusIcon = "○"
 		statusStyle = SessionStatusIdle
@@ -3715,7 +4555,7 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 	// Title styling with cached styles
 	var titleStyle lipgloss.Style
 	switch inst.Status {
-	case session.StatusRunning, session.StatusWaiting:
+	case session.StatusRunning, session.StatusWaiting, session.StatusApproval:
 		titleStyle = SessionTitleActive
 	case session.StatusError:
 		titleStyle = SessionTitleError
@@ -3744,9 +4584,23 @@ func (h *Home) renderSessionItem(b *strings.Builder, item session.Item, selected
 	}
 
 	title := titleStyle.Render(inst.Title)
+	if h.isMarked(inst.ID, itemIndex) {
+		markStyle := SessionMarkedStyle
+		if selected {
+			markStyle = SessionTitleSelStyle
+		}
+		title = markStyle.Render("✓ ") + title
+	}
 	tool := toolStyle.Render(" " + inst.Tool)
+	if inst.WorktreeBranch != "" {
+		branchStyle := SessionBranchStyle
+		if selected {
+			branchStyle = SessionTitleSelStyle
+		}
+		tool += branchStyle.Render(" ⎇ " + inst.WorktreeBranch)
+	}
 
-	// Build row: [baseIndent][selection][tree][status] [title] [tool]
+	// Build row: [baseIndent][selection][tree][status] [title] [tool] [branch]
 	// Format: " ├─ ● session-name tool" or "▶└─ ● session-name tool"
 	// Sub-sessions get extra indent: "   ├─◐ sub-session tool"
 	row := fmt.Sprintf("%s%s%s %s %s%s", baseIndent, selectionPrefix, treeStyle.Render(treeConnector), status, title, tool)
@@ -3773,42 +4627,11 @@ func (h *Home) renderLaunchingState(inst *session.Instance, width int, startTime
 		emoji = "🚀"
 	}
 
-	switch inst.Tool {
-	case "claude":
-		toolName = "Claude Code"
-		if isResuming {
-			toolDesc = "Resuming Claude session..."
-		} else {
-			toolDesc = "Starting Claude session..."
-		}
-	case "gemini":
-		toolName = "Gemini"
-		if isResuming {
-			toolDesc = "Resuming Gemini session..."
-		} else {
-			toolDesc = "Connecting to Gemini..."
-		}
-	case "aider":
-		toolName = "Aider"
-		if isResuming {
-			toolDesc = "Resuming Aider session..."
-		} else {
-			toolDesc = "Starting Aider..."
-		}
-	case "codex":
-		toolName = "Codex"
-		if isResuming {
-			toolDesc = "Resuming Codex session..."
-		} else {
-			toolDesc = "Starting Codex..."
-		}
-	default:
-		toolName = "Shell"
-		if isResuming {
-			toolDesc = "Resuming shell session..."
-		} else {
-			toolDesc = "Launching shell session..."
-		}
+	toolName = session.GetToolAdapter(inst.Tool).DisplayName()
+	if isResuming {
+		toolDesc = fmt.Sprintf("Resuming %s session...", toolName)
+	} else {
+		toolDesc = fmt.Sprintf("Starting %s...", toolName)
 	}
 
 	// Centered layout
@@ -4013,6 +4836,9 @@ func (h *Home) renderPreviewPane(width, height int) string {
 	case session.StatusWaiting:
 		statusIcon = "◐"
 		statusColor = ColorYellow
+	case session.StatusApproval:
+		statusIcon = "◆"
+		statusColor = ColorOrange
 	case session.StatusError:
 		statusIcon = "✕"
 		statusColor = ColorRed
@@ -4031,6 +4857,10 @@ func (h *Home) renderPreviewPane(width, height int) string {
 	pathStr := truncatePath(selected.ProjectPath, width-4)
 	b.WriteString(infoStyle.Render("📁 " + pathStr))
 	b.WriteString("\n")
+	if selected.WorktreeBranch != "" {
+		b.WriteString(infoStyle.Render("⎇ " + selected.WorktreeBranch + " (worktree)"))
+		b.WriteString("\n")
+	}
 
 	// Activity time - shows when session was last active
 	activityTime := selected.GetLastActivityTime()
@@ -4057,7 +4887,7 @@ func (h *Home) renderPreviewPane(width, height int) string {
 	b.WriteString("\n")
 
 	// Claude-specific info (session ID and MCPs)
-	if selected.Tool == "claude" {
+	if selected.RunsClaude() {
 		// Section divider for Claude info
 		claudeHeader := renderSectionDivider("Claude", width-4)
 		b.WriteString(claudeHeader)
@@ -4318,7 +5148,7 @@ func (h *Home) renderPreviewPane(width, height int) string {
 	// Apply animation logic to launching, resuming, AND MCP loading
 	if isLaunching || isResuming || isMcpLoading {
 		timeSinceStart := time.Since(animationStartTime)
-		if selected.Tool == "claude" {
+		if selected.RunsClaude() {
 			// Claude session: show animation for at least 6 seconds
 			minAnimationTime := 6 * time.Second
 			if timeSinceStart < minAnimationTime {
@@ -4582,13 +5412,15 @@ func (h *Home) renderGroupPreview(group *session.Group, width, height int) strin
 	b.WriteString("\n\n")
 
 	// Status breakdown with inline badges
-	running, waiting, idle, errored := 0, 0, 0, 0
+	running, waiting, approval, idle, errored := 0, 0, 0, 0, 0
 	for _, sess := range group.Sessions {
 		switch sess.Status {
 		case session.StatusRunning:
 			running++
 		case session.StatusWaiting:
 			waiting++
+		case session.StatusApproval:
+			approval++
 		case session.StatusIdle:
 			idle++
 		case session.StatusError:
@@ -4598,6 +5430,9 @@ func (h *Home) renderGroupPreview(group *session.Group, width, height int) strin
 
 	// Compact status line (inline, not badges)
 	var statuses []string
+	if approval > 0 {
+		statuses = append(statuses, ApprovalStyle.Render(fmt.Sprintf("◆ %d approval", approval)))
+	}
 	if running > 0 {
 		statuses = append(statuses, lipgloss.NewStyle().Foreground(ColorGreen).Render(fmt.Sprintf("● %d running", running)))
 	}
@@ -4645,6 +5480,8 @@ func (h *Home) renderGroupPreview(group *session.Group, width, height int) strin
 				statusIcon, statusColor = "●", ColorGreen
 			case session.StatusWaiting:
 				statusIcon, statusColor = "◐", ColorYellow
+			case session.StatusApproval:
+				statusIcon, statusColor = "◆", ColorOrange
 			case session.StatusError:
 				statusIcon, statusColor = "✕", ColorRed
 			}
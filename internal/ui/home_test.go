@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -375,3 +376,173 @@ func TestHomeGlobalSearchEscape(t *testing.T) {
 		t.Error("Global search should be hidden after pressing Escape")
 	}
 }
+
+func TestHomeMarkSessions(t *testing.T) {
+	home := NewHome()
+	home.width = 100
+	home.height = 30
+
+	a := session.NewInstanceWithGroup("a", "/tmp/a", "work")
+	b := session.NewInstanceWithGroup("b", "/tmp/b", "work/api")
+	c := session.NewInstanceWithGroup("c", "/tmp/c", "play")
+	home.instancesMu.Lock()
+	home.instances = []*session.Instance{a, b, c}
+	home.instancesMu.Unlock()
+	home.groupTree = session.NewGroupTree(home.instances)
+	home.rebuildFlatItems()
+
+	find := func(match func(session.Item) bool) int {
+		for i, item := range home.flatItems {
+			if match(item) {
+				return i
+			}
+		}
+		t.Fatal("item not found in flatItems")
+		return -1
+	}
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+
+	// Space on a group marks its sessions and its subgroups' sessions
+	home.cursor = find(func(item session.Item) bool {
+		return item.Type == session.ItemTypeGroup && item.Path == "work"
+	})
+	home.Update(space)
+	if got := len(home.markedInstances()); got != 2 {
+		t.Fatalf("marked %d sessions after space on group, want 2", got)
+	}
+	if home.markedSessions[c.ID] {
+		t.Error("session in another group should not be marked")
+	}
+
+	// Space again unmarks them
+	home.Update(space)
+	if got := len(home.markedInstances()); got != 0 {
+		t.Errorf("marked %d sessions after second space, want 0", got)
+	}
+
+	// V...V marks a range of sessions
+	home.cursor = find(func(item session.Item) bool { return item.Session == a })
+	home.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'V'}})
+	home.cursor = find(func(item session.Item) bool { return item.Session == c })
+	home.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'V'}})
+	if got := len(home.markedInstances()); got < 2 || !home.markedSessions[a.ID] || !home.markedSessions[c.ID] {
+		t.Errorf("range marked %d sessions, want a and c among them", got)
+	}
+	if home.rangeAnchor != -1 {
+		t.Error("range selection should end after the second V")
+	}
+
+	// Marked sessions are the targets of bulk actions; Esc clears them
+	if got := len(home.bulkTargets()); got != len(home.markedInstances()) {
+		t.Errorf("bulkTargets = %d sessions, want the %d marked", got, len(home.markedInstances()))
+	}
+	home.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := len(home.markedInstances()); got != 0 {
+		t.Errorf("marked %d sessions after Esc, want 0", got)
+	}
+}
+
+func TestHomeBroadcastNeedsRunningSessions(t *testing.T) {
+	home := NewHome()
+	home.width = 100
+	home.height = 30
+
+	a := session.NewInstanceWithGroup("a", "/tmp/a", "work")
+	home.instancesMu.Lock()
+	home.instances = []*session.Instance{a}
+	home.instancesMu.Unlock()
+	home.groupTree = session.NewGroupTree(home.instances)
+	home.rebuildFlatItems()
+
+	for i, item := range home.flatItems {
+		if item.Type == session.ItemTypeGroup && item.Path == "work" {
+			home.cursor = i
+		}
+	}
+	home.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	if home.messageDialog.IsVisible() {
+		t.Error("broadcast dialog should not open for a group without running sessions")
+	}
+	if home.err == nil {
+		t.Error("expected an error explaining why nothing was broadcast")
+	}
+}
+
+func TestBroadcastViewResults(t *testing.T) {
+	v := NewBroadcastView()
+	v.SetSize(100, 40)
+	v.Show("work", "Review the diff", []string{"1", "2"}, []string{"api", "web"})
+
+	v.AddResult(session.BroadcastResult{SessionID: "2", Title: "web", Reply: "Looks good.", Status: session.StatusWaiting})
+	view := v.View()
+	if !strings.Contains(view, "1/2 done") || !strings.Contains(view, "Looks good.") {
+		t.Errorf("view should show web's reply and the count:\n%s", view)
+	}
+	if !strings.Contains(view, "… api") {
+		t.Errorf("view should show api as pending:\n%s", view)
+	}
+
+	v.AddResult(session.BroadcastResult{SessionID: "1", Title: "api", Error: "timed out", Status: session.StatusRunning})
+	v.Finish()
+	if !v.IsDone() || !strings.Contains(v.View(), "2/2 done") {
+		t.Error("broadcast should be done with both results in")
+	}
+}
+
+func TestHomeUndoShowsInfo(t *testing.T) {
+	home := NewHome()
+	home.width = 100
+	home.height = 30
+
+	entry := &session.JournalEntry{Seq: 1, Changes: []session.JournalChange{{
+		Op:     session.JournalRename,
+		Before: &session.InstanceData{ID: "a", Title: "old"},
+		After:  &session.InstanceData{ID: "a", Title: "new"},
+	}}}
+	home.Update(operationUndoneMsg{entries: []*session.JournalEntry{entry}})
+	if home.err != nil {
+		t.Errorf("undo success shown as error: %v", home.err)
+	}
+	if !strings.HasPrefix(home.info, "Undone: ") {
+		t.Errorf("info = %q, want the undone operation", home.info)
+	}
+
+	home.Update(operationUndoneMsg{err: session.ErrNothingToUndo})
+	if home.err == nil {
+		t.Error("undo failure should be shown as an error")
+	}
+}
+
+func TestHomeBulkDeleteOffersWorktrees(t *testing.T) {
+	home := NewHome()
+	home.width = 100
+	home.height = 30
+
+	a := session.NewInstanceWithGroup("a", "/tmp/a", "work")
+	b := session.NewInstanceWithGroup("b", "/tmp/b", "work")
+	b.WorktreePath = "/tmp/b-feature"
+	b.WorktreeBranch = "feature"
+	home.instancesMu.Lock()
+	home.instances = []*session.Instance{a, b}
+	home.instancesMu.Unlock()
+	home.groupTree = session.NewGroupTree(home.instances)
+	home.rebuildFlatItems()
+	home.markedSessions[a.ID] = true
+	home.markedSessions[b.ID] = true
+
+	home.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if !home.confirmDialog.IsVisible() || home.confirmDialog.GetConfirmType() != ConfirmDeleteSessions {
+		t.Fatal("d on marked sessions should ask to delete them")
+	}
+	if got := home.confirmDialog.GetWorktrees(); len(got) != 1 || got[0] != b.WorktreePath {
+		t.Errorf("worktrees offered = %v, want [%s]", got, b.WorktreePath)
+	}
+	if view := home.confirmDialog.View(); !strings.Contains(view, "w + Worktree") || !strings.Contains(view, b.WorktreePath) {
+		t.Error("bulk delete confirmation should list the worktrees and offer w")
+	}
+
+	home.confirmDialog.ShowSessions(ConfirmDeleteSessions, []string{a.ID}, []string{a.Title}, 0)
+	if strings.Contains(home.confirmDialog.View(), "w + Worktree") {
+		t.Error("w should only be offered when a session has a worktree")
+	}
+}
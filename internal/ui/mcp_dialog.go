@@ -2,7 +2,9 @@ package ui
 
 import (
 	"log"
+	"sort"
 
+	"github.com/asheshgoplani/agent-deck/internal/mcppool"
 	"github.com/asheshgoplani/agent-deck/internal/session"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -32,7 +34,7 @@ type MCPItem struct {
 	IsPooled    bool // True if this MCP uses socket pool
 }
 
-// MCPDialog handles MCP management for Claude and Gemini sessions
+// MCPDialog handles MCP management for sessions of tools that support MCPs
 type MCPDialog struct {
 	visible     bool
 	width       int
@@ -40,6 +42,7 @@ type MCPDialog struct {
 	projectPath string
 	sessionID   string // ID of the session being managed (for restart)
 	tool        string // "claude" or "gemini"
+	adapter     session.ToolAdapter
 
 	// Current scope and column
 	scope  MCPScope
@@ -60,6 +63,7 @@ type MCPDialog struct {
 	// Track changes
 	localChanged  bool
 	globalChanged bool
+	localInitial  map[string]bool // LOCAL attached names when shown
 
 	err error
 }
@@ -77,6 +81,7 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 	// Store session ID and tool for restart
 	m.sessionID = sessionID
 	m.tool = tool
+	m.adapter = session.GetToolAdapter(tool)
 
 	// Get all available MCPs from config.toml (the pool)
 	availableMCPs := session.GetAvailableMCPs()
@@ -90,7 +95,8 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 		if def, ok := availableMCPs[name]; ok {
 			desc = def.Description
 		}
-		isPooled := pool != nil && pool.ShouldPool(name) && pool.IsRunning(name)
+		isPooled := pool != nil && pool.ShouldPool(name) &&
+			(pool.IsRunning(name) || pool.GetStatus(name) == mcppool.StatusFailed)
 		itemsMap[name] = MCPItem{Name: name, Description: desc, IsPooled: isPooled}
 	}
 
@@ -106,9 +112,9 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 	m.globalAttached = nil
 	m.globalAvailable = nil
 
-	if tool == "gemini" {
-		// Gemini: Only global MCPs from settings.json
-		mcpInfo := session.GetGeminiMCPInfo(projectPath)
+	if m.GlobalOnly() {
+		// Tools without per-project MCPs (Gemini): only their global settings
+		mcpInfo := m.adapter.MCPInfo(projectPath)
 		globalAttachedNames := make(map[string]bool)
 		for _, name := range mcpInfo.Global {
 			globalAttachedNames[name] = true
@@ -124,7 +130,7 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 			}
 		}
 
-		// Add orphan GLOBAL MCPs (attached in settings.json but not in config.toml pool)
+		// Add orphan GLOBAL MCPs (attached in the tool's settings but not in config.toml pool)
 		for name := range globalAttachedNames {
 			if !poolNames[name] {
 				m.globalAttached = append(m.globalAttached, MCPItem{
@@ -199,8 +205,8 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 
 	m.visible = true
 	m.projectPath = projectPath
-	// Gemini only has global scope, Claude starts with local
-	if tool == "gemini" {
+	// Global-only tools have one scope, Claude starts with local
+	if m.GlobalOnly() {
 		m.scope = MCPScopeGlobal
 	} else {
 		m.scope = MCPScopeLocal
@@ -213,6 +219,10 @@ func (m *MCPDialog) Show(projectPath string, sessionID string, tool string) erro
 	m.localChanged = false
 	m.globalChanged = false
 	m.err = nil
+	m.localInitial = make(map[string]bool, len(m.localAttached))
+	for _, item := range m.localAttached {
+		m.localInitial[item.Name] = true
+	}
 
 	return nil
 }
@@ -245,6 +255,36 @@ func (m *MCPDialog) HasChanged() bool {
 	return result
 }
 
+// LocalDelta returns the LOCAL MCPs attached and detached since the dialog
+// was shown, so the same change can be applied to other projects
+func (m *MCPDialog) LocalDelta() (attached, detached []string) {
+	current := make(map[string]bool, len(m.localAttached))
+	for _, item := range m.localAttached {
+		current[item.Name] = true
+		if !m.localInitial[item.Name] {
+			attached = append(attached, item.Name)
+		}
+	}
+	for name := range m.localInitial {
+		if !current[name] {
+			detached = append(detached, name)
+		}
+	}
+	sort.Strings(detached)
+	return attached, detached
+}
+
+// GetTool returns the tool of the session being managed
+func (m *MCPDialog) GetTool() string {
+	return m.tool
+}
+
+// GlobalOnly reports whether the tool only has global MCPs (no per-project
+// config), like Gemini
+func (m *MCPDialog) GlobalOnly() bool {
+	return m.adapter != nil && !m.adapter.ProjectMCPs()
+}
+
 // GetProjectPath returns the project path being managed
 func (m *MCPDialog) GetProjectPath() string {
 	return m.projectPath
@@ -334,15 +374,15 @@ func (m *MCPDialog) Apply() error {
 	log.Printf("[MCP-DEBUG] Apply() called - tool=%q, localChanged=%v, globalChanged=%v, projectPath=%q",
 		m.tool, m.localChanged, m.globalChanged, m.projectPath)
 
-	if m.tool == "gemini" {
-		// Gemini: Only global scope, write to settings.json
+	if m.GlobalOnly() {
+		// Only global scope, written to the tool's settings
 		if m.globalChanged {
 			enabledNames := make([]string, len(m.globalAttached))
 			for i, item := range m.globalAttached {
 				enabledNames[i] = item.Name
 			}
 
-			if err := session.WriteGeminiMCPSettings(enabledNames); err != nil {
+			if err := m.adapter.WriteMCPConfig(m.projectPath, enabledNames); err != nil {
 				m.err = err
 				return err
 			}
@@ -405,8 +445,8 @@ func (m *MCPDialog) Update(msg tea.KeyMsg) (*MCPDialog, tea.Cmd) {
 	switch msg.String() {
 	case "tab":
 		// Switch scope: LOCAL <-> GLOBAL (Claude only)
-		// Gemini only has global scope, so Tab does nothing
-		if m.tool != "gemini" {
+		// Global-only tools have one scope, so Tab does nothing
+		if !m.GlobalOnly() {
 			if m.scope == MCPScopeLocal {
 				m.scope = MCPScopeGlobal
 			} else {
@@ -447,14 +487,14 @@ func (m *MCPDialog) View() string {
 
 	// Title varies by tool
 	title := "MCP Manager"
-	if m.tool == "gemini" {
-		title = "MCP Manager (Gemini)"
+	if m.GlobalOnly() {
+		title = "MCP Manager (" + m.adapter.DisplayName() + ")"
 	}
 
-	// Scope tabs - Gemini only has global
+	// Scope tabs - global-only tools have just one
 	var tabs string
-	if m.tool == "gemini" {
-		// Gemini: Only show GLOBAL (centered)
+	if m.GlobalOnly() {
+		// Only show GLOBAL (centered)
 		globalTab := lipgloss.NewStyle().Bold(true).Foreground(ColorAccent).Render("[GLOBAL]")
 		tabs = "──────────────── " + globalTab + " ────────────────"
 	} else {
@@ -494,8 +534,8 @@ func (m *MCPDialog) View() string {
 
 	// Scope description
 	var scopeDesc string
-	if m.tool == "gemini" {
-		scopeDesc = DimStyle.Render("Writes to: ~/.gemini/settings.json")
+	if m.GlobalOnly() {
+		scopeDesc = DimStyle.Render("Writes to: " + m.adapter.DisplayName() + " settings (all projects)")
 	} else if m.scope == MCPScopeLocal {
 		scopeDesc = DimStyle.Render("Writes to: .mcp.json (this project only)")
 	} else {
@@ -511,7 +551,7 @@ func (m *MCPDialog) View() string {
 	// Hint with consistent styling
 	hintStyle := lipgloss.NewStyle().Foreground(ColorComment)
 	var hint string
-	if m.tool == "gemini" {
+	if m.GlobalOnly() {
 		hint = hintStyle.Render("←→ column │ Space move │ Enter apply │ Esc cancel")
 	} else {
 		hint = hintStyle.Render("Tab scope │ ←→ column │ Space move │ Enter apply │ Esc cancel")
@@ -581,6 +621,23 @@ func (m *MCPDialog) View() string {
 	)
 }
 
+// poolIndicator returns the socket pool marker for an MCP, reflecting the live
+// proxy status so crashes and restarts show up while the dialog is open
+func poolIndicator(name string) string {
+	pool := session.GetGlobalPool()
+	if pool == nil {
+		return " 🔌"
+	}
+	switch pool.GetStatus(name) {
+	case mcppool.StatusStarting, mcppool.StatusRestarting:
+		return " ⟳"
+	case mcppool.StatusFailed:
+		return " ✗"
+	default:
+		return " 🔌"
+	}
+}
+
 // renderColumn renders a single column (Attached or Available)
 func (m *MCPDialog) renderColumn(title string, items []MCPItem, selectedIdx int, focused bool) string {
 	// Header
@@ -610,7 +667,7 @@ func (m *MCPDialog) renderColumn(title string, items []MCPItem, selectedIdx int,
 			name := item.Name
 			// Add pool indicator for MCPs in socket pool
 			if item.IsPooled {
-				name = name + " 🔌"
+				name = name + poolIndicator(item.Name)
 			}
 			// Add orphan indicator for MCPs not in config.toml
 			if item.IsOrphan {
@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// MessageDialog asks for a message to send to one or more sessions, or for
+// a prompt to broadcast (collecting the replies)
+type MessageDialog struct {
+	visible      bool
+	messageInput textinput.Model
+	sessionIDs   []string // Sessions the message goes to
+	targetName   string   // Session title, group name or "N sessions"
+	broadcast    bool     // Collect the replies (see BroadcastView)
+	width        int
+	height       int
+}
+
+// NewMessageDialog creates a new message dialog
+func NewMessageDialog() *MessageDialog {
+	ti := textinput.New()
+	ti.Placeholder = "Message"
+	ti.CharLimit = 2000
+	ti.Width = 50
+
+	return &MessageDialog{
+		messageInput: ti,
+	}
+}
+
+// Show shows the dialog for sending to the given sessions
+func (m *MessageDialog) Show(sessionIDs []string, targetName string) {
+	m.visible = true
+	m.sessionIDs = sessionIDs
+	m.targetName = targetName
+	m.broadcast = false
+	m.messageInput.SetValue("")
+	m.messageInput.Focus()
+}
+
+// ShowBroadcast shows the dialog for broadcasting a prompt to the sessions
+func (m *MessageDialog) ShowBroadcast(sessionIDs []string, targetName string) {
+	m.Show(sessionIDs, targetName)
+	m.broadcast = true
+}
+
+// IsBroadcast returns whether the prompt is broadcast (replies collected)
+func (m *MessageDialog) IsBroadcast() bool {
+	return m.broadcast
+}
+
+// Hide hides the dialog
+func (m *MessageDialog) Hide() {
+	m.visible = false
+	m.sessionIDs = nil
+	m.messageInput.Blur()
+}
+
+// IsVisible returns whether the dialog is visible
+func (m *MessageDialog) IsVisible() bool {
+	return m.visible
+}
+
+// GetSessionIDs returns the sessions the message goes to
+func (m *MessageDialog) GetSessionIDs() []string {
+	return m.sessionIDs
+}
+
+// GetValue returns the message
+func (m *MessageDialog) GetValue() string {
+	return strings.TrimSpace(m.messageInput.Value())
+}
+
+// SetSize updates dialog dimensions
+func (m *MessageDialog) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Update handles input
+func (m *MessageDialog) Update(msg tea.KeyMsg) (*MessageDialog, tea.Cmd) {
+	var cmd tea.Cmd
+	m.messageInput, cmd = m.messageInput.Update(msg)
+	return m, cmd
+}
+
+// View renders the dialog
+func (m *MessageDialog) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	// Responsive dialog width
+	dialogWidth := 60
+	if m.width > 0 && m.width < dialogWidth+10 {
+		dialogWidth = m.width - 10
+		if dialogWidth < 30 {
+			dialogWidth = 30
+		}
+	}
+	titleWidth := dialogWidth - 4
+
+	titleStyle := DialogTitleStyle.Width(titleWidth)
+	targetInfo := lipgloss.NewStyle().
+		Foreground(ColorCyan).
+		Render("To: " + m.targetName)
+	hintStyle := lipgloss.NewStyle().Foreground(ColorComment)
+	title := "Send Message"
+	hint := hintStyle.Render("Enter send │ Esc cancel")
+	if m.broadcast {
+		title = "Broadcast"
+		hint = hintStyle.Render("Enter send and collect the replies │ Esc cancel")
+	}
+
+	dialogContent := lipgloss.JoinVertical(
+		lipgloss.Center,
+		titleStyle.Render(title),
+		"",
+		targetInfo,
+		"",
+		m.messageInput.View(),
+		"",
+		hint,
+	)
+
+	dialog := DialogBoxStyle.
+		Width(dialogWidth).
+		Render(dialogContent)
+
+	// Center the dialog
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		dialog,
+	)
+}
+
+// sendTargetName describes who a message goes to: the session's title, or
+// how many sessions
+func sendTargetName(titles []string) string {
+	if len(titles) == 1 {
+		return titles[0]
+	}
+	return sessionCount(len(titles))
+}
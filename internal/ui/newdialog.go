@@ -9,6 +9,8 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
 )
 
 // NewDialog represents the new session creation dialog
@@ -28,6 +30,16 @@ type NewDialog struct {
 	pathSuggestionCursor int      // tracks selected suggestion in dropdown
 	pathSuggestionSource  string   // "recent" or "autocomplete"
 	pathSuggestionOffset int      // scroll offset for displaying suggestions
+	worktree             bool     // create a git worktree for the session
+	claudeFlagsInput     textinput.Model
+	skipPermissions      bool // --dangerously-skip-permissions for claude
+	defaultSkip          bool // [claude] dangerous_mode, stored only when overridden
+
+	// Template picker, shown when config.toml defines [templates.NAME]
+	templates      []string // "" (no template) followed by the template names
+	templateCursor int
+	shownGroupPath string // Group the dialog was opened in, restored
+	shownGroupName string // when the template is deselected
 }
 
 // NewNewDialog creates a new NewDialog instance
@@ -58,10 +70,17 @@ func NewNewDialog() *NewDialog {
 	commandInput.CharLimit = 100
 	commandInput.Width = 40
 
+	// Create Claude flags input (shown when claude is selected)
+	claudeFlagsInput := textinput.New()
+	claudeFlagsInput.Placeholder = "--model opus --permission-mode plan"
+	claudeFlagsInput.CharLimit = 256
+	claudeFlagsInput.Width = 40
+
 	return &NewDialog{
-		nameInput:       nameInput,
-		pathInput:       pathInput,
-		commandInput:    commandInput,
+		nameInput:        nameInput,
+		pathInput:        pathInput,
+		commandInput:     commandInput,
+		claudeFlagsInput: claudeFlagsInput,
 		focusIndex:      0,
 		visible:         false,
 		presetCommands:  []string{"", "claude", "gemini", "opencode", "codex"},
@@ -79,10 +98,24 @@ func (d *NewDialog) ShowInGroup(groupPath, groupName string) {
 	}
 	d.parentGroupPath = groupPath
 	d.parentGroupName = groupName
+	d.shownGroupPath = groupPath
+	d.shownGroupName = groupName
 	d.visible = true
-	d.focusIndex = 0
 	d.nameInput.SetValue("")
-	d.nameInput.Focus()
+	d.worktree = false
+	d.templates = nil
+	d.templateCursor = 0
+	if names := session.GetTemplateNames(); len(names) > 0 {
+		d.templates = append([]string{""}, names...)
+	}
+	d.focusIndex = d.fieldIndex("name")
+	d.updateFocus()
+	d.claudeFlagsInput.SetValue("")
+	d.defaultSkip = false
+	if userConfig, err := session.LoadUserConfig(); err == nil && userConfig != nil {
+		d.defaultSkip = userConfig.Claude.DangerousMode
+	}
+	d.skipPermissions = d.defaultSkip
 	// Keep commandCursor at previously set default (don't reset to 0)
 
 	// Clear suggestion state when showing dialog
@@ -183,6 +216,117 @@ func (d *NewDialog) GetValues() (name, path, command string) {
 	return name, path, command
 }
 
+// UseWorktree returns whether the session should run in a new git worktree
+func (d *NewDialog) UseWorktree() bool {
+	return d.worktree
+}
+
+// claudeSelected reports whether the claude preset is selected
+func (d *NewDialog) claudeSelected() bool {
+	return d.commandCursor < len(d.presetCommands) && d.presetCommands[d.commandCursor] == "claude"
+}
+
+// fields returns the focusable fields in tab order
+func (d *NewDialog) fields() []string {
+	var fields []string
+	if len(d.templates) > 0 {
+		fields = append(fields, "template")
+	}
+	fields = append(fields, "name", "path", "command", "worktree")
+	if d.claudeSelected() {
+		fields = append(fields, "claude-flags", "skip-permissions")
+	}
+	return fields
+}
+
+// fieldCount returns the number of focusable fields
+func (d *NewDialog) fieldCount() int {
+	return len(d.fields())
+}
+
+// field returns the name of the focused field
+func (d *NewDialog) field() string {
+	fields := d.fields()
+	if d.focusIndex < 0 || d.focusIndex >= len(fields) {
+		return ""
+	}
+	return fields[d.focusIndex]
+}
+
+// fieldIndex returns the focus index of a field
+func (d *NewDialog) fieldIndex(name string) int {
+	for idx, field := range d.fields() {
+		if field == name {
+			return idx
+		}
+	}
+	return 0
+}
+
+// GetTemplate returns the selected template (nil when none is selected)
+func (d *NewDialog) GetTemplate() *session.TemplateDef {
+	if d.templateCursor <= 0 || d.templateCursor >= len(d.templates) {
+		return nil
+	}
+	return session.GetTemplate(d.templates[d.templateCursor])
+}
+
+// selectTemplate moves the template picker and fills the dialog from the
+// selected template: command, group and Claude options. Its MCPs,
+// environment and prompt are applied when the session is created.
+func (d *NewDialog) selectTemplate(delta int) {
+	d.templateCursor = (d.templateCursor + delta + len(d.templates)) % len(d.templates)
+	tmpl := d.GetTemplate()
+	if tmpl == nil {
+		d.parentGroupPath = d.shownGroupPath
+		d.parentGroupName = d.shownGroupName
+		return
+	}
+
+	d.commandCursor = 0
+	d.commandInput.SetValue("")
+	if tmpl.Command == "" && tmpl.Tool != "" && tmpl.Tool != "shell" {
+		d.SetDefaultTool(tmpl.Tool)
+	}
+	if d.commandCursor == 0 {
+		d.commandInput.SetValue(tmpl.StartCommand())
+	}
+
+	if tmpl.Group != "" {
+		d.parentGroupPath = tmpl.Group
+		d.parentGroupName = tmpl.Group
+	} else {
+		d.parentGroupPath = d.shownGroupPath
+		d.parentGroupName = d.shownGroupName
+	}
+
+	d.claudeFlagsInput.SetValue(tmpl.Claude.String())
+	d.skipPermissions = d.defaultSkip
+	if tmpl.Claude != nil && tmpl.Claude.DangerousMode != nil {
+		d.skipPermissions = *tmpl.Claude.DangerousMode
+	}
+}
+
+// GetClaudeOptions returns the per-session Claude options entered in the
+// dialog (nil when claude isn't selected or nothing was changed)
+func (d *NewDialog) GetClaudeOptions() (*session.ClaudeOptions, error) {
+	if !d.claudeSelected() {
+		return nil, nil
+	}
+	opts, err := session.ParseClaudeFlags(d.claudeFlagsInput.Value())
+	if err != nil {
+		return nil, err
+	}
+	if d.skipPermissions != d.defaultSkip {
+		if opts == nil {
+			opts = &session.ClaudeOptions{}
+		}
+		skip := d.skipPermissions
+		opts.DangerousMode = &skip
+	}
+	return opts, nil
+}
+
 // Validate checks if the dialog values are valid and returns an error message if not
 func (d *NewDialog) Validate() string {
 	name := strings.TrimSpace(d.nameInput.Value())
@@ -203,6 +347,11 @@ func (d *NewDialog) Validate() string {
 		return "Project path cannot be empty"
 	}
 
+	// Check Claude flags
+	if _, err := d.GetClaudeOptions(); err != nil {
+		return err.Error()
+	}
+
 	return "" // Valid
 }
 
@@ -405,14 +554,19 @@ func (d *NewDialog) updateFocus() {
 	d.nameInput.Blur()
 	d.pathInput.Blur()
 	d.commandInput.Blur()
+	d.claudeFlagsInput.Blur()
 
-	switch d.focusIndex {
-	case 0:
+	switch d.field() {
+	case "name":
 		d.nameInput.Focus()
-	case 1:
+	case "path":
 		d.pathInput.Focus()
-	case 2:
-		// Command selection (no text input focus needed for presets)
+	case "command":
+		if d.commandCursor == 0 {
+			d.commandInput.Focus()
+		}
+	case "claude-flags":
+		d.claudeFlagsInput.Focus()
 	}
 }
 
@@ -429,7 +583,7 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 		switch msg.String() {
 		case "tab":
 			// On path field: try path completion first
-			if d.focusIndex == 1 {
+			if d.field() == "path" {
 				currentPath := strings.TrimSpace(d.pathInput.Value())
 				completed := d.tryCompletePath(currentPath)
 				if completed {
@@ -443,45 +597,45 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 				}
 			}
 			// Move to next field
-			d.focusIndex = (d.focusIndex + 1) % 3
+			d.focusIndex = (d.focusIndex + 1) % d.fieldCount()
 			d.updateFocus()
 			return d, cmd
 
 		case "ctrl+n":
 			// Next suggestion (when on path field)
-			if d.focusIndex == 1 && len(d.pathSuggestions) > 0 {
+			if d.field() == "path" && len(d.pathSuggestions) > 0 {
 				d.scrollSuggestionsDown()
 				return d, nil
 			}
 
 		case "ctrl+p":
 			// Previous suggestion (when on path field)
-			if d.focusIndex == 1 && len(d.pathSuggestions) > 0 {
+			if d.field() == "path" && len(d.pathSuggestions) > 0 {
 				d.scrollSuggestionsUp()
 				return d, nil
 			}
 
 		case "down":
 			// On path field with suggestions: scroll down
-			if d.focusIndex == 1 && len(d.pathSuggestions) > 0 {
+			if d.field() == "path" && len(d.pathSuggestions) > 0 {
 				d.scrollSuggestionsDown()
 				return d, nil
 			}
 			// Otherwise navigate fields
-			d.focusIndex = (d.focusIndex + 1) % 3
+			d.focusIndex = (d.focusIndex + 1) % d.fieldCount()
 			d.updateFocus()
 			return d, nil
 
 		case "up":
 			// On path field with suggestions: scroll up
-			if d.focusIndex == 1 && len(d.pathSuggestions) > 0 {
+			if d.field() == "path" && len(d.pathSuggestions) > 0 {
 				d.scrollSuggestionsUp()
 				return d, nil
 			}
 			// Otherwise navigate fields (shift+tab behavior)
 			d.focusIndex--
 			if d.focusIndex < 0 {
-				d.focusIndex = 2
+				d.focusIndex = d.fieldCount() - 1
 			}
 			d.updateFocus()
 			return d, nil
@@ -489,7 +643,7 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 		case "shift+tab":
 			d.focusIndex--
 			if d.focusIndex < 0 {
-				d.focusIndex = 2
+				d.focusIndex = d.fieldCount() - 1
 			}
 			d.updateFocus()
 			return d, nil
@@ -502,9 +656,30 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 			// Let parent handle enter (create session)
 			return d, nil
 
+		case " ":
+			// Worktree toggle
+			switch d.field() {
+			case "worktree":
+				d.worktree = !d.worktree
+				return d, nil
+			case "skip-permissions":
+				d.skipPermissions = !d.skipPermissions
+				return d, nil
+			}
+
 		case "left":
-			// Command selection
-			if d.focusIndex == 2 {
+			// Command and template selection
+			switch d.field() {
+			case "worktree":
+				d.worktree = !d.worktree
+				return d, nil
+			case "skip-permissions":
+				d.skipPermissions = !d.skipPermissions
+				return d, nil
+			case "template":
+				d.selectTemplate(-1)
+				return d, nil
+			case "command":
 				d.commandCursor--
 				if d.commandCursor < 0 {
 					d.commandCursor = len(d.presetCommands) - 1
@@ -513,8 +688,18 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 			}
 
 		case "right":
-			// Command selection
-			if d.focusIndex == 2 {
+			// Command and template selection
+			switch d.field() {
+			case "worktree":
+				d.worktree = !d.worktree
+				return d, nil
+			case "skip-permissions":
+				d.skipPermissions = !d.skipPermissions
+				return d, nil
+			case "template":
+				d.selectTemplate(1)
+				return d, nil
+			case "command":
 				d.commandCursor = (d.commandCursor + 1) % len(d.presetCommands)
 				return d, nil
 			}
@@ -522,10 +707,10 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 	}
 
 	// Update focused input
-	switch d.focusIndex {
-	case 0:
+	switch d.field() {
+	case "name":
 		d.nameInput, cmd = d.nameInput.Update(msg)
-	case 1:
+	case "path":
 		oldPath := d.pathInput.Value()
 		d.pathInput, cmd = d.pathInput.Update(msg)
 		newPath := d.pathInput.Value()
@@ -533,6 +718,13 @@ func (d *NewDialog) Update(msg tea.Msg) (*NewDialog, tea.Cmd) {
 		if oldPath != newPath && newPath != "" {
 			d.updatePathSuggestions(newPath)
 		}
+	case "command":
+		// Custom command input (shell preset)
+		if d.commandCursor == 0 {
+			d.commandInput, cmd = d.commandInput.Update(msg)
+		}
+	case "claude-flags":
+		d.claudeFlagsInput, cmd = d.claudeFlagsInput.Update(msg)
 	}
 
 	return d, cmd
@@ -584,8 +776,28 @@ func (d *NewDialog) View() string {
 	content.WriteString(groupInfoStyle.Render("  in group: " + d.parentGroupName))
 	content.WriteString("\n\n")
 
+	// Template picker
+	if len(d.templates) > 0 {
+		if d.field() == "template" {
+			content.WriteString(activeLabelStyle.Render("▶ Template:"))
+		} else {
+			content.WriteString(labelStyle.Render("  Template:"))
+		}
+		name := "none"
+		if d.templateCursor > 0 {
+			name = d.templates[d.templateCursor]
+		}
+		content.WriteString(labelStyle.Render(fmt.Sprintf(" ◀ %s ▶", name)))
+		content.WriteString("\n")
+		if tmpl := d.GetTemplate(); tmpl != nil {
+			content.WriteString(lipgloss.NewStyle().Foreground(ColorComment).Render("  " + templateSummary(tmpl)))
+			content.WriteString("\n")
+		}
+		content.WriteString("\n")
+	}
+
 	// Name input
-	if d.focusIndex == 0 {
+	if d.field() == "name" {
 		content.WriteString(activeLabelStyle.Render("▶ Name:"))
 	} else {
 		content.WriteString(labelStyle.Render("  Name:"))
@@ -596,7 +808,7 @@ func (d *NewDialog) View() string {
 	content.WriteString("\n\n")
 
 	// Path input
-	if d.focusIndex == 1 {
+	if d.field() == "path" {
 		label := "▶ Path:"
 		if len(d.pathSuggestions) > 0 {
 			label += " (Tab: 自动补全)"
@@ -611,7 +823,7 @@ func (d *NewDialog) View() string {
 	content.WriteString("\n")
 
 	// Show path suggestions dropdown when path field is focused
-	if d.focusIndex == 1 && len(d.pathSuggestions) > 0 {
+	if d.field() == "path" && len(d.pathSuggestions) > 0 {
 		suggestionStyle := lipgloss.NewStyle().
 			Foreground(ColorComment)
 		selectedStyle := lipgloss.NewStyle().
@@ -675,7 +887,7 @@ func (d *NewDialog) View() string {
 	content.WriteString("\n")
 
 	// Command selection
-	if d.focusIndex == 2 {
+	if d.field() == "command" {
 		content.WriteString(activeLabelStyle.Render("▶ Command:"))
 	} else {
 		content.WriteString(labelStyle.Render("  Command:"))
@@ -719,6 +931,43 @@ func (d *NewDialog) View() string {
 		content.WriteString("\n\n")
 	}
 
+	// Worktree toggle
+	checkbox := "[ ] use the path as is"
+	if d.worktree {
+		checkbox = "[x] new branch and worktree of the path's repository"
+	}
+	if d.field() == "worktree" {
+		content.WriteString(activeLabelStyle.Render("▶ Git worktree: "))
+	} else {
+		content.WriteString(labelStyle.Render("  Git worktree: "))
+	}
+	content.WriteString(labelStyle.Render(checkbox))
+	content.WriteString("\n\n")
+
+	// Claude options (only if claude is selected)
+	if d.claudeSelected() {
+		if d.field() == "claude-flags" {
+			content.WriteString(activeLabelStyle.Render("▶ Claude flags:"))
+		} else {
+			content.WriteString(labelStyle.Render("  Claude flags:"))
+		}
+		content.WriteString("\n  ")
+		content.WriteString(d.claudeFlagsInput.View())
+		content.WriteString("\n\n")
+
+		skipBox := "[ ] ask for permissions"
+		if d.skipPermissions {
+			skipBox = "[x] --dangerously-skip-permissions"
+		}
+		if d.field() == "skip-permissions" {
+			content.WriteString(activeLabelStyle.Render("▶ Skip permissions: "))
+		} else {
+			content.WriteString(labelStyle.Render("  Skip permissions: "))
+		}
+		content.WriteString(labelStyle.Render(skipBox))
+		content.WriteString("\n\n")
+	}
+
 	// Help text with better contrast
 	helpStyle := lipgloss.NewStyle().
 		Foreground(ColorComment). // Use consistent theme color
@@ -737,3 +986,24 @@ func (d *NewDialog) View() string {
 		dialog,
 	)
 }
+
+// templateSummary describes what a template adds beyond the dialog's fields
+func templateSummary(tmpl *session.TemplateDef) string {
+	var parts []string
+	if tmpl.Description != "" {
+		parts = append(parts, tmpl.Description)
+	}
+	if len(tmpl.MCPs) > 0 {
+		parts = append(parts, "MCPs: "+strings.Join(tmpl.MCPs, ", "))
+	}
+	if len(tmpl.Env) > 0 {
+		parts = append(parts, fmt.Sprintf("%d env vars", len(tmpl.Env)))
+	}
+	if tmpl.Prompt != "" {
+		parts = append(parts, "sends a prompt")
+	}
+	if len(parts) == 0 {
+		return "tool: " + tmpl.ToolName()
+	}
+	return strings.Join(parts, " · ")
+}
@@ -2,8 +2,13 @@ package ui
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
 )
 
 func TestNewNewDialog(t *testing.T) {
@@ -233,3 +238,57 @@ func TestNewDialog_MalformedPathFix(t *testing.T) {
 		})
 	}
 }
+
+func TestNewDialog_TemplatePicker(t *testing.T) {
+	// Reload the real config once HOME is restored
+	t.Cleanup(func() { _, _ = session.ReloadUserConfig() })
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	config := `[templates.review]
+tool = "claude"
+group = "reviews"
+prompt = "Review {{branch}}"
+
+[templates.review.claude]
+model = "opus"
+`
+	if err := os.MkdirAll(filepath.Join(home, ".agent-deck"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".agent-deck", "config.toml"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.ReloadUserConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewNewDialog()
+	d.ShowInGroup("work", "work")
+	if d.field() != "name" {
+		t.Errorf("focused field = %q, want name", d.field())
+	}
+	if d.GetTemplate() != nil {
+		t.Error("no template should be selected initially")
+	}
+
+	d.focusIndex = d.fieldIndex("template")
+	d.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if tmpl := d.GetTemplate(); tmpl == nil || tmpl.Prompt != "Review {{branch}}" {
+		t.Fatalf("GetTemplate() = %+v, want review", tmpl)
+	}
+	if _, _, command := d.GetValues(); command != "claude" {
+		t.Errorf("command = %q, want claude", command)
+	}
+	if d.GetSelectedGroup() != "reviews" {
+		t.Errorf("group = %q, want reviews", d.GetSelectedGroup())
+	}
+	if opts, _ := d.GetClaudeOptions(); opts == nil || opts.Model != "opus" {
+		t.Errorf("GetClaudeOptions() = %+v", opts)
+	}
+
+	// Back to no template restores the group the dialog was opened in
+	d.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if d.GetTemplate() != nil || d.GetSelectedGroup() != "work" {
+		t.Errorf("template = %+v, group = %q", d.GetTemplate(), d.GetSelectedGroup())
+	}
+}
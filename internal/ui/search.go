@@ -212,7 +212,7 @@ func (s *Search) View() string {
 		hintStr = lipgloss.NewStyle().
 			Foreground(ColorComment).
 			Italic(true).
-			Render("  Tip: waiting / running / idle to filter by status")
+			Render("  Tip: waiting / approval / running / idle to filter by status")
 	}
 
 	// Keyboard shortcuts hint
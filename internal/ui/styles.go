@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
 )
 
 // Tokyo Night Color Palette
@@ -74,6 +76,10 @@ var (
 			Foreground(ColorYellow).
 			Bold(true)
 
+	ApprovalStyle = lipgloss.NewStyle().
+			Foreground(ColorOrange).
+			Bold(true)
+
 	IdleStyle = lipgloss.NewStyle().
 			Foreground(ColorComment)
 
@@ -190,13 +196,15 @@ func MenuKey(key, description string) string {
 }
 
 // StatusIndicator returns a styled status indicator
-// Standard symbols: ● running, ◐ waiting, ○ idle, ✕ error, ⟳ starting
+// Standard symbols: ● running, ◐ waiting, ◆ approval, ○ idle, ✕ error, ⟳ starting
 func StatusIndicator(status string) string {
 	switch status {
 	case "running":
 		return RunningStyle.Render("●")
 	case "waiting":
 		return WaitingStyle.Render("◐")
+	case "approval":
+		return ApprovalStyle.Render("◆")
 	case "idle":
 		return IdleStyle.Render("○")
 	case "error":
@@ -209,46 +217,18 @@ func StatusIndicator(status string) string {
 }
 
 // ToolIcon returns the icon for a given tool
-// Checks user config for custom tools first, then falls back to built-ins
+// Custom [tools.NAME] icons take precedence over the tool adapter's icon
 func ToolIcon(tool string) string {
-	// Use session.GetToolIcon which handles custom + built-in
-	// Import would be circular, so we duplicate the logic here
-	// Custom icons are handled by the session layer's GetToolDef
-	switch tool {
-	case "claude":
-		return IconClaude
-	case "gemini":
-		return IconGemini
-	case "opencode":
-		return IconOpenCode
-	case "codex":
-		return IconCodex
-	case "cursor":
-		return "📝"
-	case "shell":
-		return IconShell
-	default:
-		return IconShell
-	}
+	return session.GetToolIcon(tool)
 }
 
-// ToolColor returns the brand color for a given tool
-// Claude=orange (Anthropic), Gemini=purple (Google AI), Codex=cyan, Aider=red
+// ToolColor returns the brand color of a tool's adapter
+// (Claude=orange, Gemini=purple, Codex=cyan, Aider=red), gray if it has none
 func ToolColor(tool string) lipgloss.Color {
-	switch tool {
-	case "claude":
-		return ColorOrange // Anthropic's orange
-	case "gemini":
-		return ColorPurple // Google AI purple
-	case "codex":
-		return ColorCyan // Light blue for OpenAI
-	case "aider":
-		return ColorRed // Red for Aider
-	case "cursor":
-		return ColorAccent // Blue for Cursor
-	default:
-		return ColorTextDim // Default gray
+	if color := session.GetToolAdapter(tool).Color(); color != "" {
+		return lipgloss.Color(color)
 	}
+	return ColorTextDim
 }
 
 // List Item Styles (used by legacy list.go component in tests)
@@ -325,6 +305,7 @@ var (
 	// Session status indicator styles
 	SessionStatusRunning = lipgloss.NewStyle().Foreground(ColorGreen)
 	SessionStatusWaiting = lipgloss.NewStyle().Foreground(ColorYellow)
+	SessionStatusApproval = lipgloss.NewStyle().Foreground(ColorOrange).Bold(true)
 	SessionStatusIdle    = lipgloss.NewStyle().Foreground(ColorTextDim)
 	SessionStatusError   = lipgloss.NewStyle().Foreground(ColorRed)
 	SessionStatusSelStyle = lipgloss.NewStyle().Foreground(ColorBg).Background(ColorAccent)
@@ -335,9 +316,15 @@ var (
 	SessionTitleError   = lipgloss.NewStyle().Foreground(ColorText).Underline(true)
 	SessionTitleSelStyle = lipgloss.NewStyle().Bold(true).Foreground(ColorBg).Background(ColorAccent)
 
+	// Worktree branch shown after the title
+	SessionBranchStyle = lipgloss.NewStyle().Foreground(ColorPurple)
+
 	// Selection indicator
 	SessionSelectionPrefix = lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
 
+	// Mark shown before sessions marked for bulk actions
+	SessionMarkedStyle = lipgloss.NewStyle().Foreground(ColorGreen).Bold(true)
+
 	// Group item styles
 	GroupExpandStyle    = lipgloss.NewStyle().Foreground(ColorText)
 	GroupNameStyle      = lipgloss.NewStyle().Bold(true).Foreground(ColorCyan)
@@ -345,6 +332,7 @@ var (
 	GroupHotkeyStyle    = lipgloss.NewStyle().Foreground(ColorComment)
 	GroupStatusRunning  = lipgloss.NewStyle().Foreground(ColorGreen)
 	GroupStatusWaiting  = lipgloss.NewStyle().Foreground(ColorYellow)
+	GroupStatusApproval = lipgloss.NewStyle().Foreground(ColorOrange).Bold(true)
 
 	// Group selected styles
 	GroupNameSelStyle   = lipgloss.NewStyle().Bold(true).Foreground(ColorBg).Background(ColorAccent)
@@ -2,6 +2,8 @@ package ui
 
 import (
 	"testing"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
 func TestColorsDefined(t *testing.T) {
@@ -26,6 +28,7 @@ func TestStatusIndicator(t *testing.T) {
 	}{
 		{"running", "●"},
 		{"waiting", "○"},
+		{"approval", "◆"},
 		{"idle", "◌"},
 		{"error", "✕"},
 		{"unknown", "◌"},
@@ -58,6 +61,26 @@ func TestToolIcon(t *testing.T) {
 	}
 }
 
+func TestToolColor(t *testing.T) {
+	tests := []struct {
+		tool     string
+		expected lipgloss.Color
+	}{
+		{"claude", ColorOrange},
+		{"gemini", ColorPurple},
+		{"codex", ColorCyan},
+		{"aider", ColorRed},
+		{"cursor", ColorAccent},
+		{"shell", ColorTextDim},
+		{"unknown", ColorTextDim},
+	}
+	for _, tt := range tests {
+		if result := ToolColor(tt.tool); result != tt.expected {
+			t.Errorf("ToolColor(%s) = %s, want %s", tt.tool, result, tt.expected)
+		}
+	}
+}
+
 func TestMenuKey(t *testing.T) {
 	result := MenuKey("q", "Quit")
 	if result == "" {
@@ -0,0 +1,447 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// transcriptLineKind is what a line of the transcript viewer shows
+type transcriptLineKind int
+
+const (
+	lineText transcriptLineKind = iota
+	lineUser
+	lineAssistant
+	lineCall
+	lineCallBody
+)
+
+// transcriptLine is a wrapped line of the transcript viewer
+type transcriptLine struct {
+	text string
+	kind transcriptLineKind
+	call int // Tool call of a lineCall header
+}
+
+// TranscriptViewer shows a session's whole conversation: scrollable, with
+// collapsible tool calls and search
+type TranscriptViewer struct {
+	visible    bool
+	width      int
+	height     int
+	transcript *session.Transcript
+
+	// Tool calls, numbered in order of appearance
+	calls    []*session.TranscriptToolCall
+	expanded map[int]bool
+	selected int // Selected tool call (-1: none)
+
+	lines  []transcriptLine
+	scroll int
+
+	// Search within the transcript
+	input     textinput.Model
+	searching bool
+	query     string
+	matches   []int // Lines matching query
+	match     int   // Current match
+}
+
+// NewTranscriptViewer creates a new transcript viewer
+func NewTranscriptViewer() *TranscriptViewer {
+	ti := textinput.New()
+	ti.Placeholder = "Search transcript..."
+	ti.CharLimit = 100
+	ti.Width = 40
+
+	return &TranscriptViewer{
+		input:    ti,
+		expanded: make(map[int]bool),
+		selected: -1,
+	}
+}
+
+// Show opens the viewer on a transcript
+func (v *TranscriptViewer) Show(t *session.Transcript) {
+	v.visible = true
+	v.transcript = t
+	v.calls = nil
+	for i := range t.Messages {
+		for j := range t.Messages[i].ToolCalls {
+			v.calls = append(v.calls, &t.Messages[i].ToolCalls[j])
+		}
+	}
+	v.expanded = make(map[int]bool)
+	v.selected = -1
+	v.scroll = 0
+	v.searching = false
+	v.query = ""
+	v.input.SetValue("")
+	v.input.Blur()
+	v.rebuild()
+}
+
+// Hide hides the viewer
+func (v *TranscriptViewer) Hide() {
+	v.visible = false
+	v.transcript = nil
+	v.calls = nil
+	v.lines = nil
+}
+
+// IsVisible returns whether the viewer is visible
+func (v *TranscriptViewer) IsVisible() bool {
+	return v.visible
+}
+
+// SetSize sets the dimensions of the viewer
+func (v *TranscriptViewer) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+	if v.visible {
+		v.rebuild()
+	}
+}
+
+// contentWidth is the width available to transcript lines
+func (v *TranscriptViewer) contentWidth() int {
+	return max(v.width-8, 20)
+}
+
+// pageHeight is the number of transcript lines shown at once
+func (v *TranscriptViewer) pageHeight() int {
+	return max(v.height-9, 5)
+}
+
+// Update handles messages for the viewer
+func (v *TranscriptViewer) Update(msg tea.Msg) (*TranscriptViewer, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !v.visible || !ok {
+		return v, nil
+	}
+
+	// Typing a search
+	if v.searching {
+		switch keyMsg.String() {
+		case "enter":
+			v.searching = false
+			v.input.Blur()
+			v.jumpToMatch(0)
+		case "esc":
+			v.searching = false
+			v.input.Blur()
+			v.setQuery("")
+		default:
+			var cmd tea.Cmd
+			v.input, cmd = v.input.Update(keyMsg)
+			v.setQuery(v.input.Value())
+			v.jumpToMatch(0)
+			return v, cmd
+		}
+		return v, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "v":
+		if v.query != "" && keyMsg.String() == "esc" {
+			v.setQuery("")
+			return v, nil
+		}
+		v.Hide()
+	case "up", "k":
+		v.scrollBy(-1)
+	case "down", "j":
+		v.scrollBy(1)
+	case "pgup", "ctrl+u":
+		v.scrollBy(-v.pageHeight())
+	case "pgdown", "ctrl+d", " ":
+		v.scrollBy(v.pageHeight())
+	case "g", "home":
+		v.scroll = 0
+	case "G", "end":
+		v.scrollBy(len(v.lines))
+	case "]", "tab":
+		v.selectCall(1)
+	case "[", "shift+tab":
+		v.selectCall(-1)
+	case "enter":
+		if v.selected >= 0 {
+			v.expanded[v.selected] = !v.expanded[v.selected]
+			v.rebuild()
+		}
+	case "t":
+		// Expand all tool calls, or collapse all if all are expanded
+		expand := false
+		for i := range v.calls {
+			if !v.expanded[i] {
+				expand = true
+			}
+		}
+		for i := range v.calls {
+			v.expanded[i] = expand
+		}
+		v.rebuild()
+	case "/":
+		v.searching = true
+		v.input.SetValue(v.query)
+		v.input.Focus()
+	case "n":
+		v.jumpToMatch(v.match + 1)
+	case "N":
+		v.jumpToMatch(v.match - 1)
+	}
+	return v, nil
+}
+
+// scrollBy scrolls by delta lines, within bounds
+func (v *TranscriptViewer) scrollBy(delta int) {
+	v.scroll = max(min(v.scroll+delta, len(v.lines)-v.pageHeight()), 0)
+}
+
+// scrollTo scrolls line into view, with some context above it
+func (v *TranscriptViewer) scrollTo(line int) {
+	if line < v.scroll || line >= v.scroll+v.pageHeight() {
+		v.scroll = 0
+		v.scrollBy(line - 3)
+	}
+}
+
+// selectCall selects the next (1) or previous (-1) tool call
+func (v *TranscriptViewer) selectCall(dir int) {
+	if len(v.calls) == 0 {
+		return
+	}
+	switch {
+	case v.selected < 0 && dir < 0:
+		v.selected = len(v.calls) - 1
+	case v.selected < 0:
+		v.selected = 0
+	default:
+		v.selected = (v.selected + dir + len(v.calls)) % len(v.calls)
+	}
+	for i, line := range v.lines {
+		if line.kind == lineCall && line.call == v.selected {
+			v.scrollTo(i)
+			break
+		}
+	}
+}
+
+// setQuery sets the search query and finds the lines matching it
+func (v *TranscriptViewer) setQuery(query string) {
+	v.query = query
+	v.findMatches()
+}
+
+// findMatches finds the lines containing the query
+func (v *TranscriptViewer) findMatches() {
+	v.matches = nil
+	v.match = 0
+	if v.query == "" {
+		return
+	}
+	query := strings.ToLower(v.query)
+	for i, line := range v.lines {
+		if strings.Contains(strings.ToLower(line.text), query) {
+			v.matches = append(v.matches, i)
+		}
+	}
+}
+
+// jumpToMatch scrolls to the nth match (wrapping around)
+func (v *TranscriptViewer) jumpToMatch(n int) {
+	if len(v.matches) == 0 {
+		return
+	}
+	v.match = (n%len(v.matches) + len(v.matches)) % len(v.matches)
+	v.scrollTo(v.matches[v.match])
+}
+
+// rebuild lays the transcript out in wrapped lines
+func (v *TranscriptViewer) rebuild() {
+	v.lines = nil
+	if v.transcript == nil {
+		return
+	}
+	width := v.contentWidth()
+	add := func(kind transcriptLineKind, text string, indent string) {
+		for _, line := range strings.Split(text, "\n") {
+			for _, w := range wrapTranscriptLine(line, width-len(indent)) {
+				v.lines = append(v.lines, transcriptLine{text: indent + w, kind: kind, call: -1})
+			}
+		}
+	}
+
+	call := 0
+	for _, m := range v.transcript.Messages {
+		header := session.TranscriptRoleLabel(m.Role)
+		if ts := session.FormatTranscriptTime(m.Timestamp); ts != "" {
+			header += " · " + ts
+		}
+		kind := lineAssistant
+		if m.Role == "user" {
+			kind = lineUser
+		}
+		v.lines = append(v.lines, transcriptLine{text: header, kind: kind, call: -1})
+
+		if text := strings.TrimSpace(m.Text); text != "" {
+			add(lineText, text, "  ")
+		}
+		for _, tc := range m.ToolCalls {
+			marker := "▸"
+			if v.expanded[call] {
+				marker = "▾"
+			}
+			title := fmt.Sprintf("  %s 🔧 %s", marker, tc.Name)
+			if tc.Error {
+				title += " (failed)"
+			}
+			if !v.expanded[call] {
+				title += "  " + truncateTranscriptText(tc.Input, width-len(title)-2)
+			}
+			v.lines = append(v.lines, transcriptLine{text: title, kind: lineCall, call: call})
+			if v.expanded[call] {
+				if tc.Input != "" {
+					add(lineCallBody, tc.Input, "      ")
+				}
+				if tc.Output != "" {
+					add(lineCallBody, "→ "+strings.TrimRight(tc.Output, "\n"), "      ")
+				}
+			}
+			call++
+		}
+		v.lines = append(v.lines, transcriptLine{call: -1})
+	}
+
+	v.findMatches()
+	v.scrollBy(0)
+}
+
+// truncateTranscriptText shortens text to one line of at most n bytes
+func truncateTranscriptText(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if n < 4 {
+		return ""
+	}
+	if len(text) > n {
+		return strings.ToValidUTF8(text[:n-3], "") + "..."
+	}
+	return text
+}
+
+// wrapTranscriptLine wraps a line at word boundaries, cutting words longer
+// than the width
+func wrapTranscriptLine(line string, width int) []string {
+	line = strings.TrimRight(strings.ReplaceAll(line, "\t", "    "), " ")
+	if width < 1 || len(line) <= width {
+		return []string{line}
+	}
+	var lines []string
+	for len(line) > width {
+		cut := strings.LastIndexByte(line[:width], ' ')
+		if cut <= 0 {
+			cut = width
+		}
+		lines = append(lines, strings.ToValidUTF8(line[:cut], ""))
+		line = strings.TrimLeft(line[cut:], " ")
+	}
+	return append(lines, line)
+}
+
+// View renders the viewer
+func (v *TranscriptViewer) View() string {
+	if !v.visible || v.transcript == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	// Header
+	t := v.transcript
+	header := globalSearchHeaderStyle.Render(fmt.Sprintf("📜 %s", t.Heading()))
+	info := lipgloss.NewStyle().Foreground(ColorComment).
+		Render(fmt.Sprintf("  %s %s · %d messages · %d tool calls", ToolIcon(t.Tool), t.Tool, len(t.Messages), len(v.calls)))
+	b.WriteString(header + info + "\n\n")
+
+	// Visible lines
+	userStyle := lipgloss.NewStyle().Foreground(ColorGreen).Bold(true)
+	assistantStyle := lipgloss.NewStyle().Foreground(ColorCyan).Bold(true)
+	callStyle := lipgloss.NewStyle().Foreground(ColorPurple)
+	bodyStyle := lipgloss.NewStyle().Foreground(ColorComment)
+	textStyle := lipgloss.NewStyle().Foreground(ColorText)
+
+	end := min(v.scroll+v.pageHeight(), len(v.lines))
+	for i := v.scroll; i < end; i++ {
+		line := v.lines[i]
+		style := textStyle
+		switch line.kind {
+		case lineUser:
+			style = userStyle
+		case lineAssistant:
+			style = assistantStyle
+		case lineCall:
+			style = callStyle
+			if line.call == v.selected {
+				style = globalSelectedStyle.Padding(0)
+			}
+		case lineCallBody:
+			style = bodyStyle
+		}
+		b.WriteString(v.highlight(line.text, style) + "\n")
+	}
+	for i := end - v.scroll; i < v.pageHeight(); i++ {
+		b.WriteString("\n")
+	}
+
+	// Status: search and position
+	var status string
+	switch {
+	case v.searching:
+		status = "/" + v.input.View()
+	case v.query != "" && len(v.matches) == 0:
+		status = fmt.Sprintf("No matches for %q", v.query)
+	case v.query != "":
+		status = fmt.Sprintf("Match %d/%d for %q", v.match+1, len(v.matches), v.query)
+	}
+	position := fmt.Sprintf("%d-%d/%d", min(v.scroll+1, len(v.lines)), end, len(v.lines))
+	padding := max(v.contentWidth()-lipgloss.Width(status)-len(position), 1)
+	b.WriteString(lipgloss.NewStyle().Foreground(ColorComment).Render(status+strings.Repeat(" ", padding)+position) + "\n")
+
+	b.WriteString(lipgloss.NewStyle().Foreground(ColorComment).
+		Render("[↑↓] Scroll  [PgUp/PgDn] Page  [g/G] Top/End  [Tab] Tool call  [Enter] Expand  [t] All  [/] Search  [n/N] Match  [Esc] Close"))
+
+	box := lipgloss.NewStyle().
+		Width(v.contentWidth()+2).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(ColorCyan).
+		Padding(0, 1).
+		Render(b.String())
+	return centerInScreen(box, v.width, v.height)
+}
+
+// highlight renders a line in style, with the search query highlighted
+func (v *TranscriptViewer) highlight(text string, style lipgloss.Style) string {
+	if v.query == "" || text == "" {
+		return style.Render(text)
+	}
+	lower := strings.ToLower(text)
+	query := strings.ToLower(v.query)
+
+	var b strings.Builder
+	for {
+		idx := strings.Index(lower, query)
+		if idx == -1 {
+			b.WriteString(style.Render(text))
+			break
+		}
+		b.WriteString(style.Render(text[:idx]))
+		b.WriteString(highlightStyle.Render(text[idx : idx+len(query)]))
+		text, lower = text[idx+len(query):], lower[idx+len(query):]
+	}
+	return b.String()
+}
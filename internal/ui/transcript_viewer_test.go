@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asheshgoplani/agent-deck/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func testTranscript() *session.Transcript {
+	return &session.Transcript{
+		Tool:      "claude",
+		SessionID: "abc-123",
+		Title:     "api",
+		Messages: []session.TranscriptMessage{
+			{Role: "user", Text: "List the files"},
+			{Role: "assistant", Text: "Let me look.", ToolCalls: []session.TranscriptToolCall{
+				{Name: "Bash", Input: `{"command":"ls"}`, Output: "main.go\nserver.go"},
+			}},
+			{Role: "assistant", Text: "There are two files."},
+		},
+	}
+}
+
+func viewerKey(v *TranscriptViewer, keys ...string) {
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		}
+		v.Update(msg)
+	}
+}
+
+func viewerText(v *TranscriptViewer) string {
+	var texts []string
+	for _, line := range v.lines {
+		texts = append(texts, line.text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+func TestTranscriptViewerToolCalls(t *testing.T) {
+	v := NewTranscriptViewer()
+	v.SetSize(100, 40)
+	v.Show(testTranscript())
+
+	if !v.IsVisible() || len(v.calls) != 1 {
+		t.Fatalf("Expected visible viewer with 1 tool call, got %d", len(v.calls))
+	}
+	if strings.Contains(viewerText(v), "server.go") {
+		t.Error("Tool calls should start collapsed")
+	}
+	if view := v.View(); !strings.Contains(view, "api") || !strings.Contains(view, "Bash") {
+		t.Errorf("View should show the title and tool calls:\n%s", view)
+	}
+
+	viewerKey(v, "tab", "enter")
+	if v.selected != 0 || !strings.Contains(viewerText(v), "server.go") {
+		t.Error("Enter should expand the selected tool call")
+	}
+	viewerKey(v, "enter")
+	if strings.Contains(viewerText(v), "server.go") {
+		t.Error("Enter should collapse the expanded tool call")
+	}
+
+	viewerKey(v, "t")
+	if !strings.Contains(viewerText(v), "server.go") {
+		t.Error("t should expand all tool calls")
+	}
+
+	viewerKey(v, "esc")
+	if v.IsVisible() {
+		t.Error("Esc should close the viewer")
+	}
+}
+
+func TestTranscriptViewerSearch(t *testing.T) {
+	v := NewTranscriptViewer()
+	v.SetSize(100, 40)
+	v.Show(testTranscript())
+
+	viewerKey(v, "/", "f", "i", "l", "e", "s", "enter")
+	if v.searching || v.query != "files" || len(v.matches) != 2 {
+		t.Fatalf("Expected 2 matches for 'files', got query %q, %d matches", v.query, len(v.matches))
+	}
+	viewerKey(v, "n")
+	if v.match != 1 {
+		t.Errorf("n should go to the next match, got %d", v.match)
+	}
+	viewerKey(v, "n")
+	if v.match != 0 {
+		t.Errorf("n should wrap around, got %d", v.match)
+	}
+
+	// Esc clears the search before closing
+	viewerKey(v, "esc")
+	if v.query != "" || !v.IsVisible() {
+		t.Error("Esc should clear the search first")
+	}
+}